@@ -0,0 +1,412 @@
+package main
+
+import (
+	"archive/zip"     // Reading entries of the bundle
+	"bytes"           // Document assembly
+	"encoding/binary" // Varints and fixed-width fields
+	"errors"          // Malformed message marker
+	"fmt"             // Value rendering
+	"math"            // Float values
+	"path"            // Zip entry names
+	"path/filepath"   // Output paths
+	"sort"            // Module listing
+	"strconv"         // Integer values
+	"strings"         // Indentation and module names
+)
+
+// Locations inside an Android App Bundle. Modules keep their manifest and
+// resources as aapt2 protocol buffers (Resources.proto), not binary XML.
+const (
+	bundleBaseModule = "base/"
+	bundleManifest   = "manifest/AndroidManifest.xml"
+	bundleResources  = "resources.pb"
+)
+
+// Protocol buffer wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// errProtobuf is returned for protocol buffer messages that can't be read.
+var errProtobuf = errors.New("malformed protocol buffer")
+
+// protoField is one field of an encoded protocol buffer message.
+type protoField struct {
+	Number int    // Field number
+	Wire   int    // Wire type
+	Value  uint64 // Varint and fixed-width values
+	Bytes  []byte // Length-delimited values: strings, bytes and nested messages
+}
+
+// protoFields splits a protocol buffer message into its fields, in order.
+func protoFields(data []byte) ([]protoField, error) {
+	var fields []protoField
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, errProtobuf
+		}
+		data = data[n:]
+		field := protoField{Number: int(key >> 3), Wire: int(key & 7)}
+		switch field.Wire {
+		case wireVarint:
+			field.Value, n = binary.Uvarint(data)
+			if n <= 0 {
+				return nil, errProtobuf
+			}
+			data = data[n:]
+		case wireFixed64:
+			if len(data) < 8 {
+				return nil, errProtobuf
+			}
+			field.Value, data = binary.LittleEndian.Uint64(data), data[8:]
+		case wireFixed32:
+			if len(data) < 4 {
+				return nil, errProtobuf
+			}
+			field.Value, data = uint64(binary.LittleEndian.Uint32(data)), data[4:]
+		case wireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || length > uint64(len(data)-n) {
+				return nil, errProtobuf
+			}
+			field.Bytes, data = data[n:n+int(length)], data[n+int(length):]
+		default: // Groups are not used by aapt2
+			return nil, errProtobuf
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// protoMessage returns the fields of a message, or none when it is malformed.
+// The decoders below read optional data, so a bad submessage is just absent.
+func protoMessage(data []byte) []protoField {
+	fields, _ := protoFields(data)
+	return fields
+}
+
+// isBundle reports whether a path names an Android App Bundle.
+func isBundle(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), ".aab")
+}
+
+// extractAAB lays out the base module of an Android App Bundle like extractAPK
+// does for an APK: the manifest and res/xml files are converted from aapt2's
+// protocol buffer XML and the default string, bool and integer values come from
+// resources.pb, so no bundletool or Java is needed. Feature modules are left
+// out; a bundle without a base module is an error naming the modules found.
+func extractAAB(aabPath, outputDir string) (string, error) {
+	archive, err := zip.OpenReader(aabPath)
+	if err != nil {
+		return "", err
+	}
+	defer archive.Close()
+	data, err := readZipEntry(&archive.Reader, bundleBaseModule+bundleManifest)
+	if err != nil {
+		if modules := bundleModules(&archive.Reader); len(modules) > 0 {
+			return "", fmt.Errorf("bundle has no base module (%s%s), only: %s", bundleBaseModule, bundleManifest, strings.Join(modules, ", "))
+		}
+		return "", fmt.Errorf("bundle has no base module (%s%s)", bundleBaseModule, bundleManifest)
+	}
+	manifest, err := decodeProtoXML(data)
+	if err != nil {
+		return "", fmt.Errorf("%s%s: %w", bundleBaseModule, bundleManifest, err)
+	}
+	values := resourceValues{}
+	if table, err := readZipEntry(&archive.Reader, bundleBaseModule+bundleResources); err == nil {
+		values = readProtoResourceTable(table)
+	}
+
+	files := extractedFiles(manifest, values)
+	for _, entry := range archive.File {
+		dir, file := path.Split(entry.Name)
+		switch {
+		case dir == bundleBaseModule+"res/xml/" && strings.HasSuffix(file, ".xml"):
+			if content, err := readZipEntry(&archive.Reader, entry.Name); err == nil {
+				if decoded, err := decodeProtoXML(content); err == nil {
+					files[filepath.Join("res", "xml", file)] = decoded
+				}
+			}
+		case dir == "META-INF/" && isSignatureBlock(file):
+			if content, err := readZipEntry(&archive.Reader, entry.Name); err == nil {
+				files[filepath.Join("original", "META-INF", file)] = content
+			}
+		}
+	}
+	return writeExtracted(outputDir, files)
+}
+
+// bundleModules lists the modules of a bundle, those with a manifest.
+func bundleModules(archive *zip.Reader) []string {
+	var modules []string
+	for _, entry := range archive.File {
+		if module, ok := strings.CutSuffix(entry.Name, "/"+bundleManifest); ok && !strings.Contains(module, "/") {
+			modules = append(modules, module)
+		}
+	}
+	sort.Strings(modules)
+	return modules
+}
+
+// protoXMLWriter renders an aapt2 XmlNode tree as XML text.
+type protoXMLWriter struct {
+	out      bytes.Buffer      // XML text
+	prefixes map[string]string // Namespace prefixes by URI
+}
+
+// decodeProtoXML converts an aapt2 protocol buffer XML document (XmlNode) into
+// XML text for the manifest parser. Attributes keep their source value, which
+// aapt2 stores next to the compiled one, so references stay @type/name.
+func decodeProtoXML(data []byte) ([]byte, error) {
+	fields, err := protoFields(data)
+	if err != nil {
+		return nil, err
+	}
+	w := &protoXMLWriter{prefixes: make(map[string]string)}
+	w.out.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n")
+	for _, field := range fields {
+		if field.Number == 1 && field.Wire == wireBytes { // XmlNode.element
+			w.element(field.Bytes, 0)
+			return w.out.Bytes(), nil
+		}
+	}
+	return nil, fmt.Errorf("no root element: %w", errProtobuf)
+}
+
+// element writes one XmlElement with its attributes and children.
+func (w *protoXMLWriter) element(data []byte, depth int) {
+	var name string
+	var declarations, attributes []string
+	var children [][]byte
+	for _, field := range protoMessage(data) {
+		switch field.Number {
+		case 1: // namespace_declaration
+			var prefix, uri string
+			for _, ns := range protoMessage(field.Bytes) {
+				switch ns.Number {
+				case 1:
+					prefix = string(ns.Bytes)
+				case 2:
+					uri = string(ns.Bytes)
+				}
+			}
+			if uri == androidNamespace {
+				prefix = "android"
+			}
+			w.prefixes[uri] = prefix
+			declarations = append(declarations, fmt.Sprintf(" xmlns:%s=\"%s\"", prefix, escapeXML(uri)))
+		case 3:
+			name = string(field.Bytes)
+		case 4:
+			if attribute := w.attribute(field.Bytes); attribute != "" {
+				attributes = append(attributes, attribute)
+			}
+		case 5:
+			children = append(children, field.Bytes)
+		}
+	}
+	indent := strings.Repeat("    ", depth)
+	fmt.Fprintf(&w.out, "%s<%s%s%s", indent, name, strings.Join(declarations, ""), strings.Join(attributes, ""))
+	var elements [][]byte
+	var text strings.Builder
+	for _, child := range children {
+		for _, field := range protoMessage(child) {
+			switch field.Number {
+			case 1: // XmlNode.element
+				elements = append(elements, field.Bytes)
+			case 2: // XmlNode.text
+				text.WriteString(string(field.Bytes))
+			}
+		}
+	}
+	content := strings.TrimSpace(text.String())
+	switch {
+	case len(elements) == 0 && content == "":
+		w.out.WriteString("/>\n")
+	case len(elements) == 0:
+		fmt.Fprintf(&w.out, ">%s</%s>\n", escapeXML(content), name)
+	default:
+		w.out.WriteString(">\n")
+		for _, child := range elements {
+			w.element(child, depth+1)
+		}
+		fmt.Fprintf(&w.out, "%s</%s>\n", indent, name)
+	}
+}
+
+// attribute renders one XmlAttribute as ` prefix:name="value"`.
+func (w *protoXMLWriter) attribute(data []byte) string {
+	var ns, name, value string
+	var id uint32
+	var compiled []byte
+	for _, field := range protoMessage(data) {
+		switch field.Number {
+		case 1:
+			ns = string(field.Bytes)
+		case 2:
+			name = string(field.Bytes)
+		case 3:
+			value = string(field.Bytes)
+		case 5:
+			id = uint32(field.Value)
+		case 6:
+			compiled = field.Bytes
+		}
+	}
+	if known, ok := androidAttributes[id]; ok && (name == "" || ns == androidNamespace) {
+		name = known
+	}
+	if name == "" {
+		return ""
+	}
+	if value == "" && compiled != nil {
+		value = protoItemValue(name, compiled)
+	}
+	if prefix, ok := w.prefixes[ns]; ok && ns != "" {
+		name = prefix + ":" + name
+	}
+	return fmt.Sprintf(" %s=\"%s\"", name, escapeXML(value))
+}
+
+// protoItemValue renders a compiled Item for attributes without a source value.
+func protoItemValue(attrName string, data []byte) string {
+	for _, field := range protoMessage(data) {
+		switch field.Number {
+		case 1: // Reference
+			var name string
+			var id uint64
+			attribute := false
+			for _, ref := range protoMessage(field.Bytes) {
+				switch ref.Number {
+				case 1:
+					attribute = ref.Value == 1
+				case 2:
+					id = ref.Value
+				case 3:
+					name = string(ref.Bytes)
+				}
+			}
+			marker := "@"
+			if attribute {
+				marker = "?"
+			}
+			switch {
+			case name != "":
+				return marker + name
+			case id>>24 == 0x01 && frameworkNames[uint32(id)] != "":
+				return marker + "android:" + frameworkNames[uint32(id)]
+			}
+			return fmt.Sprintf("%s0x%08x", marker, id)
+		case 2, 3, 4: // String, RawString, StyledString
+			for _, str := range protoMessage(field.Bytes) {
+				if str.Number == 1 {
+					return string(str.Bytes)
+				}
+			}
+		case 7: // Primitive
+			return protoPrimitive(attrName, field.Bytes)
+		}
+	}
+	return ""
+}
+
+// protoPrimitive renders a Primitive value like attributeValue does for binary XML.
+func protoPrimitive(attrName string, data []byte) string {
+	for _, field := range protoMessage(data) {
+		switch field.Number {
+		case 3, 4, 5: // float, deprecated dimension and fraction
+			return strconv.FormatFloat(float64(math.Float32frombits(uint32(field.Value))), 'g', -1, 32)
+		case 6:
+			if attrName == "protectionLevel" {
+				return protectionLevelName(uint32(field.Value))
+			}
+			return strconv.Itoa(int(int32(field.Value)))
+		case 7:
+			return "0x" + strconv.FormatUint(uint64(uint32(field.Value)), 16)
+		case 8:
+			return strconv.FormatBool(field.Value != 0)
+		case 9, 10, 11, 12:
+			return fmt.Sprintf("#%08x", uint32(field.Value))
+		}
+	}
+	return ""
+}
+
+// readProtoResourceTable extracts the string, bool and integer values of the
+// default configuration from an aapt2 ResourceTable, keyed like resourceValues.
+// It mirrors readResourceTableNames for resources.arsc.
+func readProtoResourceTable(data []byte) resourceValues {
+	values := resourceValues{}
+	for _, table := range protoMessage(data) {
+		if table.Number != 2 { // ResourceTable.package
+			continue
+		}
+		for _, pkg := range protoMessage(table.Bytes) {
+			if pkg.Number != 3 { // Package.type
+				continue
+			}
+			var typeName string
+			var entries [][]byte
+			for _, field := range protoMessage(pkg.Bytes) {
+				switch field.Number {
+				case 2:
+					typeName = string(field.Bytes)
+				case 3:
+					entries = append(entries, field.Bytes)
+				}
+			}
+			if typeName != "string" && typeName != "bool" && typeName != "integer" {
+				continue
+			}
+			for _, entry := range entries {
+				if name, value, ok := protoDefaultValue(entry); ok {
+					values[typeName+"/"+name] = value
+				}
+			}
+		}
+	}
+	return values
+}
+
+// protoDefaultValue returns the name and default-configuration value of an
+// Entry whose value is a simple item.
+func protoDefaultValue(entry []byte) (string, string, bool) {
+	var name string
+	var value []byte
+	for _, field := range protoMessage(entry) {
+		switch field.Number {
+		case 2:
+			name = string(field.Bytes)
+		case 6: // ConfigValue
+			var config, item []byte
+			for _, part := range protoMessage(field.Bytes) {
+				switch part.Number {
+				case 1:
+					config = part.Bytes
+				case 2: // Value
+					for _, v := range protoMessage(part.Bytes) {
+						if v.Number == 4 { // Value.item
+							item = v.Bytes
+						}
+					}
+				}
+			}
+			if len(config) == 0 && item != nil { // The default configuration is empty
+				value = item
+			}
+		}
+	}
+	if name == "" || value == nil {
+		return "", "", false
+	}
+	for _, field := range protoMessage(value) {
+		if field.Number == 1 { // References have no literal value
+			return "", "", false
+		}
+	}
+	return name, protoItemValue("", value), true
+}
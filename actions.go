@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"     // Output formatting
+	"io"      // Output destination
+	"slices"  // Deduplicating actions
+	"sort"    // Ordering the inventory
+	"strings" // Namespace checks
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// actionInfo summarizes one intent action across the app's filters.
+type actionInfo struct {
+	Action   string `json:"action"`   // Intent action name
+	Handlers int    `json:"handlers"` // Components with a filter for the action
+	Exported bool   `json:"exported"` // Whether any handler is exported
+	Custom   bool   `json:"custom"`   // Defined by the app rather than the platform
+}
+
+// frameworkActionPrefixes are the namespaces of actions defined by the Android
+// platform and Google Play services. Anything else is an app-defined protocol.
+var frameworkActionPrefixes = []string{
+	"android.intent.",
+	"android.provider.",
+	"android.net.",
+	"android.bluetooth.",
+	"android.nfc.",
+	"android.app.",
+	"android.appwidget.",
+	"android.accounts.",
+	"android.media.",
+	"android.hardware.",
+	"android.location.",
+	"android.telephony.",
+	"android.telecom.",
+	"android.service.",
+	"android.settings.",
+	"android.speech.",
+	"android.accessibilityservice.",
+	"android.view.",
+	"android.os.",
+	"android.content.",
+	"android.search.",
+	"android.security.",
+	"android.credentials.",
+	"com.google.android.c2dm.",
+	"com.google.android.gms.",
+	"com.google.firebase.",
+}
+
+// isCustomAction reports whether an action is app-defined rather than a framework one.
+func isCustomAction(action string) bool {
+	for _, prefix := range frameworkActionPrefixes {
+		if strings.HasPrefix(action, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// customActions returns the app-defined actions handled by a component.
+func customActions(component App) []string {
+	var actions []string
+	for _, filter := range component.Filters {
+		for _, action := range filter.Actions {
+			if isCustomAction(action.Name) && !slices.Contains(actions, action.Name) {
+				actions = append(actions, action.Name)
+			}
+		}
+	}
+	return actions
+}
+
+// collectActions lists every distinct action of the app's intent filters, custom
+// actions first and each group sorted by name.
+func collectActions(manifest Manifest) []actionInfo {
+	index := make(map[string]*actionInfo)
+	for _, group := range componentGroups(manifest) {
+		for _, component := range group.Components {
+			exported, _ := isExported(component, group.Kind)
+			seen := make(map[string]bool) // Count each component once per action
+			for _, filter := range component.Filters {
+				for _, action := range filter.Actions {
+					if seen[action.Name] {
+						continue
+					}
+					seen[action.Name] = true
+					info := index[action.Name]
+					if info == nil {
+						info = &actionInfo{Action: action.Name, Custom: isCustomAction(action.Name)}
+						index[action.Name] = info
+					}
+					info.Handlers++
+					info.Exported = info.Exported || exported
+				}
+			}
+		}
+	}
+
+	actions := make([]actionInfo, 0, len(index))
+	for _, info := range index {
+		actions = append(actions, *info)
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		if actions[i].Custom != actions[j].Custom {
+			return actions[i].Custom
+		}
+		return actions[i].Action < actions[j].Action
+	})
+	return actions
+}
+
+// printActions renders the per-app action inventory.
+func printActions(w io.Writer, actions []actionInfo) {
+	green := color.New(color.FgGreen).SprintFunc()
+	printSection(w, "\nActions:")
+	for _, info := range actions {
+		exported := "not exported"
+		if info.Exported {
+			exported = "exported"
+		}
+		fmt.Fprintf(w, "  %s (%d handler(s), %s)\n", green(info.Action), info.Handlers, exported)
+	}
+}
+
+// printActionIndex renders the cross-app action index of a batch run: every
+// action with the apps handling it, custom actions first.
+func printActionIndex(w io.Writer, reports []*report) {
+	apps := make(map[string][]string)
+	custom := make(map[string]bool)
+	for _, r := range reports {
+		for _, info := range r.Actions {
+			apps[info.Action] = append(apps[info.Action], r.Package)
+			custom[info.Action] = info.Custom
+		}
+	}
+	actions := sortedKeys(apps)
+	sort.SliceStable(actions, func(i, j int) bool { return custom[actions[i]] && !custom[actions[j]] })
+
+	green := color.New(color.FgGreen).SprintFunc()
+	printSection(w, "\nAction index:")
+	for _, action := range actions {
+		fmt.Fprintf(w, "  %s — %s\n", green(action), strings.Join(apps[action], ", "))
+	}
+}
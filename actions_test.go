@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"   // Rendered output
+	"slices"  // Result comparison
+	"strings" // Output checks
+	"testing" // Test harness
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// actionsManifest declares custom and framework actions across exported and
+// non-exported components, with SYNC handled by two of them.
+const actionsManifest = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="org.example.actions">
+    <application>
+        <activity android:name=".Main" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.MAIN"/>
+                <category android:name="android.intent.category.LAUNCHER"/>
+            </intent-filter>
+        </activity>
+        <activity android:name=".Hidden" android:exported="false">
+            <intent-filter>
+                <action android:name="org.example.action.HIDDEN"/>
+            </intent-filter>
+        </activity>
+        <service android:name=".SyncService" android:exported="true">
+            <intent-filter>
+                <action android:name="org.example.action.SYNC"/>
+            </intent-filter>
+            <intent-filter>
+                <action android:name="org.example.action.SYNC"/>
+            </intent-filter>
+        </service>
+        <service android:name=".JobService" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.BOOT_COMPLETED"/>
+            </intent-filter>
+        </service>
+        <receiver android:name=".SyncReceiver" android:exported="true">
+            <intent-filter>
+                <action android:name="org.example.action.SYNC"/>
+                <action android:name="com.google.android.c2dm.intent.RECEIVE"/>
+            </intent-filter>
+        </receiver>
+    </application>
+</manifest>
+`
+
+func TestCollectActions(t *testing.T) {
+	r := analyzeFixture(t, actionsManifest, "<resources/>\n")
+	want := []actionInfo{
+		{Action: "org.example.action.HIDDEN", Handlers: 1, Exported: false, Custom: true},
+		{Action: "org.example.action.SYNC", Handlers: 2, Exported: true, Custom: true}, // Two filters of one service count once
+		{Action: "android.intent.action.BOOT_COMPLETED", Handlers: 1, Exported: true},
+		{Action: "android.intent.action.MAIN", Handlers: 1, Exported: true},
+		{Action: "com.google.android.c2dm.intent.RECEIVE", Handlers: 1, Exported: true},
+	}
+	if !slices.Equal(r.Actions, want) {
+		t.Errorf("actions:\n got %+v\nwant %+v", r.Actions, want)
+	}
+}
+
+func TestPrintActionIndex(t *testing.T) {
+	reports := []*report{
+		{Package: "org.example.one", Actions: []actionInfo{{Action: "org.example.action.SYNC", Custom: true}, {Action: "android.intent.action.VIEW"}}},
+		{Package: "org.example.two", Actions: []actionInfo{{Action: "android.intent.action.VIEW"}, {Action: "com.vendor.action.PING", Custom: true}}},
+	}
+	var out bytes.Buffer
+	printActionIndex(&out, reports)
+	want := []string{
+		"Action index:",
+		"  com.vendor.action.PING — org.example.two",
+		"  org.example.action.SYNC — org.example.one",
+		"  android.intent.action.VIEW — org.example.one, org.example.two",
+	}
+	if got := strings.Split(strings.TrimSpace(out.String()), "\n"); !slices.Equal(got, want) {
+		t.Errorf("action index:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestIsCustomAction(t *testing.T) {
+	for action, custom := range map[string]bool{
+		"android.intent.action.VIEW":             false,
+		"android.net.conn.CONNECTIVITY_CHANGE":   false,
+		"android.bluetooth.device.action.FOUND":  false,
+		"com.google.android.c2dm.intent.RECEIVE": false,
+		"com.google.firebase.MESSAGING_EVENT":    false,
+		"org.example.action.SYNC":                true,
+		"android.intentional.action.LOOKALIKE":   true, // Prefixes match whole namespace segments
+		"com.example.android.intent.action.MAIN": true,
+		"":                                       true,
+	} {
+		if got := isCustomAction(action); got != custom {
+			t.Errorf("isCustomAction(%q) = %t, want %t", action, got, custom)
+		}
+	}
+}
+
+func TestCustomActionsWeighServices(t *testing.T) {
+	r := analyzeFixture(t, actionsManifest, "<resources/>\n")
+	severities := make(map[string]string)
+	for _, f := range r.Findings {
+		if f.Rule == "exported-service" {
+			severities[f.Component] = f.Severity
+		}
+	}
+	if severities[".SyncService"] != "high" || severities[".JobService"] != "low" {
+		t.Errorf("service severities %v, want high for the custom action and low for framework actions only", severities)
+	}
+}
+
+func TestCustomActionsOnly(t *testing.T) {
+	o := textOptions()
+	o.CustomActionsOnly = true
+	setOpts(t, o)
+	_, text := renderFixture(t, actionsManifest, "<resources/>\n")
+	for _, name := range []string{".SyncService", ".SyncReceiver"} {
+		if !strings.Contains(text, name+" (exported") {
+			t.Errorf("-custom-actions-only dropped %s, which handles a custom action", name)
+		}
+	}
+	for _, name := range []string{".Main", ".JobService"} {
+		if strings.Contains(text, name+" (exported") {
+			t.Errorf("-custom-actions-only kept %s, which handles framework actions only:\n%s", name, text)
+		}
+	}
+}
+
+func TestCustomActionsHighlighted(t *testing.T) {
+	setOpts(t, textOptions())
+	color.NoColor = false
+	t.Cleanup(func() { color.NoColor = true })
+	_, text := renderFixture(t, actionsManifest, "<resources/>\n")
+	magenta := color.New(color.FgMagenta).Sprint("org.example.action.SYNC")
+	if !strings.Contains(text, "  "+magenta+"\n") {
+		t.Errorf("custom action not shown in magenta:\n%q", text)
+	}
+	if strings.Contains(text, color.New(color.FgMagenta).Sprint("android.intent.action.BOOT_COMPLETED")) {
+		t.Error("framework action shown in the custom action color")
+	}
+}
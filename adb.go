@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bufio"   // Parsing `adb devices` output
+	"bytes"   // Capturing adb stderr
+	"errors"  // Dry-run sentinel
+	"fmt"     // Error formatting
+	"os/exec" // External command execution
+	"strings" // Output parsing
+	"time"    // Dry-run timeline
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// errDryRun is returned for every device command under -dry-run. Its message is
+// what reports show in place of a result.
+var errDryRun = errors.New("not executed (dry run)")
+
+// adbDevice is one line of `adb devices`.
+type adbDevice struct {
+	Serial string // Device serial, as accepted by adb -s
+	State  string // device, unauthorized, offline, ...
+}
+
+// adbClient runs adb against a single, verified device. Every feature that
+// talks to a device goes through it so device selection and error reporting
+// stay consistent.
+type adbClient struct {
+	serial  string        // Serial passed to every command with -s
+	dryRun  bool          // Print commands instead of running them
+	elapsed time.Duration // Planned time into the dry run, advanced by wait
+}
+
+// newADB selects the device adb commands will target. An explicit serial must
+// be attached and ready; without one, exactly one ready device must be attached.
+// Under -dry-run no device is needed and nothing is checked.
+func newADB(serial string) (*adbClient, error) {
+	if opts.DryRun {
+		color.Yellow("Dry run: adb commands are printed, not executed.")
+		return &adbClient{serial: serial, dryRun: true}, nil
+	}
+	if _, err := exec.LookPath("adb"); err != nil {
+		return nil, fmt.Errorf("adb not found in PATH; install the Android platform tools")
+	}
+	devices, err := adbDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	if serial != "" {
+		for _, device := range devices {
+			if device.Serial == serial {
+				if err := deviceReady(device); err != nil {
+					return nil, err
+				}
+				return &adbClient{serial: serial}, nil
+			}
+		}
+		return nil, fmt.Errorf("device %s is not attached (adb devices lists %d)", serial, len(devices))
+	}
+
+	switch len(devices) {
+	case 0:
+		return nil, fmt.Errorf("no device attached; connect one or start an emulator")
+	case 1:
+		if err := deviceReady(devices[0]); err != nil {
+			return nil, err
+		}
+		return &adbClient{serial: devices[0].Serial}, nil
+	default:
+		serials := make([]string, len(devices))
+		for i, device := range devices {
+			serials[i] = device.Serial
+		}
+		return nil, fmt.Errorf("%d devices attached (%s); choose one with -serial", len(devices), strings.Join(serials, ", "))
+	}
+}
+
+// adbDevices lists the attached devices with their states.
+func adbDevices() ([]adbDevice, error) {
+	output, err := exec.Command("adb", "devices").Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing devices: %w", err)
+	}
+	var devices []adbDevice
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "*") || fields[0] == "List" {
+			continue // Header and daemon start-up chatter
+		}
+		devices = append(devices, adbDevice{Serial: fields[0], State: fields[1]})
+	}
+	return devices, nil
+}
+
+// deviceReady explains why a device cannot take commands, if it can't.
+func deviceReady(device adbDevice) error {
+	switch device.State {
+	case "device":
+		return nil
+	case "unauthorized":
+		return fmt.Errorf("device %s is unauthorized; accept the USB debugging prompt on the device", device.Serial)
+	case "offline":
+		return fmt.Errorf("device %s is offline; reconnect it or restart the adb server", device.Serial)
+	default:
+		return fmt.Errorf("device %s is not ready (state %s)", device.Serial, device.State)
+	}
+}
+
+// run executes an adb command on the selected device and returns its stdout.
+// Failures carry the last line adb printed on stderr.
+// Under -dry-run the command is printed with its planned start time instead
+// and errDryRun is returned.
+func (a *adbClient) run(args ...string) (string, error) {
+	if a.serial != "" {
+		args = append([]string{"-s", a.serial}, args...)
+	}
+	if a.dryRun {
+		fmt.Fprintf(color.Output, "[t+%s] adb %s\n", a.elapsed, strings.Join(args, " "))
+		return "", errDryRun
+	}
+	cmd := exec.Command("adb", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return string(output), fmt.Errorf("adb %s: %w: %s", strings.Join(args, " "), err, lastLine(message))
+		}
+		return string(output), fmt.Errorf("adb %s: %w", strings.Join(args, " "), err)
+	}
+	return string(output), nil
+}
+
+// requirePackage fails unless the package is installed on the device, so
+// intents are never fired at an app that isn't there.
+func (a *adbClient) requirePackage(pkg string) error {
+	output, err := a.run("shell", "pm", "path", pkg)
+	if errors.Is(err, errDryRun) {
+		return nil // Assume it is installed so the rest of the plan is printed
+	}
+	if err != nil || !strings.Contains(output, "package:") {
+		return fmt.Errorf("package %s is not installed on device %s", pkg, a.serial)
+	}
+	return nil
+}
+
+// wait pauses between device commands, e.g. to let an activity settle. Under
+// -dry-run it only advances the planned timeline shown next to each command.
+func (a *adbClient) wait(d time.Duration) {
+	if a.dryRun {
+		a.elapsed += d
+		return
+	}
+	time.Sleep(d)
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"         // Captured dry-run plan
+	"os"            // Fake adb script, invocation log
+	"path/filepath" // Fixture paths
+	"strings"       // Output checks
+	"testing"       // Test harness
+	"time"          // Dry-run timeline
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// fakeADBScript stands in for adb. It lists $FAKE_ADB_DEVICES, knows the
+// packages in $FAKE_ADB_PACKAGES, logs every invocation to $FAKE_ADB_LOG and
+// answers am like a device: intents for nowhere.example don't resolve.
+const fakeADBScript = `#!/bin/sh
+echo "$*" >> "$FAKE_ADB_LOG"
+if [ "$*" = devices ]; then
+	printf '* daemon not running; starting now at tcp:5037\n* daemon started successfully\nList of devices attached\n%b' "$FAKE_ADB_DEVICES"
+	exit 0
+fi
+[ "$1" = -s ] && shift 2
+if [ "$1 $2 $3" = "shell pm path" ]; then
+	for p in $FAKE_ADB_PACKAGES; do
+		[ "$p" = "$4" ] && echo "package:/data/app/$p/base.apk"
+	done
+	exit 0
+fi
+case "$*" in
+*nowhere.example*) echo "Starting: Intent { act=android.intent.action.VIEW }"; echo "Error: Activity not started, unable to resolve Intent" ;;
+*gone.example*) echo "error: device offline" >&2; exit 1 ;;
+*) echo "Status: ok" ;;
+esac
+`
+
+// fakeADB puts the fake adb first on PATH with the devices and packages given
+// and returns the file its invocations are logged to.
+func fakeADB(t *testing.T, devices, packages string) string {
+	t.Helper()
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "adb"), []byte(fakeADBScript), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	log := filepath.Join(t.TempDir(), "adb.log")
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("FAKE_ADB_DEVICES", devices)
+	t.Setenv("FAKE_ADB_PACKAGES", packages)
+	t.Setenv("FAKE_ADB_LOG", log)
+	saved := launchInterval
+	launchInterval = 0
+	t.Cleanup(func() { launchInterval = saved })
+	return log
+}
+
+// adbLog returns the logged adb invocations, one per line.
+func adbLog(t *testing.T, log string) []string {
+	t.Helper()
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestNewADB(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		devices string
+		serial  string
+		want    string // Selected serial
+		wantErr string // Part of the error, empty when a device is selected
+	}{
+		{name: "one device", devices: `emulator-5554\tdevice\n`, want: "emulator-5554"},
+		{name: "none", devices: "", wantErr: "no device attached"},
+		{name: "several without serial", devices: `emulator-5554\tdevice\nR58M123\tdevice\n`, wantErr: "2 devices attached (emulator-5554, R58M123); choose one with -serial"},
+		{name: "several with serial", devices: `emulator-5554\tdevice\nR58M123\tdevice\n`, serial: "R58M123", want: "R58M123"},
+		{name: "serial not attached", devices: `emulator-5554\tdevice\n`, serial: "R58M123", wantErr: "device R58M123 is not attached"},
+		{name: "unauthorized", devices: `R58M123\tunauthorized\n`, wantErr: "accept the USB debugging prompt"},
+		{name: "offline", devices: `emulator-5554\toffline\n`, wantErr: "device emulator-5554 is offline"},
+		{name: "chosen device offline", devices: `emulator-5554\tdevice\nR58M123\toffline\n`, serial: "R58M123", wantErr: "device R58M123 is offline"},
+		{name: "unknown state", devices: `R58M123\trecovery\n`, wantErr: "not ready (state recovery)"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeADB(t, tc.devices, "")
+			setOpts(t, options{})
+			device, err := newADB(tc.serial)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("newADB(%q) error = %v, want one containing %q", tc.serial, err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if device.serial != tc.want {
+				t.Errorf("selected %s, want %s", device.serial, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewADBMissing(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	setOpts(t, options{})
+	if _, err := newADB(""); err == nil || !strings.Contains(err.Error(), "adb not found in PATH") {
+		t.Errorf("error = %v, want adb not found", err)
+	}
+}
+
+// launchCases are intent test cases of org.example, one of which doesn't
+// resolve on the device and one whose adb call fails, plus a provider case
+// -launch leaves alone.
+func launchCases() []testCase {
+	return []testCase{
+		newTestCase("activity", "https://example.com/a", "android.intent.action.VIEW", "", "org.example", "org.example.Main"),
+		newTestCase("receiver", "sync://run", "org.example.SYNC", "", "org.example", "org.example.SyncReceiver"),
+		newTestCase("activity", "https://nowhere.example/x", "android.intent.action.VIEW", "", "org.example", "org.example.Main"),
+		newTestCase("activity", "https://gone.example/x", "android.intent.action.VIEW", "", "org.example", "org.example.Main"),
+		{URI: "content://org.example.files/", Package: "org.example", Component: "org.example.Files", ADB: "adb shell content query --uri content://org.example.files/"},
+	}
+}
+
+func TestLaunchTestCases(t *testing.T) {
+	log := fakeADB(t, `emulator-5554\tdevice\n`, "com.other org.example")
+	setOpts(t, options{})
+	device, err := newADB("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := launchCases()
+	launchTestCases(device, "org.example", cases)
+
+	for i, want := range []string{"ok", "ok", "Error: Activity not started, unable to resolve Intent", "error: device offline", ""} {
+		if !strings.Contains(cases[i].Result, want) || (want == "") != (cases[i].Result == "") {
+			t.Errorf("case %d (%s) result = %q, want %q", i, cases[i].URI, cases[i].Result, want)
+		}
+	}
+	calls := adbLog(t, log)
+	want := []string{
+		"devices",
+		"-s emulator-5554 shell pm path org.example",
+		"-s emulator-5554 shell am start -W -a android.intent.action.VIEW -d https://example.com/a -n org.example/org.example.Main",
+		"-s emulator-5554 shell am broadcast -a org.example.SYNC -d sync://run -n org.example/org.example.SyncReceiver",
+		"-s emulator-5554 shell am start -W -a android.intent.action.VIEW -d https://nowhere.example/x -n org.example/org.example.Main",
+		"-s emulator-5554 shell am start -W -a android.intent.action.VIEW -d https://gone.example/x -n org.example/org.example.Main",
+	}
+	if got := strings.Join(calls, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("adb invocations:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+}
+
+func TestLaunchTestCasesNotInstalled(t *testing.T) {
+	log := fakeADB(t, `emulator-5554\tdevice\n`, "com.other")
+	setOpts(t, options{})
+	device, err := newADB("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := launchCases()
+	launchTestCases(device, "org.example", cases)
+	for _, c := range cases[:4] {
+		if c.Result != "package org.example is not installed on device emulator-5554" {
+			t.Errorf("%s result = %q, want the package to be reported missing", c.URI, c.Result)
+		}
+	}
+	for _, call := range adbLog(t, log) {
+		if strings.Contains(call, " am ") {
+			t.Errorf("fired %q at a device without the package", call)
+		}
+	}
+}
+
+// TestRunLaunchWithoutDevice checks -launch fails up front, before analyzing
+// anything, when no device is attached.
+func TestRunLaunchWithoutDevice(t *testing.T) {
+	fakeADB(t, "", "")
+	setOpts(t, options{Format: "text", Launch: true, Folder: filepath.Join(t.TempDir(), "missing")})
+	if code := run(); code != 1 {
+		t.Errorf("run() = %d, want 1", code)
+	}
+}
+
+// TestLaunchDryRun checks -dry-run needs no adb at all, prints every command
+// on the planned timeline and marks each result as not executed.
+func TestLaunchDryRun(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	setOpts(t, options{DryRun: true})
+	var plan bytes.Buffer
+	saved := color.Output
+	color.Output = &plan
+	t.Cleanup(func() { color.Output = saved })
+
+	device, err := newADB("R58M123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	cases := launchCases()
+	launchTestCases(device, "org.example", cases)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("dry run took %s; waits should only advance the timeline", elapsed)
+	}
+
+	for _, c := range cases[:4] {
+		if c.Result != "not executed (dry run)" {
+			t.Errorf("%s result = %q, want not executed", c.URI, c.Result)
+		}
+	}
+	if cases[4].Result != "" {
+		t.Errorf("provider case result = %q, want none", cases[4].Result)
+	}
+	want := []string{
+		"[t+0s] adb -s R58M123 shell pm path org.example",
+		"[t+0s] adb -s R58M123 shell am start -W -a android.intent.action.VIEW -d https://example.com/a -n org.example/org.example.Main",
+		"[t+2s] adb -s R58M123 shell am broadcast -a org.example.SYNC -d sync://run -n org.example/org.example.SyncReceiver",
+		"[t+4s] adb -s R58M123 shell am start -W -a android.intent.action.VIEW -d https://nowhere.example/x -n org.example/org.example.Main",
+		"[t+6s] adb -s R58M123 shell am start -W -a android.intent.action.VIEW -d https://gone.example/x -n org.example/org.example.Main",
+	}
+	for _, line := range want {
+		if !strings.Contains(plan.String(), line+"\n") {
+			t.Errorf("dry-run plan lacks %q:\n%s", line, plan.String())
+		}
+	}
+
+	var text bytes.Buffer
+	printLaunchResults(&text, cases)
+	if !strings.Contains(text.String(), "https://example.com/a android.intent.action.VIEW org.example.Main -> not executed (dry run)") {
+		t.Errorf("launch results don't mark the dry run:\n%s", text.String())
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bytes"         // Capturing apktool stderr
+	"fmt"           // Error formatting
+	"os"            // Cleaning partial output
+	"os/exec"       // External command execution
+	"path/filepath" // Output directory naming
+	"regexp"        // Framework package id extraction
+	"strconv"       // Version parsing
+	"strings"       // Output directory naming and stderr matching
+	"time"          // Retry backoff
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// retryBackoff is the delay before the first retry; it doubles on each attempt.
+// Tests shorten it.
+var retryBackoff = 2 * time.Second
+
+// permanentFailures are apktool stderr fragments for inputs that will never
+// decompile, no matter how often we retry.
+var permanentFailures = []string{
+	"zip END header not found",
+	"java.util.zip.ZipException",
+	"is not a valid zip file",
+	"was not found or was not readable",
+	"UnsupportedClassVersionError",
+}
+
+// missingFramework matches apktool's complaint about an uninstalled framework
+// package, "Can't find framework resources for package of id: 2" raised as a
+// CantFindFrameworkResException, capturing the package id it needs (1 is
+// framework-res.apk itself, 2 and up are OEM and carrier packages).
+var missingFramework = regexp.MustCompile(`CantFindFrameworkResException|Can't find framework resources for package of id: (\d+)`)
+
+// decompiledDir names the folder an APK or bundle is decompiled or extracted
+// into, next to the input.
+func decompiledDir(apkPath string) string {
+	name := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(apkPath), ".apk"), ".aab")
+	return filepath.Join(filepath.Dir(apkPath), name+"_decompiled")
+}
+
+// Uses apktool to decompile an APK file to a specified output directory.
+// apktool's stderr is captured and included in the returned error.
+func decompileAPK(apkPath string) (string, error) {
+	outputDir := decompiledDir(apkPath) // Naming the output directory
+	args := []string{"d", apkPath, "-o", outputDir, "-f"}
+	if opts.FrameworkDir != "" { // Frameworks installed outside apktool's default directory
+		args = append(args, "-p", opts.FrameworkDir)
+	}
+	cmd := exec.Command("apktool", args...) // Constructing the apktool command
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run() // Executing the command
+	if err != nil {
+		return outputDir, &apktoolError{err: err, stderr: strings.TrimSpace(stderr.String())} // Error handling for command execution failure
+	}
+	return outputDir, nil // Successful decompilation returns the output directory
+}
+
+// apktoolError is a failed apktool run together with what it printed on stderr.
+type apktoolError struct {
+	err    error
+	stderr string
+}
+
+func (e *apktoolError) Error() string {
+	if e.stderr == "" {
+		return e.err.Error()
+	}
+	if id, ok := e.missingFramework(); ok {
+		if id == "" {
+			id = "of an unknown id"
+		}
+		return fmt.Sprintf("this APK requires framework package %s, which apktool doesn't have; install the device's framework with \"apktool if framework-res.apk\" (and the OEM package for ids above 1), pass -framework-dir if it lives elsewhere, or use -framework <file> -install-framework", id)
+	}
+	return fmt.Sprintf("%s: %s", e.err, lastLine(e.stderr))
+}
+
+func (e *apktoolError) Unwrap() error { return e.err }
+
+// missingFramework reports whether apktool failed for want of a framework
+// package and returns its id, empty when stderr doesn't name it.
+func (e *apktoolError) missingFramework() (string, bool) {
+	var id string
+	matches := missingFramework.FindAllStringSubmatch(e.stderr, -1)
+	for _, match := range matches {
+		if match[1] != "" {
+			id = match[1]
+		}
+	}
+	return id, len(matches) > 0
+}
+
+// installFramework runs "apktool if" so apktool can decode APKs that depend on
+// the framework package, into -framework-dir when it is set.
+func installFramework(path string) error {
+	args := []string{"if", path}
+	if opts.FrameworkDir != "" {
+		args = append(args, "-p", opts.FrameworkDir)
+	}
+	cmd := exec.Command("apktool", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return &apktoolError{err: err, stderr: strings.TrimSpace(stderr.String())}
+	}
+	return nil
+}
+
+// permanent reports whether the captured stderr shows the input can never
+// decompile, a missing framework included: retrying won't install it.
+func (e *apktoolError) permanent() bool {
+	if _, missing := e.missingFramework(); missing {
+		return true
+	}
+	for _, fragment := range permanentFailures {
+		if strings.Contains(e.stderr, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// decompileWithRetry runs decompileAPK, retrying transient failures up to
+// -retries times with exponential backoff. The partial output directory is
+// removed before every retry. It returns the number of attempts made.
+func decompileWithRetry(apkPath string) (string, int, error) {
+	backoff := retryBackoff
+	for attempt := 1; ; attempt++ {
+		outputDir, err := decompileAPK(apkPath)
+		if err == nil {
+			return outputDir, attempt, nil
+		}
+		apkErr, ok := err.(*apktoolError)
+		if attempt > opts.Retries || (ok && apkErr.permanent()) {
+			return "", attempt, err
+		}
+		os.RemoveAll(outputDir) // Start the retry from a clean slate
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// lastLine returns the last non-empty line of s, which for apktool is usually the cause.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// minAPKToolVersion is the oldest apktool known to decode current manifests correctly.
+const minAPKToolVersion = "2.9.0"
+
+// apktoolVersion runs `apktool --version` and returns the reported version.
+func apktoolVersion() (string, error) {
+	output, err := exec.Command("apktool", "--version").Output()
+	if err != nil {
+		return "", err
+	}
+	version := strings.TrimPrefix(lastLine(string(output)), "v")
+	if version == "" {
+		return "", fmt.Errorf("apktool printed no version")
+	}
+	return version, nil
+}
+
+// checkAPKToolVersion warns when the installed apktool is older than the
+// recommended minimum. With -require-apktool-version set, a version below the
+// required one (or an unreadable version) is returned as an error instead.
+func checkAPKToolVersion() error {
+	version, err := apktoolVersion()
+	if err != nil {
+		if opts.RequireAPKTool != "" {
+			return fmt.Errorf("could not determine apktool version: %w", err)
+		}
+		return nil // The decompile itself will report a missing apktool
+	}
+	if opts.RequireAPKTool != "" {
+		if compareVersions(version, opts.RequireAPKTool) < 0 {
+			return fmt.Errorf("apktool %s is older than the required %s", version, opts.RequireAPKTool)
+		}
+		return nil
+	}
+	if compareVersions(version, minAPKToolVersion) < 0 {
+		color.Yellow("Warning: apktool %s is older than %s and may mis-decode newer manifests.", version, minAPKToolVersion)
+	}
+	return nil
+}
+
+// compareVersions compares dotted numeric versions such as "2.9.3" and returns
+// -1, 0 or 1. Non-numeric suffixes like "-dirty" are ignored.
+func compareVersions(a, b string) int {
+	partsA, partsB := versionParts(a), versionParts(b)
+	for i := range max(len(partsA), len(partsB)) {
+		var x, y int
+		if i < len(partsA) {
+			x = partsA[i]
+		}
+		if i < len(partsB) {
+			y = partsB[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionParts splits the leading numeric components out of a version string.
+func versionParts(version string) []int {
+	var parts []int
+	for _, field := range strings.Split(strings.TrimPrefix(version, "v"), ".") {
+		digits := field
+		if end := strings.IndexFunc(field, func(r rune) bool { return r < '0' || r > '9' }); end >= 0 {
+			digits = field[:end]
+		}
+		number, err := strconv.Atoi(digits)
+		if err != nil {
+			break
+		}
+		parts = append(parts, number)
+		if len(digits) != len(field) {
+			break // Stop at suffixes such as "3-dirty"
+		}
+	}
+	return parts
+}
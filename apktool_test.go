@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"        // Error inspection
+	"os"            // Fake apktool script
+	"path/filepath" // Fixture paths
+	"runtime"       // Shell script availability
+	"strconv"       // Failure count in the script
+	"strings"       // Error messages
+	"testing"       // Test harness
+	"time"          // Shortened backoff
+)
+
+// fakeAPKTool puts an apktool shell script on PATH that fails the first
+// failures runs, printing stderr and leaving partial output behind, and then
+// succeeds. It returns the file counting the runs.
+func fakeAPKTool(t *testing.T, failures int, stderr string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake apktool is a shell script")
+	}
+	bin := t.TempDir()
+	runs := filepath.Join(bin, "runs")
+	script := `#!/bin/sh
+# Invoked as: apktool d <apk> -o <dir> -f
+out="$4"
+echo run >> "` + runs + `"
+if [ "$(wc -l < "` + runs + `")" -le ` + strconv.Itoa(failures) + ` ]; then
+	mkdir -p "$out" && touch "$out/partial"
+	echo "` + stderr + `" >&2
+	exit 1
+fi
+mkdir -p "$out" && echo '<manifest/>' > "$out/AndroidManifest.xml"
+`
+	if err := os.WriteFile(filepath.Join(bin, "apktool"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	saved := retryBackoff
+	retryBackoff = time.Millisecond
+	t.Cleanup(func() { retryBackoff = saved })
+	return runs
+}
+
+// countRuns returns how often the fake apktool ran.
+func countRuns(t *testing.T, runs string) int {
+	t.Helper()
+	data, err := os.ReadFile(runs)
+	if err != nil {
+		return 0
+	}
+	return strings.Count(string(data), "run\n")
+}
+
+func TestDecompileRetryTransient(t *testing.T) {
+	runs := fakeAPKTool(t, 1, "W: Could not delete file, it is locked")
+	setOpts(t, options{Retries: 1})
+	apk := filepath.Join(t.TempDir(), "app.apk")
+	outputDir, attempts, err := decompileWithRetry(apk)
+	if err != nil {
+		t.Fatalf("decompile failed: %v", err)
+	}
+	if attempts != 2 || countRuns(t, runs) != 2 {
+		t.Errorf("attempts = %d, runs = %d; want 2 each", attempts, countRuns(t, runs))
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "partial")); !os.IsNotExist(err) {
+		t.Error("partial output of the failed attempt was not cleaned before the retry")
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "AndroidManifest.xml")); err != nil {
+		t.Errorf("decompiled output missing: %v", err)
+	}
+}
+
+func TestDecompileRetriesExhausted(t *testing.T) {
+	runs := fakeAPKTool(t, 5, "W: Could not delete file, it is locked")
+	setOpts(t, options{Retries: 2})
+	_, attempts, err := decompileWithRetry(filepath.Join(t.TempDir(), "app.apk"))
+	if err == nil {
+		t.Fatal("decompile succeeded, want the last failure")
+	}
+	if attempts != 3 || countRuns(t, runs) != 3 {
+		t.Errorf("attempts = %d, runs = %d; want 3 each (1 + 2 retries)", attempts, countRuns(t, runs))
+	}
+	var apkErr *apktoolError
+	if !errors.As(err, &apkErr) || !strings.Contains(err.Error(), "it is locked") {
+		t.Errorf("error %q doesn't carry apktool's stderr", err)
+	}
+}
+
+func TestDecompileNoRetryOnPermanentFailure(t *testing.T) {
+	runs := fakeAPKTool(t, 1, "brut.androlib.AndrolibException: java.util.zip.ZipException: zip END header not found")
+	setOpts(t, options{Retries: 3})
+	_, attempts, err := decompileWithRetry(filepath.Join(t.TempDir(), "app.apk"))
+	if err == nil {
+		t.Fatal("decompile succeeded, want the permanent failure")
+	}
+	if attempts != 1 || countRuns(t, runs) != 1 {
+		t.Errorf("attempts = %d, runs = %d; want 1 each, a corrupt zip is never retried", attempts, countRuns(t, runs))
+	}
+}
+
+// TestDecompileMissingFramework checks apktool's missing framework error is
+// explained with the package id it names, and not retried.
+func TestDecompileMissingFramework(t *testing.T) {
+	for stderr, want := range map[string]string{
+		"brut.androlib.exceptions.CantFindFrameworkResException: Can't find framework resources for package of id: 2. You must install proper framework files, see project website for more info.": "requires framework package 2,",
+		"W: Can't find framework resources for package of id: 1":                       "requires framework package 1,",
+		"Exception in thread \"main\" brut.androlib.err.CantFindFrameworkResException": "requires framework package of an unknown id,",
+	} {
+		runs := fakeAPKTool(t, 1, stderr)
+		setOpts(t, options{Retries: 2})
+		_, attempts, err := decompileWithRetry(filepath.Join(t.TempDir(), "app.apk"))
+		if err == nil || !strings.Contains(err.Error(), want) || !strings.Contains(err.Error(), "apktool if framework-res.apk") {
+			t.Errorf("stderr %q: error %v, want it to explain the missing framework (%s)", stderr, err, want)
+		}
+		if attempts != 1 || countRuns(t, runs) != 1 {
+			t.Errorf("stderr %q: attempts = %d, runs = %d; want 1 each, a missing framework stays missing", stderr, attempts, countRuns(t, runs))
+		}
+	}
+}
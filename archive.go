@@ -0,0 +1,308 @@
+package main
+
+import (
+	"archive/tar"   // Reading .tar.gz bundles
+	"archive/zip"   // Reading .zip bundles and probing APKs
+	"bytes"         // Magic-byte comparison
+	"compress/gzip" // Decompressing .tar.gz bundles
+	"fmt"           // Error formatting
+	"io"            // Stream copying with limits
+	"io/fs"         // Directory walking
+	"os"            // File and temp dir handling
+	"path/filepath" // Safe path construction
+	"strings"       // Extension checks
+	"time"          // Preserving archived modification times
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// Limits applied while extracting archives so a hostile bundle can't fill the disk.
+const (
+	maxArchiveEntrySize = 1 << 30 // Largest single file we are willing to extract (1 GiB)
+	maxArchiveTotalSize = 4 << 30 // Largest combined size extracted from one archive (4 GiB)
+)
+
+// zipMagic is the local file header signature every APK (being a zip) starts with.
+var zipMagic = []byte("PK\x03\x04")
+
+// target describes a single APK queued for analysis.
+type target struct {
+	Path   string // Path to the APK on disk
+	Origin string // Archive the APK was extracted from, empty for direct input
+	Entry  string // Path of the APK inside Origin
+}
+
+// label names the target for headers and reports, e.g. "bundle.zip!/app.apk" for archive members.
+func (t target) label() string {
+	if t.Origin == "" {
+		return t.Path
+	}
+	return t.Origin + "!/" + t.Entry
+}
+
+// isArchive reports whether the path looks like a release bundle rather than an APK.
+func isArchive(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasSuffix(lower, ".zip") || strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// resolveTargets turns the -apk argument into the list of APKs to analyze.
+// The argument may be a glob such as builds/*.apk, expanded here so it behaves
+// the same under every shell; an existing file is always taken literally, even
+// if its name contains glob metacharacters.
+// A directory, as -dir passes it, is walked for APKs with walkAPKs.
+// Archives are unpacked into temp dirs; the returned cleanup removes them.
+func resolveTargets(apkPath string) ([]target, func(), error) {
+	noop := func() {}
+	if info, err := os.Stat(apkPath); err == nil && info.IsDir() {
+		targets, err := walkAPKs(apkPath)
+		return targets, noop, err
+	}
+	if _, err := os.Stat(apkPath); err == nil || !strings.ContainsAny(apkPath, "*?[") {
+		return resolveInput(apkPath)
+	}
+
+	matches, err := filepath.Glob(apkPath)
+	if err != nil {
+		return nil, noop, fmt.Errorf("invalid pattern %q: %w", apkPath, err)
+	}
+	if len(matches) == 0 {
+		return nil, noop, fmt.Errorf("pattern %q matched no files", apkPath)
+	}
+	var targets []target
+	var cleanups []func()
+	cleanup := func() {
+		for _, c := range cleanups {
+			c()
+		}
+	}
+	for _, match := range matches {
+		if info, err := os.Stat(match); err != nil || info.IsDir() {
+			continue
+		}
+		matched, c, err := resolveInput(match)
+		if err != nil {
+			cleanup()
+			return nil, noop, err
+		}
+		targets = append(targets, matched...)
+		cleanups = append(cleanups, c)
+	}
+	if len(targets) == 0 {
+		return nil, noop, fmt.Errorf("pattern %q matched no files", apkPath)
+	}
+	return targets, cleanup, nil
+}
+
+// walkAPKs finds the APKs under a -dir directory at any depth, in lexical
+// order. Subdirectories that can't be read are reported and skipped, as are the
+// _decompiled folders earlier runs left next to the APKs.
+func walkAPKs(dir string) ([]target, error) {
+	var targets []target
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		switch {
+		case err != nil && path == dir:
+			return err
+		case err != nil:
+			color.Yellow("Warning: skipping %s: %s", path, err)
+			return nil
+		case entry.IsDir() && path != dir && strings.HasSuffix(entry.Name(), "_decompiled"):
+			return filepath.SkipDir
+		case entry.Type().IsRegular() && strings.EqualFold(filepath.Ext(path), ".apk"):
+			targets = append(targets, target{Path: path})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no .apk files found under %s", dir)
+	}
+	return targets, nil
+}
+
+// resolveInput expands one input file: an APK is its own target, a bundle is
+// unpacked into a temp dir that the returned cleanup removes.
+func resolveInput(apkPath string) ([]target, func(), error) {
+	noop := func() {}
+	if !isArchive(apkPath) {
+		return []target{{Path: apkPath}}, noop, nil
+	}
+
+	tempDir, err := os.MkdirTemp("", "deeeeper-archive-")
+	if err != nil {
+		return nil, noop, err
+	}
+	cleanup := func() { os.RemoveAll(tempDir) }
+
+	var extracted []string
+	if strings.HasSuffix(strings.ToLower(apkPath), ".zip") {
+		extracted, err = extractZipAPKs(apkPath, tempDir)
+	} else {
+		extracted, err = extractTarGzAPKs(apkPath, tempDir)
+	}
+	if err != nil {
+		cleanup()
+		return nil, noop, err
+	}
+
+	var targets []target
+	for _, path := range extracted {
+		if looksLikeAPK(path) {
+			entry, _ := filepath.Rel(tempDir, path)
+			targets = append(targets, target{Path: path, Origin: apkPath, Entry: filepath.ToSlash(entry)})
+		}
+	}
+	if len(targets) == 0 {
+		cleanup()
+		return nil, noop, fmt.Errorf("no APKs found in archive %s", apkPath)
+	}
+	return targets, cleanup, nil
+}
+
+// extractZipAPKs extracts every zip entry carrying the zip magic into destDir.
+func extractZipAPKs(archivePath, destDir string) ([]string, error) {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var extracted []string
+	var total int64
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		if file.UncompressedSize64 > maxArchiveEntrySize {
+			return nil, fmt.Errorf("archive entry %s exceeds the %d byte limit", file.Name, int64(maxArchiveEntrySize))
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		path, written, err := extractCandidate(rc, file.Name, file.Modified, destDir, maxArchiveTotalSize-total)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		total += written
+		if path != "" {
+			extracted = append(extracted, path)
+		}
+	}
+	return extracted, nil
+}
+
+// extractTarGzAPKs extracts every regular tar entry carrying the zip magic into destDir.
+func extractTarGzAPKs(archivePath, destDir string) ([]string, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var extracted []string
+	var total int64
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue // Directories, links and devices are never APKs
+		}
+		if header.Size > maxArchiveEntrySize {
+			return nil, fmt.Errorf("archive entry %s exceeds the %d byte limit", header.Name, int64(maxArchiveEntrySize))
+		}
+		path, written, err := extractCandidate(tr, header.Name, header.ModTime, destDir, maxArchiveTotalSize-total)
+		if err != nil {
+			return nil, err
+		}
+		total += written
+		if path != "" {
+			extracted = append(extracted, path)
+		}
+	}
+	return extracted, nil
+}
+
+// extractCandidate writes an entry to destDir if it starts with the zip magic.
+// The archived modification time is kept so -since applies to bundle members.
+// It returns the written path (empty when skipped) and the number of bytes written.
+func extractCandidate(r io.Reader, name string, modified time.Time, destDir string, budget int64) (string, int64, error) {
+	magic := make([]byte, len(zipMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return "", 0, nil // Too short to be an APK
+	}
+	if !bytes.Equal(magic, zipMagic) {
+		return "", 0, nil
+	}
+
+	path, err := safeJoin(destDir, name)
+	if err != nil {
+		return "", 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", 0, err
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return "", 0, err
+	}
+
+	limit := min(budget, maxArchiveEntrySize)
+	written, err := io.Copy(out, io.LimitReader(io.MultiReader(bytes.NewReader(magic), r), limit+1))
+	out.Close() // Closed before Chtimes so the final write doesn't bump the time again
+	if err != nil {
+		return "", written, err
+	}
+	if written > limit {
+		return "", written, fmt.Errorf("archive entry %s exceeds the extraction size limit", name)
+	}
+	if !modified.IsZero() {
+		os.Chtimes(path, modified, modified)
+	}
+	return path, written, nil
+}
+
+// safeJoin joins an archive entry name onto destDir, rejecting names that
+// would escape it (zip-slip) such as absolute paths or ".." components.
+func safeJoin(destDir, name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if filepath.IsAbs(name) || strings.HasPrefix(name, "/") {
+		return "", fmt.Errorf("refusing to extract absolute archive path %s", name)
+	}
+	path := filepath.Join(destDir, filepath.FromSlash(name))
+	rel, err := filepath.Rel(destDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("refusing to extract archive path %s outside of %s", name, destDir)
+	}
+	return path, nil
+}
+
+// looksLikeAPK confirms a zip file is an APK by checking for a manifest entry.
+func looksLikeAPK(path string) bool {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return false
+	}
+	defer reader.Close()
+	for _, file := range reader.File {
+		if file.Name == "AndroidManifest.xml" {
+			return true
+		}
+	}
+	return false
+}
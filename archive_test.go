@@ -0,0 +1,182 @@
+package main
+
+import (
+	"archive/tar"   // Fixture .tar.gz bundles
+	"archive/zip"   // Fixture .zip bundles
+	"bytes"         // In-memory entry contents
+	"compress/gzip" // Fixture .tar.gz bundles
+	"os"            // Fixture files
+	"path/filepath" // Fixture paths
+	"strings"       // Error messages
+	"testing"       // Test harness
+	"time"          // Archived modification times
+)
+
+// apkPayload starts with the zip magic, so extraction takes it for an APK.
+const apkPayload = "PK\x03\x04 not really an apk"
+
+// writeZipBundle writes a zip holding each entry name with apkPayload.
+func writeZipBundle(t *testing.T, path string, names ...string) {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range names {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(apkPayload))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeTarGzBundle writes a .tar.gz holding each entry name with apkPayload.
+func writeTarGzBundle(t *testing.T, path string, names ...string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	w := tar.NewWriter(gz)
+	for _, name := range names {
+		header := &tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(apkPayload)), ModTime: time.Unix(0, 0)}
+		if err := w.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(apkPayload))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	gz.Close()
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSafeJoin(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "out")
+	for name, ok := range map[string]bool{
+		"app.apk":              true,
+		"release/app.apk":      true,
+		"release/../app.apk":   true,
+		"../evil.apk":          false,
+		"release/../../evil":   false,
+		`..\evil.apk`:          false,
+		"/etc/evil.apk":        false,
+		`\windows\evil.apk`:    false,
+		"..":                   false,
+		"release/..../app.apk": true,
+	} {
+		path, err := safeJoin(dest, name)
+		if ok && (err != nil || !strings.HasPrefix(path, dest+string(filepath.Separator))) {
+			t.Errorf("safeJoin(%q) = %q, %v; want a path inside %s", name, path, err, dest)
+		}
+		if !ok && err == nil {
+			t.Errorf("safeJoin(%q) = %q, want it refused", name, path)
+		}
+	}
+}
+
+// TestExtractZipSlip checks entries escaping the temp dir, by ".." or by an
+// absolute path, fail the extraction of both bundle formats without writing
+// anything outside it.
+func TestExtractZipSlip(t *testing.T) {
+	for _, format := range []struct {
+		ext     string
+		write   func(*testing.T, string, ...string)
+		extract func(string, string) ([]string, error)
+	}{
+		{".zip", writeZipBundle, extractZipAPKs},
+		{".tar.gz", writeTarGzBundle, extractTarGzAPKs},
+	} {
+		for _, name := range []string{"../evil.apk", "/evil.apk"} {
+			t.Run(format.ext+" "+name, func(t *testing.T) {
+				root := t.TempDir()
+				bundle := filepath.Join(root, "bundle"+format.ext)
+				format.write(t, bundle, "app.apk", name)
+				dest := filepath.Join(root, "out")
+				if err := os.Mkdir(dest, 0o755); err != nil {
+					t.Fatal(err)
+				}
+				extracted, err := format.extract(bundle, dest)
+				if err == nil || !strings.Contains(err.Error(), "refusing to extract") {
+					t.Fatalf("extracted %q, error %v; want the entry refused", extracted, err)
+				}
+				if _, err := os.Stat(filepath.Join(root, "evil.apk")); !os.IsNotExist(err) {
+					t.Error("../evil.apk was written next to the extraction dir")
+				}
+			})
+		}
+	}
+}
+
+func TestExtractArchive(t *testing.T) {
+	root := t.TempDir()
+	for _, bundle := range []string{"bundle.zip", "bundle.tar.gz"} {
+		path := filepath.Join(root, bundle)
+		if bundle == "bundle.zip" {
+			writeZipBundle(t, path, "release/app.apk")
+		} else {
+			writeTarGzBundle(t, path, "release/app.apk")
+		}
+		dest := filepath.Join(root, bundle+"_out")
+		extract := extractZipAPKs
+		if bundle == "bundle.tar.gz" {
+			extract = extractTarGzAPKs
+		}
+		extracted, err := extract(path, dest)
+		if err != nil {
+			t.Fatalf("%s: %v", bundle, err)
+		}
+		want := filepath.Join(dest, "release", "app.apk")
+		if len(extracted) != 1 || extracted[0] != want {
+			t.Errorf("%s: extracted %q, want %s", bundle, extracted, want)
+		}
+	}
+}
+
+// TestExtractSizeLimit checks an entry announcing more than the per-entry
+// limit is refused before any byte is read, and that one outgrowing the
+// remaining budget is refused while it is being written.
+func TestExtractSizeLimit(t *testing.T) {
+	root := t.TempDir()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	huge, err := w.CreateRaw(&zip.FileHeader{Name: "huge.apk", Method: zip.Store, UncompressedSize64: maxArchiveEntrySize + 1, CompressedSize64: uint64(len(apkPayload))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	huge.Write([]byte(apkPayload))
+	w.Close()
+	bundle := filepath.Join(root, "huge.zip")
+	if err := os.WriteFile(bundle, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := extractZipAPKs(bundle, filepath.Join(root, "zip")); err == nil || !strings.Contains(err.Error(), "exceeds the") {
+		t.Errorf("zip entry over the limit: error = %v", err)
+	}
+
+	buf.Reset()
+	gz := gzip.NewWriter(&buf)
+	// Only the header is written: the size check comes before the content.
+	tar.NewWriter(gz).WriteHeader(&tar.Header{Name: "huge.apk", Typeflag: tar.TypeReg, Mode: 0o644, Size: maxArchiveEntrySize + 1})
+	gz.Close()
+	bundle = filepath.Join(root, "huge.tar.gz")
+	if err := os.WriteFile(bundle, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := extractTarGzAPKs(bundle, filepath.Join(root, "tar")); err == nil || !strings.Contains(err.Error(), "exceeds the") {
+		t.Errorf("tar entry over the limit: error = %v", err)
+	}
+
+	dest := filepath.Join(root, "budget")
+	path, written, err := extractCandidate(strings.NewReader(apkPayload), "app.apk", time.Time{}, dest, 8)
+	if err == nil || path != "" || written <= 8 {
+		t.Errorf("entry over the remaining budget: path %q, written %d, error %v", path, written, err)
+	}
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"encoding/binary" // Little-endian chunk fields
+	"errors"          // Malformed table marker
+	"slices"          // Locale letter checks
+	"strconv"         // Integer values
+	"unicode/utf16"   // UTF-16 string pools
+)
+
+// Chunk types of the binary resource table format, see ResourceTypes.h.
+const (
+	resStringPoolType   = 0x0001
+	resTableType        = 0x0002
+	resTablePackageType = 0x0200
+	resTableTypeType    = 0x0201
+)
+
+// Res_value data types Deeeeper reads.
+const (
+	resValueString  = 0x03
+	resValueIntDec  = 0x10
+	resValueIntHex  = 0x11
+	resValueBoolean = 0x12
+)
+
+// Flags of string pools, type chunks and entries.
+const (
+	stringPoolUTF8   = 1 << 8
+	typeFlagSparse   = 0x01
+	typeFlagOffset16 = 0x02
+	entryFlagComplex = 0x0001
+	entryFlagCompact = 0x0008
+	noEntry32        = 0xFFFFFFFF
+	noEntry16        = 0xFFFF
+)
+
+// errResourceTable is returned for resource tables that can't be read.
+var errResourceTable = errors.New("malformed resource table")
+
+// readResourceTableNames extracts the string, bool and integer values of the
+// default configuration from a resources.arsc, keyed like resourceValues.
+// Other resource types, styled strings, references and bags are skipped. It
+// also returns the "type/name" of every resource ID in the table, of any type,
+// which binary XML uses in place of references.
+func readResourceTableNames(data []byte) (resourceValues, map[uint32]string, error) {
+	return readResourceTableLocales(data, nil)
+}
+
+// readResourceTableLocales is readResourceTableNames that also collects the
+// strings of locale-only configurations into localized, keyed by the values
+// folder apktool would write them to, such as "values-de" or "values-pt-rBR".
+// localized may be nil when they aren't wanted.
+func readResourceTableLocales(data []byte, localized map[string]resourceValues) (resourceValues, map[uint32]string, error) {
+	kind, headerSize, size, ok := chunkHeader(data, 0)
+	if !ok || kind != resTableType {
+		return nil, nil, errResourceTable
+	}
+	values := make(resourceValues)
+	names := make(map[uint32]string)
+	var globalStrings []string
+	for offset := headerSize; offset+8 <= size; {
+		kind, _, chunkSize, ok := chunkHeader(data, offset)
+		if !ok || chunkSize < 8 || offset+chunkSize > size {
+			return nil, nil, errResourceTable
+		}
+		chunk := data[offset : offset+chunkSize]
+		var err error
+		switch kind {
+		case resStringPoolType:
+			globalStrings, err = readStringPool(chunk)
+		case resTablePackageType:
+			err = readPackage(chunk, globalStrings, values, names, localized)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		offset += chunkSize
+	}
+	return values, names, nil
+}
+
+// chunkHeader reads the ResChunk_header at offset. ok is false when it doesn't fit.
+func chunkHeader(data []byte, offset int) (kind, headerSize, size int, ok bool) {
+	if offset < 0 || offset+8 > len(data) {
+		return 0, 0, 0, false
+	}
+	kind = int(binary.LittleEndian.Uint16(data[offset:]))
+	headerSize = int(binary.LittleEndian.Uint16(data[offset+2:]))
+	size = int(binary.LittleEndian.Uint32(data[offset+4:]))
+	return kind, headerSize, size, headerSize >= 8 && headerSize <= size && offset+size <= len(data)
+}
+
+// readStringPool decodes every string of a ResStringPool chunk.
+func readStringPool(chunk []byte) ([]string, error) {
+	if len(chunk) < 28 {
+		return nil, errResourceTable
+	}
+	headerSize := int(binary.LittleEndian.Uint16(chunk[2:]))
+	count := int(binary.LittleEndian.Uint32(chunk[8:]))
+	isUTF8 := binary.LittleEndian.Uint32(chunk[16:])&stringPoolUTF8 != 0
+	stringsStart := int(binary.LittleEndian.Uint32(chunk[20:]))
+	if count < 0 || count > (len(chunk)-headerSize)/4 {
+		return nil, errResourceTable
+	}
+	pool := make([]string, count)
+	for i := range pool {
+		offset := stringsStart + int(binary.LittleEndian.Uint32(chunk[headerSize+4*i:]))
+		s, ok := poolString(chunk, offset, isUTF8)
+		if !ok {
+			return nil, errResourceTable
+		}
+		pool[i] = s
+	}
+	return pool, nil
+}
+
+// poolString decodes one length-prefixed pool string.
+func poolString(chunk []byte, offset int, isUTF8 bool) (string, bool) {
+	if isUTF8 {
+		_, offset, ok := poolLength8(chunk, offset) // Length in UTF-16 units, unused
+		if !ok {
+			return "", false
+		}
+		length, offset, ok := poolLength8(chunk, offset)
+		if !ok || offset+length > len(chunk) {
+			return "", false
+		}
+		return string(chunk[offset : offset+length]), true
+	}
+	if offset < 0 || offset+2 > len(chunk) {
+		return "", false
+	}
+	length := int(binary.LittleEndian.Uint16(chunk[offset:]))
+	offset += 2
+	if length&0x8000 != 0 {
+		if offset+2 > len(chunk) {
+			return "", false
+		}
+		length = (length&0x7FFF)<<16 | int(binary.LittleEndian.Uint16(chunk[offset:]))
+		offset += 2
+	}
+	if offset+2*length > len(chunk) {
+		return "", false
+	}
+	units := make([]uint16, length)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(chunk[offset+2*i:])
+	}
+	return string(utf16.Decode(units)), true
+}
+
+// poolLength8 reads a one- or two-byte UTF-8 pool length.
+func poolLength8(chunk []byte, offset int) (int, int, bool) {
+	if offset < 0 || offset >= len(chunk) {
+		return 0, 0, false
+	}
+	length := int(chunk[offset])
+	offset++
+	if length&0x80 != 0 {
+		if offset >= len(chunk) {
+			return 0, 0, false
+		}
+		length = (length&0x7F)<<8 | int(chunk[offset])
+		offset++
+	}
+	return length, offset, true
+}
+
+// readPackage reads the type chunks of a ResTable_package into values, names
+// and localized.
+func readPackage(chunk []byte, globalStrings []string, values resourceValues, names map[uint32]string, localized map[string]resourceValues) error {
+	if len(chunk) < 284 {
+		return errResourceTable
+	}
+	headerSize := int(binary.LittleEndian.Uint16(chunk[2:]))
+	packageID := binary.LittleEndian.Uint32(chunk[8:])
+	typeNames, err := nestedStringPool(chunk, int(binary.LittleEndian.Uint32(chunk[268:])))
+	if err != nil {
+		return err
+	}
+	keyNames, err := nestedStringPool(chunk, int(binary.LittleEndian.Uint32(chunk[276:])))
+	if err != nil {
+		return err
+	}
+	for offset := headerSize; offset+8 <= len(chunk); {
+		kind, _, size, ok := chunkHeader(chunk, offset)
+		if !ok || size < 8 {
+			return errResourceTable
+		}
+		if kind == resTableTypeType {
+			readType(chunk[offset:offset+size], packageID, typeNames, keyNames, globalStrings, values, names, localized)
+		}
+		offset += size
+	}
+	return nil
+}
+
+// nestedStringPool decodes the string pool chunk starting at offset.
+func nestedStringPool(chunk []byte, offset int) ([]string, error) {
+	kind, _, size, ok := chunkHeader(chunk, offset)
+	if !ok || kind != resStringPoolType {
+		return nil, errResourceTable
+	}
+	return readStringPool(chunk[offset : offset+size])
+}
+
+// readType records the names of the entries of one ResTable_type chunk and
+// reads their simple values in the default configuration, and strings of a
+// locale-only configuration into localized when it isn't nil. Values of other
+// configurations and types are ignored.
+func readType(chunk []byte, packageID uint32, typeNames, keyNames, globalStrings []string, values resourceValues, names map[uint32]string, localized map[string]resourceValues) {
+	if len(chunk) < 24 {
+		return
+	}
+	headerSize := int(binary.LittleEndian.Uint16(chunk[2:]))
+	id := int(chunk[8])
+	flags := chunk[9]
+	count := int(binary.LittleEndian.Uint32(chunk[12:]))
+	entriesStart := int(binary.LittleEndian.Uint32(chunk[16:]))
+	configSize := int(binary.LittleEndian.Uint32(chunk[20:]))
+	if id < 1 || id > len(typeNames) || 20+configSize > headerSize || headerSize > len(chunk) {
+		return
+	}
+	typeName := typeNames[id-1]
+	simple := typeName == "string" || typeName == "bool" || typeName == "integer"
+	target := values
+	if folder := configLocaleFolder(chunk[20 : 20+configSize]); folder != "values" {
+		simple = false // Not the default configuration
+		if folder != "" && typeName == "string" && localized != nil {
+			if localized[folder] == nil {
+				localized[folder] = make(resourceValues)
+			}
+			simple, target = true, localized[folder]
+		}
+	}
+	for i := range count {
+		var offset int
+		index := i
+		switch {
+		case flags&typeFlagSparse != 0: // (index, offset/4) pairs
+			if headerSize+4*i+4 > len(chunk) {
+				return
+			}
+			index = int(binary.LittleEndian.Uint16(chunk[headerSize+4*i:]))
+			offset = 4 * int(binary.LittleEndian.Uint16(chunk[headerSize+4*i+2:]))
+		case flags&typeFlagOffset16 != 0: // offset/4 as uint16
+			if headerSize+2*i+2 > len(chunk) {
+				return
+			}
+			raw := binary.LittleEndian.Uint16(chunk[headerSize+2*i:])
+			if raw == noEntry16 {
+				continue
+			}
+			offset = 4 * int(raw)
+		default:
+			if headerSize+4*i+4 > len(chunk) {
+				return
+			}
+			raw := binary.LittleEndian.Uint32(chunk[headerSize+4*i:])
+			if raw == noEntry32 {
+				continue
+			}
+			offset = int(raw)
+		}
+		key, ok := entryKey(chunk, entriesStart+offset)
+		if !ok || key >= len(keyNames) {
+			continue
+		}
+		name := typeName + "/" + keyNames[key]
+		names[packageID<<24|uint32(id)<<16|uint32(index)] = name
+		if !simple {
+			continue
+		}
+		_, dataType, data, ok := readEntry(chunk, entriesStart+offset)
+		if !ok {
+			continue
+		}
+		value, ok := simpleValue(dataType, data, globalStrings)
+		if _, seen := target[name]; ok && !seen {
+			target[name] = value
+		}
+	}
+}
+
+// entryKey reads the key of a ResTable_entry of any kind, bags included.
+func entryKey(chunk []byte, offset int) (int, bool) {
+	if offset < 0 || offset+8 > len(chunk) {
+		return 0, false
+	}
+	if flags := binary.LittleEndian.Uint16(chunk[offset+2:]); flags&entryFlagCompact != 0 {
+		return int(binary.LittleEndian.Uint16(chunk[offset:])), true
+	}
+	return int(binary.LittleEndian.Uint32(chunk[offset+4:])), true
+}
+
+// readEntry reads the key and value of a ResTable_entry, compact or not. ok
+// is false for bags (complex entries) and entries that don't fit.
+func readEntry(chunk []byte, offset int) (key int, dataType byte, data uint32, ok bool) {
+	if offset < 0 || offset+8 > len(chunk) {
+		return 0, 0, 0, false
+	}
+	size := int(binary.LittleEndian.Uint16(chunk[offset:]))
+	flags := binary.LittleEndian.Uint16(chunk[offset+2:])
+	if flags&entryFlagCompact != 0 { // Key in the size field, type in the high flag byte
+		return size, byte(flags >> 8), binary.LittleEndian.Uint32(chunk[offset+4:]), true
+	}
+	if flags&entryFlagComplex != 0 || offset+size+8 > len(chunk) {
+		return 0, 0, 0, false
+	}
+	key = int(binary.LittleEndian.Uint32(chunk[offset+4:]))
+	value := chunk[offset+size:]
+	return key, value[3], binary.LittleEndian.Uint32(value[4:]), true
+}
+
+// simpleValue renders a Res_value of a supported type as its resource-file text.
+func simpleValue(dataType byte, data uint32, globalStrings []string) (string, bool) {
+	switch dataType {
+	case resValueString:
+		if int(data) < len(globalStrings) {
+			return globalStrings[data], true
+		}
+	case resValueIntDec:
+		return strconv.Itoa(int(int32(data))), true
+	case resValueIntHex:
+		return "0x" + strconv.FormatUint(uint64(data), 16), true
+	case resValueBoolean:
+		return strconv.FormatBool(data != 0), true
+	}
+	return "", false
+}
+
+// configLocaleFolder names the values folder of a ResTable_config: "values"
+// for the default configuration, "values-<lang>[-r<REGION>]" for one that
+// only sets a two-letter locale, and "" for anything else.
+func configLocaleFolder(config []byte) string {
+	if len(config) < 12 {
+		return ""
+	}
+	for i, b := range config[4:] {
+		if b != 0 && (i < 4 || i >= 8) { // Only the locale field, bytes 8 to 11, may be set
+			return ""
+		}
+	}
+	language, region := config[8:10], config[10:12]
+	switch {
+	case language[0] == 0:
+		if region[0] != 0 {
+			return ""
+		}
+		return "values"
+	case language[0]&0x80 != 0 || !isLowerASCII(language): // Packed three-letter language
+		return ""
+	case region[0] == 0:
+		return "values-" + string(language)
+	case region[0]&0x80 != 0 || !isUpperASCII(region): // Packed numeric region
+		return ""
+	}
+	return "values-" + string(language) + "-r" + string(region)
+}
+
+// isLowerASCII reports whether every byte is a lowercase ASCII letter.
+func isLowerASCII(b []byte) bool {
+	return !slices.ContainsFunc(b, func(c byte) bool { return c < 'a' || c > 'z' })
+}
+
+// isUpperASCII reports whether every byte is an uppercase ASCII letter.
+func isUpperASCII(b []byte) bool {
+	return !slices.ContainsFunc(b, func(c byte) bool { return c < 'A' || c > 'Z' })
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json" // Statement list encoding
+	"os"            // Output files
+	"path/filepath" // Output layout
+	"slices"        // Statement deduplication
+	"strings"       // Wildcard hosts
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// handleAllURLs is the relation App Links verification checks for.
+const handleAllURLs = "delegate_permission/common.handle_all_urls"
+
+// assetStatement is one entry of a Digital Asset Links statement list.
+type assetStatement struct {
+	Relation []string    `json:"relation"` // Granted relations
+	Target   assetTarget `json:"target"`   // App the relations are granted to
+}
+
+// assetTarget identifies an Android app by package and signing certificate.
+type assetTarget struct {
+	Namespace    string   `json:"namespace"`                // Always android_app
+	PackageName  string   `json:"package_name"`             // Application ID
+	Fingerprints []string `json:"sha256_cert_fingerprints"` // Colon-separated SHA-256 of the signing certificate
+}
+
+// writeAssetLinks writes the assetlinks.json each autoVerify host must serve
+// for the analyzed apps to pass App Links verification, mirrored as
+// <dir>/<host>/.well-known/assetlinks.json, plus <dir>/assetlinks.json with
+// every statement combined. Apps whose signing certificate is unknown are
+// skipped with a hint about -cert-fingerprint.
+func writeAssetLinks(dir string, reports []*report) error {
+	byHost := make(map[string][]assetStatement)
+	var combined []assetStatement
+	for _, r := range reports {
+		fingerprint := r.SigningCert
+		if opts.CertFingerprint != "" {
+			fingerprint = opts.CertFingerprint
+		}
+		if fingerprint == "" {
+			color.Yellow("Warning: signing certificate of %s unknown, no assetlinks.json generated; pass -cert-fingerprint", r.Package)
+			continue
+		}
+		statement := assetStatement{
+			Relation: []string{handleAllURLs},
+			Target:   assetTarget{Namespace: "android_app", PackageName: r.Package, Fingerprints: []string{fingerprint}},
+		}
+		for _, host := range r.Hosts {
+			if !host.AutoVerify {
+				continue
+			}
+			name := strings.TrimPrefix(host.Host, "*.") // Wildcard hosts are verified at the parent domain
+			if !slices.ContainsFunc(byHost[name], statement.equal) {
+				byHost[name] = append(byHost[name], statement)
+			}
+			if !slices.ContainsFunc(combined, statement.equal) {
+				combined = append(combined, statement)
+			}
+		}
+	}
+
+	for host, statements := range byHost {
+		path := filepath.Join(dir, unsafeFileChars.ReplaceAllString(host, "_"), ".well-known", "assetlinks.json")
+		if err := writeStatements(path, statements); err != nil {
+			return err
+		}
+	}
+	if len(combined) == 0 {
+		color.Yellow("No autoVerify hosts found; no assetlinks.json generated.")
+		return nil
+	}
+	return writeStatements(filepath.Join(dir, "assetlinks.json"), combined)
+}
+
+// equal reports whether two statements grant the same app.
+func (s assetStatement) equal(other assetStatement) bool {
+	return s.Target.PackageName == other.Target.PackageName && slices.Equal(s.Target.Fingerprints, other.Target.Fingerprints)
+}
+
+// writeStatements writes a statement list as indented JSON, creating parent directories.
+func writeStatements(path string, statements []assetStatement) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(statements, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
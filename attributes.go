@@ -0,0 +1,54 @@
+package main
+
+// componentInfo is the structured-output view of one manifest component.
+type componentInfo struct {
+	Type        string            `json:"type"`                  // activity, alias, service, receiver or provider
+	Name        string            `json:"name"`                  // Component name as declared
+	Exported    bool              `json:"exported"`              // Resolved exported state, implicit defaults included
+	Actions     []string          `json:"actions"`               // Distinct intent actions handled, never null
+	URIs        []string          `json:"uris"`                  // Deeplink URIs constructed from its filters and res/xml meta-data, never null
+	Attributes  map[string]string `json:"attributes,omitempty"`  // Every attribute as found in the manifest
+	Authorities []string          `json:"authorities,omitempty"` // Provider authorities, one entry each
+	Filters     []filterInfo      `json:"filters,omitempty"`     // Intent filters in declaration order
+	Badges      []string          `json:"badges,omitempty"`      // Reduced-trust contexts it is reachable from, see reachabilityBadges
+}
+
+// filterInfo is the structured-output view of one intent filter.
+type filterInfo struct {
+	Attributes map[string]string `json:"attributes,omitempty"` // Every attribute as found in the manifest
+	Actions    []string          `json:"actions,omitempty"`    // Actions of the filter in declaration order
+	Categories []string          `json:"categories,omitempty"` // Categories of the filter in declaration order
+	Data       []Data            `json:"data,omitempty"`       // <data> elements with their attributes verbatim
+}
+
+// collectComponents lists every declared component with its raw attributes,
+// its resolved exported state, actions and constructed deeplink URIs.
+func collectComponents(manifest Manifest, folder string) []componentInfo {
+	var components []componentInfo
+	for _, group := range componentGroups(manifest) {
+		for _, component := range group.Components {
+			info := componentInfo{Type: group.Kind, Name: component.Name, Attributes: component.Attributes, Authorities: providerAuthorities(component)}
+			info.Exported, _ = isExported(component, group.Kind)
+			info.Actions, info.URIs = []string{}, []string{} // Empty arrays keep the shape stable for jq
+			info.URIs = append(info.URIs, componentURIs(folder, component)...)
+			for _, filter := range component.Filters {
+				for _, action := range filter.Actions {
+					info.Actions = appendUnique(info.Actions, action.Name)
+				}
+			}
+			info.Badges, _ = reachabilityBadges(component)
+			for _, filter := range component.Filters {
+				var actions, categories []string
+				for _, action := range filter.Actions {
+					actions = append(actions, action.Name)
+				}
+				for _, category := range filter.Categories {
+					categories = append(categories, category.Name)
+				}
+				info.Filters = append(info.Filters, filterInfo{Attributes: filter.Attributes, Actions: actions, Categories: categories, Data: filter.Data})
+			}
+			components = append(components, info)
+		}
+	}
+	return components
+}
@@ -0,0 +1,419 @@
+package main
+
+import (
+	"archive/zip"     // Reading entries of the APK
+	"bytes"           // Document assembly
+	"encoding/binary" // Little-endian chunk fields
+	"encoding/xml"    // Escaping names and values
+	"errors"          // Malformed document marker
+	"fmt"             // Value rendering
+	"io"              // Reading zip entries
+	"math"            // Float values
+	"os"              // Output folder
+	"path"            // Zip entry names
+	"path/filepath"   // Output paths
+	"slices"          // Signature block extensions
+	"sort"            // Deterministic value files
+	"strconv"         // Integer values
+	"strings"         // Indentation and flag names
+)
+
+// Chunk types of Android binary XML, see ResourceTypes.h.
+const (
+	resXMLType            = 0x0003
+	resXMLStartNamespace  = 0x0100
+	resXMLEndNamespace    = 0x0101
+	resXMLStartElement    = 0x0102
+	resXMLEndElement      = 0x0103
+	resXMLCData           = 0x0104
+	resXMLResourceMapType = 0x0180
+)
+
+// Res_value data types of attributes, besides those in arsc.go.
+const (
+	resValueNull      = 0x00
+	resValueReference = 0x01
+	resValueAttribute = 0x02
+	resValueFloat     = 0x04
+	resValueColorMin  = 0x1c
+	resValueColorMax  = 0x1f
+	noString          = 0xFFFFFFFF
+)
+
+// androidNamespace is the URI of the android: attribute namespace.
+const androidNamespace = "http://schemas.android.com/apk/res/android"
+
+// errBinaryXML is returned for binary XML documents that can't be decoded.
+var errBinaryXML = errors.New("malformed binary XML")
+
+// androidAttributes names the framework attributes the parser models by
+// resource ID, for manifests whose string pool has their names stripped.
+var androidAttributes = map[uint32]string{
+	0x01010001: "label", 0x01010002: "icon", 0x01010003: "name",
+	0x01010006: "permission", 0x01010007: "readPermission", 0x01010008: "writePermission",
+	0x01010009: "protectionLevel", 0x0101000b: "sharedUserId", 0x0101000e: "enabled", 0x0101000f: "debuggable",
+	0x01010010: "exported", 0x01010011: "process", 0x01010013: "multiprocess",
+	0x01010018: "authorities", 0x0101001b: "grantUriPermissions", 0x0101001c: "priority",
+	0x01010024: "value", 0x01010025: "resource", 0x01010026: "mimeType",
+	0x01010027: "scheme", 0x01010028: "host", 0x01010029: "port",
+	0x0101002a: "path", 0x0101002b: "pathPrefix", 0x0101002c: "pathPattern",
+	0x01010202: "targetActivity", 0x01010280: "allowBackup", 0x010104ec: "usesCleartextTraffic", 0x0101054c: "targetSandboxVersion",
+}
+
+// protectionBases and protectionFlags name the parts of an integer
+// android:protectionLevel, the way apktool writes them.
+var (
+	protectionBases = []string{"normal", "dangerous", "signature", "signatureOrSystem"}
+	protectionFlags = []struct {
+		bit  uint32
+		name string
+	}{
+		{0x10, "privileged"}, {0x20, "development"}, {0x40, "appop"}, {0x80, "pre23"},
+		{0x100, "installer"}, {0x200, "verifier"}, {0x400, "preinstalled"}, {0x800, "setup"},
+	}
+)
+
+// apkResourceTable reads the values and resource names of an APK's
+// resources.arsc, and the localized strings into localized unless it is nil.
+func apkResourceTable(archive *zip.Reader, localized map[string]resourceValues) (map[uint32]string, resourceValues) {
+	data, err := readZipEntry(archive, "resources.arsc")
+	if err != nil {
+		return nil, nil
+	}
+	values, names, err := readResourceTableLocales(data, localized)
+	if err != nil {
+		return nil, nil
+	}
+	return names, values
+}
+
+// readZipEntry returns the contents of one entry of an archive.
+func readZipEntry(archive *zip.Reader, name string) ([]byte, error) {
+	entry, err := archive.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer entry.Close()
+	return io.ReadAll(entry)
+}
+
+// decodeZipXML decodes one binary XML entry of an archive.
+func decodeZipXML(archive *zip.Reader, name string, names map[uint32]string) ([]byte, error) {
+	data, err := readZipEntry(archive, name)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := decodeBinaryXML(data, names)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return decoded, nil
+}
+
+// extractAPK lays out what the analysis reads from an APK the way apktool
+// would, without running it: the decoded manifest, res/values files with the
+// app's default string, bool and integer resources, a strings.xml per
+// locale, the decoded res/xml
+// resources and the v1 signature blocks under original/META-INF, written to
+// outputDir. Smali is not produced, so code heuristics find nothing in it.
+func extractAPK(apkPath, outputDir string) (string, error) {
+	archive, err := zip.OpenReader(apkPath)
+	if err != nil {
+		return "", err
+	}
+	defer archive.Close()
+	localized := make(map[string]resourceValues)
+	names, values := apkResourceTable(&archive.Reader, localized)
+	manifest, err := decodeZipXML(&archive.Reader, "AndroidManifest.xml", names)
+	if err != nil {
+		return "", err
+	}
+
+	files := extractedFiles(manifest, values)
+	for folder, localeValues := range localized { // For -locale and strings only a locale defines
+		files[filepath.Join("res", folder, "strings.xml")] = valuesFile(localeValues, "string")
+	}
+	for _, entry := range archive.File {
+		dir, file := path.Split(entry.Name)
+		switch {
+		case dir == "res/xml/" && strings.HasSuffix(file, ".xml"):
+			if decoded, err := decodeZipXML(&archive.Reader, entry.Name, names); err == nil {
+				files[filepath.Join("res", "xml", file)] = decoded
+			}
+		case dir == "META-INF/" && isSignatureBlock(file):
+			if content, err := readZipEntry(&archive.Reader, entry.Name); err == nil {
+				files[filepath.Join("original", "META-INF", file)] = content
+			}
+		}
+	}
+	return writeExtracted(outputDir, files)
+}
+
+// extractedFiles starts the file set of an extracted folder with the manifest
+// and the res/values files of the default string, bool and integer resources.
+func extractedFiles(manifest []byte, values resourceValues) map[string][]byte {
+	files := map[string][]byte{"AndroidManifest.xml": manifest}
+	for kind, file := range map[string]string{"string": "strings.xml", "bool": "bools.xml", "integer": "integers.xml"} {
+		files[filepath.Join("res", "values", file)] = valuesFile(values, kind)
+	}
+	return files
+}
+
+// isSignatureBlock reports whether a META-INF file holds a v1 signature.
+func isSignatureBlock(file string) bool {
+	return slices.Contains([]string{".RSA", ".DSA", ".EC"}, strings.ToUpper(path.Ext(file)))
+}
+
+// writeExtracted replaces outputDir, like apktool -f, with the given files
+// and returns it.
+func writeExtracted(outputDir string, files map[string][]byte) (string, error) {
+	if err := os.RemoveAll(outputDir); err != nil {
+		return "", err
+	}
+	for name, content := range files {
+		target := filepath.Join(outputDir, name)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(target, content, 0o644); err != nil {
+			return "", err
+		}
+	}
+	return outputDir, nil
+}
+
+// valuesFile renders the resources of one type as a res/values file.
+func valuesFile(values resourceValues, kind string) []byte {
+	var b bytes.Buffer
+	b.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<resources>\n")
+	var names []string
+	for key := range values {
+		if name, ok := strings.CutPrefix(key, kind+"/"); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "    <%s name=\"%s\">%s</%s>\n", kind, escapeXML(name), escapeXML(values[kind+"/"+name]), kind)
+	}
+	b.WriteString("</resources>\n")
+	return b.Bytes()
+}
+
+// escapeXML escapes text for use in XML content and attribute values.
+func escapeXML(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+// axmlDecoder holds the state of one binary XML document being decoded.
+type axmlDecoder struct {
+	pool       []string          // String pool
+	attrIDs    []uint32          // Resource IDs of the pool's attribute names
+	names      map[uint32]string // App resource names by ID
+	prefixes   map[string]string // Namespace prefixes by URI
+	pending    []string          // Namespace declarations for the next element
+	out        bytes.Buffer      // XML text
+	depth      int               // Current element depth
+	openedTags []string          // Names of open elements
+	openTag    bool              // The last start tag still lacks its ">", for self-closing
+}
+
+// decodeBinaryXML decodes an Android binary XML document (AXML) into XML text.
+// Attribute values are rendered like apktool does: references as @type/name
+// (or @android:type/name, via frameworkNames), booleans and integers as text,
+// and references with unknown names as @0x7f... IDs.
+func decodeBinaryXML(data []byte, names map[uint32]string) ([]byte, error) {
+	kind, headerSize, size, ok := chunkHeader(data, 0)
+	if !ok || kind != resXMLType {
+		return nil, errBinaryXML
+	}
+	d := &axmlDecoder{names: names, prefixes: make(map[string]string)}
+	d.out.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n")
+	for offset := headerSize; offset+8 <= size; {
+		kind, _, chunkSize, ok := chunkHeader(data, offset)
+		if !ok || chunkSize < 8 || offset+chunkSize > size {
+			return nil, errBinaryXML
+		}
+		chunk := data[offset : offset+chunkSize]
+		if d.openTag && kind != resXMLEndElement {
+			d.out.WriteString(">\n")
+			d.openTag = false
+		}
+		var err error
+		switch kind {
+		case resStringPoolType:
+			d.pool, err = readStringPool(chunk)
+		case resXMLResourceMapType:
+			for i := 8; i+4 <= len(chunk); i += 4 {
+				d.attrIDs = append(d.attrIDs, binary.LittleEndian.Uint32(chunk[i:]))
+			}
+		case resXMLStartNamespace:
+			err = d.startNamespace(chunk)
+		case resXMLStartElement:
+			err = d.startElement(chunk)
+		case resXMLEndElement:
+			err = d.endElement()
+		case resXMLCData:
+			if len(chunk) >= 20 {
+				d.out.WriteString(escapeXML(d.string(binary.LittleEndian.Uint32(chunk[16:]))))
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		offset += chunkSize
+	}
+	if d.depth != 0 || d.out.Len() == 0 {
+		return nil, errBinaryXML
+	}
+	return d.out.Bytes(), nil
+}
+
+// string returns a pool string, or "" for an absent or out-of-range index.
+func (d *axmlDecoder) string(index uint32) string {
+	if index == noString || int(index) >= len(d.pool) {
+		return ""
+	}
+	return d.pool[index]
+}
+
+// startNamespace records a prefix declared for the next element.
+func (d *axmlDecoder) startNamespace(chunk []byte) error {
+	if len(chunk) < 24 {
+		return errBinaryXML
+	}
+	prefix := d.string(binary.LittleEndian.Uint32(chunk[16:]))
+	uri := d.string(binary.LittleEndian.Uint32(chunk[20:]))
+	switch {
+	case uri == androidNamespace: // Obfuscators rename it, the parser expects android:
+		prefix = "android"
+	case prefix == "":
+		prefix = "ns" + strconv.Itoa(len(d.prefixes))
+	}
+	d.prefixes[uri] = prefix
+	d.pending = append(d.pending, fmt.Sprintf(" xmlns:%s=\"%s\"", prefix, escapeXML(uri)))
+	return nil
+}
+
+// startElement writes an opening tag with its attributes.
+func (d *axmlDecoder) startElement(chunk []byte) error {
+	if len(chunk) < 36 {
+		return errBinaryXML
+	}
+	name := d.string(binary.LittleEndian.Uint32(chunk[20:]))
+	attrStart := int(binary.LittleEndian.Uint16(chunk[24:]))
+	attrSize := int(binary.LittleEndian.Uint16(chunk[26:]))
+	attrCount := int(binary.LittleEndian.Uint16(chunk[28:]))
+	if name == "" || attrSize < 20 || 16+attrStart+attrCount*attrSize > len(chunk) {
+		return errBinaryXML
+	}
+	fmt.Fprintf(&d.out, "%s<%s", strings.Repeat("    ", d.depth), name)
+	for _, declaration := range d.pending {
+		d.out.WriteString(declaration)
+	}
+	d.pending = nil
+	for i := range attrCount {
+		attr := chunk[16+attrStart+i*attrSize:]
+		ns := d.string(binary.LittleEndian.Uint32(attr))
+		nameIndex := binary.LittleEndian.Uint32(attr[4:])
+		attrName := d.string(nameIndex)
+		if id, ok := d.attrID(nameIndex); ok && (attrName == "" || ns == androidNamespace) {
+			if known, found := androidAttributes[id]; found {
+				attrName = known // Some obfuscators scramble the pool names
+			}
+		}
+		if attrName == "" {
+			continue
+		}
+		value := d.attributeValue(attrName, binary.LittleEndian.Uint32(attr[8:]), attr[15], binary.LittleEndian.Uint32(attr[16:]))
+		if prefix, ok := d.prefixes[ns]; ok && ns != "" {
+			attrName = prefix + ":" + attrName
+		}
+		fmt.Fprintf(&d.out, " %s=\"%s\"", attrName, escapeXML(value))
+	}
+	d.openTag = true
+	d.openedTags = append(d.openedTags, name)
+	d.depth++
+	return nil
+}
+
+// endElement closes the innermost open element.
+func (d *axmlDecoder) endElement() error {
+	if d.depth == 0 {
+		return errBinaryXML
+	}
+	d.depth--
+	if d.openTag {
+		d.out.WriteString("/>\n")
+		d.openTag = false
+	} else {
+		fmt.Fprintf(&d.out, "%s</%s>\n", strings.Repeat("    ", d.depth), d.openedTags[d.depth])
+	}
+	d.openedTags = d.openedTags[:d.depth]
+	return nil
+}
+
+// attrID returns the resource ID of an attribute name from the resource map.
+func (d *axmlDecoder) attrID(nameIndex uint32) (uint32, bool) {
+	if int(nameIndex) < len(d.attrIDs) {
+		return d.attrIDs[nameIndex], true
+	}
+	return 0, false
+}
+
+// attributeValue renders a typed attribute value as text.
+func (d *axmlDecoder) attributeValue(attrName string, raw uint32, dataType byte, data uint32) string {
+	if raw != noString {
+		return d.string(raw)
+	}
+	switch {
+	case dataType == resValueReference, dataType == resValueAttribute:
+		marker := map[bool]string{true: "@", false: "?"}[dataType == resValueReference]
+		if data>>24 == 0x01 {
+			if name, ok := frameworkNames[data]; ok {
+				return marker + "android:" + name
+			}
+			return fmt.Sprintf("%sandroid:0x%08x", marker, data) // Flagged as an unresolved framework reference
+		}
+		if name, ok := d.names[data]; ok {
+			return marker + name
+		}
+		return fmt.Sprintf("%s0x%08x", marker, data)
+	case dataType == resValueString:
+		return d.string(data)
+	case dataType == resValueBoolean:
+		return strconv.FormatBool(data != 0)
+	case dataType == resValueIntDec, dataType == resValueIntHex:
+		if attrName == "protectionLevel" {
+			return protectionLevelName(data)
+		}
+		if dataType == resValueIntHex {
+			return "0x" + strconv.FormatUint(uint64(data), 16)
+		}
+		return strconv.Itoa(int(int32(data)))
+	case dataType == resValueFloat:
+		return strconv.FormatFloat(float64(math.Float32frombits(data)), 'g', -1, 32)
+	case dataType >= resValueColorMin && dataType <= resValueColorMax:
+		return fmt.Sprintf("#%08x", data)
+	case dataType == resValueNull:
+		return ""
+	}
+	return fmt.Sprintf("0x%x", data)
+}
+
+// protectionLevelName renders an integer protectionLevel as its flag names.
+func protectionLevelName(level uint32) string {
+	base := int(level & 0xf)
+	if base >= len(protectionBases) {
+		return "0x" + strconv.FormatUint(uint64(level), 16)
+	}
+	parts := []string{protectionBases[base]}
+	for _, flag := range protectionFlags {
+		if level&flag.bit != 0 {
+			parts = append(parts, flag.name)
+		}
+	}
+	return strings.Join(parts, "|")
+}
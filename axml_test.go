@@ -0,0 +1,128 @@
+package main
+
+import (
+	"archive/zip"   // Fixture APK
+	"errors"        // Error inspection
+	"io"            // Discarded text report
+	"os"            // Golden file
+	"path/filepath" // Fixture paths
+	"strings"       // Output checks
+	"testing"       // Test harness
+)
+
+// axmlAPK is a minimal APK holding a binary AndroidManifest.xml and a
+// resources.arsc in aapt's format: a permission with an integer protection
+// level, an activity whose deeplink host is a @string reference, and a
+// provider with boolean attributes.
+var axmlAPK = filepath.Join("testdata", "axml", "app.apk")
+
+// axmlFixture returns the binary manifest of the fixture APK and the resource
+// names of its resources.arsc.
+func axmlFixture(t *testing.T) ([]byte, map[uint32]string) {
+	t.Helper()
+	archive, err := zip.OpenReader(axmlAPK)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer archive.Close()
+	manifest, err := readZipEntry(&archive.Reader, "AndroidManifest.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names, _ := apkResourceTable(&archive.Reader, nil)
+	return manifest, names
+}
+
+func TestDecodeBinaryXML(t *testing.T) {
+	manifest, names := axmlFixture(t)
+	decoded, err := decodeBinaryXML(manifest, names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", "axml", "AndroidManifest.xml")
+	if *update {
+		if err := os.WriteFile(golden, decoded, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(decoded) != string(want) {
+		t.Errorf("decoded manifest differs from %s (go test -run DecodeBinaryXML -update rewrites it):\n%s", golden, decoded)
+	}
+}
+
+// TestDecodeBinaryXMLWithoutNames checks references keep their resource ID
+// when resources.arsc is missing or unreadable, instead of failing.
+func TestDecodeBinaryXMLWithoutNames(t *testing.T) {
+	manifest, _ := axmlFixture(t)
+	decoded, err := decodeBinaryXML(manifest, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`android:label="@0x7f010000"`, `android:host="@0x7f010001"`, `android:protectionLevel="signature|privileged"`} {
+		if !strings.Contains(string(decoded), want) {
+			t.Errorf("decoded manifest lacks %s:\n%s", want, decoded)
+		}
+	}
+}
+
+func TestDecodeBinaryXMLMalformed(t *testing.T) {
+	manifest, names := axmlFixture(t)
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"text xml":  []byte(`<?xml version="1.0"?><manifest/>`),
+		"truncated": manifest[:len(manifest)/2],
+		"no body":   manifest[:8],
+	} {
+		if _, err := decodeBinaryXML(data, names); !errors.Is(err, errBinaryXML) {
+			t.Errorf("%s: error = %v, want %v", name, err, errBinaryXML)
+		}
+	}
+}
+
+func TestProtectionLevelName(t *testing.T) {
+	for level, want := range map[uint32]string{
+		0x0:  "normal",
+		0x1:  "dangerous",
+		0x2:  "signature",
+		0x12: "signature|privileged",
+		0x42: "signature|appop",
+	} {
+		if got := protectionLevelName(level); got != want {
+			t.Errorf("protectionLevelName(%#x) = %q, want %q", level, got, want)
+		}
+	}
+}
+
+// TestExtractAPK checks the extracted folder analyzes like apktool output:
+// references resolve through the strings written from resources.arsc.
+func TestExtractAPK(t *testing.T) {
+	setOpts(t, textOptions())
+	dir, err := extractAPK(axmlAPK, filepath.Join(t.TempDir(), "app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stringsXML, err := os.ReadFile(filepath.Join(dir, "res", "values", "strings.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(stringsXML), `<string name="link_host">links.example.com</string>`) {
+		t.Errorf("strings.xml lacks link_host:\n%s", stringsXML)
+	}
+	result, err := analyzeFolder(io.Discard, io.Discard, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Package != "com.example.bin" {
+		t.Errorf("package = %q, want com.example.bin", result.Package)
+	}
+	if len(result.Unresolved) > 0 {
+		t.Errorf("unresolved references %q; strings.xml should define them", result.Unresolved)
+	}
+	if len(result.Hosts) != 1 || result.Hosts[0].Host != "links.example.com" {
+		t.Errorf("hosts = %+v, want links.example.com from @string/link_host", result.Hosts)
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bufio"   // Reading /proc/meminfo
+	"bytes"   // Per-target output buffers
+	"fmt"     // Error formatting
+	"io"      // Flushing buffered output
+	"os"      // Memory information files
+	"strconv" // Parsing memory figures
+	"strings" // Parsing memory figures
+	"sync"    // Worker pool
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// Scheduling defaults for batch runs. Each apktool run is a JVM, so decompiles
+// are capped separately from the cheap post-decompile parsing.
+const (
+	defaultDecompileJobs = 2          // Concurrent apktool processes when memory allows
+	apktoolMemory        = 1536 << 20 // Conservative RAM estimate for one apktool JVM
+)
+
+// decompile runs apktool on an APK with retries, see decompileWithRetry. Tests
+// replace it with a fake decompiler.
+var decompile = decompileWithRetry
+
+// targetResult carries the buffered output of one analyzed APK back to the printer.
+type targetResult struct {
+	output   bytes.Buffer  // Rendered analysis, flushed once the target is done
+	progress bytes.Buffer  // Progress and warnings, flushed to stderr just before output
+	report   *report       // Structured result when the analysis succeeded
+	err      error         // Failure that stopped the analysis, if any
+	done     chan struct{} // Closed when output and err are final
+}
+
+// analyzeTarget decodes or decompiles a single APK and writes its analysis to w
+// and how it got there to progress. The manifest and resources are decoded in-process unless -apktool is set;
+// apktool is the fallback when that fails. App bundles are always decoded
+// in-process. The decompile step holds one of the decompile slots for its
+// duration.
+func analyzeTarget(w, progress io.Writer, t target, decompileSlots chan struct{}) (*report, error) {
+	extractDir := decompiledDir(t.Path)
+	if opts.Quick { // Nothing is left next to the APKs
+		tempDir, err := os.MkdirTemp("", "deeeeper-quick-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(tempDir)
+		extractDir = tempDir
+	}
+	var outputDir string
+	var err error
+	if isBundle(t.Path) { // apktool can't read bundles, so there is no fallback
+		if outputDir, err = extractAAB(t.Path, extractDir); err != nil {
+			return nil, fmt.Errorf("reading app bundle: %w", err)
+		}
+		progressf(progress, "Decoded the bundle's base module in-process (no smali)")
+		return analyzeExtracted(w, progress, t, outputDir)
+	}
+	if !opts.APKTool {
+		outputDir, err = extractAPK(t.Path, extractDir)
+		switch {
+		case err == nil && opts.Quick:
+			progressf(progress, "Decoded manifest in-process (quick mode, no smali)")
+		case err == nil:
+			progressf(progress, "Decoded manifest in-process (no smali; use -apktool for code heuristics)")
+		case opts.NoFallback:
+			return nil, fmt.Errorf("decoding the manifest in-process (no apktool fallback with -no-fallback): %w", err)
+		default:
+			color.New(color.FgYellow).Fprintf(progress, "Could not decode the manifest in-process (%s), falling back to apktool\n", err)
+		}
+	}
+	if opts.APKTool || err != nil {
+		progressf(progress, "Decompiling APK...")
+		decompileSlots <- struct{}{}
+		var attempts int
+		outputDir, attempts, err = decompile(t.Path)
+		<-decompileSlots
+		if err != nil { // Handling errors from APK decompilation
+			if attempts > 1 {
+				return nil, fmt.Errorf("decompiling APK (%d attempts): %w", attempts, err)
+			}
+			return nil, fmt.Errorf("decompiling APK: %w", err)
+		}
+		if attempts > 1 { // Record flaky decompiles in the report
+			color.New(color.FgYellow).Fprintf(progress, "Decompiled after %d attempts (apktool failed transiently)\n", attempts)
+		}
+	}
+	return analyzeExtracted(w, progress, t, outputDir)
+}
+
+// analyzeExtracted analyzes the decoded or decompiled folder of a target and
+// fills in what the report takes from the input file itself.
+func analyzeExtracted(w, progress io.Writer, t target, outputDir string) (*report, error) {
+	result, err := analyzeFolder(w, progress, outputDir)
+	if err != nil {
+		return nil, err
+	}
+	result.Target, result.Origin = t.label(), t.Origin
+	result.SHA256, _ = fileSHA256(t.Path)
+	if result.SigningCert == "" { // v2/v3-only APKs have no META-INF signature file
+		result.SigningCert, _ = apkCertFingerprint(t.Path)
+	}
+	return result, nil
+}
+
+// analyzeTargets decompiles and analyzes every APK, continuing past failures.
+// Up to -jobs targets are processed at once with at most -decompile-jobs
+// apktool processes running; output is printed in input order.
+// It returns the reports of the successful targets and the number that failed.
+func analyzeTargets(targets []target) ([]*report, int) {
+	status := &progress{total: len(targets)}
+	stopHeartbeat := startHeartbeat(status, opts.Heartbeat)
+	defer stopHeartbeat()
+
+	decompileSlots := make(chan struct{}, decompileJobs())
+	results := make([]*targetResult, len(targets))
+	for i := range results {
+		results[i] = &targetResult{done: make(chan struct{})}
+	}
+
+	queue := make(chan int)
+	var workers sync.WaitGroup
+	for range max(opts.Jobs, 1) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for i := range queue {
+				result := results[i]
+				result.report, result.err = analyzeTarget(&result.output, &result.progress, targets[i], decompileSlots)
+				if result.err == nil {
+					if err := streamReport(result.report); err != nil { // Best effort; the scan goes on
+						color.New(color.FgYellow).Fprintf(&result.progress, "Warning: could not post results to %s: %s\n", redactURL(opts.Webhook), err)
+					}
+				}
+				status.done.Add(1)
+				close(result.done)
+			}
+		}()
+	}
+	go func() {
+		for i := range targets {
+			queue <- i
+		}
+		close(queue)
+	}()
+
+	batch := len(targets) > 1
+	var reports []*report
+	failed := 0
+	for i, t := range targets {
+		result := results[i]
+		<-result.done
+
+		if batch || t.Origin != "" { // Label each APK so batch output stays readable
+			pkg := ""
+			if result.report != nil && result.report.Package != "" {
+				pkg = " [" + result.report.Package + "]"
+			}
+			header := color.New(color.FgMagenta)
+			if t.Origin != "" {
+				header.Fprintf(textWriter(), "\n=== %s (from %s)%s ===\n", t.Entry, t.Origin, pkg)
+			} else {
+				header.Fprintf(textWriter(), "\n=== %s%s ===\n", t.Path, pkg)
+			}
+		}
+		io.Copy(color.Output, &result.progress)
+		io.Copy(textWriter(), &result.output)
+		if result.err != nil {
+			color.Red("Error %s\n", result.err)
+			failed++
+		} else {
+			reports = append(reports, result.report)
+		}
+		result.output.Reset() // Release the buffers once printed
+		result.progress.Reset()
+	}
+	workers.Wait()
+	if opts.WarnDuplicatePackages {
+		warnDuplicatePackages(reports)
+	}
+	return reports, failed
+}
+
+// warnDuplicatePackages points out APKs in one batch that decompiled to the same
+// package, which usually means a file was copied or mislabeled.
+func warnDuplicatePackages(reports []*report) {
+	targetsByPackage := make(map[string][]string)
+	for _, r := range reports {
+		if r.Package != "" {
+			targetsByPackage[r.Package] = append(targetsByPackage[r.Package], r.Target)
+		}
+	}
+	for _, pkg := range sortedKeys(targetsByPackage) {
+		if targets := targetsByPackage[pkg]; len(targets) > 1 {
+			color.Yellow("Warning: %d APKs resolve to package %s (possible duplicates): %s", len(targets), pkg, strings.Join(targets, ", "))
+		}
+	}
+}
+
+// decompileJobs returns the apktool concurrency limit. An explicit
+// -decompile-jobs wins; otherwise the default is lowered when the host (or its
+// cgroup) does not have enough free memory for that many JVMs.
+func decompileJobs() int {
+	if opts.DecompileJobs > 0 {
+		return opts.DecompileJobs
+	}
+	available, ok := availableMemory()
+	if !ok {
+		return defaultDecompileJobs
+	}
+	return max(1, min(defaultDecompileJobs, int(available/apktoolMemory)))
+}
+
+// availableMemory reports the memory available to this process in bytes,
+// taking the tighter of /proc/meminfo and a cgroup v2 limit. It returns false
+// on platforms where neither source exists.
+func availableMemory() (uint64, bool) {
+	var available uint64
+	found := false
+
+	if file, err := os.Open("/proc/meminfo"); err == nil {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) >= 2 && fields[0] == "MemAvailable:" {
+				if kb, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+					available, found = kb*1024, true
+				}
+			}
+		}
+		file.Close()
+	}
+
+	limit, limitErr := readCgroupValue("/sys/fs/cgroup/memory.max")
+	usage, usageErr := readCgroupValue("/sys/fs/cgroup/memory.current")
+	if limitErr == nil && usageErr == nil && limit > usage {
+		if !found || limit-usage < available {
+			available, found = limit-usage, true
+		}
+	}
+	return available, found
+}
+
+// readCgroupValue parses a single-number cgroup file; "max" is reported as an error.
+func readCgroupValue(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"         // Captured output
+	"errors"        // Fake decompile failures
+	"fmt"           // Fixture packages
+	"io"            // Discarded output
+	"os"            // Fixture APK copy
+	"os/exec"       // apktool availability
+	"path/filepath" // Fixture paths
+	"strings"       // Output checks
+	"sync/atomic"   // Concurrency accounting
+	"testing"       // Test harness
+	"time"          // Simulated decompile durations
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// fakeDecompiler stands in for apktool: it hands out prepared folders and
+// records how many decompiles ran at once.
+type fakeDecompiler struct {
+	folders map[string]string        // Decompiled folder per APK path
+	delays  map[string]time.Duration // How long each decompile takes
+	fail    map[string]bool          // APK paths whose decompile fails
+	active  atomic.Int32             // Decompiles running right now
+	peak    atomic.Int32             // Most decompiles seen running at once
+	calls   atomic.Int32             // Decompiles started
+}
+
+func (f *fakeDecompiler) decompile(apkPath string) (string, int, error) {
+	f.calls.Add(1)
+	running := f.active.Add(1)
+	defer f.active.Add(-1)
+	for {
+		peak := f.peak.Load()
+		if running <= peak || f.peak.CompareAndSwap(peak, running) {
+			break
+		}
+	}
+	time.Sleep(f.delays[apkPath])
+	if f.fail[apkPath] {
+		return "", 1, errors.New("fake apktool failure")
+	}
+	return f.folders[apkPath], 1, nil
+}
+
+// batchFixture prepares n decompiled targets with packages org.example.app0
+// and up. Earlier targets take longer to decompile, so they finish last.
+func batchFixture(t *testing.T, n int) ([]target, *fakeDecompiler) {
+	t.Helper()
+	fake := &fakeDecompiler{folders: make(map[string]string), delays: make(map[string]time.Duration), fail: make(map[string]bool)}
+	var targets []target
+	for i := range n {
+		dir := t.TempDir()
+		manifest := strings.Replace(selftestManifest, `package="org.deeeeper.selftest"`, fmt.Sprintf(`package="org.example.app%d"`, i), 1)
+		if err := writeSelftestTarget(dir, manifest, selftestStrings); err != nil {
+			t.Fatal(err)
+		}
+		apk := filepath.Join(t.TempDir(), fmt.Sprintf("app%d.apk", i))
+		fake.folders[apk] = dir
+		fake.delays[apk] = time.Duration(n-i) * 15 * time.Millisecond
+		targets = append(targets, target{Path: apk})
+	}
+	saved := decompile
+	decompile = fake.decompile
+	t.Cleanup(func() { decompile = saved })
+	return targets, fake
+}
+
+func TestAnalyzeTargetsDecompileSlots(t *testing.T) {
+	for _, tc := range []struct {
+		jobs, decompileJobs int
+	}{
+		{jobs: 6, decompileJobs: 2},
+		{jobs: 6, decompileJobs: 1},
+		{jobs: 2, decompileJobs: 4}, // Workers cap decompiles too
+	} {
+		t.Run(fmt.Sprintf("jobs=%d,decompile-jobs=%d", tc.jobs, tc.decompileJobs), func(t *testing.T) {
+			targets, fake := batchFixture(t, 6)
+			setOpts(t, options{Format: "json", APKTool: true, Jobs: tc.jobs, DecompileJobs: tc.decompileJobs})
+			reports, failed := analyzeTargets(targets)
+			if failed != 0 || len(reports) != len(targets) {
+				t.Fatalf("%d reports, %d failed; want %d and 0", len(reports), failed, len(targets))
+			}
+			want := min(tc.jobs, tc.decompileJobs)
+			if peak := fake.peak.Load(); peak != int32(want) {
+				t.Errorf("peak concurrent decompiles = %d, want %d", peak, want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeTargetsOrder(t *testing.T) {
+	targets, fake := batchFixture(t, 5)
+	fake.fail[targets[2].Path] = true
+	setOpts(t, options{Format: "json", APKTool: true, Jobs: 5, DecompileJobs: 5})
+	reports, failed := analyzeTargets(targets)
+	if failed != 1 {
+		t.Errorf("failed = %d, want 1", failed)
+	}
+	var packages []string
+	for _, r := range reports {
+		packages = append(packages, r.Package)
+	}
+	want := "org.example.app0 org.example.app1 org.example.app3 org.example.app4"
+	if got := strings.Join(packages, " "); got != want {
+		t.Errorf("reports in order %s, want input order %s", got, want)
+	}
+	if calls := fake.calls.Load(); calls != 5 {
+		t.Errorf("%d decompiles, want one per target", calls)
+	}
+}
+
+// TestAnalyzeTargetWarningsBuffered checks analysis warnings land in the
+// target's progress buffer rather than on the shared stderr, where parallel
+// workers would interleave them.
+func TestAnalyzeTargetWarningsBuffered(t *testing.T) {
+	targets, _ := batchFixture(t, 1)
+	setOpts(t, options{Format: "json", APKTool: true, Locale: "xx"}) // No res/values-xx: one warning
+	var shared bytes.Buffer
+	saved := color.Output
+	color.Output = &shared
+	t.Cleanup(func() { color.Output = saved })
+
+	var progress bytes.Buffer
+	if _, err := analyzeTarget(io.Discard, &progress, targets[0], make(chan struct{}, 1)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(progress.String(), "Warning: no res/values-xx folder") {
+		t.Errorf("progress buffer lacks the locale warning:\n%s", progress.String())
+	}
+	if shared.Len() != 0 {
+		t.Errorf("analysis wrote to the shared output:\n%s", shared.String())
+	}
+}
+
+// BenchmarkAnalyzeTarget compares -quick with the default in-process decode
+// and with a full apktool decompile of the fixture APK. The apktool case is
+// skipped when apktool isn't on PATH.
+func BenchmarkAnalyzeTarget(b *testing.B) {
+	data, err := os.ReadFile(axmlAPK)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, mode := range []struct {
+		name    string
+		options options
+	}{
+		{"quick", options{Format: "json", Quick: true, NoFallback: true}},
+		{"in-process", options{Format: "json", NoFallback: true}},
+		{"apktool", options{Format: "json", APKTool: true}},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			if mode.options.APKTool {
+				if _, err := exec.LookPath("apktool"); err != nil {
+					b.Skip("apktool not found in PATH")
+				}
+			}
+			apk := filepath.Join(b.TempDir(), "app.apk") // Decompiled folders land next to it
+			if err := os.WriteFile(apk, data, 0o644); err != nil {
+				b.Fatal(err)
+			}
+			setOpts(b, mode.options)
+			slots := make(chan struct{}, 1)
+			b.ResetTimer()
+			for range b.N {
+				if _, err := analyzeTarget(io.Discard, io.Discard, target{Path: apk}, slots); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
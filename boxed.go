@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"          // Box rendering
+	"io"           // Output destination
+	"slices"       // Ordering rule IDs
+	"strings"      // Border construction
+	"unicode/utf8" // Visible width of box content
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// styledLine is one detail line of a component listing. Text and note are kept
+// apart from the color so boxed output can measure and pad the visible width.
+type styledLine struct {
+	text  string                        // Colored part of the line
+	paint func(a ...interface{}) string // Color applied to text
+	note  string                        // Uncolored suffix, e.g. the source of a resource deeplink
+}
+
+// riskSummary condenses the findings for one component into its worst severity
+// and a single line such as "risk: high (exported-provider, single-user-provider)".
+func riskSummary(findings []finding, kind, name string) (string, string) {
+	worst := ""
+	var ruleIDs []string
+	for _, f := range findings {
+		if f.Kind != kind || f.Component != name {
+			continue
+		}
+		if worst == "" || severityRank[f.Severity] > severityRank[worst] {
+			worst = f.Severity
+		}
+		if !slices.Contains(ruleIDs, f.Rule) {
+			ruleIDs = append(ruleIDs, f.Rule)
+		}
+	}
+	if worst == "" {
+		return "info", "risk: none recorded"
+	}
+	slices.Sort(ruleIDs)
+	return worst, fmt.Sprintf("risk: %s (%s)", worst, strings.Join(ruleIDs, ", "))
+}
+
+// severityColors maps finding severities to the color of the box risk line.
+var severityColors = map[string]color.Attribute{
+	"critical": color.FgHiRed,
+	"high":     color.FgRed,
+	"medium":   color.FgYellow,
+	"low":      color.FgBlue,
+	"info":     color.FgWhite,
+}
+
+// printBox renders one exported component inside a bordered box: the risk
+// summary on top, then the name, type and attributes, then its detail lines.
+func printBox(w io.Writer, name, kind, attributes, severity, summary string, lines []styledLine) {
+	border := color.New(color.FgHiBlack).SprintFunc()
+	cyan := color.New(color.FgCyan).SprintFunc()
+	risk := color.New(severityColors[severity]).SprintFunc()
+
+	header := []styledLine{
+		{text: summary, paint: risk},
+		{text: name, paint: cyan, note: " [" + kind + "]"},
+		{text: attributes, paint: fmt.Sprint},
+	}
+	width := 0
+	for _, line := range append(header, lines...) {
+		width = max(width, utf8.RuneCountInString(line.text+line.note))
+	}
+
+	row := func(line styledLine) {
+		padding := strings.Repeat(" ", width-utf8.RuneCountInString(line.text+line.note))
+		fmt.Fprintf(w, "%s %s%s%s %s\n", border("│"), line.paint(line.text), line.note, padding, border("│"))
+	}
+	rule := strings.Repeat("─", width+2)
+	fmt.Fprintln(w, border("┌"+rule+"┐"))
+	row(header[0])
+	fmt.Fprintln(w, border("├"+rule+"┤"))
+	row(header[1])
+	row(header[2])
+	if len(lines) > 0 {
+		fmt.Fprintln(w, border("├"+rule+"┤"))
+		for _, line := range lines {
+			row(line)
+		}
+	}
+	fmt.Fprintln(w, border("└"+rule+"┘"))
+}
@@ -0,0 +1,101 @@
+package main
+
+// protectedBroadcasts are actions the platform declares with
+// <protected-broadcast>: only system processes may send them, so a receiver
+// listening for them can't be triggered by a spoofed intent from another app.
+var protectedBroadcasts = map[string]bool{
+	"android.intent.action.BOOT_COMPLETED":                             true,
+	"android.intent.action.LOCKED_BOOT_COMPLETED":                      true,
+	"android.intent.action.PRE_BOOT_COMPLETED":                         true,
+	"android.intent.action.USER_PRESENT":                               true,
+	"android.intent.action.USER_UNLOCKED":                              true,
+	"android.intent.action.ACTION_SHUTDOWN":                            true,
+	"android.intent.action.REBOOT":                                     true,
+	"android.intent.action.SCREEN_ON":                                  true,
+	"android.intent.action.SCREEN_OFF":                                 true,
+	"android.intent.action.TIME_TICK":                                  true,
+	"android.intent.action.TIME_SET":                                   true,
+	"android.intent.action.TIMEZONE_CHANGED":                           true,
+	"android.intent.action.DATE_CHANGED":                               true,
+	"android.intent.action.LOCALE_CHANGED":                             true,
+	"android.intent.action.CONFIGURATION_CHANGED":                      true,
+	"android.intent.action.BATTERY_CHANGED":                            true,
+	"android.intent.action.BATTERY_LOW":                                true,
+	"android.intent.action.BATTERY_OKAY":                               true,
+	"android.intent.action.ACTION_POWER_CONNECTED":                     true,
+	"android.intent.action.ACTION_POWER_DISCONNECTED":                  true,
+	"android.intent.action.DEVICE_STORAGE_LOW":                         true,
+	"android.intent.action.DEVICE_STORAGE_OK":                          true,
+	"android.intent.action.AIRPLANE_MODE":                              true,
+	"android.intent.action.PACKAGE_ADDED":                              true,
+	"android.intent.action.PACKAGE_REPLACED":                           true,
+	"android.intent.action.MY_PACKAGE_REPLACED":                        true,
+	"android.intent.action.PACKAGE_REMOVED":                            true,
+	"android.intent.action.PACKAGE_FULLY_REMOVED":                      true,
+	"android.intent.action.PACKAGE_CHANGED":                            true,
+	"android.intent.action.PACKAGE_DATA_CLEARED":                       true,
+	"android.intent.action.PACKAGE_RESTARTED":                          true,
+	"android.intent.action.MY_PACKAGE_SUSPENDED":                       true,
+	"android.intent.action.MY_PACKAGE_UNSUSPENDED":                     true,
+	"android.intent.action.UID_REMOVED":                                true,
+	"android.intent.action.USER_ADDED":                                 true,
+	"android.intent.action.USER_REMOVED":                               true,
+	"android.intent.action.USER_SWITCHED":                              true,
+	"android.intent.action.MEDIA_MOUNTED":                              true,
+	"android.intent.action.MEDIA_UNMOUNTED":                            true,
+	"android.intent.action.MEDIA_EJECT":                                true,
+	"android.intent.action.HEADSET_PLUG":                               true,
+	"android.intent.action.DOCK_EVENT":                                 true,
+	"android.intent.action.NEW_OUTGOING_CALL":                          true,
+	"android.intent.action.PHONE_STATE":                                true,
+	"android.intent.action.SIM_STATE_CHANGED":                          true,
+	"android.intent.action.APPLICATION_RESTRICTIONS_CHANGED":           true,
+	"android.intent.action.MANAGED_PROFILE_ADDED":                      true,
+	"android.intent.action.MANAGED_PROFILE_REMOVED":                    true,
+	"android.intent.action.MANAGED_PROFILE_UNLOCKED":                   true,
+	"android.intent.action.MANAGED_PROFILE_AVAILABLE":                  true,
+	"android.intent.action.MANAGED_PROFILE_UNAVAILABLE":                true,
+	"android.app.action.DEVICE_ADMIN_ENABLED":                          true,
+	"android.app.action.DEVICE_ADMIN_DISABLED":                         true,
+	"android.app.action.DEVICE_ADMIN_DISABLE_REQUESTED":                true,
+	"android.app.action.PROFILE_PROVISIONING_COMPLETE":                 true,
+	"android.app.action.NEXT_ALARM_CLOCK_CHANGED":                      true,
+	"android.app.action.SCHEDULE_EXACT_ALARM_PERMISSION_STATE_CHANGED": true,
+	"android.appwidget.action.APPWIDGET_UPDATE":                        true,
+	"android.appwidget.action.APPWIDGET_ENABLED":                       true,
+	"android.appwidget.action.APPWIDGET_DISABLED":                      true,
+	"android.appwidget.action.APPWIDGET_DELETED":                       true,
+	"android.net.conn.CONNECTIVITY_CHANGE":                             true,
+	"android.net.wifi.STATE_CHANGE":                                    true,
+	"android.net.wifi.WIFI_STATE_CHANGED":                              true,
+	"android.bluetooth.adapter.action.STATE_CHANGED":                   true,
+	"android.bluetooth.device.action.ACL_CONNECTED":                    true,
+	"android.bluetooth.device.action.ACL_DISCONNECTED":                 true,
+	"android.bluetooth.device.action.BOND_STATE_CHANGED":               true,
+	"android.location.PROVIDERS_CHANGED":                               true,
+	"android.location.MODE_CHANGED":                                    true,
+	"android.media.RINGER_MODE_CHANGED":                                true,
+	"android.media.AUDIO_BECOMING_NOISY":                               true,
+	"android.os.action.POWER_SAVE_MODE_CHANGED":                        true,
+	"android.os.action.DEVICE_IDLE_MODE_CHANGED":                       true,
+	"android.provider.Telephony.SMS_RECEIVED":                          true,
+	"android.provider.Telephony.SMS_DELIVER":                           true,
+	"android.provider.Telephony.WAP_PUSH_DELIVER":                      true,
+	"android.provider.Telephony.WAP_PUSH_RECEIVED":                     true,
+	"android.telephony.action.DEFAULT_SMS_SUBSCRIPTION_CHANGED":        true,
+	"com.google.android.c2dm.intent.RECEIVE":                           true, // Requires the signature-level c2dm.permission.SEND
+}
+
+// spoofableActions returns the actions of a receiver that any app may
+// broadcast: everything that is not a protected broadcast.
+func spoofableActions(component App) []string {
+	var actions []string
+	for _, filter := range component.Filters {
+		for _, action := range filter.Actions {
+			if !protectedBroadcasts[action.Name] {
+				actions = appendUnique(actions, action.Name)
+			}
+		}
+	}
+	return actions
+}
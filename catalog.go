@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"     // Summary formatting
+	"io"      // Output destination
+	"sort"    // Deterministic ordering
+	"strings" // URI splitting
+
+	"Deeeeper/Deeeeper/deeeeper" // Scheme and host normalization
+	"github.com/fatih/color"     // Colorized output in terminal
+)
+
+// catalogSummary describes the deeplink surface across every app of a batch run.
+type catalogSummary struct {
+	Apps          int                 `json:"apps"`           // Distinct apps in the batch
+	SharedSchemes map[string][]string `json:"shared_schemes"` // Custom scheme → apps declaring it
+	SharedHosts   map[string][]string `json:"shared_hosts"`   // scheme://host → apps declaring it
+	SharedURIs    map[string][]string `json:"shared_uris"`    // Full URI → apps declaring it
+	UniqueURIs    map[string][]string `json:"unique_uris"`    // App → URIs no other app declares
+}
+
+// buildCatalog aggregates the exported deeplinks of every report. Apps are
+// identified by package name so several builds of one app count once. Schemes and
+// hosts are compared case-insensitively.
+func buildCatalog(reports []*report) catalogSummary {
+	schemes := make(map[string]map[string]bool)
+	hosts := make(map[string]map[string]bool)
+	uris := make(map[string]map[string]bool)
+	apps := make(map[string]bool)
+
+	add := func(index map[string]map[string]bool, key, app string) {
+		if index[key] == nil {
+			index[key] = make(map[string]bool)
+		}
+		index[key][app] = true
+	}
+
+	for _, r := range reports {
+		app := r.Package
+		if app == "" {
+			app = r.Target
+		}
+		apps[app] = true
+		for _, f := range r.Findings {
+			for _, uri := range f.URIs {
+				scheme, host := splitSchemeHost(uri)
+				if scheme != "http" && scheme != "https" { // Web schemes are shared by everyone; hosts cover them
+					add(schemes, scheme, app)
+				}
+				if host != "" {
+					add(hosts, scheme+"://"+host, app)
+				}
+				add(uris, uri, app)
+			}
+		}
+	}
+
+	summary := catalogSummary{
+		Apps:          len(apps),
+		SharedSchemes: shared(schemes),
+		SharedHosts:   shared(hosts),
+		SharedURIs:    shared(uris),
+		UniqueURIs:    make(map[string][]string),
+	}
+	for uri, owners := range uris {
+		if len(owners) == 1 {
+			for app := range owners {
+				summary.UniqueURIs[app] = append(summary.UniqueURIs[app], uri)
+			}
+		}
+	}
+	for app := range summary.UniqueURIs {
+		sort.Strings(summary.UniqueURIs[app])
+	}
+	return summary
+}
+
+// shared keeps the index entries claimed by more than one app, with sorted app lists.
+func shared(index map[string]map[string]bool) map[string][]string {
+	result := make(map[string][]string)
+	for key, owners := range index {
+		if len(owners) < 2 {
+			continue
+		}
+		for app := range owners {
+			result[key] = append(result[key], app)
+		}
+		sort.Strings(result[key])
+	}
+	return result
+}
+
+// splitSchemeHost returns the normalized scheme and host of a constructed URI.
+func splitSchemeHost(uri string) (string, string) {
+	scheme, rest, found := strings.Cut(uri, "://")
+	if !found {
+		scheme, _, _ = strings.Cut(uri, ":")
+		return deeeeper.NormalizeScheme(scheme), ""
+	}
+	host := rest
+	if end := strings.IndexAny(rest, "/?#"); end >= 0 {
+		host = rest[:end]
+	}
+	return deeeeper.NormalizeScheme(scheme), deeeeper.NormalizeHost(host)
+}
+
+// printCatalog renders the cross-app summary for the terminal.
+func printCatalog(w io.Writer, summary catalogSummary) {
+	section := color.New(color.FgYellow)
+	cyan := color.New(color.FgCyan).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+
+	section.Fprintf(w, "\nCatalog summary (%d apps):\n", summary.Apps)
+	for _, group := range []struct {
+		title string
+		index map[string][]string
+	}{
+		{"Shared custom schemes", summary.SharedSchemes},
+		{"Shared hosts", summary.SharedHosts},
+		{"Shared deeplinks", summary.SharedURIs},
+	} {
+		section.Fprintf(w, "%s:\n", group.title)
+		if len(group.index) == 0 {
+			fmt.Fprintln(w, "  (none)")
+		}
+		for _, key := range sortedKeys(group.index) {
+			fmt.Fprintf(w, "  %s — %s\n", green(key), strings.Join(group.index[key], ", "))
+		}
+	}
+	section.Fprintln(w, "Unique deeplinks:")
+	if len(summary.UniqueURIs) == 0 {
+		fmt.Fprintln(w, "  (none)")
+	}
+	for _, app := range sortedKeys(summary.UniqueURIs) {
+		fmt.Fprintf(w, "  %s\n", cyan(app))
+		for _, uri := range summary.UniqueURIs[app] {
+			fmt.Fprintf(w, "    %s\n", green(uri))
+		}
+	}
+}
+
+// sortedKeys returns the keys of a string-keyed map in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
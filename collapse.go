@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"     // Summary formatting
+	"sort"    // Family ordering
+	"strings" // Path splitting
+)
+
+// uriFamily gathers the declared paths of one scheme+host, kept apart by how
+// they match: exact paths, prefixes and patterns.
+type uriFamily struct {
+	Base    string                    // scheme://host, or scheme: for opaque URIs
+	Members map[string]map[string]int // First path segment ("/product/") -> kind -> count
+	Total   int                       // Distinct URIs in the family
+	Only    string                    // The URI itself when the family has a single member
+}
+
+// pathKinds are the ways a <data> path can match, in display order.
+var pathKinds = []struct{ kind, singular, plural string }{
+	{"path", "path", "paths"},
+	{"prefix", "prefix", "prefixes"},
+	{"pattern", "pattern", "patterns"},
+}
+
+// collapseFilters groups the URIs of a component's filters by scheme and host.
+func collapseFilters(filters []IntentFilter) []*uriFamily {
+	index := make(map[string]*uriFamily)
+	var families []*uriFamily
+	seen := make(map[string]bool)
+	for _, filter := range filters {
+		if opts.Browsable && !isBrowsable(filter) {
+			continue
+		}
+		for _, data := range filter.Data {
+			uri := constructURI(data)
+			if uri == "" || seen[uri] {
+				continue
+			}
+			seen[uri] = true
+			base := constructURI(Data{Scheme: data.Scheme, Host: data.Host, Port: data.Port})
+			path, kind := data.Path, "path"
+			switch {
+			case data.Path != "":
+			case data.PathPrefix != "":
+				path, kind = data.PathPrefix, "prefix"
+			case data.PathPattern != "":
+				path, kind = data.PathPattern, "pattern"
+			default:
+				path = ""
+			}
+			family := index[base]
+			if family == nil {
+				family = &uriFamily{Base: strings.TrimSuffix(base, "/"), Members: make(map[string]map[string]int), Only: uri}
+				index[base] = family
+				families = append(families, family)
+			}
+			segment := pathFamily(path)
+			if family.Members[segment] == nil {
+				family.Members[segment] = make(map[string]int)
+			}
+			family.Members[segment][kind]++
+			family.Total++
+		}
+	}
+	return families
+}
+
+// pathFamily returns the first path segment of a path as a directory
+// ("/product/"), or "/" for top-level and empty paths.
+func pathFamily(path string) string {
+	trimmed := strings.TrimPrefix(path, "/")
+	if segment, _, nested := strings.Cut(trimmed, "/"); nested && segment != "" {
+		return "/" + segment + "/"
+	}
+	return "/"
+}
+
+// summary renders a family on one line, e.g.
+// "https://app.example.com — 37 paths under /product/, 12 under /account/".
+func (f *uriFamily) summary() string {
+	if f.Total == 1 {
+		return f.Only
+	}
+	type part struct {
+		count int
+		text  string
+	}
+	var parts []part
+	for segment, kinds := range f.Members {
+		for _, k := range pathKinds {
+			count := kinds[k.kind]
+			if count == 0 {
+				continue
+			}
+			noun := k.plural
+			if count == 1 {
+				noun = k.singular
+			}
+			parts = append(parts, part{count, fmt.Sprintf("%d %s under %s", count, noun, segment)})
+		}
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		if parts[i].count != parts[j].count {
+			return parts[i].count > parts[j].count
+		}
+		return parts[i].text < parts[j].text
+	})
+	texts := make([]string, len(parts))
+	for i, p := range parts {
+		texts[i] = p.text
+	}
+	return fmt.Sprintf("%s — %s", f.Base, strings.Join(texts, ", "))
+}
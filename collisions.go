@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"     // Pattern and evidence formatting
+	"io"      // Output destination
+	"slices"  // Component lists
+	"strings" // Normalization
+
+	"Deeeeper/Deeeeper/deeeeper" // Scheme and host normalization
+	"github.com/fatih/color"     // Colorized output in terminal
+)
+
+// deeplinkCollision is one URI pattern that several exported activities of the
+// app claim for the same action, so opening it asks the user to choose.
+type deeplinkCollision struct {
+	Pattern    string   `json:"pattern"`    // Expanded filter shape, e.g. https://example.com/open/*
+	Action     string   `json:"action"`     // Intent action the filters share
+	Components []string `json:"components"` // Claiming components as declared, in manifest order
+	Kind       string   `json:"kind"`       // Kind of the first claiming component, activity or alias
+}
+
+// collectDeeplinkCollisions expands the filters of every exported activity and
+// alias, as -matrix does, and returns the patterns claimed by more than one
+// component for the same action. Schemes and hosts compare normalized.
+// An alias and its target activity count as one component, since an alias
+// exists to share its target's behavior, and a component repeating a pattern
+// in several of its own filters doesn't collide with itself.
+func collectDeeplinkCollisions(manifest Manifest) []deeplinkCollision {
+	type claim struct {
+		kind       string          // Kind of the first claimant
+		names      []string        // Declared names, in manifest order
+		identities map[string]bool // Activities behind them, aliases resolved
+	}
+	claims := make(map[string]*claim)
+	var order []string
+	for _, group := range componentGroups(manifest)[:2] { // Activities and aliases
+		for _, component := range group.Components {
+			if exported, _ := isExported(component, group.Kind); !exported {
+				continue
+			}
+			identity := qualifiedName(manifest.Package, component.Name)
+			if group.Kind == "alias" && component.TargetActivity != "" {
+				identity = qualifiedName(manifest.Package, component.TargetActivity)
+			}
+			for _, filter := range component.Filters {
+				for _, row := range expandFilter(0, filter) {
+					pattern, ok := collisionPattern(row)
+					if !ok {
+						continue
+					}
+					for _, action := range filter.Actions {
+						key := action.Name + "\x00" + pattern
+						c := claims[key]
+						if c == nil {
+							c = &claim{kind: group.Kind, identities: make(map[string]bool)}
+							claims[key] = c
+							order = append(order, key)
+						}
+						c.identities[identity] = true
+						if !slices.Contains(c.names, component.Name) {
+							c.names = append(c.names, component.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	var collisions []deeplinkCollision
+	for _, key := range order {
+		if c := claims[key]; len(c.identities) > 1 {
+			action, pattern, _ := strings.Cut(key, "\x00")
+			collisions = append(collisions, deeplinkCollision{Pattern: pattern, Action: action, Components: c.names, Kind: c.kind})
+		}
+	}
+	return collisions
+}
+
+// collisionPattern renders an expanded filter row as a URI pattern, with the
+// scheme and host normalized. ok is false for rows holding unresolved
+// references or placeholders, which could only collide by accident.
+func collisionPattern(row filterRow) (string, bool) {
+	for _, part := range []string{row.Scheme, row.Host, row.Port, row.Path} {
+		if deeeeper.HasUnresolved(part) {
+			return "", false
+		}
+	}
+	pattern := deeeeper.NormalizeScheme(row.Scheme) + ":"
+	if ssp, ok := strings.CutPrefix(row.Path, "ssp "); ok {
+		return pattern + ssp, true
+	}
+	if row.Host != "-" || row.Port != "-" {
+		pattern += "//"
+		if row.Host != "-" {
+			pattern += deeeeper.NormalizeHost(row.Host)
+		}
+		if row.Port != "-" {
+			pattern += ":" + row.Port
+		}
+	}
+	if row.Path != "-" {
+		pattern += row.Path
+	}
+	return pattern, true
+}
+
+// collisionFindings raises an informational finding per colliding pattern,
+// reported on the first component claiming it. Its ID is keyed on the claimant
+// whose qualified name sorts first instead, so it doesn't follow manifest order.
+func collisionFindings(pkg string, collisions []deeplinkCollision) []finding {
+	var findings []finding
+	for _, collision := range collisions {
+		evidence := fmt.Sprintf("%s for %s is claimed by %s", collision.Pattern, collision.Action, strings.Join(collision.Components, ", "))
+		f := newFinding(pkg, "deeplink-collision", collision.Kind, collision.Components[0], nil, evidence)
+		var claimants []string
+		for _, name := range collision.Components {
+			claimants = append(claimants, qualifiedName(pkg, name))
+		}
+		f.Fingerprint = fingerprint(pkg, "deeplink-collision", collision.Kind, slices.Min(claimants), collision.Action, collision.Pattern)
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// printDeeplinkCollisions lists the patterns claimed by several components.
+func printDeeplinkCollisions(w io.Writer, collisions []deeplinkCollision) {
+	printSection(w, "\nDeeplink collisions (the user is asked to choose):")
+	cyan := color.New(color.FgCyan).SprintFunc()
+	for _, collision := range collisions {
+		action := ""
+		if collision.Action != "android.intent.action.VIEW" {
+			action = fmt.Sprintf(" (%s)", collision.Action)
+		}
+		names := make([]string, len(collision.Components))
+		for i, name := range collision.Components {
+			names[i] = cyan(name)
+		}
+		fmt.Fprintf(w, "  %s%s: %s\n", collision.Pattern, action, strings.Join(names, ", "))
+	}
+}
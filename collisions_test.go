@@ -0,0 +1,110 @@
+package main
+
+import (
+	"slices"  // Result comparison
+	"strings" // Output checks
+	"testing" // Test harness
+)
+
+// collisionManifest copies the /open filter of .Main into .Copy, with the
+// scheme and host in other case, which is the deliberate collision. The rest
+// claims the same URI without colliding: an alias of .Main, a hidden
+// activity, .Main itself in a second filter and .Sharer for another action.
+// The unresolved host of .Pending never compares.
+const collisionManifest = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="org.example.collide">
+    <application>
+        <activity android:name=".Main" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="https" android:host="example.com" android:pathPrefix="/open"/>
+            </intent-filter>
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="https" android:host="example.com" android:pathPrefix="/open"/>
+                <data android:scheme="https" android:host="example.com" android:path="/main-only"/>
+            </intent-filter>
+        </activity>
+        <activity-alias android:name=".Shortcut" android:targetActivity=".Main" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="https" android:host="example.com" android:path="/main-only"/>
+            </intent-filter>
+        </activity-alias>
+        <activity android:name="org.example.collide.Copy" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="HTTPS" android:host="Example.COM" android:pathPrefix="/open"/>
+            </intent-filter>
+        </activity>
+        <activity android:name=".Hidden" android:exported="false">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="https" android:host="example.com" android:pathPrefix="/open"/>
+            </intent-filter>
+        </activity>
+        <activity android:name=".Sharer" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.SEND"/>
+                <data android:scheme="https" android:host="example.com" android:path="/main-only"/>
+            </intent-filter>
+        </activity>
+        <activity android:name=".Pending" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="https" android:host="@string/missing_host" android:pathPrefix="/open"/>
+            </intent-filter>
+        </activity>
+        <activity android:name=".PendingCopy" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="https" android:host="@string/missing_host" android:pathPrefix="/open"/>
+            </intent-filter>
+        </activity>
+    </application>
+</manifest>
+`
+
+func TestCollectDeeplinkCollisions(t *testing.T) {
+	setOpts(t, textOptions())
+	collisions := collectDeeplinkCollisions(parseTestManifest(t, collisionManifest))
+	want := []deeplinkCollision{{
+		Pattern:    "https://example.com/open*",
+		Action:     "android.intent.action.VIEW",
+		Components: []string{".Main", "org.example.collide.Copy"},
+		Kind:       "activity",
+	}}
+	if len(collisions) != len(want) {
+		t.Fatalf("collisions = %+v, want %+v", collisions, want)
+	}
+	for i, c := range collisions {
+		if c.Pattern != want[i].Pattern || c.Action != want[i].Action || c.Kind != want[i].Kind || !slices.Equal(c.Components, want[i].Components) {
+			t.Errorf("collision %d = %+v, want %+v", i, c, want[i])
+		}
+	}
+}
+
+// TestCollisionReport checks the collision is listed in the text report and
+// raised once as an informational finding naming both components.
+func TestCollisionReport(t *testing.T) {
+	setOpts(t, textOptions())
+	result, text := renderFixture(t, collisionManifest, "<resources/>")
+	if !strings.Contains(text, "Deeplink collisions (the user is asked to choose):\n  https://example.com/open*: .Main, org.example.collide.Copy\n") {
+		t.Errorf("report lacks the collision:\n%s", text)
+	}
+	var found []finding
+	for _, f := range result.Findings {
+		if f.Rule == "deeplink-collision" {
+			found = append(found, f)
+		}
+	}
+	if len(found) != 1 {
+		t.Fatalf("deeplink-collision findings = %+v, want 1", found)
+	}
+	f := found[0]
+	if f.Severity != "info" || f.Component != ".Main" || f.Evidence != "https://example.com/open* for android.intent.action.VIEW is claimed by .Main, org.example.collide.Copy" {
+		t.Errorf("finding = %+v", f)
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"os" // Environment and output streams
+
+	"github.com/mattn/go-isatty" // Terminal detection
+)
+
+// colorEnabled decides whether output is colored: never with -no-color, a
+// non-empty NO_COLOR (see no-color.org) or TERM=dumb, and otherwise only when
+// out, the stream the output goes to, is a terminal. The color package only
+// looks at stdout, which is wrong once machine formats move the output to stderr.
+func colorEnabled(out *os.File) bool {
+	if opts.NoColor || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return isatty.IsTerminal(out.Fd()) || isatty.IsCygwinTerminal(out.Fd())
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/csv" // Quoting and row output
+	"os"           // Output file
+	"strconv"      // Boolean columns
+)
+
+// csvHeader names the columns of -csv, one row per component and action/URI pair.
+var csvHeader = []string{"package", "type", "component", "exported", "action", "scheme", "host", "port", "path", "uri"}
+
+// checkCreatable creates (and truncates) an output file up front, so a path
+// that can't be written fails before minutes of decompiling instead of after.
+func checkCreatable(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// csvRows flattens the components of a report: one row per filter action and
+// <data> URI combination, one per provider authority, and a single bare row
+// for components with neither.
+func csvRows(r *report) [][]string {
+	var rows [][]string
+	for _, c := range r.Components {
+		row := func(action string, data Data, uri string) []string {
+			return []string{r.Package, c.Type, c.Name, strconv.FormatBool(c.Exported), action, data.Scheme, data.Host, data.Port, dataPath(data), uri}
+		}
+		before := len(rows)
+		for _, filter := range c.Filters {
+			actions := filter.Actions
+			if len(actions) == 0 {
+				actions = []string{""}
+			}
+			var uris []Data
+			for _, data := range filter.Data {
+				if data.IsSchemeData() && !opts.OnlyComponents {
+					uris = append(uris, data)
+				}
+			}
+			for _, action := range actions {
+				if len(uris) == 0 {
+					rows = append(rows, row(action, Data{}, ""))
+				}
+				for _, data := range uris {
+					rows = append(rows, row(action, data, constructURI(data)))
+				}
+			}
+		}
+		for _, authority := range c.Authorities {
+			if opts.OnlyComponents {
+				break
+			}
+			rows = append(rows, row("", Data{Scheme: "content", Host: authority}, "content://"+authority))
+		}
+		if len(rows) == before {
+			rows = append(rows, row("", Data{}, ""))
+		}
+	}
+	return rows
+}
+
+// dataPath returns the path attribute a <data> element declares: the exact
+// path, prefix or pattern, or the scheme-specific part of opaque URIs.
+func dataPath(data Data) string {
+	switch {
+	case data.Path != "":
+		return data.Path
+	case data.PathPrefix != "":
+		return data.PathPrefix
+	case data.PathPattern != "":
+		return data.PathPattern
+	}
+	return data.SchemeSpecificPart()
+}
+
+// writeCSV writes the components of every report to path with a header row.
+func writeCSV(path string, reports []*report) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	writer := csv.NewWriter(file)
+	writer.Write(csvHeader)
+	for _, r := range reports {
+		writer.WriteAll(csvRows(r))
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -1,88 +1,233 @@
 package main
 
 import (
-	"encoding/xml" // XML parsing support
-	"flag"         // Command-line flag parsing
-	"fmt"          // I/O formatting
-	"os"           // Operating system functionalities
-	"os/exec"      // External command execution
+	"flag"          // Command-line flag parsing
+	"fmt"           // I/O formatting
+	"io"            // Writers for rendered output
+	"os"            // Operating system functionalities
+	"path/filepath" // Report paths
+	"slices"        // Output format validation
 	"strconv"
 	"strings" // String manipulation functions
+	"time"    // Durations for batch options
 
-	"github.com/fatih/color" // Colorized output in terminal
+	"Deeeeper/Deeeeper/deeeeper" // Manifest model and URI construction
+	"github.com/fatih/color"     // Colorized output in terminal
 )
 
-// StringResource defines the structure for parsing strings.xml files.
-type StringResource struct {
-	XMLName xml.Name `xml:"resources"` // The root XML element
-	Strings []String `xml:"string"`    // Slice of String elements within the file
-}
-
-// String holds data for a single string element within strings.xml.
-type String struct {
-	Name string `xml:"name,attr"` // String's name attribute
-	Text string `xml:",chardata"` // Text content of the string element
-}
-
-type Manifest struct {
-	XMLName    xml.Name `xml:"manifest"`
-	Activities []App    `xml:"application>activity"`
-	Aliases    []App    `xml:"application>activity-alias"`
-	Services   []App    `xml:"application>service"`
-	Receivers  []App    `xml:"application>receiver"`
-}
-
-// App encapsulates an application component like an activity or service, including its intent filters.
-type App struct {
-	Name     string         `xml:"name,attr"`     // Component name
-	Exported string         `xml:"exported,attr"` // Exported status
-	Filters  []IntentFilter `xml:"intent-filter"` // Intent filters
-}
-
-// IntentFilter contains actions and data elements for filtering intents.
-type IntentFilter struct {
-	Actions []Action `xml:"action"` // Actions within the filter
-	Data    []Data   `xml:"data"`   // Data elements specifying URI patterns
-}
-
-// Action defines an action element within an intent-filter.
-type Action struct {
-	Name string `xml:"name,attr"` // Action name
-}
+// toolVersion is the Deeeeper release shown in the banner and recorded in reports.
+const toolVersion = "1.0.1"
 
-// Data represents a data element within an intent-filter, detailing URI handling.
-type Data struct {
-	Scheme      string `xml:"scheme,attr"`      // URI scheme
-	Host        string `xml:"host,attr"`        // Hostname
-	Port        string `xml:"port,attr"`        // Port number
-	Path        string `xml:"path,attr"`        // Exact path
-	PathPrefix  string `xml:"pathPrefix,attr"`  // Path prefix
-	PathPattern string `xml:"pathPattern,attr"` // Path pattern
+// options holds the analysis settings collected from the command line.
+type options struct {
+	APKPath               string        // APK file or bundle given with -apk
+	Folder                string        // Already decompiled folder given with -folder
+	StringsProperties     string        // Extra name=value strings file used for placeholder resolution
+	Locale                string        // Language whose res/values-<locale> strings take precedence, e.g. de or pt-BR
+	Format                string        // Output format, see outputFormats
+	Output                string        // File receiving the results instead of stdout, colors stripped
+	Force                 bool          // Let -o replace an existing file
+	NoColor               bool          // Plain output even on a terminal; NO_COLOR does the same
+	Quiet                 bool          // No banner, progress messages or section headers
+	Action                string        // Only list components handling this intent action
+	CustomActionsOnly     bool          // Only list components handling app-defined actions
+	HTMLDir               string        // Directory receiving per-APK HTML reports and an index.html
+	HTML                  string        // File receiving a single self-contained HTML report
+	Open                  bool          // Open the written HTML report or PoC page in the default browser
+	ResolveAliases        bool          // Attribute alias deeplinks to the alias's targetActivity
+	ReportUnknown         bool          // Summarize manifest elements and attributes the parser does not model
+	TestCases             string        // File receiving deeplink test cases as JSON
+	ZapContext            string        // File receiving a ZAP context covering the http(s) deeplinks
+	CSV                   string        // File receiving one CSV row per component and action/URI pair
+	Markdown              string        // File receiving a Markdown report
+	SARIF                 string        // File receiving a SARIF 2.1.0 log for code scanning
+	Template              string        // text/template file rendered to stdout instead of the text output
+	Expect                string        // Spec file of the deeplinks the app must expose
+	ExpectAllowMissing    bool          // Don't fail when expected deeplinks are absent
+	ExpectAllowUnexpected bool          // Don't fail on deeplinks missing from the spec
+	GenAssetLinks         string        // Directory receiving the assetlinks.json each autoVerify host must serve
+	CertFingerprint       string        // Signing certificate SHA-256 used when it can't be read from the APK
+	TargetSDK             int           // SDK level used to evaluate implicit exports, 0 when unset
+	Catalog               bool          // Emit a cross-app summary of deeplink surfaces
+	Hook                  string        // Command run after analysis with the JSON result on stdin
+	HookStrict            bool          // Fail the run when a hook exits non-zero
+	HookCombined          bool          // Also run the hook once for the combined batch result
+	HookTimeout           time.Duration // Kill a hook running longer than this, 0 for no limit
+	Verbose               bool          // Print extra diagnostics such as hook stderr
+	SelfTest              bool          // Verify the installation against a synthetic target
+	Strict                bool          // Fail on malformed manifests instead of repairing them
+	Framework             string        // framework-res.apk or resources.arsc resolving @android: references
+	FrameworkDir          string        // apktool framework directory, passed to apktool as -p
+	InstallFramework      bool          // Install -framework into apktool before decompiling
+	APKTool               bool          // Always decompile with apktool instead of decoding the manifest in-process
+	Quick                 bool          // Decode in-process into a scratch folder, skipping apktool's version check
+	NoFallback            bool          // Fail instead of falling back to apktool when in-process decoding fails
+	EncodePlaceholders    bool          // Percent-encode {placeholder} braces in URIs instead of keeping them visible
+	ADBCommands           bool          // Print the adb command of every deeplink and provider URI
+	ADB                   bool          // Print the adb command of every deeplink grouped per component
+	Inventory             bool          // Print one flat, sorted deeplink list per app
+	OnlyDeeplinks         bool          // Report only activities and aliases that declare deeplinks
+	OnlyComponents        bool          // Report the exported components without constructing URIs
+	Browsable             bool          // List only the URIs of VIEW filters with the BROWSABLE category
+	PostURL               string        // Endpoint receiving the JSON results
+	PostHeaders           headerList    // Extra request headers for -post-url
+	PostMode              string        // per-target or combined
+	PostTimeout           time.Duration // Timeout of one -post-url request
+	PostInsecure          bool          // Skip TLS verification for -post-url
+	Webhook               string        // URL receiving each report as soon as its APK is done
+	NotifyWebhook         string        // URL that receives a summary of notable findings
+	NotifyMinSeverity     string        // Lowest severity that triggers a notification
+	NotifyFormat          string        // Notification payload shape: json or slack
+	NotifyTop             int           // Findings included in a notification
+	Heartbeat             time.Duration // Interval between progress lines in non-interactive batch runs
+	Jobs                  int           // APKs analyzed in parallel
+	DecompileJobs         int           // Concurrent apktool processes, 0 picks a memory-aware default
+	Retries               int           // Extra apktool attempts after a transient failure
+	RequireAPKTool        string        // Minimum apktool version that must be installed
+	Boxed                 bool          // Draw each exported component in a bordered box with a risk summary
+	Sort                  string        // Component order: manifest (default) or risk
+	RouterHosts           int           // Distinct hosts that make a component router-style, 0 to ignore
+	RouterSchemes         int           // Distinct schemes that make a component router-style, 0 to ignore
+	RouterPaths           int           // Distinct path families that make a component router-style, 0 to ignore
+	Matrix                bool          // Tabulate each component's filters instead of listing URIs
+	Collapse              bool          // Summarize each scheme+host's URIs as path families
+	Serial                string        // adb device serial used by device features
+	DryRun                bool          // Print adb commands instead of running them
+	Launch                bool          // Fire every intent test case at the device
+	QR                    bool          // Render deeplinks as terminal QR codes
+	QRLimit               int           // Most QR codes rendered per APK, 0 for all
+	ServePoC              string        // Address of the local PoC server, empty when disabled
+	ServeLAN              bool          // Bind the PoC server to all interfaces instead of localhost
+	ServeTimeout          time.Duration // Stop the PoC server after this long, 0 to wait for Ctrl-C
+	SchemeRules           string        // JSON file of URI construction hints per scheme
+	WarnDuplicatePackages bool          // Warn when several APKs in a batch share a package
+	SkipUnchanged         bool          // Reuse the previous report of APKs whose hash did not change
+	StateFile             string        // Where -skip-unchanged keeps hashes and reports
+	Diff                  string        // JSON report of an earlier build to compare against
+	Since                 time.Time     // Only analyze inputs modified after this, zero when unset
 }
 
-// IsSchemeData checks if the Data struct represents a URI scheme.
-func (d Data) IsSchemeData() bool {
-	return d.Scheme != "" || d.Host != "" || d.Port != "" || d.Path != "" || d.PathPrefix != "" || d.PathPattern != ""
-}
+// opts is populated from the command-line flags in main.
+var opts options
 
-// Uses apktool to decompile an APK file to a specified output directory.
-func decompileAPK(apkPath string) (string, error) {
-	outputDir := strings.TrimSuffix(apkPath, ".apk") + "_decompiled"    // Naming the output directory
-	cmd := exec.Command("apktool", "d", apkPath, "-o", outputDir, "-f") // Constructing the apktool command
-	err := cmd.Run()                                                    // Executing the command
-	if err != nil {
-		return "", err // Error handling for command execution failure
-	}
-	return outputDir, nil // Successful decompilation returns the output directory
-}
+// The manifest model lives in the deeeeper package, which other tools import;
+// these aliases keep the analysis code reading as before.
+type (
+	String         = deeeeper.String
+	Manifest       = deeeeper.Manifest
+	PermissionDecl = deeeeper.PermissionDecl
+	UsesPermission = deeeeper.UsesPermission
+	UsesSDK        = deeeeper.UsesSDK
+	UsesFeature    = deeeeper.UsesFeature
+	DistModule     = deeeeper.DistModule
+	Application    = deeeeper.Application
+	App            = deeeeper.App
+	IntentFilter   = deeeeper.IntentFilter
+	Action         = deeeeper.Action
+	Category       = deeeeper.Category
+	Data           = deeeeper.Data
+	MetaData       = deeeeper.MetaData
+	ProviderPath   = deeeeper.ProviderPath
+)
 
 // displayHelp
 func displayHelp() {
 	color.Yellow("Usage: deeeeper [OPTIONS]\n")
 	color.Yellow("Options:\n")
-	color.Yellow("  -apk <path>       Path to the APK file to be decompiled\n")
-	color.Yellow("  -folder <path>    Folder to search in if APK is already decompiled\n")
-	color.Yellow("  -h, --help        Display this help and exit\n")
+	color.Yellow("  -apk <path>                   APK file to be decompiled, a glob such as 'builds/*.apk', or a .zip/.tar.gz bundle\n")
+	color.Yellow("  -aab <path>                   Android App Bundle (or glob of them) whose base module is decoded in-process; -apk accepts .aab too\n")
+	color.Yellow("  -dir <path>                   Directory searched recursively for .apk files, each analyzed like -apk, continuing past failures\n")
+	color.Yellow("  -folder <path>                Folder to search in if APK is already decompiled\n")
+	color.Yellow("  -strings-properties <file>    Properties file of name=value strings used alongside or instead of strings.xml\n")
+	color.Yellow("  -locale <lang>                Prefer the strings of res/values-<lang> (e.g. de or pt-BR) over the default res/values\n")
+	color.Yellow("  -format <name>                Output format: text (default), json, defectdojo (Generic Findings JSON on stdout), zap-urls (http(s) deeplinks as URLs) or uris\n")
+	color.Yellow("  -json                         Shorthand for -format json\n")
+	color.Yellow("  -uris                         Shorthand for -format uris: only the deeplink URIs on stdout, one per line, for piping\n")
+	color.Yellow("  -o <file>                     Also write the text output, without colors, to a file (or the -format document instead of stdout)\n")
+	color.Yellow("  -force                        Let -o overwrite an existing file\n")
+	color.Yellow("  -no-color                     Disable colors (also with NO_COLOR set, or when the output isn't a terminal)\n")
+	color.Yellow("  -quiet, -q                    Leave out the banner, progress messages and section headers; warnings and errors still go to stderr\n")
+	color.Yellow("  -action <name>                Only list components handling this action; SEND selects SEND and SEND_MULTIPLE\n")
+	color.Yellow("  -custom-actions-only          Only list components handling custom (non-framework) actions, shown in magenta\n")
+	color.Yellow("  -boxed                        Draw each exported component in a box headed by a one-line risk summary\n")
+	color.Yellow("  -sort <order>                 Component order: manifest (default) or risk, router-style components first, then by worst finding\n")
+	color.Yellow("  -router-hosts <n>             Flag activities handling at least n distinct hosts as router-style (default 10, 0 to ignore)\n")
+	color.Yellow("  -router-schemes <n>           Flag activities handling at least n distinct schemes as router-style (default 5, 0 to ignore)\n")
+	color.Yellow("  -router-paths <n>             Flag activities handling at least n distinct path families as router-style (default 15, 0 to ignore)\n")
+	color.Yellow("  -collapse                     Summarize URIs per scheme+host, e.g. \"37 paths under /product/\"\n")
+	color.Yellow("  -matrix                       Show deeplinks as a table with one row per filter and scheme/host/port/path combination\n")
+	color.Yellow("  -qr                           Render each concrete deeplink as a QR code to scan with the test device's camera (also inline in -html and -html-dir pages)\n")
+	color.Yellow("  -qr-limit <n>                 Most QR codes rendered per APK with -qr (default 0, all)\n")
+	color.Yellow("  -serve-poc <[host]:port>      After analysis, serve a clickable deeplink page (with intent:// variants) that logs clicks\n")
+	color.Yellow("  -serve-lan                    Expose the -serve-poc page on the LAN (default: localhost only)\n")
+	color.Yellow("  -serve-timeout <duration>     Stop the -serve-poc server after this long (default: wait for Ctrl-C)\n")
+	color.Yellow("  -scheme-rules <file>          JSON file of per-scheme URI hints: style (authority or opaque), default_host, default_path\n")
+	color.Yellow("  -open                         Open the HTML report (-html, -html-dir) or PoC page in the default browser\n")
+	color.Yellow("  -html <file>                  Write one self-contained HTML report: components per type, filter box, copyable deeplinks\n")
+	color.Yellow("  -html-dir <dir>               Write one HTML report per APK plus a sortable index.html (package, deeplinks, risk)\n")
+	color.Yellow("  -resolve-aliases              Attribute activity-alias deeplinks and findings to the targetActivity that handles them\n")
+	color.Yellow("  -report-unknown               Summarize manifest elements and attributes Deeeeper does not model yet\n")
+	color.Yellow("  -gen-assetlinks <dir>         Write the assetlinks.json each autoVerify host must serve (<dir>/<host>/.well-known/)\n")
+	color.Yellow("  -cert-fingerprint <sha256>    Signing certificate fingerprint for -gen-assetlinks when it can't be read from the APK\n")
+	color.Yellow("  -expect <file>                Fail unless the deeplinks match this JSON/YAML spec exactly (missing/unexpected/matched)\n")
+	color.Yellow("  -expect-allow-missing         Don't fail -expect when expected deeplinks are absent\n")
+	color.Yellow("  -expect-allow-unexpected      Don't fail -expect on deeplinks the spec doesn't list\n")
+	color.Yellow("  -testcases <file>             Write each deeplink as a JSON test case (uri, action, package, component)\n")
+	color.Yellow("  -csv <file>                   Write a CSV table with one row per component and action/URI pair, for client reports\n")
+	color.Yellow("  -markdown <file>              Write a Markdown report for triage notes: exported components per type and a block of all deeplink URIs\n")
+	color.Yellow("  -sarif <file>                 Write a SARIF 2.1.0 log for CI code scanning: one result per finding and per browsable deeplink\n")
+	color.Yellow("  -template <file>              Render the reports to stdout with a Go text/template; helpers: exported, ofType, uris, join\n")
+	color.Yellow("  -zap-context <file>           Write a ZAP context whose include regexes cover the http(s) deeplink hosts and path prefixes\n")
+	color.Yellow("  -target-sdk <n>               Evaluate implicit exports as if running on SDK level n\n")
+	color.Yellow("  -jobs <n>                     Number of APKs analyzed in parallel (default 1)\n")
+	color.Yellow("  -serial <serial>              adb device used by device features; required when several devices are attached\n")
+	color.Yellow("  -launch                       Fire every intent test case at the attached device with adb, 2s apart, and report each outcome\n")
+	color.Yellow("  -dry-run                      With -launch, print the adb commands in order with planned timings instead of running them; results read \"not executed (dry run)\"\n")
+	color.Yellow("  -skip-unchanged               Skip APKs whose hash matches the previous run, reusing their report marked (unchanged)\n")
+	color.Yellow("  -state-file <file>            State file used by -skip-unchanged (default deeeeper-state.json next to -o, in -html-dir, else in the working directory)\n")
+	color.Yellow("  -diff <report.json>           Compare with the -format json report of an earlier build: exported components, permissions, application flags\n")
+	color.Yellow("  -since <time>                 Only analyze APKs (or a -folder) modified after this RFC3339 time\n")
+	color.Yellow("  -newer-than <file>            Only analyze APKs (or a -folder) modified after this file\n")
+	color.Yellow("  -warn-duplicate-packages      Warn when several APKs in a batch resolve to the same package (likely duplicates)\n")
+	color.Yellow("  -decompile-jobs <n>           Maximum concurrent apktool processes (default: 2, fewer on low-memory hosts)\n")
+	color.Yellow("  -retries <n>                  Retry a failed apktool run n times with backoff (default 1)\n")
+	color.Yellow("  -require-apktool-version <v>  Fail unless apktool is at least version v (otherwise older than 2.9.0 only warns)\n")
+	color.Yellow("  -catalog                      Summarize schemes, hosts and deeplinks shared across apps vs unique to one\n")
+	color.Yellow("  -hook <command>               Run a shell command per APK with its JSON on stdin and DEEEEPER_TARGET, _PACKAGE, _FINDINGS[_<SEVERITY>] set\n")
+	color.Yellow("  -hook-strict                  Exit non-zero when a -hook run fails (default: report and continue)\n")
+	color.Yellow("  -hook-combined                In batch runs, also run -hook once with all results\n")
+	color.Yellow("  -hook-timeout <duration>      Kill a -hook run after this long (default 1m, 0 for no limit)\n")
+	color.Yellow("  -selftest                     Verify the installation: analyze a built-in synthetic app and print PASS/FAIL per check\n")
+	color.Yellow("  -verbose                      Print extra diagnostics, such as the stderr of -hook runs\n")
+	color.Yellow("  -strict                       Fail on malformed manifests instead of repairing bare '&' and duplicate xmlns\n")
+	color.Yellow("  -framework <file>             framework-res.apk of the device, resolving @android: references (defaults to apktool's installed one)\n")
+	color.Yellow("  -framework-dir <dir>          apktool framework directory to decode with and read the installed framework from (apktool -p)\n")
+	color.Yellow("  -install-framework            Install the -framework APK into apktool (apktool if) before decompiling OEM or carrier APKs\n")
+	color.Yellow("  -apktool                      Decompile with apktool instead of decoding the manifest in-process (needed for smali heuristics)\n")
+	color.Yellow("  -quick                        Triage mode: decode in-process only, leave no _decompiled folders and skip apktool's version check\n")
+	color.Yellow("  -no-fallback                  Fail instead of running apktool when the in-process decoder can't read an APK\n")
+	color.Yellow("  -encode-placeholders          Percent-encode {placeholder} braces in URIs (strict form) instead of keeping them visible\n")
+	color.Yellow("  -adb                          Print the adb am start command of every deeplink, grouped per component for copy-paste test runs\n")
+	color.Yellow("  -adb-commands                 Print adb commands grouped per component: am start per deeplink, content query/read per provider URI\n")
+	color.Yellow("  -only-deeplinks               Only deeplink-bearing activities and aliases with their URIs; for deeplink testing\n")
+	color.Yellow("  -browsable                    Only URIs of filters with the VIEW action and BROWSABLE category, i.e. reachable from a browser link\n")
+	color.Yellow("  -only-components              Only the exported components, no URIs and no strings.xml; fastest, for attack-surface triage\n")
+	color.Yellow("  -inventory                    List every deeplink (content:// included) once, sorted, with the components declaring it\n")
+	color.Yellow("  -post-url <url>               POST the JSON results after analysis; delivery failures exit with code 3\n")
+	color.Yellow("  -post-header <header>         Extra \"Name: value\" header for -post-url, repeatable; $VARS in values are expanded\n")
+	color.Yellow("  -post-mode <mode>             per-target (default, one request per APK) or combined (one document)\n")
+	color.Yellow("  -post-timeout <duration>      Timeout of each -post-url request (default 30s)\n")
+	color.Yellow("  -post-insecure                Skip TLS certificate verification for -post-url\n")
+	color.Yellow("  -webhook <url>                POST each APK's report as JSON as soon as it is done, retrying with backoff (errors never fail the run)\n")
+	color.Yellow("  -notify-webhook <url>         POST a summary of notable findings to this URL (delivery errors never fail the run)\n")
+	color.Yellow("  -notify-min-severity <sev>    Lowest severity that triggers a notification: info, low, medium, high (default), critical\n")
+	color.Yellow("  -notify-format <name>         Notification payload: json (default) or slack (Block Kit message)\n")
+	color.Yellow("  -notify-top <n>               Findings listed in a notification (default 5)\n")
+	color.Yellow("  -heartbeat <duration>         Progress line interval on stderr for non-interactive batch runs (default 30s, 0 disables)\n")
+	color.Yellow("  -cpuprofile <file>            Write a CPU profile of the whole run (inspect with: go tool pprof -top deeeeper <file>)\n")
+	color.Yellow("  -memprofile <file>            Write a heap profile taken at the end of the run\n")
+	color.Yellow("  -trace <file>                 Write an execution trace (inspect with: go tool trace <file>)\n")
+	color.Yellow("  -h, --help                    Display this help and exit\n")
 }
 
 // displayBanner
@@ -95,153 +240,916 @@ func displayBanner() {
 dMMMMP" dMMMMMP dMMMMMP dMMMMMP dMMMMMP dMP     dMMMMMP dMP dMP    
 
  	Deeeeper - Decompile, find activities and deeplinks
- 	Version: 1.0.1
+ 	Version: ` + toolVersion + `
 	`
-	color.Magenta(banner)
+	color.Magenta("%s", banner)
+}
+
+// progressf prints a green progress message to w, unless -quiet.
+func progressf(w io.Writer, format string, a ...any) {
+	if !opts.Quiet {
+		color.New(color.FgGreen).Fprintf(w, format+"\n", a...)
+	}
+}
+
+// warnf prints a yellow warning line to w. Unlike progress, warnings are kept
+// under -quiet.
+func warnf(w io.Writer, format string, a ...any) {
+	color.New(color.FgYellow).Fprintf(w, format+"\n", a...)
+}
+
+// printSection prints a yellow section header of the text report, unless
+// -quiet, which leaves only the results themselves.
+func printSection(w io.Writer, title string) {
+	if !opts.Quiet {
+		color.New(color.FgYellow).Fprintln(w, title)
+	}
 }
 
 // processComponents processes each application component and prints detailed info with colors
-func processComponents(components []App) {
+func processComponents(w io.Writer, folder string, components []App, kind string, findings []finding) {
 	cyan := color.New(color.FgCyan).SprintFunc()
 	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	magenta := color.New(color.FgMagenta).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
 
+	if opts.Sort == "risk" {
+		components = sortByRisk(components, kind, findings)
+	}
 	for _, component := range components {
-		// Convert the exported attribute to a boolean for easier handling
-		exported, err := strconv.ParseBool(component.Exported)
-		if err != nil {
-			// If the exported attribute is missing or invalid, treat the component as not exported
-			exported = false
-		}
+		// Resolve the exported state, including Android's implicit defaults
+		exported, implicit := isExported(component, kind)
 
 		// Only process and display components that are exported
-		if exported {
-			fmt.Printf("%s (exported=%t)\n", cyan(component.Name), exported)
+		if exported && componentHasAction(component, opts.Action) && (!opts.CustomActionsOnly || len(customActions(component)) > 0) {
+			attributes := []string{fmt.Sprintf("exported=%t", exported)}
+			if implicit {
+				attributes = append(attributes, "implicit")
+			}
+			directBootAware := isTrue(component.DirectBootAware)
+			singleUser := isTrue(component.SingleUser)
+			if directBootAware {
+				attributes = append(attributes, "directBootAware=true")
+			}
+			if singleUser {
+				attributes = append(attributes, "singleUser=true")
+			}
+			if isTrue(component.Multiprocess) {
+				attributes = append(attributes, "multiprocess=true")
+			}
+			if component.Declarations > 1 {
+				attributes = append(attributes, fmt.Sprintf("declared %d×", component.Declarations))
+			}
+			if component.Permission != "" {
+				attributes = append(attributes, "permission="+component.Permission)
+			} else if opts.Boxed {
+				attributes = append(attributes, "no permission required")
+			}
+
+			var lines []styledLine
+			if !opts.OnlyComponents { // The inventory mode shows no URIs, content:// ones included
+				for _, authority := range providerAuthorities(component) {
+					lines = append(lines, styledLine{text: "content://" + authority, paint: green})
+				}
+			}
+			// Flag attribute combinations that change the component's exposure
+			if directBootAware {
+				lines = append(lines, styledLine{text: "reachable before first unlock (direct boot)", paint: yellow})
+			}
+			if singleUser {
+				lines = append(lines, styledLine{text: "single instance shared across all device users", paint: yellow})
+			}
+			badges, evidence := reachabilityBadges(component)
+			for i, badge := range badges {
+				lines = append(lines, styledLine{text: badge, paint: yellow, note: " (" + evidence[i] + ")"})
+			}
+			if note := replaceNote(component); note != "" {
+				lines = append(lines, styledLine{text: note, paint: yellow})
+			}
+			if kind == "activity" || kind == "alias" {
+				if note := routerNote(measureSurface(component, kind)); note != "" {
+					lines = append(lines, styledLine{text: note + " (central router, review its dispatch)", paint: yellow})
+				}
+			}
 
 			// Process each intent filter within the component
 			for _, filter := range component.Filters {
+				if ranking := filterRanking(filter); ranking != "" {
+					lines = append(lines, styledLine{text: ranking, paint: yellow})
+				}
 				for _, action := range filter.Actions {
-					fmt.Printf("  %s\n", green(action.Name))
+					line := styledLine{text: action.Name, paint: green}
+					if isCustomAction(action.Name) { // App-defined protocols stand out from framework actions
+						line.paint = magenta
+					}
+					if kind == "receiver" && protectedBroadcasts[action.Name] {
+						line.note = " (protected broadcast)"
+					}
+					lines = append(lines, line)
+				}
+				if isContentHandler(filter) {
+					lines = append(lines, styledLine{text: contentHandlerLine(filter), paint: green})
+					continue
+				}
+				if opts.Matrix || opts.Collapse || opts.OnlyComponents {
+					continue // URIs are tabulated or summarized below, or not wanted
+				}
+				if opts.Browsable && !isBrowsable(filter) {
+					continue // Not reachable from a link in a browser
 				}
 				for _, data := range filter.Data {
 					uri := constructURI(data)
-					if uri != "" {
-						fmt.Printf("  %s\n", green(uri))
+					if uri == "" {
+						continue
+					}
+					if problem := uriProblem(uri); problem != "" { // Still listed: a malformed filter is a finding about the app
+						lines = append(lines, styledLine{text: uri, paint: red, note: " (invalid: " + problem + ")"})
+					} else {
+						lines = append(lines, styledLine{text: uri, paint: green})
 					}
 				}
 			}
+			if opts.Matrix {
+				for _, row := range matrixTable(component.Filters) {
+					lines = append(lines, styledLine{text: row, paint: fmt.Sprint})
+				}
+			} else if opts.Collapse {
+				for _, family := range collapseFilters(component.Filters) {
+					lines = append(lines, styledLine{text: family.summary(), paint: green})
+				}
+			}
+
+			// Deeplinks declared in res/xml files referenced from meta-data
+			var links []xmlDeeplink
+			if !opts.OnlyComponents && !opts.Browsable { // Their categories aren't declared in the manifest
+				links = metaDataDeeplinks(folder, component)
+			}
+			for _, link := range links {
+				lines = append(lines, styledLine{text: link.URI, paint: green, note: " (from " + link.Source + ")"})
+			}
+
+			if opts.Boxed {
+				name := component.Name
+				if target, ok := resolvedAlias(component, kind); ok {
+					name, target = target, name
+					attributes = append(attributes, "via alias "+target)
+				}
+				severity, summary := riskSummary(findings, kind, name)
+				printBox(w, name, kind, strings.Join(attributes, ", "), severity, summary, lines)
+				continue
+			}
+			if target, ok := resolvedAlias(component, kind); ok {
+				fmt.Fprintf(w, "%s (%s, via alias %s)\n", cyan(target), strings.Join(attributes, ", "), component.Name)
+			} else {
+				fmt.Fprintf(w, "%s (%s)\n", cyan(component.Name), strings.Join(attributes, ", "))
+			}
+			for _, line := range lines {
+				fmt.Fprintf(w, "  %s%s\n", line.paint(line.text), line.note)
+			}
 		}
 	}
 }
 
-// constructURI builds a URI string from Data struct
-func constructURI(data Data) string {
-	if !data.IsSchemeData() {
+// filterRanking describes the priority and order of a filter, which decide
+// which of several matching handlers wins. It is empty when neither is set.
+func filterRanking(filter IntentFilter) string {
+	var parts []string
+	if filter.Priority != "" {
+		parts = append(parts, "priority="+filter.Priority)
+	}
+	if filter.Order != "" {
+		parts = append(parts, "order="+filter.Order)
+	}
+	if len(parts) == 0 {
 		return ""
 	}
-	// Construct the path correctly, considering all attributes (path, pathPrefix, pathPattern)
-	var path string
-	if data.Path != "" {
-		path = data.Path
-	} else if data.PathPrefix != "" {
-		path = data.PathPrefix
-	} else if data.PathPattern != "" {
-		path = data.PathPattern
+	return "filter " + strings.Join(parts, ", ") + " (higher values are preferred)"
+}
+
+// resolvedAlias returns the targetActivity of an activity-alias when
+// -resolve-aliases is set, so deeplinks point at the class holding the code.
+func resolvedAlias(component App, kind string) (string, bool) {
+	if !opts.ResolveAliases || kind != "alias" || component.TargetActivity == "" {
+		return "", false
 	}
+	return component.TargetActivity, true
+}
 
-	// Ensure the path starts with a "/"
-	if path != "" && !strings.HasPrefix(path, "/") {
-		path = "/" + path
+// isExported resolves whether a component of the given kind is reachable by other apps.
+// An explicit android:exported wins (invalid values count as not exported). When the
+// attribute is absent, a component with intent filters is implicitly exported: an app
+// shipping such a manifest targets an SDK below 31, since on 31+ it fails to build.
+// -target-sdk 31 or higher turns the default off; providers are only exported by
+// default when -target-sdk is 16 or lower. The second result reports whether the
+// exported state was implied rather than declared.
+func isExported(component App, kind string) (exported bool, implicit bool) {
+	if component.Exported != "" {
+		return isTrue(component.Exported), false
 	}
+	if opts.TargetSDK >= 31 {
+		return false, false // Such a manifest doesn't build, only explicit declarations count
+	}
+	if kind == "provider" {
+		return opts.TargetSDK != 0 && opts.TargetSDK <= 16, true
+	}
+	return len(component.Filters) > 0, true
+}
 
-	uri := fmt.Sprintf("%s://%s%s", data.Scheme, data.Host, path)
-	return uri
+// isTrue reports whether a boolean manifest attribute is set to true.
+func isTrue(value string) bool {
+	parsed, err := strconv.ParseBool(value)
+	return err == nil && parsed
 }
 
-func main() {
-	displayBanner()
+// constructURI builds the percent-encoded URI of a <data> element, keeping
+// {placeholder} braces readable unless -encode-placeholders is set.
+func constructURI(data Data) string {
+	return deeeeper.DeeplinkURI(data, opts.EncodePlaceholders)
+}
+
+// analyzeFolder parses the manifest and strings of a decompiled APK, writes its
+// components to w in text mode and its warnings to progress, and returns the
+// collected report.
+func analyzeFolder(w, progress io.Writer, folder string) (*report, error) {
+	// Paths for manifest and strings assuming the standard apktool layout
+	manifestPath := filepath.Join(folder, "AndroidManifest.xml")
+
+	// Reading and merging the strings.xml files, which a component listing without URIs doesn't need
+	stringMap := make(map[string]string)
+	if !opts.OnlyComponents {
+		var err error
+		if stringMap, err = loadStringMap(progress, folder); err != nil {
+			return nil, err
+		}
+	}
 
+	// Reading and preprocessing AndroidManifest.xml
+	manifestFile, err := os.ReadFile(manifestPath)
+	if err != nil { // Error handling for file reading failure
+		return nil, fmt.Errorf("reading manifest file: %w", err)
+	}
+
+	rawManifest := toUTF8(manifestFile)
+
+	manifest, repairs, err := parseManifest(rawManifest) // Unmarshalling manifest XML, repairing it unless -strict
+	if err != nil {                                      // Error handling for XML unmarshalling failure
+		return nil, fmt.Errorf("parsing manifest: %w", err)
+	}
+	deeeeper.ResolveManifest(&manifest, loadResourceValues(folder, stringMap).resources()) // Replacing @string, @bool and @integer references with their values
+	mergeRules := applyMergeRules(&manifest)
+	if isPreMergeManifest(folder, rawManifest) {
+		warnf(progress, "Warning: %s still carries tools: merge rules, so it looks like a source manifest; the final merged manifest may differ", manifestPath)
+	}
+	unresolved := unresolvedFrameworkReferences(manifest)
+	for _, reference := range unresolved {
+		warnf(progress, "Warning: unresolved framework reference %s; it reads as false or empty (pass -framework with the device's framework-res.apk)", reference)
+	}
+	for _, reference := range unresolvedManifestReferences(manifest, !opts.OnlyComponents) { // Strings aren't loaded with -only-components
+		hint := "output built from it is incomplete"
+		switch {
+		case strings.HasPrefix(reference, "@string/"):
+			hint = "no strings.xml defines it, output built from it is incomplete (try -locale or -strings-properties)"
+		case strings.HasPrefix(reference, "${"):
+			hint = "only the Gradle build substitutes placeholders, analyze the merged manifest instead"
+		}
+		warnf(progress, "Warning: unresolved %s; %s", reference, hint)
+		unresolved = append(unresolved, reference)
+	}
+	if len(repairs) > 0 {
+		warnf(progress, "Warning: %s was parsed after repair: %s", manifestPath, strings.Join(repairs, ", "))
+	}
+	for _, conflict := range mergeDuplicates(&manifest) {
+		warnf(progress, "Warning: %s", conflict)
+	}
+	for _, conflict := range duplicateAuthorities(manifest) {
+		warnf(progress, "Warning: %s", conflict)
+	}
+	if opts.OnlyDeeplinks {
+		narrowToDeeplinks(&manifest, folder)
+	}
+
+	result := &report{
+		Target:       folder,
+		Package:      manifest.Package,
+		SharedUserID: manifest.SharedUserID,
+		Findings:     collectFindings(manifest, folder),
+		ShareTargets: collectShareTargets(manifest, folder),
+		Hosts:        collectHosts(manifest),
+		TestCases:    collectTestCases(manifest, folder),
+		Actions:      collectActions(manifest),
+		Components:   collectComponents(manifest, folder),
+		Repairs:      repairs,
+		Protections:  collectProtections(manifest),
+		Surface:      collectSurface(manifest),
+		Unresolved:   unresolved,
+		MergeRules:   mergeRules,
+	}
+	if opts.OnlyComponents { // URIs built from unresolved strings would be misleading
+		result.Hosts, result.TestCases = nil, nil
+		for i := range result.Findings {
+			result.Findings[i].URIs = nil
+		}
+		for i := range result.Components {
+			result.Components[i].URIs = []string{}
+		}
+	}
+	result.InvalidURIs = countInvalid(result.TestCases)
+	for _, request := range manifest.UsesPermissions {
+		result.Requested = appendUnique(result.Requested, request.Name)
+	}
+	result.MinSDK = minSDKVersion(manifest, folder)
+	result.Permissions = collectPermissionRequests(manifest, result.MinSDK)
+	result.Features = collectFeatures(manifest)
+	result.Declared = collectPermissionDeclarations(manifest)
+	result.Flags = collectApplicationFlags(manifest)
+	result.SigningCert, _ = folderCertFingerprint(folder)
+	result.GrantChains = collectGrantChains(manifest, folder)
+	result.Findings = append(result.Findings, weakPermissionFindings(manifest, result.Protections)...)
+	result.Findings = append(result.Findings, grantChainFindings(manifest.Package, result.GrantChains)...)
+	result.Instant = collectInstant(manifest)
+	result.Findings = append(result.Findings, instantFindings(manifest.Package, result.Instant)...)
+	if !opts.OnlyComponents {
+		result.Collisions = collectDeeplinkCollisions(manifest)
+		result.Findings = append(result.Findings, collisionFindings(manifest.Package, result.Collisions)...)
+	}
+	attachSnippets(result.Findings, manifest, result.Protections, folder)
+	if opts.Inventory {
+		result.Inventory = collectInventory(manifest, folder)
+	}
+	if opts.ReportUnknown {
+		usage, err := findUnknown(rawManifest)
+		if err != nil {
+			return nil, fmt.Errorf("scanning manifest for unmodeled entries: %w", err)
+		}
+		printUnknown(w, usage)
+	}
+	if machineFormat() {
+		return result, nil // Structured formats are rendered once all targets are done
+	}
+
+	// Process components
+	if manifest.SharedUserID != "" { // A shared sandbox changes the impact of everything below
+		printSection(w, "\nApplication:")
+		label := ""
+		if manifest.SharedUserLabel != "" {
+			label = fmt.Sprintf(" (label %s)", manifest.SharedUserLabel)
+		}
+		color.New(color.FgRed).Fprintf(w, "sharedUserId=%s%s — shares its sandbox with every app signed by the same key\n", manifest.SharedUserID, label)
+	}
+	printSection(w, "\nProcessing Activities:")
+	processComponents(w, folder, manifest.Application.Activities, "activity", result.Findings)
+
+	printSection(w, "\nProcessing Aliases:")
+	processComponents(w, folder, manifest.Application.Aliases, "alias", result.Findings)
+
+	if !opts.OnlyDeeplinks {
+		printSection(w, "\nProcessing Services:")
+		processComponents(w, folder, manifest.Application.Services, "service", result.Findings)
+
+		printSection(w, "\nProcessing Receivers:")
+		processComponents(w, folder, manifest.Application.Receivers, "receiver", result.Findings)
+
+		printSection(w, "\nProcessing Providers:")
+		processComponents(w, folder, manifest.Application.Providers, "provider", result.Findings)
+	}
+
+	if len(result.Actions) > 0 && !opts.OnlyDeeplinks {
+		printActions(w, result.Actions)
+	}
+	if len(result.Protections) > 0 && !opts.OnlyDeeplinks {
+		printProtections(w, result.Protections)
+	}
+	if len(result.Permissions)+len(result.Features) > 0 && !opts.OnlyDeeplinks {
+		printPermissionRequests(w, result.Permissions, result.Features, result.MinSDK)
+	}
+	if len(result.MergeRules) > 0 {
+		printMergeRules(w, result.MergeRules)
+	}
+	if len(result.Hosts) > 0 {
+		printHosts(w, result.Hosts)
+	}
+	if len(result.Surface) > 0 && !opts.OnlyComponents {
+		printSurface(w, result.Surface)
+	}
+	if len(result.Collisions) > 0 {
+		printDeeplinkCollisions(w, result.Collisions)
+	}
+	if result.Instant != nil {
+		printInstant(w, result.Instant)
+	}
+	if len(result.ShareTargets) > 0 && !opts.OnlyDeeplinks {
+		printShareTargets(w, result.ShareTargets)
+	}
+	if len(result.GrantChains) > 0 && !opts.OnlyDeeplinks {
+		printGrantChains(w, result.GrantChains)
+	}
+	if opts.Inventory {
+		printInventory(w, result.Inventory)
+	}
+	if opts.ADBCommands && len(result.TestCases) > 0 {
+		printADBCommands(w, result.TestCases)
+	}
+	if opts.ADB && len(result.TestCases) > 0 {
+		printDeeplinkCommands(w, result.TestCases)
+	}
+	if opts.QR && len(result.TestCases) > 0 {
+		printQRCodes(w, result.TestCases, opts.QRLimit)
+	}
+	if result.InvalidURIs > 0 {
+		color.New(color.FgRed).Fprintf(w, "\n%d constructed URI(s) are invalid and were left out of adb commands\n", result.InvalidURIs)
+	}
+
+	return result, nil
+}
+
+// run performs the analysis selected by the parsed flags and returns the process exit code.
+func run() int {
+	var reports []*report
+	exitCode := 0
+	var device *adbClient
+	if opts.Launch { // Fail before a long analysis when no device can take the intents
+		var err error
+		if device, err = newADB(opts.Serial); err != nil {
+			color.Red("Error %s\n", err)
+			return 1
+		}
+	}
+	if opts.APKPath != "" { // Proceed if APK path is provided
+		if !opts.Quick { // Asking apktool starts a JVM
+			if err := checkAPKToolVersion(); err != nil {
+				color.Red("Error %s\n", err)
+				return 1
+			}
+		}
+		if opts.InstallFramework {
+			if err := installFramework(opts.Framework); err != nil {
+				color.Red("Error installing framework %s: %s\n", opts.Framework, err)
+				return 1
+			}
+			progressf(color.Output, "Installed framework %s into apktool.", opts.Framework)
+		}
+		targets, cleanup, err := resolveTargets(opts.APKPath)
+		if err != nil { // Handling errors from archive extraction
+			color.Red("Error reading APK input: %s\n", err)
+			return 1 // Exiting with error code
+		}
+		targets, skipped := filterSince(targets, opts.Since)
+		if skipped > 0 {
+			color.Yellow("Skipping %d APKs not modified since %s.", skipped, opts.Since.Format(time.RFC3339))
+		}
+		if opts.SkipUnchanged {
+			state, err := loadState(stateFilePath())
+			if err != nil {
+				color.Red("Error reading state file: %s\n", err)
+				cleanup()
+				return 1
+			}
+			targets, reports = skipUnchanged(targets, state)
+		}
+		analyzed, failed := analyzeTargets(targets)
+		reports = append(reports, analyzed...)
+		if opts.SkipUnchanged {
+			if err := saveState(stateFilePath(), reports); err != nil {
+				color.Red("Error writing state file: %s\n", err)
+				exitCode = 1
+			}
+		}
+		cleanup() // Removing any extracted archive contents
+		if failed > 0 {
+			exitCode = 1 // Still report the APKs that succeeded
+		}
+		switch {
+		case len(targets) > 1 && failed > 0:
+			color.Red("%d of %d APKs analyzed, %d failed.", len(targets)-failed, len(targets), failed)
+		case len(targets) > 1:
+			progressf(color.Output, "All %d APKs analyzed.", len(targets))
+		case failed > 0:
+			color.Red("%d of %d APKs could not be analyzed.", failed, len(targets))
+		}
+	} else if opts.Folder != "" && !folderChangedSince(opts.Folder, opts.Since) {
+		color.Yellow("Skipping %s, not modified since %s.", opts.Folder, opts.Since.Format(time.RFC3339))
+	} else if opts.Folder != "" { // If only the folder path is provided
+		progressf(color.Output, "Using provided folder for search...")
+		result, err := analyzeFolder(textWriter(), color.Output, opts.Folder)
+		if err != nil {
+			color.Red("Error %s\n", err)
+			return 1 // Exiting with error code
+		}
+		if err := streamReport(result); err != nil { // Best effort; never changes the exit code
+			color.Yellow("Warning: could not post results to %s: %s", redactURL(opts.Webhook), err)
+		}
+		reports = append(reports, result)
+	} else {
+		color.Red("Please provide either an APK file or a folder to proceed.")
+		return 1 // Exit if neither flag is provided
+	}
+
+	if device != nil {
+		for _, r := range reports {
+			progressf(color.Output, "Launching the intents of %s...", r.Package)
+			launchTestCases(device, r.Package, r.TestCases)
+			if !machineFormat() {
+				printLaunchResults(textWriter(), r.TestCases)
+			}
+		}
+	}
+	if opts.Diff != "" {
+		baseline, err := loadBaseline(opts.Diff)
+		if err != nil {
+			color.Red("Error reading -diff baseline: %s\n", err)
+			return 1
+		}
+		for _, r := range reports {
+			old, ok := baseline[r.Package]
+			if !ok {
+				warnf(color.Output, "Warning: %s has no report for %s; nothing to compare.", opts.Diff, r.Package)
+				continue
+			}
+			r.Diff = diffReports(old, r)
+			if !machineFormat() {
+				printDiff(textWriter(), r.Package, r.Diff)
+			}
+		}
+	}
+	if !machineFormat() {
+		printSharedUserGroups(textWriter(), reports)
+		if len(reports) > 1 {
+			printActionIndex(textWriter(), reports)
+		}
+	}
+	if opts.Catalog && !machineFormat() {
+		printCatalog(textWriter(), buildCatalog(reports))
+	}
+	if machineFormat() {
+		if err := writeReports(resultsWriter(), reports); err != nil {
+			if opts.Template != "" {
+				color.Red("Error executing -template: %s\n", err)
+				return 1
+			}
+			color.Red("Error writing %s output: %s\n", opts.Format, err)
+			return 1
+		}
+	}
+	if opts.HTMLDir != "" {
+		if err := writeHTMLSite(opts.HTMLDir, reports); err != nil {
+			color.Red("Error writing HTML reports: %s\n", err)
+			return 1
+		}
+		openInBrowser(filepath.Join(opts.HTMLDir, "index.html"))
+	}
+	if opts.HTML != "" {
+		if err := writeHTMLFile(opts.HTML, reports); err != nil {
+			color.Red("Error writing HTML report: %s\n", err)
+			return 1
+		}
+		openInBrowser(opts.HTML)
+	}
+	if opts.GenAssetLinks != "" {
+		if err := writeAssetLinks(opts.GenAssetLinks, reports); err != nil {
+			color.Red("Error writing assetlinks.json files: %s\n", err)
+			return 1
+		}
+	}
+	if opts.TestCases != "" {
+		if err := writeTestCases(opts.TestCases, reports); err != nil {
+			color.Red("Error writing test cases: %s\n", err)
+			return 1
+		}
+	}
+	if opts.CSV != "" {
+		if err := writeCSV(opts.CSV, reports); err != nil {
+			color.Red("Error writing CSV: %s\n", err)
+			return 1
+		}
+	}
+	if opts.Markdown != "" {
+		if err := writeMarkdown(opts.Markdown, reports); err != nil {
+			color.Red("Error writing Markdown report: %s\n", err)
+			return 1
+		}
+	}
+	if opts.SARIF != "" {
+		if err := writeSARIF(opts.SARIF, reports); err != nil {
+			color.Red("Error writing SARIF: %s\n", err)
+			return 1
+		}
+	}
+	if opts.ZapContext != "" {
+		if err := writeZapContext(opts.ZapContext, reports); err != nil {
+			color.Red("Error writing ZAP context: %s\n", err)
+			return 1
+		}
+	}
+	if opts.Expect != "" {
+		violations, err := runExpectations(textWriter(), reports)
+		if err != nil {
+			color.Red("Error reading -expect spec: %s\n", err)
+			return 1
+		}
+		if violations > 0 {
+			color.Red("%d of %d targets don't match the expected deeplinks.", violations, len(reports))
+			exitCode = 1
+		}
+	}
+	if failed := runHooks(reports); failed > 0 && opts.HookStrict {
+		color.Red("%d hook runs failed.", failed)
+		exitCode = 1
+	}
+	if failed := postResults(reports); failed > 0 && exitCode == 0 {
+		exitCode = deliveryExitCode // Analysis worked; only delivery failed
+	}
+	sendNotifications(reports) // Best effort; never changes the exit code
+	if opts.ServePoC != "" {
+		if err := servePoC(reports); err != nil {
+			color.Red("Error serving PoC page: %s\n", err)
+			return 1
+		}
+	}
+
+	progressf(color.Output, "Done.")
+	return exitCode
+}
+
+func main() {
 	// Command-line flags definition
-	apkPath := flag.String("apk", "", "Path to the APK file to be decompiled")
-	folderPath := flag.String("folder", "", "Folder to search in if APK is already decompiled")
+	flag.StringVar(&opts.APKPath, "apk", "", "Path or glob of the APK files (or .zip/.tar.gz bundles of APKs) to be decompiled")
+	flag.StringVar(&opts.Folder, "folder", "", "Folder to search in if APK is already decompiled")
+	flag.StringVar(&opts.StringsProperties, "strings-properties", "", "Properties file of name=value strings used for placeholder resolution")
+	flag.StringVar(&opts.Locale, "locale", "", "Prefer the strings of res/values-<locale> over the default res/values")
+	flag.StringVar(&opts.Format, "format", "text", "Output format: text, json, defectdojo, zap-urls or uris")
+	jsonOutput := flag.Bool("json", false, "Shorthand for -format json")
+	urisOutput := flag.Bool("uris", false, "Shorthand for -format uris")
+	aabPath := flag.String("aab", "", "Android App Bundle (.aab) to analyze, decoding its base module in-process")
+	dirPath := flag.String("dir", "", "Directory to search recursively for .apk files to analyze")
+	flag.StringVar(&opts.Action, "action", "", "Only list components handling this action (SEND, VIEW, ... are shorthand for android.intent.action.*)")
+	flag.BoolVar(&opts.CustomActionsOnly, "custom-actions-only", false, "Only list components handling custom (app-defined) actions")
+	flag.BoolVar(&opts.Boxed, "boxed", false, "Draw each exported component in a box with a one-line risk summary")
+	flag.StringVar(&opts.Sort, "sort", "manifest", "Component order: manifest or risk (router-style components first, then by worst severity)")
+	flag.IntVar(&opts.RouterHosts, "router-hosts", 10, "Distinct hosts that make an activity router-style (0 to ignore)")
+	flag.IntVar(&opts.RouterSchemes, "router-schemes", 5, "Distinct schemes that make an activity router-style (0 to ignore)")
+	flag.IntVar(&opts.RouterPaths, "router-paths", 15, "Distinct path families that make an activity router-style (0 to ignore)")
+	flag.BoolVar(&opts.Collapse, "collapse", false, "Summarize each component's URIs per scheme and host as path families with counts")
+	flag.BoolVar(&opts.Matrix, "matrix", false, "Show each component's deeplinks as a per-filter table")
+	flag.BoolVar(&opts.QR, "qr", false, "Render each deeplink as a QR code in the terminal")
+	flag.IntVar(&opts.QRLimit, "qr-limit", 0, "Most QR codes rendered per APK with -qr (0 for all)")
+	flag.StringVar(&opts.ServePoC, "serve-poc", "", "Serve a clickable deeplink test page on this [host]:port after analysis")
+	flag.BoolVar(&opts.ServeLAN, "serve-lan", false, "Expose the -serve-poc page on the LAN instead of localhost only")
+	flag.DurationVar(&opts.ServeTimeout, "serve-timeout", 0, "Stop the -serve-poc server after this long (0 waits for Ctrl-C)")
+	flag.StringVar(&opts.SchemeRules, "scheme-rules", "", "JSON file mapping schemes to URI construction hints (style, default_host, default_path)")
+	flag.BoolVar(&opts.Open, "open", false, "Open the HTML report or PoC page in the default browser")
+	flag.StringVar(&opts.HTML, "html", "", "Write a single self-contained HTML report with a filter box and a copy button per deeplink")
+	flag.StringVar(&opts.HTMLDir, "html-dir", "", "Write one HTML report per APK and a sortable index.html into this directory")
+	flag.BoolVar(&opts.ResolveAliases, "resolve-aliases", false, "Attribute activity-alias deeplinks to their targetActivity")
+	flag.BoolVar(&opts.ReportUnknown, "report-unknown", false, "Summarize manifest elements and attributes Deeeeper does not model")
+	flag.StringVar(&opts.GenAssetLinks, "gen-assetlinks", "", "Write the assetlinks.json each autoVerify host must serve into this directory")
+	flag.StringVar(&opts.CertFingerprint, "cert-fingerprint", "", "SHA-256 signing certificate fingerprint for -gen-assetlinks when the APK doesn't reveal it")
+	flag.StringVar(&opts.Expect, "expect", "", "JSON or YAML list of the deeplinks (and handlers) the app must expose exactly")
+	flag.BoolVar(&opts.ExpectAllowMissing, "expect-allow-missing", false, "Don't fail -expect when expected deeplinks are absent")
+	flag.BoolVar(&opts.ExpectAllowUnexpected, "expect-allow-unexpected", false, "Don't fail -expect on deeplinks the spec doesn't list")
+	flag.StringVar(&opts.TestCases, "testcases", "", "Write deeplink intent-resolution test cases as a JSON array to this file")
+	flag.StringVar(&opts.CSV, "csv", "", "Write one CSV row per component and action/URI pair (type, name, exported, action, scheme, host, port, path, uri)")
+	flag.StringVar(&opts.Markdown, "markdown", "", "Write a Markdown report: exported components per type and all deeplink URIs")
+	flag.StringVar(&opts.SARIF, "sarif", "", "Write a SARIF 2.1.0 log with one result per finding and per browsable deeplink")
+	flag.StringVar(&opts.Template, "template", "", "Render the reports to stdout with a Go text/template file")
+	flag.StringVar(&opts.Output, "o", "", "Also write the text output, without colors, to this file (or the -format document instead of stdout)")
+	flag.BoolVar(&opts.Force, "force", false, "Let -o overwrite an existing file")
+	flag.BoolVar(&opts.NoColor, "no-color", false, "Disable colored output")
+	flag.BoolVar(&opts.Quiet, "quiet", false, "Print only results: no banner, progress messages or section headers")
+	flag.BoolVar(&opts.Quiet, "q", false, "Shorthand for -quiet")
+	flag.StringVar(&opts.ZapContext, "zap-context", "", "Write a ZAP context file including the hosts and path prefixes of http(s) deeplinks")
+	flag.IntVar(&opts.TargetSDK, "target-sdk", 0, "Evaluate implicit exports as if running on this SDK level")
+	flag.IntVar(&opts.Retries, "retries", 1, "Times to retry a failed apktool run (permanent failures are not retried)")
+	flag.StringVar(&opts.RequireAPKTool, "require-apktool-version", "", "Fail unless the installed apktool is at least this version")
+	flag.BoolVar(&opts.Catalog, "catalog", false, "After a batch run, summarize deeplink surfaces shared across apps and unique to one")
+	flag.StringVar(&opts.Hook, "hook", "", "Run this shell command after analysis with the JSON result on stdin")
+	flag.BoolVar(&opts.HookStrict, "hook-strict", false, "Exit non-zero when a -hook run fails")
+	flag.BoolVar(&opts.HookCombined, "hook-combined", false, "In batch runs, also run -hook once with all results")
+	flag.DurationVar(&opts.HookTimeout, "hook-timeout", time.Minute, "Kill a -hook run after this long (0 for no limit)")
+	flag.BoolVar(&opts.SelfTest, "selftest", false, "Analyze a built-in synthetic app and check the results (exit code 0 when all checks pass)")
+	flag.BoolVar(&opts.Verbose, "verbose", false, "Print extra diagnostics, such as the stderr of -hook runs")
+	flag.BoolVar(&opts.Strict, "strict", false, "Fail on malformed manifests instead of attempting repairs")
+	flag.StringVar(&opts.Framework, "framework", "", "framework-res.apk (or resources.arsc) used to resolve @android: references of system apps")
+	flag.StringVar(&opts.FrameworkDir, "framework-dir", "", "apktool framework directory holding installed framework packages (passed to apktool as -p)")
+	flag.BoolVar(&opts.InstallFramework, "install-framework", false, "Install the -framework APK into apktool before decompiling")
+	flag.BoolVar(&opts.APKTool, "apktool", false, "Always decompile with apktool instead of decoding the manifest in-process")
+	flag.BoolVar(&opts.Quick, "quick", false, "Triage mode: decode in-process into a scratch folder that is removed afterwards, without consulting apktool")
+	flag.BoolVar(&opts.NoFallback, "no-fallback", false, "Fail instead of falling back to apktool when in-process decoding fails")
+	flag.BoolVar(&opts.EncodePlaceholders, "encode-placeholders", false, "Percent-encode {placeholder} braces in constructed URIs instead of keeping them visible")
+	flag.BoolVar(&opts.ADBCommands, "adb-commands", false, "Print adb am start and content query/read commands for every deeplink and provider URI, grouped per component")
+	flag.BoolVar(&opts.ADB, "adb", false, "Print the adb am start command of every deeplink, grouped per component")
+	flag.BoolVar(&opts.OnlyDeeplinks, "only-deeplinks", false, "Report only deeplink-bearing activities and aliases with their URIs")
+	flag.BoolVar(&opts.OnlyComponents, "only-components", false, "Report the exported component inventory without URIs; strings.xml is not loaded")
+	flag.BoolVar(&opts.Browsable, "browsable", false, "Only list the URIs of intent filters with both the VIEW action and the BROWSABLE category")
+	flag.BoolVar(&opts.Inventory, "inventory", false, "Print a flat, sorted, de-duplicated list of every deeplink with its declaring components")
+	flag.StringVar(&opts.PostURL, "post-url", "", "POST the JSON results to this endpoint after analysis")
+	flag.Var(&opts.PostHeaders, "post-header", "Extra \"Name: value\" header for -post-url (repeatable, $VARS are expanded)")
+	flag.StringVar(&opts.PostMode, "post-mode", "per-target", "How -post-url sends batches: per-target or combined")
+	flag.DurationVar(&opts.PostTimeout, "post-timeout", 30*time.Second, "Timeout of each -post-url request")
+	flag.BoolVar(&opts.PostInsecure, "post-insecure", false, "Skip TLS certificate verification for -post-url")
+	flag.StringVar(&opts.Webhook, "webhook", "", "POST each APK's report as JSON to this URL as soon as it is analyzed")
+	flag.StringVar(&opts.NotifyWebhook, "notify-webhook", "", "POST a summary of notable findings to this URL after analysis")
+	flag.StringVar(&opts.NotifyMinSeverity, "notify-min-severity", "high", "Lowest finding severity that triggers a notification")
+	flag.StringVar(&opts.NotifyFormat, "notify-format", "json", "Notification payload: json or slack (Block Kit)")
+	flag.IntVar(&opts.NotifyTop, "notify-top", 5, "Number of findings included in a notification")
+	flag.DurationVar(&opts.Heartbeat, "heartbeat", 30*time.Second, "Interval between progress lines on stderr in non-interactive batch runs (0 disables)")
+	flag.IntVar(&opts.Jobs, "jobs", 1, "Number of APKs analyzed in parallel")
+	flag.BoolVar(&opts.WarnDuplicatePackages, "warn-duplicate-packages", false, "Warn when several APKs in a batch resolve to the same package")
+	flag.IntVar(&opts.DecompileJobs, "decompile-jobs", 0, "Maximum concurrent apktool processes (0 picks a default from available memory)")
+	flag.BoolVar(&opts.DryRun, "dry-run", false, "With -launch, print the adb commands with planned timings instead of running them")
+	flag.BoolVar(&opts.Launch, "launch", false, "Fire every intent test case at the attached device with adb and report each outcome")
+	flag.StringVar(&opts.Serial, "serial", "", "Serial of the adb device used by device features (required when several are attached)")
+	flag.BoolVar(&opts.SkipUnchanged, "skip-unchanged", false, "Skip APKs whose hash matches the previous run and reuse their report")
+	flag.StringVar(&opts.StateFile, "state-file", "", "State file used by -skip-unchanged")
+	flag.StringVar(&opts.Diff, "diff", "", "Compare with the -format json report of an earlier build")
+	since := flag.String("since", "", "Only analyze APKs modified after this RFC3339 time")
+	newerThan := flag.String("newer-than", "", "Only analyze APKs modified after this file")
+	cpuProfile := flag.String("cpuprofile", "", "Write a CPU profile of the whole run to this file")
+	memProfile := flag.String("memprofile", "", "Write a heap profile taken at the end of the run to this file")
+	traceFile := flag.String("trace", "", "Write an execution trace of the whole run to this file")
 	help := flag.Bool("help", false, "Display help")
 	flag.BoolVar(help, "h", false, "Display help (shorthand)")
 
+	// Defaults from DEEEEPER_OPTS come first so the real command line overrides them
+	defaults, err := envArgs()
+	if err != nil {
+		color.Red("Error %s\n", err)
+		os.Exit(1)
+	}
+	flag.CommandLine.Parse(defaults)
+	if flag.NArg() > 0 {
+		color.Red("Error %s: unexpected argument %q\n", envOptsVar, flag.Arg(0))
+		os.Exit(1)
+	}
+	fromEnv := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { fromEnv[f.Name] = true })
 	flag.Parse() // Parsing the command-line flags
+	if *jsonOutput {
+		opts.Format = "json"
+	}
+	if *urisOutput {
+		opts.Format = "uris"
+	}
+	if *aabPath != "" { // Bundles go through the -apk target handling
+		if opts.APKPath != "" || !isBundle(*aabPath) {
+			color.Red("Error -aab takes a .aab file and can't be combined with -apk\n")
+			os.Exit(1)
+		}
+		opts.APKPath = *aabPath
+	}
+	if *dirPath != "" { // The walked APKs go through the -apk target handling too
+		if info, err := os.Stat(*dirPath); opts.APKPath != "" || err != nil || !info.IsDir() {
+			color.Red("Error -dir takes a directory and can't be combined with -apk or -aab\n")
+			os.Exit(1)
+		}
+		opts.APKPath = *dirPath
+	}
+
+	color.Output = color.Error // Banner, progress, warnings and errors; stdout only carries results
+	terminal := os.Stdout
+	if machineFormat() { // The structured document owns stdout
+		terminal = os.Stderr
+	}
+	color.NoColor = !colorEnabled(terminal) // Covers the banner and every Sprint helper too
+	if !opts.Quiet {
+		displayBanner()
+	}
+	if opts.Verbose {
+		printEffectiveOptions(color.Output, fromEnv, commandLineFlags(os.Args[1:]))
+	}
 
 	if *help { // If help flag is invoked, display help menu
 		displayHelp()
 		return // Exit after displaying help
 	}
 
-	// Variables for manifest and strings file paths
-	var manifestPath, stringsPath string
-
-	if *apkPath != "" { // Proceed if APK path is provided
-		color.Green("Decompiling APK...")
-		outputDir, err := decompileAPK(*apkPath)
-		if err != nil { // Handling errors from APK decompilation
-			color.Red("Error decompiling APK: %s\n", err)
-			os.Exit(1) // Exiting with error code
-		}
-		// Setting paths for manifest and strings within the decompiled directory
-		manifestPath = fmt.Sprintf("%s/AndroidManifest.xml", outputDir)
-		stringsPath = fmt.Sprintf("%s/res/values/strings.xml", outputDir)
-	} else if *folderPath != "" { // If only the folder path is provided
-		color.Green("Using provided folder for search...")
-		// Directly set paths assuming the standard structure within the folder
-		manifestPath = fmt.Sprintf("%s/AndroidManifest.xml", *folderPath)
-		stringsPath = fmt.Sprintf("%s/res/values/strings.xml", *folderPath)
-	} else {
-		color.Red("Please provide either an APK file or a folder to proceed.")
-		os.Exit(1) // Exit if neither flag is provided
+	if !slices.Contains(outputFormats, opts.Format) {
+		color.Red("Unknown output format %q (expected one of: %s)\n", opts.Format, strings.Join(outputFormats, ", "))
+		os.Exit(1)
 	}
-
-	// Reading and parsing strings.xml
-	stringsFile, err := os.ReadFile(stringsPath)
-	if err != nil { // Error handling for file reading failure
-		color.Red("Error reading strings file: %s\n", err)
-		os.Exit(1) // Exiting with error code
+	if opts.Template != "" { // Fail on template syntax before decompiling
+		if opts.Format != "text" {
+			color.Red("Error -template replaces the output and can't be combined with -format %s\n", opts.Format)
+			os.Exit(1)
+		}
+		if err := loadOutputTemplate(opts.Template); err != nil {
+			color.Red("Error -template: %s\n", err)
+			os.Exit(1)
+		}
 	}
 
-	var stringResources StringResource
-	xml.Unmarshal(stringsFile, &stringResources) // Unmarshalling XML into struct
-	stringMap := make(map[string]string)         // Map for string name-value pairs
-	for _, s := range stringResources.Strings {
-		stringMap[s.Name] = s.Text // Populating the map
+	if _, ok := severityRank[opts.NotifyMinSeverity]; !ok {
+		color.Red("Unknown severity %q for -notify-min-severity\n", opts.NotifyMinSeverity)
+		os.Exit(1)
+	}
+	if opts.PostMode != "per-target" && opts.PostMode != "combined" {
+		color.Red("Unknown post mode %q (expected per-target or combined)\n", opts.PostMode)
+		os.Exit(1)
+	}
+	if opts.Sort != "manifest" && opts.Sort != "risk" {
+		color.Red("Unknown sort order %q (expected manifest or risk)\n", opts.Sort)
+		os.Exit(1)
+	}
+	if opts.NotifyFormat != "json" && opts.NotifyFormat != "slack" {
+		color.Red("Unknown notification format %q (expected json or slack)\n", opts.NotifyFormat)
+		os.Exit(1)
+	}
+	if opts.NotifyTop < 1 {
+		color.Red("Error -notify-top must be at least 1, got %d\n", opts.NotifyTop)
+		os.Exit(1)
+	}
+	if (opts.DryRun || opts.Serial != "") && !opts.Launch { // No other feature talks to a device
+		color.Red("Error -dry-run and -serial only apply to -launch\n")
+		os.Exit(1)
 	}
 
-	// Reading and preprocessing AndroidManifest.xml
-	manifestFile, err := os.ReadFile(manifestPath)
-	if err != nil { // Error handling for file reading failure
-		color.Red("Error reading manifest file: %s\n", err)
-		os.Exit(1) // Exiting with error code
+	if err := checkOutputMode(); err != nil {
+		color.Red("Error %s\n", err)
+		os.Exit(1)
 	}
 
-	rawManifest := string(manifestFile) // Converting file content to string
-	for key, value := range stringMap { // Replacing placeholders with actual string values
-		placeholder := fmt.Sprintf("@string/%s", key)
-		rawManifest = strings.ReplaceAll(rawManifest, placeholder, value)
+	if opts.CertFingerprint != "" {
+		if opts.CertFingerprint, err = normalizeFingerprint(opts.CertFingerprint); err != nil {
+			color.Red("Error -cert-fingerprint: %s\n", err)
+			os.Exit(1)
+		}
 	}
 
-	var manifest Manifest
-	err = xml.Unmarshal([]byte(rawManifest), &manifest) // Unmarshalling manifest XML
-	if err != nil {                                     // Error handling for XML unmarshalling failure
-		color.Red("Error parsing manifest: %s\n", err)
-		os.Exit(1) // Exiting with error code
+	if opts.CSV != "" { // Fail now rather than after decompiling
+		if err := checkCreatable(opts.CSV); err != nil {
+			color.Red("Error -csv: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	if opts.HTML != "" {
+		if err := checkCreatable(opts.HTML); err != nil {
+			color.Red("Error -html: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	if opts.SARIF != "" {
+		if err := checkCreatable(opts.SARIF); err != nil {
+			color.Red("Error -sarif: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	if opts.InstallFramework && opts.Framework == "" {
+		color.Red("Error -install-framework needs the framework APK given with -framework\n")
+		os.Exit(1)
+	}
+	if opts.InstallFramework { // Only apktool decodes with installed OEM frameworks
+		opts.APKTool = true
+	}
+	if opts.Quick && opts.APKTool {
+		color.Red("Error -quick decodes in-process and can't be combined with -apktool or -install-framework\n")
+		os.Exit(1)
+	}
+	if opts.Framework != "" {
+		if err := loadFramework(opts.Framework); err != nil {
+			color.Red("Error reading framework resources: %s\n", err)
+			os.Exit(1)
+		}
+	} else if installed := installedFramework(); installed != "" {
+		if err := loadFramework(installed); err != nil { // Best effort: the bundled defaults remain
+			color.Yellow("Warning: apktool framework %s unreadable: %s", installed, err)
+		}
 	}
 
-	// Process components
-	color.Yellow("\nProcessing Activities:")
-	processComponents(manifest.Activities)
+	if opts.SchemeRules != "" {
+		if err := loadSchemeRules(opts.SchemeRules); err != nil {
+			color.Red("Error reading scheme rules: %s\n", err)
+			os.Exit(1)
+		}
+	}
 
-	color.Yellow("\nProcessing Aliases:")
-	processComponents(manifest.Aliases)
+	if opts.Since, err = parseSince(*since, *newerThan); err != nil {
+		color.Red("Error %s\n", err)
+		os.Exit(1)
+	}
 
-	color.Yellow("\nProcessing Services:")
-	processComponents(manifest.Services)
+	if opts.SelfTest {
+		os.Exit(runSelfTest())
+	}
 
-	color.Yellow("\nProcessing Receivers:")
-	processComponents(manifest.Receivers)
+	if opts.Output != "" { // Created up front so a taken name fails before decompiling
+		if err := openResultsFile(opts.Output); err != nil {
+			color.Red("Error -o: %s\n", err)
+			os.Exit(1)
+		}
+	}
 
-	color.Green("Done.")
+	stopProfiling, err := startProfiling(*cpuProfile, *memProfile, *traceFile)
+	if err != nil {
+		color.Red("Error starting profiler: %s\n", err)
+		os.Exit(1)
+	}
+	code := run()
+	stopProfiling() // Profiles cover the whole run, including apktool subprocess waits
+	if resultsFile != nil {
+		if err := resultsFile.Close(); err != nil {
+			color.Red("Error writing %s: %s\n", opts.Output, err)
+			code = 1
+		}
+	}
+	os.Exit(code)
 }
@@ -0,0 +1,81 @@
+package deeeeper
+
+import (
+	"encoding/xml" // Custom element decoding
+)
+
+// namespacePrefixes maps the usual manifest namespace URIs back to the
+// prefixes used in source manifests.
+var namespacePrefixes = map[string]string{
+	"http://schemas.android.com/apk/res/android": "android",
+	"http://schemas.android.com/tools":           "tools",
+	"http://schemas.android.com/apk/res-auto":    "app",
+}
+
+// RawAttributes keeps every attribute of an element, keyed as written in the
+// manifest (android:exported). Unknown namespaces keep their URI as prefix.
+func RawAttributes(attrs []xml.Attr) map[string]string {
+	if len(attrs) == 0 {
+		return nil
+	}
+	raw := make(map[string]string, len(attrs))
+	for _, attr := range attrs {
+		if attr.Name.Space == "xmlns" || attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+			continue // Namespace declarations are not attributes of the component
+		}
+		key := attr.Name.Local
+		if attr.Name.Space != "" {
+			prefix, ok := namespacePrefixes[attr.Name.Space]
+			if !ok {
+				prefix = attr.Name.Space
+			}
+			key = prefix + ":" + key
+		}
+		raw[key] = attr.Value
+	}
+	return raw
+}
+
+// UnmarshalXML decodes a component as usual and additionally records all of
+// its attributes, modeled or not.
+func (a *App) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain App // Same fields without this method, avoiding recursion
+	if err := d.DecodeElement((*plain)(a), &start); err != nil {
+		return err
+	}
+	a.Attributes = RawAttributes(start.Attr)
+	return nil
+}
+
+// UnmarshalXML decodes a permission declaration as usual and additionally
+// records all of its attributes, tools: merge rules included.
+func (p *PermissionDecl) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain PermissionDecl
+	if err := d.DecodeElement((*plain)(p), &start); err != nil {
+		return err
+	}
+	p.Attributes = RawAttributes(start.Attr)
+	return nil
+}
+
+// UnmarshalXML decodes a permission request as usual and additionally records
+// all of its attributes, tools: merge rules included.
+func (u *UsesPermission) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain UsesPermission
+	if err := d.DecodeElement((*plain)(u), &start); err != nil {
+		return err
+	}
+	u.Attributes = RawAttributes(start.Attr)
+	return nil
+}
+
+// UnmarshalXML decodes an intent filter as usual and additionally records all
+// of its attributes, modeled or not.
+func (f *IntentFilter) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain IntentFilter
+	if err := d.DecodeElement((*plain)(f), &start); err != nil {
+		return err
+	}
+	f.Attributes = RawAttributes(start.Attr)
+	return nil
+}
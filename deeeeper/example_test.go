@@ -0,0 +1,41 @@
+package deeeeper_test
+
+import (
+	"fmt"     // Example output
+	"log"     // Example error handling
+	"strings" // Inline strings.xml
+
+	"Deeeeper/Deeeeper/deeeeper"
+)
+
+// Example resolves the string references of an apktool-decoded manifest and
+// lists the deeplinks its activities declare.
+func Example() {
+	manifest := []byte(`<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="org.example.shop">
+    <application>
+        <activity android:name=".Product" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="https" android:host="@string/shop_host" android:pathPrefix="/p/"/>
+                <data android:scheme="shop" android:host="product"/>
+            </intent-filter>
+        </activity>
+    </application>
+</manifest>`)
+	values, err := deeeeper.ParseStrings(strings.NewReader(`<resources><string name="shop_host">shop.example.com</string></resources>`))
+	if err != nil {
+		log.Fatal(err)
+	}
+	m, err := deeeeper.ParseManifest(manifest)
+	if err != nil {
+		log.Fatal(err)
+	}
+	deeeeper.ResolveManifest(&m, deeeeper.StringResources(values))
+	for _, link := range deeeeper.ExtractDeeplinks(m) {
+		fmt.Println(m.Package, link.Component, link.URI)
+	}
+	// Output:
+	// org.example.shop .Product https://shop.example.com/p/
+	// org.example.shop .Product shop://product
+}
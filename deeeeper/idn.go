@@ -0,0 +1,135 @@
+package deeeeper
+
+import (
+	"slices"       // Distinct scripts
+	"sort"         // Deterministic script lists
+	"strings"      // Label handling
+	"unicode"      // Script tables
+	"unicode/utf8" // ASCII detection
+
+	"golang.org/x/net/idna" // UTS #46 mapping, validation and punycode
+)
+
+// acePrefix is the ASCII-compatible encoding prefix of IDNA labels.
+const acePrefix = "xn--"
+
+// transitional converts hosts like idna.Lookup but maps the deviation
+// characters as IDNA2003 did, ß to ss among them, which is how Android's
+// java.net.IDN resolves them.
+var transitional = idna.New(idna.MapForLookup(), idna.Transitional(true), idna.BidiRule())
+
+// cjkScripts may be mixed with each other and with Latin in one label, as
+// Japanese, Chinese and Korean names routinely are.
+var cjkScripts = map[string]bool{"Latin": true, "Han": true, "Hiragana": true, "Katakana": true, "Hangul": true, "Bopomofo": true}
+
+// NormalizeHost returns the form hosts are grouped, de-duplicated and compared
+// in: lower-cased and without the trailing dot of a fully qualified name, so
+// App.Example.COM. and app.example.com are one host. A trailing :port is kept.
+// Raw fields keep the spelling the manifest declares.
+func NormalizeHost(host string) string {
+	name, port, hasPort := strings.Cut(strings.ToLower(host), ":")
+	name = strings.TrimRight(name, ".")
+	if hasPort {
+		return name + ":" + port
+	}
+	return name
+}
+
+// NormalizeScheme lower-cases a scheme, which RFC 3986 makes case-insensitive.
+func NormalizeScheme(scheme string) string {
+	return strings.ToLower(scheme)
+}
+
+// HostToASCII returns the ASCII form of a host name through the UTS #46
+// lookup mapping: the form DNS and other machine consumers need. Fullwidth and
+// other compatibility characters map to their plain form, deviation
+// characters such as ß as IDNA2003 mapped them, and labels idna.Lookup
+// rejects, such as a zero width joiner outside of a joining script, are an
+// error. A leading "*." wildcard and a trailing :port are kept. On error the
+// host is only normalized, see NormalizeHost.
+func HostToASCII(host string) (string, error) {
+	name, port, hasPort := strings.Cut(NormalizeHost(host), ":")
+	wildcard := ""
+	if rest, ok := strings.CutPrefix(name, "*."); ok {
+		wildcard, name = "*.", rest
+	}
+	if _, err := idna.Lookup.ToASCII(name); err != nil {
+		return NormalizeHost(host), err
+	}
+	ascii, err := transitional.ToASCII(name)
+	if err != nil {
+		return NormalizeHost(host), err
+	}
+	if hasPort {
+		return wildcard + ascii + ":" + port, nil
+	}
+	return wildcard + ascii, nil
+}
+
+// HostToUnicode returns the display form of a host name, decoding xn-- labels.
+// Labels that fail to decode are kept as they are.
+func HostToUnicode(host string) string {
+	labels := strings.Split(strings.ToLower(host), ".")
+	for i, label := range labels {
+		if !strings.HasPrefix(label, acePrefix) {
+			continue
+		}
+		if decoded, err := idna.Lookup.ToUnicode(label); err == nil {
+			labels[i] = decoded
+		}
+	}
+	return strings.Join(labels, ".")
+}
+
+// MixedScripts returns the scripts of every host label that combines letters of
+// several scripts, such as a Cyrillic "а" among Latin letters: a common
+// homograph lure. Latin combined with CJK scripts is not reported.
+func MixedScripts(host string) []string {
+	var mixed []string
+	for _, label := range strings.Split(HostToUnicode(host), ".") {
+		scripts := labelScripts(label)
+		if len(scripts) < 2 || allCJK(scripts) {
+			continue
+		}
+		for _, script := range scripts {
+			if !slices.Contains(mixed, script) {
+				mixed = append(mixed, script)
+			}
+		}
+	}
+	sort.Strings(mixed)
+	return mixed
+}
+
+// labelScripts returns the sorted scripts of the letters in a label, ignoring
+// digits, hyphens and other characters shared by all scripts.
+func labelScripts(label string) []string {
+	seen := make(map[string]bool)
+	for _, r := range label {
+		if r < utf8.RuneSelf && !unicode.IsLetter(r) {
+			continue
+		}
+		for name, table := range unicode.Scripts {
+			if name != "Common" && name != "Inherited" && unicode.Is(table, r) {
+				seen[name] = true
+				break
+			}
+		}
+	}
+	scripts := make([]string, 0, len(seen))
+	for name := range seen {
+		scripts = append(scripts, name)
+	}
+	sort.Strings(scripts)
+	return scripts
+}
+
+// allCJK reports whether every script is Latin or part of the CJK family.
+func allCJK(scripts []string) bool {
+	for _, script := range scripts {
+		if !cjkScripts[script] {
+			return false
+		}
+	}
+	return true
+}
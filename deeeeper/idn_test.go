@@ -0,0 +1,109 @@
+package deeeeper
+
+import (
+	"slices"  // Script lists
+	"testing" // Test harness
+)
+
+func TestHostToASCII(t *testing.T) {
+	for _, tc := range []struct {
+		host, want string
+	}{
+		{"example.com", "example.com"},
+		{"аpple.com", "xn--pple-43d.com"},   // Cyrillic а among Latin letters
+		{"аррӏе.com", "xn--80ak6aa92e.com"}, // Every letter Cyrillic
+		{"Bücher.Example", "xn--bcher-kva.example"},
+		{"例え.jp", "xn--r8jz45g.jp"},
+		{"xn--pple-43d.com", "xn--pple-43d.com"}, // Already punycoded
+		{"bücher.example:8443", "xn--bcher-kva.example:8443"},
+		{"bücher.example.", "xn--bcher-kva.example"},
+		{"ａｐｐ.example", "app.example"}, // Fullwidth letters map to ASCII
+		{"Straße.de", "strasse.de"},    // Deviation character mapped as by IDNA2003
+		{"*.Bücher.example", "*.xn--bcher-kva.example"},
+	} {
+		got, err := HostToASCII(tc.host)
+		if err != nil || got != tc.want {
+			t.Errorf("HostToASCII(%q) = %q, %v; want %q", tc.host, got, err, tc.want)
+		}
+	}
+}
+
+// TestHostToASCIIInvalid checks hosts UTS #46 rejects are reported, and only
+// normalized.
+func TestHostToASCIIInvalid(t *testing.T) {
+	for host, want := range map[string]string{
+		"pay\u200dpal.example": "pay\u200dpal.example", // Zero width joiner between Latin letters
+		"My_Host.example":      "my_host.example",
+		"xn--!!!.example":      "xn--!!!.example",
+		"{host}.example":       "{host}.example",
+	} {
+		got, err := HostToASCII(host)
+		if err == nil || got != want {
+			t.Errorf("HostToASCII(%q) = %q, %v; want %q and an error", host, got, err, want)
+		}
+	}
+}
+
+func TestHostToUnicode(t *testing.T) {
+	for _, tc := range []struct {
+		host, want string
+	}{
+		{"xn--pple-43d.com", "аpple.com"},
+		{"XN--BCHER-KVA.example", "bücher.example"},
+		{"example.com", "example.com"},
+		{"xn--!!!.example", "xn--!!!.example"}, // Invalid punycode stays as is
+	} {
+		if got := HostToUnicode(tc.host); got != tc.want {
+			t.Errorf("HostToUnicode(%q) = %q, want %q", tc.host, got, tc.want)
+		}
+	}
+	for _, host := range []string{"аpple.com", "аррӏе.com", "bücher.example", "パスワード.example"} {
+		ascii, _ := HostToASCII(host)
+		if got := HostToUnicode(ascii); got != host {
+			t.Errorf("round trip of %q through %q gave %q", host, ascii, got)
+		}
+	}
+}
+
+func TestMixedScripts(t *testing.T) {
+	for _, tc := range []struct {
+		host string
+		want []string
+	}{
+		{"аpple.com", []string{"Cyrillic", "Latin"}},
+		{"xn--pple-43d.com", []string{"Cyrillic", "Latin"}}, // Punycoded lure
+		{"pаypal.example", []string{"Cyrillic", "Latin"}},
+		{"аррӏе.com", nil},        // Single-script labels are ordinary IDNs
+		{"bücher.example", nil},   // Latin with diacritics
+		{"パスワードabc.example", nil}, // Latin mixed with CJK is common
+		{"example.com", nil},
+	} {
+		if got := MixedScripts(tc.host); !slices.Equal(got, tc.want) {
+			t.Errorf("MixedScripts(%q) = %q, want %q", tc.host, got, tc.want)
+		}
+	}
+}
+
+func TestNormalizeHost(t *testing.T) {
+	for host, want := range map[string]string{
+		"app.example.com":       "app.example.com",
+		"App.Example.COM":       "app.example.com",
+		"APP.EXAMPLE.COM.":      "app.example.com",
+		"app.example.com..":     "app.example.com",
+		"App.Example.COM.:8443": "app.example.com:8443",
+		"*.Example.com":         "*.example.com",
+		"":                      "",
+	} {
+		if got := NormalizeHost(host); got != want {
+			t.Errorf("NormalizeHost(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+func TestNormalizeScheme(t *testing.T) {
+	for scheme, want := range map[string]string{"https": "https", "HTTPS": "https", "Intent": "intent", "my-App+v2": "my-app+v2"} {
+		if got := NormalizeScheme(scheme); got != want {
+			t.Errorf("NormalizeScheme(%q) = %q, want %q", scheme, got, want)
+		}
+	}
+}
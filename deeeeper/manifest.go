@@ -0,0 +1,187 @@
+// Package deeeeper parses the AndroidManifest.xml and strings.xml files
+// apktool decodes and constructs the deeplinks their intent filters declare.
+// The deeeeper command is built on it; other tools can use it to work with
+// manifests without running the command.
+package deeeeper
+
+import (
+	"bytes"        // Manifest input
+	"encoding/xml" // XML parsing support
+	"slices"       // Attribute checks
+)
+
+// Manifest holds the parts of AndroidManifest.xml the analysis looks at.
+type Manifest struct {
+	XMLName         xml.Name         `xml:"manifest"`
+	Package         string           `xml:"package,attr"`
+	SharedUserID    string           `xml:"sharedUserId,attr"`         // Sandbox shared with same-signature apps
+	SharedUserLabel string           `xml:"sharedUserLabel,attr"`      // User-visible label of the shared user ID
+	SandboxVersion  string           `xml:"targetSandboxVersion,attr"` // 2 for the instant app sandbox
+	Permissions     []PermissionDecl `xml:"permission"`                // Custom permissions the app declares
+	UsesPermissions []UsesPermission `xml:"uses-permission"`           // Permissions the app requests
+	UsesSDK         UsesSDK          `xml:"uses-sdk"`                  // SDK levels, unless apktool moved them to apktool.yml
+	UsesFeatures    []UsesFeature    `xml:"uses-feature"`              // Hardware and software features the app uses
+	DistModule      DistModule       `xml:"module"`                    // dist:module of an app bundle module
+	Application     Application      `xml:"application"`
+}
+
+// PermissionDecl is a <permission> element declaring a custom permission.
+type PermissionDecl struct {
+	Name            string            `xml:"name,attr"`            // Permission name
+	ProtectionLevel string            `xml:"protectionLevel,attr"` // normal (default), dangerous, signature, ... possibly combined with "|"
+	Attributes      map[string]string `xml:"-"`                    // Every attribute as found in the manifest, see UnmarshalXML
+}
+
+// UsesPermission is a <uses-permission> element requesting a permission.
+type UsesPermission struct {
+	Name          string            `xml:"name,attr"`          // Permission name
+	MaxSDKVersion string            `xml:"maxSdkVersion,attr"` // Highest SDK level the permission is requested on, empty for all
+	Attributes    map[string]string `xml:"-"`                  // Every attribute as found in the manifest, see UnmarshalXML
+}
+
+// UsesSDK is the <uses-sdk> element.
+type UsesSDK struct {
+	MinSDKVersion    string `xml:"minSdkVersion,attr"`    // Lowest SDK level the app installs on
+	TargetSDKVersion string `xml:"targetSdkVersion,attr"` // SDK level the app targets
+}
+
+// UsesFeature is a <uses-feature> element.
+type UsesFeature struct {
+	Name     string `xml:"name,attr"`     // Feature name, empty for glEsVersion-only elements
+	Required string `xml:"required,attr"` // "false" when the app installs on devices without the feature
+}
+
+// DistModule is the dist:module element bundle modules declare.
+type DistModule struct {
+	Instant string `xml:"instant,attr"` // "true" for an instant-enabled module
+}
+
+// Application holds the <application> element: its attributes and components.
+type Application struct {
+	AutoVerify string `xml:"autoVerify,attr"`           // App-wide App Links verification request
+	Permission string `xml:"permission,attr"`           // Default permission of components that declare none
+	Cleartext  string `xml:"usesCleartextTraffic,attr"` // Plain HTTP allowed, unless a network security config decides
+	Debuggable string `xml:"debuggable,attr"`           // Debuggers and run-as may attach
+	Backup     string `xml:"allowBackup,attr"`          // adb backup and cloud backup of app data, true unless declared false
+	Activities []App  `xml:"activity"`
+	Aliases    []App  `xml:"activity-alias"`
+	Services   []App  `xml:"service"`
+	Receivers  []App  `xml:"receiver"`
+	Providers  []App  `xml:"provider"`
+}
+
+// App encapsulates an application component like an activity or service, including its intent filters.
+type App struct {
+	Name                string            `xml:"name,attr"`                // Component name
+	Exported            string            `xml:"exported,attr"`            // Exported status
+	DirectBootAware     string            `xml:"directBootAware,attr"`     // Runs before the user unlocks the device
+	Authorities         string            `xml:"authorities,attr"`         // Provider authorities, separated by ";"
+	Permission          string            `xml:"permission,attr"`          // Permission callers must hold
+	ReadPermission      string            `xml:"readPermission,attr"`      // Provider permission for queries
+	WritePermission     string            `xml:"writePermission,attr"`     // Provider permission for inserts, updates and deletes
+	TargetActivity      string            `xml:"targetActivity,attr"`      // Activity an activity-alias launches
+	SingleUser          string            `xml:"singleUser,attr"`          // Provider shared across all device users
+	ShowWhenLocked      string            `xml:"showWhenLocked,attr"`      // Activity shown on top of the lock screen
+	ShowOnLockScreen    string            `xml:"showOnLockScreen,attr"`    // Legacy spelling of showWhenLocked
+	Multiprocess        string            `xml:"multiprocess,attr"`        // Provider instantiated in every client process
+	GrantURIPermissions string            `xml:"grantUriPermissions,attr"` // Provider URIs can be granted to other apps
+	PathPermissions     []ProviderPath    `xml:"path-permission"`          // Per-path provider permissions
+	GrantURIPaths       []ProviderPath    `xml:"grant-uri-permission"`     // Provider paths that can be granted
+	Filters             []IntentFilter    `xml:"intent-filter"`            // Intent filters
+	MetaData            []MetaData        `xml:"meta-data"`                // Meta-data entries, possibly referencing res/xml
+	Attributes          map[string]string `xml:"-"`                        // Every attribute as found in the manifest, see UnmarshalXML
+	Declarations        int               `xml:"-"`                        // How often the component was declared, set when merging duplicates
+}
+
+// IntentFilter contains actions and data elements for filtering intents.
+type IntentFilter struct {
+	AutoVerify string            `xml:"autoVerify,attr"` // App Links verification requested for the filter's hosts
+	Priority   string            `xml:"priority,attr"`   // Resolution priority; higher values are preferred
+	Order      string            `xml:"order,attr"`      // Preference among the app's own matching filters; higher wins
+	Actions    []Action          `xml:"action"`          // Actions within the filter
+	Categories []Category        `xml:"category"`        // Categories an intent must carry to match
+	Data       []Data            `xml:"data"`            // Data elements specifying URI patterns
+	Attributes map[string]string `xml:"-"`               // Every attribute as found in the manifest, see UnmarshalXML
+}
+
+// Action defines an action element within an intent-filter.
+type Action struct {
+	Name string `xml:"name,attr"` // Action name
+}
+
+// Category defines a category element within an intent-filter.
+type Category struct {
+	Name string `xml:"name,attr"` // Category name
+}
+
+// Data represents a data element within an intent-filter, detailing URI handling.
+type Data struct {
+	Scheme      string `xml:"scheme,attr" json:"scheme,omitempty"`            // URI scheme
+	Host        string `xml:"host,attr" json:"host,omitempty"`                // Hostname
+	Port        string `xml:"port,attr" json:"port,omitempty"`                // Port number
+	Path        string `xml:"path,attr" json:"path,omitempty"`                // Exact path
+	PathPrefix  string `xml:"pathPrefix,attr" json:"path_prefix,omitempty"`   // Path prefix
+	PathPattern string `xml:"pathPattern,attr" json:"path_pattern,omitempty"` // Path pattern
+	Ssp         string `xml:"ssp,attr" json:"ssp,omitempty"`                  // Exact scheme-specific part, e.g. a package name for package:
+	SspPrefix   string `xml:"sspPrefix,attr" json:"ssp_prefix,omitempty"`     // Scheme-specific part prefix
+	SspPattern  string `xml:"sspPattern,attr" json:"ssp_pattern,omitempty"`   // Scheme-specific part pattern
+	MimeType    string `xml:"mimeType,attr" json:"mime_type,omitempty"`       // Accepted mime type
+}
+
+// IsSchemeData checks if the Data struct represents a URI scheme.
+func (d Data) IsSchemeData() bool {
+	return d.Scheme != "" || d.Host != "" || d.Port != "" || d.Path != "" || d.PathPrefix != "" || d.PathPattern != "" || d.SchemeSpecificPart() != ""
+}
+
+// SchemeSpecificPart renders the ssp attributes: exact parts verbatim, prefixes
+// with a trailing "*" and patterns as declared. It is empty when none is set.
+func (d Data) SchemeSpecificPart() string {
+	switch {
+	case d.Ssp != "":
+		return d.Ssp
+	case d.SspPrefix != "":
+		return d.SspPrefix + "*"
+	}
+	return d.SspPattern
+}
+
+// MetaData is a <meta-data> element attached to a component.
+type MetaData struct {
+	Name     string `xml:"name,attr"`     // Meta-data key
+	Value    string `xml:"value,attr"`    // Inline value
+	Resource string `xml:"resource,attr"` // Resource reference such as @xml/shortcuts
+}
+
+// DataAttribute is one URI attribute of a <data> element.
+type DataAttribute struct {
+	Name  string // Attribute name without the android: prefix
+	Value string // Attribute value, possibly empty
+}
+
+// URIAttributes lists the URI attributes of the element, set or not.
+func (d Data) URIAttributes() []DataAttribute {
+	return []DataAttribute{
+		{"scheme", d.Scheme}, {"host", d.Host}, {"port", d.Port}, {"path", d.Path}, {"pathPrefix", d.PathPrefix},
+		{"pathPattern", d.PathPattern}, {"ssp", d.Ssp}, {"sspPrefix", d.SspPrefix}, {"sspPattern", d.SspPattern},
+	}
+}
+
+// Unresolved reports whether a URI attribute of the element still holds a
+// reference or placeholder after resolution, see HasUnresolved.
+func (d Data) Unresolved() bool {
+	return slices.ContainsFunc(d.URIAttributes(), func(attr DataAttribute) bool { return HasUnresolved(attr.Value) })
+}
+
+// ParseManifest decodes a UTF-8 AndroidManifest.xml as apktool writes it.
+// Resource references stay as declared; see ResolveManifest to substitute them.
+func ParseManifest(data []byte) (Manifest, error) {
+	return DecodeManifest(xml.NewDecoder(bytes.NewReader(data)))
+}
+
+// DecodeManifest decodes a manifest from d, which lets the caller set up the
+// decoder, such as its CharsetReader, and ask it where decoding stopped.
+func DecodeManifest(d *xml.Decoder) (Manifest, error) {
+	var manifest Manifest
+	err := d.Decode(&manifest)
+	return manifest, err
+}
@@ -0,0 +1,183 @@
+package deeeeper
+
+import (
+	"reflect" // Result comparison
+	"slices"  // Result comparison
+	"strings" // Strings input
+	"testing" // Test harness
+)
+
+// shopManifest is a small apktool-style manifest: an activity with a verified
+// https filter whose host is a string reference and a custom scheme filter,
+// an alias of it, and a provider and a service, which declare no deeplinks.
+const shopManifest = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" xmlns:tools="http://schemas.android.com/tools" package="org.example.shop">
+    <uses-sdk android:minSdkVersion="24" android:targetSdkVersion="34"/>
+    <uses-permission android:name="android.permission.INTERNET"/>
+    <application android:label="@string/app_name">
+        <activity android:name=".Product" android:exported="true" tools:ignore="AppLinkUrlError">
+            <intent-filter android:autoVerify="true">
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.DEFAULT"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="https" android:host="@string/shop_host" android:pathPrefix="/p/"/>
+            </intent-filter>
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="shop" android:host="product" android:path="/{id}"/>
+                <data android:scheme="shop" android:host="product" android:path="/{id}"/>
+            </intent-filter>
+        </activity>
+        <activity-alias android:name=".Cart" android:targetActivity=".Product" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="Shop" android:host="Cart"/>
+            </intent-filter>
+        </activity-alias>
+        <service android:name=".Sync" android:exported="false"/>
+        <provider android:name=".Files" android:authorities="org.example.shop.files" android:exported="false"/>
+    </application>
+</manifest>
+`
+
+// shopStrings defines the host through another string, with markup and an
+// entity, as apktool writes them.
+const shopStrings = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="app_name">Shop &amp; Go</string>
+    <string name="shop_host">@string/web_host</string>
+    <string name="web_host"><b>shop.example.com</b></string>
+</resources>
+`
+
+func TestParseManifest(t *testing.T) {
+	m, err := ParseManifest([]byte(shopManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Package != "org.example.shop" || m.UsesSDK.TargetSDKVersion != "34" || len(m.UsesPermissions) != 1 {
+		t.Errorf("manifest = %+v", m)
+	}
+	app := m.Application
+	if len(app.Activities) != 1 || len(app.Aliases) != 1 || len(app.Services) != 1 || len(app.Providers) != 1 {
+		t.Fatalf("components: %d activities, %d aliases, %d services, %d providers", len(app.Activities), len(app.Aliases), len(app.Services), len(app.Providers))
+	}
+	product := app.Activities[0]
+	if product.Exported != "true" || len(product.Filters) != 2 || product.Filters[0].AutoVerify != "true" {
+		t.Errorf(".Product = %+v", product)
+	}
+	if product.Attributes["tools:ignore"] != "AppLinkUrlError" || product.Attributes["android:name"] != ".Product" {
+		t.Errorf(".Product attributes = %v, want them keyed by prefix", product.Attributes)
+	}
+	if host := product.Filters[0].Data[0].Host; host != "@string/shop_host" {
+		t.Errorf("host = %q; ParseManifest should leave references alone", host)
+	}
+	if app.Aliases[0].TargetActivity != ".Product" || app.Providers[0].Authorities != "org.example.shop.files" {
+		t.Errorf("alias = %+v, provider = %+v", app.Aliases[0], app.Providers[0])
+	}
+	if _, err := ParseManifest([]byte(`<manifest><application>`)); err == nil {
+		t.Error("truncated manifest parsed without error")
+	}
+}
+
+func TestParseStrings(t *testing.T) {
+	values, err := ParseStrings(strings.NewReader(shopStrings))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"app_name": "Shop & Go", "shop_host": "@string/web_host", "web_host": "shop.example.com"}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("ParseStrings = %q, want %q", values, want)
+	}
+
+	values, err = ParseStrings(strings.NewReader(`<resources><string name="a">x</string><string name="b">`))
+	if err == nil || values["a"] != "x" {
+		t.Errorf("ParseStrings of a damaged file = %q, %v; want the entries before the damage and an error", values, err)
+	}
+}
+
+func TestResolveManifest(t *testing.T) {
+	values, err := ParseStrings(strings.NewReader(shopStrings))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values["web_host"] = `shop.example.com"&<`
+	m, err := ParseManifest([]byte(shopManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ResolveManifest(&m, StringResources(values))
+	if host := m.Application.Activities[0].Filters[0].Data[0].Host; host != `shop.example.com"&<` {
+		t.Errorf("host = %q, want the value of @string/web_host verbatim", host)
+	}
+
+	unresolved, err := ParseManifest([]byte(shopManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ResolveManifest(&unresolved, StringResources(nil))
+	declared, _ := ParseManifest([]byte(shopManifest))
+	if !reflect.DeepEqual(unresolved, declared) {
+		t.Errorf("ResolveManifest without strings changed the manifest:\n%+v", unresolved)
+	}
+}
+
+func TestUnresolvedReferences(t *testing.T) {
+	for value, want := range map[string][]string{
+		"shop.example.com":         nil,
+		"@string/shop_host":        {"@string/shop_host"},
+		"${applicationId}.files":   {"${applicationId}"},
+		"@string/a/${suffix}":      {"@string/a", "${suffix}"},
+		"@xml/filepaths":           {"@xml/filepaths"},
+		"user@example.com/profile": nil,
+	} {
+		got := UnresolvedReferences(value)
+		if !slices.Equal(got, want) {
+			t.Errorf("UnresolvedReferences(%q) = %q, want %q", value, got, want)
+		}
+		if has := HasUnresolved(value); has != (len(want) > 0) {
+			t.Errorf("HasUnresolved(%q) = %t", value, has)
+		}
+	}
+}
+
+func TestExtractDeeplinks(t *testing.T) {
+	values, err := ParseStrings(strings.NewReader(shopStrings))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := ParseManifest([]byte(shopManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ResolveManifest(&m, StringResources(values))
+	view := []string{"android.intent.action.VIEW"}
+	want := []Deeplink{
+		{Kind: "activity", Component: ".Product", Actions: view, URI: "https://shop.example.com/p/", Raw: "https://shop.example.com/p/"},
+		{Kind: "activity", Component: ".Product", Actions: view, URI: "shop://product/{id}", Raw: "shop://product/{id}"},
+		{Kind: "alias", Component: ".Cart", Actions: view, URI: "shop://cart", Raw: "Shop://Cart"},
+	}
+	if got := ExtractDeeplinks(m); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractDeeplinks =\n%+v\nwant\n%+v", got, want)
+	}
+
+	unresolved, err := ParseManifest([]byte(shopManifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ExtractDeeplinks(unresolved); !reflect.DeepEqual(got, want[1:]) {
+		t.Errorf("ExtractDeeplinks before resolving strings =\n%+v\nwant the deeplinks without a reference only:\n%+v", got, want[1:])
+	}
+}
+
+func TestUnescapePlaceholders(t *testing.T) {
+	for uri, want := range map[string]string{
+		"shop://product/%7Bid%7D":  "shop://product/{id}",
+		"https://a.example/p/.%2A": "https://a.example/p/.*",
+		"https://a.example/a%20b":  "https://a.example/a%20b",
+	} {
+		if got := UnescapePlaceholders(uri); got != want {
+			t.Errorf("UnescapePlaceholders(%q) = %q, want %q", uri, got, want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package deeeeper
+
+import (
+	"strings" // Prefix matching and escapes
+)
+
+// ProviderPath is a <path-permission> or <grant-uri-permission> element: a path
+// of the provider given exactly, as a prefix or as a pattern.
+type ProviderPath struct {
+	Path            string `xml:"path,attr"`            // Exact path
+	PathPrefix      string `xml:"pathPrefix,attr"`      // Path prefix
+	PathPattern     string `xml:"pathPattern,attr"`     // Simple glob, see ExamplePath
+	Permission      string `xml:"permission,attr"`      // Read and write permission of the path (path-permission only)
+	ReadPermission  string `xml:"readPermission,attr"`  // Read permission of the path (path-permission only)
+	WritePermission string `xml:"writePermission,attr"` // Write permission of the path (path-permission only)
+}
+
+// Declared returns the path as written, marking prefixes with "*".
+func (p ProviderPath) Declared() string {
+	switch {
+	case p.Path != "":
+		return p.Path
+	case p.PathPrefix != "":
+		return p.PathPrefix + "*"
+	}
+	return p.PathPattern
+}
+
+// Example returns a concrete path matched by the element, usable in a URI.
+func (p ProviderPath) Example() string {
+	switch {
+	case p.Path != "":
+		return p.Path
+	case p.PathPrefix != "":
+		return p.PathPrefix
+	}
+	return ExamplePath(p.PathPattern)
+}
+
+// ExamplePath synthesizes a concrete path matching an Android simple glob
+// (PatternMatcher.PATTERN_SIMPLE_GLOB): "." matches any character, "*" repeats
+// the preceding one zero or more times and "\" escapes the next character.
+// ".*" becomes "example", a lone "." becomes "x" and "c*" keeps one "c".
+// apktool writes escapes doubled ("\\."), so those count as one.
+func ExamplePath(pattern string) string {
+	pattern = strings.ReplaceAll(pattern, `\\`, `\`)
+	var path strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		repeated := i+1 < len(pattern) && pattern[i+1] == '*'
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			path.WriteByte(pattern[i])
+			continue
+		case c == '.' && repeated:
+			path.WriteString("example")
+		case c == '.':
+			path.WriteByte('x')
+		case c == '*':
+			continue // A leading or doubled "*" repeats nothing
+		default:
+			path.WriteByte(c)
+		}
+		if repeated {
+			i++
+		}
+	}
+	return path.String()
+}
+
+// Matches reports whether the element covers path.
+func (p ProviderPath) Matches(path string) bool {
+	switch {
+	case p.Path != "":
+		return path == p.Path
+	case p.PathPrefix != "":
+		return strings.HasPrefix(path, p.PathPrefix)
+	case p.PathPattern != "":
+		return matchSimpleGlob(strings.ReplaceAll(p.PathPattern, `\\`, `\`), path)
+	}
+	return false
+}
+
+// matchSimpleGlob matches path against an Android simple glob, see ExamplePath.
+func matchSimpleGlob(pattern, path string) bool {
+	if pattern == "" {
+		return path == ""
+	}
+	c, literal, rest := pattern[0], false, pattern[1:]
+	if c == '\\' && rest != "" {
+		c, literal, rest = rest[0], true, rest[1:]
+	}
+	matchOne := func(b byte) bool { return b == c || c == '.' && !literal }
+	if rest != "" && rest[0] == '*' {
+		for i := 0; ; i++ { // Try every repetition count of c
+			if matchSimpleGlob(rest[1:], path[i:]) {
+				return true
+			}
+			if i == len(path) || !matchOne(path[i]) {
+				return false
+			}
+		}
+	}
+	return path != "" && matchOne(path[0]) && matchSimpleGlob(rest, path[1:])
+}
@@ -0,0 +1,92 @@
+package deeeeper
+
+import (
+	"reflect" // Walking the parsed manifest
+	"strings" // Reference prefixes
+)
+
+// maxReferenceDepth bounds how many resource-to-resource references are followed.
+const maxReferenceDepth = 8
+
+// Resources holds the simple resource values attribute references resolve to,
+// keyed by type and name, such as "string/app_name" or "bool/exported".
+type Resources struct {
+	App       map[string]string // The app's own values
+	Framework map[string]string // Values of the android package, for @android: and @*android: references
+}
+
+// StringResources returns the Resources of a strings.xml name-value map, as
+// read by ParseStrings.
+func StringResources(stringMap map[string]string) Resources {
+	values := make(map[string]string, len(stringMap))
+	for name, value := range stringMap {
+		values["string/"+name] = value
+	}
+	return Resources{App: values}
+}
+
+// Resolve returns what an attribute value stands for: a whole-value reference
+// such as @string/host, @bool/exported or @integer/priority becomes the
+// resource's value, following references between resources. Framework
+// references (@android:, @*android:) are looked up in Framework. Anything
+// else, unknown references included, is returned unchanged.
+func (r Resources) Resolve(value string) string {
+	for range maxReferenceDepth {
+		name, ok := strings.CutPrefix(value, "@")
+		if !ok {
+			return value
+		}
+		table := r.App
+		if local, framework := cutFrameworkPrefix(name); framework {
+			table, name = r.Framework, local
+		}
+		resolved, found := table[name]
+		if !found {
+			return value
+		}
+		value = resolved
+	}
+	return value
+}
+
+// cutFrameworkPrefix strips the android: package, public or private (*android:),
+// from a reference without its "@".
+func cutFrameworkPrefix(reference string) (string, bool) {
+	return strings.CutPrefix(strings.TrimPrefix(reference, "*"), "android:")
+}
+
+// ResolveManifest resolves the resource references of every attribute of a
+// parsed manifest in place: modeled fields as well as the raw attribute maps.
+// Resolving after parsing keeps values out of the XML, so they need no
+// escaping and the manifest's offsets stay those of the file on disk.
+func ResolveManifest(m *Manifest, r Resources) {
+	resolveFields(reflect.ValueOf(m).Elem(), r)
+}
+
+// resolveFields walks strings, structs, slices and string maps, resolving every string.
+func resolveFields(v reflect.Value, r Resources) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(r.Resolve(v.String()))
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				resolveFields(v.Field(i), r)
+			}
+		}
+	case reflect.Slice:
+		for i := range v.Len() {
+			resolveFields(v.Index(i), r)
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return
+		}
+		for _, key := range v.MapKeys() {
+			resolved := r.Resolve(v.MapIndex(key).String())
+			v.SetMapIndex(key, reflect.ValueOf(resolved).Convert(v.Type().Elem()))
+		}
+	}
+}
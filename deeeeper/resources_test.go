@@ -0,0 +1,47 @@
+package deeeeper
+
+import (
+	"strings" // Cycle check
+	"testing" // Test harness
+)
+
+func TestResolve(t *testing.T) {
+	r := Resources{
+		App: map[string]string{
+			"string/a":     "@string/b",
+			"string/b":     "value",
+			"string/loop1": "@string/loop2",
+			"string/loop2": "@string/loop1",
+			"string/host":  "@android:string/config_host",
+			"bool/yes":     "true",
+		},
+		Framework: map[string]string{
+			"bool/config_voice_capable": "false",
+			"string/config_host":        "updates.oem.example",
+		},
+	}
+	for _, tc := range []struct {
+		value, want string
+	}{
+		{"@string/a", "value"},
+		{"@bool/yes", "true"},
+		{"@string/unknown", "@string/unknown"},
+		{"plain", "plain"},
+		{"prefix @string/b", "prefix @string/b"}, // Only whole-value references
+		{"${applicationId}", "${applicationId}"},
+		{"@android:bool/config_voice_capable", "false"},
+		{"@*android:bool/config_voice_capable", "false"},
+		{"@string/host", "updates.oem.example"}, // App value pointing into the framework
+		{"@android:string/a", "@android:string/a"},
+	} {
+		if got := r.Resolve(tc.value); got != tc.want {
+			t.Errorf("Resolve(%q) = %q, want %q", tc.value, got, tc.want)
+		}
+	}
+	if got := r.Resolve("@string/loop1"); !strings.HasPrefix(got, "@string/loop") {
+		t.Errorf("reference cycle resolved to %q", got)
+	}
+	if got := StringResources(map[string]string{"a": "x"}).Resolve("@string/a"); got != "x" {
+		t.Errorf("StringResources: @string/a = %q, want x", got)
+	}
+}
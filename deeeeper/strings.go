@@ -0,0 +1,96 @@
+package deeeeper
+
+import (
+	"encoding/xml" // XML parsing support
+	"io"           // End of stream
+	"regexp"       // Leftover references
+	"strings"      // Text of nested markup
+)
+
+// unresolvedToken matches what resolution can leave in an attribute: string
+// and xml resource references and ${...} placeholders, which only the Gradle
+// manifest merger substitutes.
+var unresolvedToken = regexp.MustCompile(`@string/[\w.]+|@xml/[\w.]+|\$\{[^}]*\}`)
+
+// StringResource defines the structure for parsing strings.xml files.
+type StringResource struct {
+	XMLName xml.Name `xml:"resources"` // The root XML element
+	Strings []String `xml:"string"`    // Slice of String elements within the file
+}
+
+// String holds data for a single string element within strings.xml.
+type String struct {
+	Name string `xml:"name,attr"` // String's name attribute
+	Text string `xml:"-"`         // Text content with nested markup stripped, see UnmarshalXML
+}
+
+// UnmarshalXML reads a <string> (or <bool>, <integer>) element, concatenating
+// the character data of nested markup such as <xliff:g> or <b> and of CDATA
+// sections in document order. Values wrapped entirely in xliff:g would
+// otherwise come out empty.
+func (s *String) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "name" && attr.Name.Space == "" {
+			s.Name = attr.Value
+		}
+	}
+	var text strings.Builder
+	for depth := 1; depth > 0; {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			text.Write(t)
+		}
+	}
+	s.Text = text.String()
+	return nil
+}
+
+// ParseStrings reads a strings.xml file into a name-value map, see DecodeValues.
+func ParseStrings(r io.Reader) (map[string]string, error) {
+	return DecodeValues(xml.NewDecoder(r), "string")
+}
+
+// DecodeValues streams the <element name="..."> entries of a res/values file,
+// such as <string> or <bool>, into a name-value map, one element at a time.
+// The map is always usable: on a decoding error it holds the entries read
+// before the damage.
+func DecodeValues(d *xml.Decoder, element string) (map[string]string, error) {
+	values := make(map[string]string)
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return values, nil
+		}
+		if err != nil {
+			return values, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != element {
+			continue
+		}
+		var s String
+		if err := d.DecodeElement(&s, &start); err != nil {
+			return values, err
+		}
+		values[s.Name] = s.Text
+	}
+}
+
+// HasUnresolved reports whether value holds a string or xml resource reference
+// or a ${...} placeholder.
+func HasUnresolved(value string) bool {
+	return unresolvedToken.MatchString(value)
+}
+
+// UnresolvedReferences lists the references and placeholders value holds, in order.
+func UnresolvedReferences(value string) []string {
+	return unresolvedToken.FindAllString(value, -1)
+}
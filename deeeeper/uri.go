@@ -0,0 +1,167 @@
+package deeeeper
+
+import (
+	"net/url" // URI construction
+	"strings" // Path handling
+)
+
+// SchemeRule tells DeeplinkURI how URIs of one scheme are shaped.
+type SchemeRule struct {
+	Style       string `json:"style"`        // "authority" (scheme://host/path) or "opaque" (scheme:path)
+	DefaultHost string `json:"default_host"` // Host used when a filter declares none
+	DefaultPath string `json:"default_path"` // Path (or opaque part) used when a filter declares none
+}
+
+// SchemeRules holds the URI construction hints, keyed by lower-case scheme.
+// The built-in entries cover well-known opaque schemes; callers may add
+// to and override them before constructing URIs.
+var SchemeRules = map[string]SchemeRule{
+	"mailto":  {Style: "opaque"},
+	"tel":     {Style: "opaque"},
+	"sms":     {Style: "opaque"},
+	"smsto":   {Style: "opaque"},
+	"mms":     {Style: "opaque"},
+	"mmsto":   {Style: "opaque"},
+	"geo":     {Style: "opaque"},
+	"sip":     {Style: "opaque"},
+	"package": {Style: "opaque"},
+}
+
+// uriEscapes undoes the percent-encoding of "*", which is legal in paths and
+// keeps pathPattern wildcards readable.
+var uriEscapes = strings.NewReplacer("%2A", "*")
+
+// placeholderEscapes keeps {placeholder} braces visible as well.
+var placeholderEscapes = strings.NewReplacer("%2A", "*", "%7B", "{", "%7D", "}")
+
+// DeeplinkURI builds a percent-encoded URI string from a <data> element,
+// shaped by the scheme's entry in SchemeRules when there is one. The scheme
+// and host are normalized, internationalized hosts written in their ASCII
+// form, so spellings differing only in case yield one URI. {placeholder}
+// braces stay readable unless encodePlaceholders is set. RawURI keeps the
+// declared spelling. The URI is empty for elements that describe none.
+func DeeplinkURI(data Data, encodePlaceholders bool) string {
+	u, ok := DeeplinkURL(data)
+	if !ok {
+		return ""
+	}
+	if u.Host == "" && u.Path == "" && !u.OmitHost {
+		return u.Scheme + "://"
+	}
+	u.Scheme = NormalizeScheme(u.Scheme)
+	u.Host, _ = HostToASCII(u.Host)
+	if encodePlaceholders {
+		return uriEscapes.Replace(u.String())
+	}
+	return UnescapePlaceholders(u.String())
+}
+
+// UnescapePlaceholders undoes the escapes url.URL adds to "*" and to
+// {placeholder} braces, the form DeeplinkURI returns them in. Comparing a
+// parsed and re-rendered URI with it ignores those escapes.
+func UnescapePlaceholders(uri string) string {
+	return placeholderEscapes.Replace(uri)
+}
+
+// RawURI renders the same URI as DeeplinkURI from the unencoded manifest values.
+func RawURI(data Data) string {
+	u, ok := DeeplinkURL(data)
+	if !ok {
+		return ""
+	}
+	switch {
+	case u.OmitHost:
+		return u.Scheme + ":" + u.Path
+	case u.Scheme == "": // Network-path reference, as url.URL renders it
+		return "//" + u.Host + u.Path
+	}
+	return u.Scheme + "://" + u.Host + u.Path
+}
+
+// DeeplinkURL assembles the URL a <data> element describes, holding unencoded
+// manifest values. Opaque URIs such as mailto: have OmitHost set. ok is false
+// for elements that describe no URI.
+func DeeplinkURL(data Data) (url.URL, bool) {
+	if !data.IsSchemeData() || data.Unresolved() { // A placeholder URI would only mislead
+		return url.URL{}, false
+	}
+	if ssp := data.SchemeSpecificPart(); ssp != "" { // Matched against everything after "scheme:", e.g. package:com.example.*
+		if data.Scheme == "" {
+			return url.URL{}, false // Android ignores ssp attributes without a scheme
+		}
+		return url.URL{Scheme: data.Scheme, Path: ssp, OmitHost: true}, true
+	}
+	rule := SchemeRules[NormalizeScheme(data.Scheme)]
+	// Construct the path correctly, considering all attributes (path, pathPrefix, pathPattern)
+	var path string
+	if data.Path != "" {
+		path = data.Path
+	} else if data.PathPrefix != "" {
+		path = data.PathPrefix
+	} else if data.PathPattern != "" {
+		path = data.PathPattern
+	}
+
+	if path == "" {
+		path = rule.DefaultPath
+	}
+
+	if rule.Style == "opaque" { // e.g. mailto:user@example.com, no authority
+		return url.URL{Scheme: data.Scheme, Path: strings.TrimPrefix(path, "/"), OmitHost: true}, true
+	}
+
+	// Ensure the path starts with a "/"
+	if path != "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	host := data.Host
+	if host == "" {
+		host = rule.DefaultHost
+	}
+	if host != "" && data.Port != "" { // Android ignores a port without a host
+		host += ":" + data.Port
+	}
+	return url.URL{Scheme: data.Scheme, Host: host, Path: path}, true
+}
+
+// Deeplink is a URI an activity or activity-alias declares in an intent filter.
+type Deeplink struct {
+	Kind      string   `json:"type"`              // activity or alias
+	Component string   `json:"component"`         // Component name as declared
+	Actions   []string `json:"actions,omitempty"` // Actions of the declaring filter
+	URI       string   `json:"uri"`               // Percent-encoded URI, see DeeplinkURI
+	Raw       string   `json:"raw"`               // The same URI as declared, see RawURI
+}
+
+// ExtractDeeplinks lists the deeplinks of every activity and activity-alias in
+// manifest order, each URI once per component. Whether a component is
+// reachable from other apps depends on its exported state, target SDK and
+// permissions, which is left to the caller.
+func ExtractDeeplinks(m Manifest) []Deeplink {
+	var deeplinks []Deeplink
+	groups := []struct {
+		kind       string
+		components []App
+	}{{"activity", m.Application.Activities}, {"alias", m.Application.Aliases}}
+	for _, group := range groups {
+		for _, component := range group.components {
+			seen := make(map[string]bool)
+			for _, filter := range component.Filters {
+				var actions []string
+				for _, action := range filter.Actions {
+					actions = append(actions, action.Name)
+				}
+				for _, data := range filter.Data {
+					uri := DeeplinkURI(data, false)
+					if uri == "" || seen[uri] {
+						continue
+					}
+					seen[uri] = true
+					deeplinks = append(deeplinks, Deeplink{Kind: group.kind, Component: component.Name, Actions: actions, URI: uri, Raw: RawURI(data)})
+				}
+			}
+		}
+	}
+	return deeplinks
+}
@@ -0,0 +1,75 @@
+package deeeeper
+
+import "testing"
+
+func TestDeeplinkURIEncoding(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		data    Data
+		display string // encodePlaceholders false
+		strict  string // encodePlaceholders true
+		raw     string
+	}{
+		{
+			name:    "space",
+			data:    Data{Scheme: "https", Host: "example.com", Path: "/my docs/read me"},
+			display: "https://example.com/my%20docs/read%20me",
+			strict:  "https://example.com/my%20docs/read%20me",
+			raw:     "https://example.com/my docs/read me",
+		},
+		{
+			name:    "unicode segments",
+			data:    Data{Scheme: "https", Host: "example.com", PathPrefix: "/café/über"},
+			display: "https://example.com/caf%C3%A9/%C3%BCber",
+			strict:  "https://example.com/caf%C3%A9/%C3%BCber",
+			raw:     "https://example.com/café/über",
+		},
+		{
+			name:    "placeholder braces",
+			data:    Data{Scheme: "myapp", Host: "item", Path: "/{id}/details"},
+			display: "myapp://item/{id}/details",
+			strict:  "myapp://item/%7Bid%7D/details",
+			raw:     "myapp://item/{id}/details",
+		},
+		{
+			name:    "pattern wildcard",
+			data:    Data{Scheme: "https", Host: "example.com", PathPattern: "/p/.*"},
+			display: "https://example.com/p/.*",
+			strict:  "https://example.com/p/.*",
+			raw:     "https://example.com/p/.*",
+		},
+		{
+			name:    "reserved characters",
+			data:    Data{Scheme: "https", Host: "example.com", Path: "/a?b#c%"},
+			display: "https://example.com/a%3Fb%23c%25",
+			strict:  "https://example.com/a%3Fb%23c%25",
+			raw:     "https://example.com/a?b#c%",
+		},
+		{
+			name:    "opaque with space",
+			data:    Data{Scheme: "mailto", Path: "support team@example.com"},
+			display: "mailto:support%20team@example.com",
+			strict:  "mailto:support%20team@example.com",
+			raw:     "mailto:support team@example.com",
+		},
+		{
+			name:    "missing leading slash",
+			data:    Data{Scheme: "myapp", Host: "open", Path: "deals now"},
+			display: "myapp://open/deals%20now",
+			strict:  "myapp://open/deals%20now",
+			raw:     "myapp://open/deals now",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DeeplinkURI(tc.data, false); got != tc.display {
+				t.Errorf("DeeplinkURI = %q, want %q", got, tc.display)
+			}
+			if got := DeeplinkURI(tc.data, true); got != tc.strict {
+				t.Errorf("DeeplinkURI strict = %q, want %q", got, tc.strict)
+			}
+			if got := RawURI(tc.data); got != tc.raw {
+				t.Errorf("RawURI = %q, want %q", got, tc.raw)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"   // Captured JSON report
+	"strings" // Output checks
+	"testing" // Test harness
+)
+
+// sspManifest has a package-removal watcher matching an sspPrefix, and an
+// activity taking mailto: with an exact ssp and sms: with an sspPattern.
+const sspManifest = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="org.example.watcher">
+    <application>
+        <receiver android:name=".PackageWatcher" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.PACKAGE_REMOVED"/>
+                <data android:scheme="package" android:sspPrefix="com.example."/>
+            </intent-filter>
+        </receiver>
+        <activity android:name=".Compose" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.SENDTO"/>
+                <category android:name="android.intent.category.DEFAULT"/>
+                <data android:scheme="mailto" android:ssp="support@example.com"/>
+                <data android:scheme="sms" android:sspPattern="555.*"/>
+                <data android:sspPrefix="ignored"/>
+            </intent-filter>
+        </activity>
+    </application>
+</manifest>
+`
+
+// reportJSON renders reports as -format json.
+func reportJSON(t *testing.T, reports ...*report) string {
+	t.Helper()
+	saved := opts.Format
+	opts.Format = "json"
+	defer func() { opts.Format = saved }()
+	var out bytes.Buffer
+	if err := writeReports(&out, reports); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+// TestSchemeSpecificPartFilters checks ssp filters render as scheme:ssp rather
+// than as empty or host-based URIs, and structured output keeps the
+// attributes verbatim.
+func TestSchemeSpecificPartFilters(t *testing.T) {
+	setOpts(t, textOptions())
+	result, text := renderFixture(t, sspManifest, "<resources/>")
+	for _, want := range []string{
+		".PackageWatcher (exported=true)\n  android.intent.action.PACKAGE_REMOVED (protected broadcast)\n  package:com.example.*\n",
+		"  mailto:support@example.com\n",
+		"  sms:555.*\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report lacks %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "ignored") || strings.Contains(text, "package://") {
+		t.Errorf("report renders an ssp without a scheme or as a hierarchical URI:\n%s", text)
+	}
+
+	out := reportJSON(t, result)
+	for _, want := range []string{`"ssp_prefix": "com.example."`, `"ssp": "support@example.com"`, `"ssp_pattern": "555.*"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("JSON lacks %s", want)
+		}
+	}
+}
+
+func TestSchemeSpecificPart(t *testing.T) {
+	for _, tc := range []struct {
+		data Data
+		want string
+	}{
+		{Data{Scheme: "package", Ssp: "com.example.app"}, "com.example.app"},
+		{Data{Scheme: "package", SspPrefix: "com.example."}, "com.example.*"},
+		{Data{Scheme: "package", SspPattern: "com\\..*"}, "com\\..*"},
+		{Data{Scheme: "package", Ssp: "exact", SspPrefix: "prefix"}, "exact"},
+		{Data{Scheme: "https", Host: "example.com"}, ""},
+	} {
+		if got := tc.data.SchemeSpecificPart(); got != tc.want {
+			t.Errorf("%+v: SchemeSpecificPart() = %q, want %q", tc.data, got, tc.want)
+		}
+		if !tc.data.IsSchemeData() {
+			t.Errorf("%+v: IsSchemeData() = false", tc.data)
+		}
+	}
+	if !(Data{SspPrefix: "x"}).IsSchemeData() {
+		t.Errorf("an ssp attribute alone should count as scheme data")
+	}
+}
+
+func TestIsExported(t *testing.T) {
+	filters := []IntentFilter{{Actions: []Action{{Name: "android.intent.action.VIEW"}}}}
+	for _, tc := range []struct {
+		name         string
+		exported     string
+		filters      []IntentFilter
+		kind         string
+		targetSDK    int
+		wantExported bool
+		wantImplicit bool
+	}{
+		{name: "explicit true", exported: "true", kind: "activity", wantExported: true},
+		{name: "explicit true with filters", exported: "true", filters: filters, kind: "activity", wantExported: true},
+		{name: "explicit false with filters", exported: "false", filters: filters, kind: "activity"},
+		{name: "explicit false", exported: "false", kind: "activity"},
+		{name: "explicit TRUE", exported: "TRUE", kind: "activity", wantExported: true},
+		{name: "explicit 1", exported: "1", kind: "service", wantExported: true},
+		{name: "garbage value", exported: "yes", filters: filters, kind: "activity"},
+		{name: "unresolved reference", exported: "@bool/exported", filters: filters, kind: "activity"},
+		{name: "absent with filters", filters: filters, kind: "activity", wantExported: true, wantImplicit: true},
+		{name: "absent without filters", kind: "activity", wantImplicit: true},
+		{name: "absent with filters, receiver", filters: filters, kind: "receiver", wantExported: true, wantImplicit: true},
+		{name: "absent with filters, target SDK 30", filters: filters, kind: "activity", targetSDK: 30, wantExported: true, wantImplicit: true},
+		{name: "absent with filters, target SDK 31", filters: filters, kind: "activity", targetSDK: 31},
+		{name: "absent with filters, target SDK 34", filters: filters, kind: "service", targetSDK: 34},
+		{name: "explicit true, target SDK 34", exported: "true", kind: "activity", targetSDK: 34, wantExported: true},
+		{name: "absent provider", kind: "provider", wantImplicit: true},
+		{name: "absent provider, target SDK 16", kind: "provider", targetSDK: 16, wantExported: true, wantImplicit: true},
+		{name: "absent provider, target SDK 17", kind: "provider", targetSDK: 17, wantImplicit: true},
+		{name: "absent provider with filters", filters: filters, kind: "provider", wantImplicit: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			setOpts(t, options{TargetSDK: tc.targetSDK})
+			exported, implicit := isExported(App{Exported: tc.exported, Filters: tc.filters}, tc.kind)
+			if exported != tc.wantExported || implicit != tc.wantImplicit {
+				t.Errorf("isExported = %v, %v; want %v, %v", exported, implicit, tc.wantExported, tc.wantImplicit)
+			}
+		})
+	}
+}
+
+// TestImplicitExportReported checks an activity with a filter but no
+// android:exported is reported as exported, with the evidence saying so.
+func TestImplicitExportReported(t *testing.T) {
+	setOpts(t, textOptions())
+	manifest := strings.Replace(sspManifest, `<activity android:name=".Compose" android:exported="true">`, `<activity android:name=".Compose">`, 1)
+	result, text := renderFixture(t, manifest, "<resources/>")
+	if !strings.Contains(text, ".Compose (exported=true, implicit)\n") {
+		t.Errorf("implicitly exported .Compose missing from the report:\n%s", text)
+	}
+	for _, f := range result.Findings {
+		if f.Component == ".Compose" && strings.Contains(f.Evidence, "android:exported absent; implicitly exported with 1 intent filter(s)") {
+			return
+		}
+	}
+	t.Errorf("no finding for .Compose explains the implicit export: %+v", result.Findings)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json" // Generic Findings serialization
+	"fmt"           // Description formatting
+	"io"            // Output destination
+	"strings"       // Description assembly
+	"time"          // Finding date
+)
+
+// defectDojoSeverities maps Deeeeper severities onto DefectDojo's scale.
+var defectDojoSeverities = map[string]string{
+	"critical": "Critical",
+	"high":     "High",
+	"medium":   "Medium",
+	"low":      "Low",
+	"info":     "Info",
+}
+
+// defectDojoReport is the DefectDojo "Generic Findings Import" JSON document.
+type defectDojoReport struct {
+	Findings []defectDojoFinding `json:"findings"`
+}
+
+// defectDojoFinding is one entry of a Generic Findings Import document.
+type defectDojoFinding struct {
+	Title            string `json:"title"`
+	Date             string `json:"date"`
+	Severity         string `json:"severity"`
+	Description      string `json:"description"`
+	Mitigation       string `json:"mitigation"`
+	CWE              int    `json:"cwe,omitempty"`
+	FilePath         string `json:"file_path"`
+	ComponentName    string `json:"component_name"`
+	StaticFinding    bool   `json:"static_finding"`
+	DynamicFinding   bool   `json:"dynamic_finding"`
+	UniqueIDFromTool string `json:"unique_id_from_tool"`
+	VulnIDFromTool   string `json:"vuln_id_from_tool"`
+}
+
+// writeDefectDojo writes every finding as DefectDojo Generic Findings JSON.
+// unique_id_from_tool carries the finding fingerprint so re-imports deduplicate.
+func writeDefectDojo(w io.Writer, reports []*report) error {
+	document := defectDojoReport{Findings: []defectDojoFinding{}}
+	date := time.Now().Format("2006-01-02")
+	for _, r := range reports {
+		for _, f := range r.Findings {
+			meta := rules[f.Rule]
+			document.Findings = append(document.Findings, defectDojoFinding{
+				Title:            f.Title,
+				Date:             date,
+				Severity:         defectDojoSeverities[f.Severity],
+				Description:      defectDojoDescription(r, f, meta),
+				Mitigation:       meta.Mitigation,
+				CWE:              meta.CWE,
+				FilePath:         "AndroidManifest.xml",
+				ComponentName:    r.Package,
+				StaticFinding:    true,
+				DynamicFinding:   false,
+				UniqueIDFromTool: f.Fingerprint,
+				VulnIDFromTool:   f.Rule,
+			})
+		}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+// defectDojoDescription assembles the Markdown description shown in DefectDojo.
+func defectDojoDescription(r *report, f finding, meta rule) string {
+	var b strings.Builder
+	b.WriteString(meta.Description + "\n\n")
+	fmt.Fprintf(&b, "**Component:** %s (%s)\n\n", f.Component, f.Kind)
+	if r.Package != "" {
+		fmt.Fprintf(&b, "**Package:** %s\n\n", r.Package)
+	}
+	fmt.Fprintf(&b, "**Target:** %s\n\n", r.Target)
+	if len(f.URIs) > 0 {
+		b.WriteString("**URIs:**\n\n")
+		for _, uri := range f.URIs {
+			fmt.Fprintf(&b, "- `%s`\n", uri)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "**Evidence:** `%s`", f.Evidence)
+	if f.Snippet != "" {
+		fence := codeFence(f.Snippet)
+		fmt.Fprintf(&b, "\n\n%s\n%s\n%s", fence, f.Snippet, fence)
+	}
+	return b.String()
+}
+
+// codeFence returns a Markdown fence longer than any backtick run in text, so
+// the text can't close its own code block.
+func codeFence(text string) string {
+	longest, run := 0, 0
+	for _, r := range text {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
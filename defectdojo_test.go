@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"         // Rendered document
+	"encoding/json" // Re-importing the document
+	"flag"          // -update
+	"os"            // Golden file
+	"path/filepath" // Golden file path
+	"regexp"        // Date normalization
+	"testing"       // Test harness
+)
+
+// update rewrites golden files from the current output.
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// defectDojoDate matches the import date, which changes daily.
+var defectDojoDate = regexp.MustCompile(`"date": "\d{4}-\d{2}-\d{2}"`)
+
+// TestDefectDojoRoundTrip renders two findings as Generic Findings JSON,
+// compares the document with testdata/defectdojo.json and imports it back the
+// way DefectDojo reads it, checking each field maps to the original finding.
+func TestDefectDojoRoundTrip(t *testing.T) {
+	pkg := "org.example.app"
+	deeplink := newFinding(pkg, "deeplink-handler", "activity", ".OpenActivity", []string{"https://app.example.com/open", "myapp://open"}, `android:exported="true"`)
+	deeplink.Snippet = "<data android:scheme=\"myapp\"/> <!-- ``` -->"
+	provider := newFinding(pkg, "exported-provider", "provider", ".DataProvider", nil, `android:exported="true"`)
+	r := &report{Target: "app.apk", Package: pkg, Findings: []finding{deeplink, provider}}
+
+	var out bytes.Buffer
+	if err := writeDefectDojo(&out, []*report{r}); err != nil {
+		t.Fatal(err)
+	}
+	rendered := defectDojoDate.ReplaceAll(out.Bytes(), []byte(`"date": "2026-01-01"`))
+	golden := filepath.Join("testdata", "defectdojo.json")
+	if *update {
+		if err := os.WriteFile(golden, rendered, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(rendered, want) {
+		t.Errorf("document differs from %s (go test -run DefectDojo -update rewrites it):\n%s", golden, rendered)
+	}
+
+	var imported struct {
+		Findings []map[string]any `json:"findings"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &imported); err != nil {
+		t.Fatalf("document is not valid JSON: %v", err)
+	}
+	if len(imported.Findings) != len(r.Findings) {
+		t.Fatalf("%d findings imported, want %d", len(imported.Findings), len(r.Findings))
+	}
+	dojoSeverities := map[string]bool{"Critical": true, "High": true, "Medium": true, "Low": true, "Info": true}
+	for i, f := range r.Findings {
+		got := imported.Findings[i]
+		for _, field := range []string{"title", "date", "severity", "description"} { // Required by the importer
+			if s, _ := got[field].(string); s == "" {
+				t.Errorf("finding %d: required field %s is empty", i, field)
+			}
+		}
+		if got["unique_id_from_tool"] != f.Fingerprint {
+			t.Errorf("finding %d: unique_id_from_tool = %v, want the fingerprint %s", i, got["unique_id_from_tool"], f.Fingerprint)
+		}
+		if severity, _ := got["severity"].(string); !dojoSeverities[severity] || severity != defectDojoSeverities[f.Severity] {
+			t.Errorf("finding %d: severity %q, want DefectDojo's %q for %s", i, severity, defectDojoSeverities[f.Severity], f.Severity)
+		}
+		if got["vuln_id_from_tool"] != f.Rule || got["title"] != f.Title {
+			t.Errorf("finding %d: rule or title lost: %v", i, got)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json" // Reading the baseline report
+	"fmt"           // Change lines
+	"io"            // Output destination
+	"os"            // Baseline file
+	"strconv"       // maxSdkVersion values
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// diffEntry is one difference between the baseline and the current build.
+type diffEntry struct {
+	Change   string `json:"change"`           // added, removed or changed
+	Type     string `json:"type"`             // Component type, uses-permission, permission or application
+	Name     string `json:"name"`             // Component, permission or flag name
+	Old      string `json:"old,omitempty"`    // Baseline value of a changed item
+	New      string `json:"new,omitempty"`    // Current value of a changed item
+	Severity string `json:"severity"`         // warning when the change widens what the app exposes, else info
+	Reason   string `json:"reason,omitempty"` // Why a change is a warning
+}
+
+// reportDiff compares a report with the baseline report of the same package.
+// Every array is present, empty when nothing changed.
+type reportDiff struct {
+	Baseline    string      `json:"baseline"`             // Target of the baseline report
+	Components  []diffEntry `json:"components"`           // Exported components added or removed
+	Permissions []diffEntry `json:"permission_requests"`  // <uses-permission> entries
+	Declared    []diffEntry `json:"declared_permissions"` // Custom <permission> declarations and their protection levels
+	Flags       []diffEntry `json:"application_flags"`    // debuggable, allowBackup and usesCleartextTraffic
+}
+
+// loadBaseline reads the reports of a -format json document, keyed by package.
+// When a package appears several times the first report wins.
+func loadBaseline(path string) (map[string]*report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var document jsonDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("%s is no -format json report: %w", path, err)
+	}
+	baseline := make(map[string]*report)
+	for _, r := range document.Reports {
+		if r == nil {
+			continue
+		}
+		if _, ok := baseline[r.Package]; !ok {
+			baseline[r.Package] = r
+		}
+	}
+	return baseline, nil
+}
+
+// diffReports lists what changed from old to current: added and changed items
+// in the current build's order, then removed ones in the baseline's order.
+func diffReports(old, current *report) *reportDiff {
+	return &reportDiff{
+		Baseline:    old.Target,
+		Components:  diffComponents(old.Components, current.Components),
+		Permissions: diffPermissionRequests(old.Permissions, current.Permissions),
+		Declared:    diffDeclaredPermissions(old.Declared, current.Declared),
+		Flags:       diffApplicationFlags(old.Flags, current.Flags),
+	}
+}
+
+// diffComponents compares the exported components by type and name. A
+// component that became exported counts as added.
+func diffComponents(old, current []componentInfo) []diffEntry {
+	exported := func(components []componentInfo) map[string]bool {
+		names := make(map[string]bool)
+		for _, c := range components {
+			if c.Exported {
+				names[c.Type+" "+c.Name] = true
+			}
+		}
+		return names
+	}
+	before, after := exported(old), exported(current)
+	entries := []diffEntry{}
+	for _, c := range current {
+		if c.Exported && !before[c.Type+" "+c.Name] {
+			entries = append(entries, diffEntry{Change: "added", Type: c.Type, Name: c.Name, Severity: "warning", Reason: "new exported component"})
+		}
+	}
+	for _, c := range old {
+		if c.Exported && !after[c.Type+" "+c.Name] {
+			entries = append(entries, diffEntry{Change: "removed", Type: c.Type, Name: c.Name, Severity: "info"})
+		}
+	}
+	return entries
+}
+
+// diffPermissionRequests compares the <uses-permission> entries. A new
+// dangerous permission is a warning, as is lifting the maxSdkVersion of one.
+func diffPermissionRequests(old, current []permissionRequest) []diffEntry {
+	before := make(map[string]permissionRequest)
+	for _, request := range old {
+		before[request.Name] = request
+	}
+	limit := func(request permissionRequest) string {
+		if request.MaxSDKVersion == 0 {
+			return "no maxSdkVersion"
+		}
+		return "maxSdkVersion " + strconv.Itoa(request.MaxSDKVersion)
+	}
+	entries := []diffEntry{}
+	seen := make(map[string]bool)
+	for _, request := range current {
+		seen[request.Name] = true
+		previous, ok := before[request.Name]
+		switch {
+		case !ok && request.Dangerous:
+			entries = append(entries, diffEntry{Change: "added", Type: "uses-permission", Name: request.Name, Severity: "warning", Reason: "new dangerous permission"})
+		case !ok:
+			entries = append(entries, diffEntry{Change: "added", Type: "uses-permission", Name: request.Name, Severity: "info"})
+		case previous.MaxSDKVersion != request.MaxSDKVersion:
+			entry := diffEntry{Change: "changed", Type: "uses-permission", Name: request.Name, Old: limit(previous), New: limit(request), Severity: "info"}
+			lifted := previous.MaxSDKVersion != 0 && (request.MaxSDKVersion == 0 || request.MaxSDKVersion > previous.MaxSDKVersion)
+			if lifted && request.Dangerous {
+				entry.Severity, entry.Reason = "warning", "dangerous permission requested on more SDK levels"
+			}
+			entries = append(entries, entry)
+		}
+	}
+	for _, request := range old {
+		if !seen[request.Name] {
+			entries = append(entries, diffEntry{Change: "removed", Type: "uses-permission", Name: request.Name, Severity: "info"})
+		}
+	}
+	return entries
+}
+
+// diffDeclaredPermissions compares the custom permissions and their protection
+// levels. Declaring one any app can obtain, or lowering one to such a level,
+// is a warning.
+func diffDeclaredPermissions(old, current []customPermission) []diffEntry {
+	before := make(map[string]customPermission)
+	for _, p := range old {
+		before[p.Name] = p
+	}
+	level := func(p customPermission) string {
+		if p.Raw == "" {
+			return p.ProtectionLevel
+		}
+		return p.Raw
+	}
+	entries := []diffEntry{}
+	seen := make(map[string]bool)
+	for _, p := range current {
+		seen[p.Name] = true
+		previous, ok := before[p.Name]
+		switch {
+		case !ok:
+			entry := diffEntry{Change: "added", Type: "permission", Name: p.Name, New: level(p), Severity: "info"}
+			if weakProtection(p.ProtectionLevel) {
+				entry.Severity, entry.Reason = "warning", "any app can obtain it"
+			}
+			entries = append(entries, entry)
+		case level(previous) != level(p):
+			entry := diffEntry{Change: "changed", Type: "permission", Name: p.Name, Old: level(previous), New: level(p), Severity: "info"}
+			if weakProtection(p.ProtectionLevel) && !weakProtection(previous.ProtectionLevel) {
+				entry.Severity, entry.Reason = "warning", "any app can now obtain it"
+			}
+			entries = append(entries, entry)
+		}
+	}
+	for _, p := range old {
+		if !seen[p.Name] {
+			entries = append(entries, diffEntry{Change: "removed", Type: "permission", Name: p.Name, Old: level(p), Severity: "info"})
+		}
+	}
+	return entries
+}
+
+// diffApplicationFlags compares the <application> flags as declared. A flag
+// whose effective value turns on is a warning; allowBackup is on when absent.
+func diffApplicationFlags(old, current applicationFlags) []diffEntry {
+	entries := []diffEntry{}
+	for _, flag := range []struct {
+		name          string
+		before, after string
+		defaultOn     bool
+	}{
+		{"debuggable", old.Debuggable, current.Debuggable, false},
+		{"allowBackup", old.AllowBackup, current.AllowBackup, true},
+		{"usesCleartextTraffic", old.Cleartext, current.Cleartext, false},
+	} {
+		if flag.before == flag.after {
+			continue
+		}
+		on := func(value string) bool { return isTrue(value) || value == "" && flag.defaultOn }
+		entry := diffEntry{Change: "changed", Type: "application", Name: flag.name, Old: flagValue(flag.before), New: flagValue(flag.after), Severity: "info"}
+		if on(flag.after) && !on(flag.before) {
+			entry.Severity, entry.Reason = "warning", flag.name+" is now on"
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// flagValue spells an absent attribute for the change lines.
+func flagValue(value string) string {
+	if value == "" {
+		return "unset"
+	}
+	return value
+}
+
+// printDiff lists the component changes and the permission changes against the
+// baseline in their own sections, warnings in red.
+func printDiff(w io.Writer, pkg string, diff *reportDiff) {
+	printSection(w, fmt.Sprintf("\nComponent changes of %s since %s:", pkg, diff.Baseline))
+	if len(diff.Components) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	printDiffEntries(w, diff.Components)
+	printSection(w, fmt.Sprintf("\nPermission changes of %s since %s:", pkg, diff.Baseline))
+	if len(diff.Permissions)+len(diff.Declared)+len(diff.Flags) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	printDiffEntries(w, diff.Permissions)
+	printDiffEntries(w, diff.Declared)
+	printDiffEntries(w, diff.Flags)
+}
+
+// printDiffEntries prints one line per change, naming the kind of item: the
+// component type, uses-permission, permission or application.
+func printDiffEntries(w io.Writer, entries []diffEntry) {
+	red := color.New(color.FgRed).SprintFunc()
+	for _, e := range entries {
+		line := fmt.Sprintf("%-8s %s %s", e.Change, e.Type, e.Name)
+		switch {
+		case e.Old != "" && e.New != "":
+			line += fmt.Sprintf(": %s -> %s", e.Old, e.New)
+		case e.New != "":
+			line += " (" + e.New + ")"
+		case e.Old != "":
+			line += " (" + e.Old + ")"
+		}
+		if e.Severity == "warning" {
+			fmt.Fprintf(w, "  %s\n", red(line+" [warning: "+e.Reason+"]"))
+		} else {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"         // Captured output
+	"encoding/json" // Reading the JSON report back
+	"os"            // Baseline file
+	"path/filepath" // Baseline path
+	"reflect"       // Result comparison
+	"strings"       // Output checks
+	"testing"       // Test harness
+)
+
+// debugBuildManifest is debugManifest with an exported debug menu.
+var debugBuildManifest = strings.Replace(debugManifest, `android:usesCleartextTraffic="true"/>`, `android:usesCleartextTraffic="true">
+        <activity android:name=".DebugMenu" android:exported="true"/>
+    </application>`, 1)
+
+// TestDiffReports compares the release build of org.example.shop with its
+// debug build and checks every change is listed with its severity.
+func TestDiffReports(t *testing.T) {
+	setOpts(t, textOptions())
+	release := analyzeFixture(t, releaseManifest, "<resources/>")
+	debug := analyzeFixture(t, debugBuildManifest, "<resources/>")
+	diff := diffReports(release, debug)
+
+	want := &reportDiff{
+		Baseline: release.Target,
+		Components: []diffEntry{
+			{Change: "added", Type: "activity", Name: ".DebugMenu", Severity: "warning", Reason: "new exported component"},
+		},
+		Permissions: []diffEntry{
+			{Change: "added", Type: "uses-permission", Name: "android.permission.CAMERA", Severity: "warning", Reason: "new dangerous permission"},
+		},
+		Declared: []diffEntry{
+			{Change: "changed", Type: "permission", Name: "org.example.shop.ORDERS", Old: "signature|privileged", New: "normal", Severity: "warning", Reason: "any app can now obtain it"},
+			{Change: "added", Type: "permission", Name: "org.example.shop.DEBUG", New: "0x12", Severity: "info"},
+		},
+		Flags: []diffEntry{
+			{Change: "changed", Type: "application", Name: "debuggable", Old: "unset", New: "true", Severity: "warning", Reason: "debuggable is now on"},
+			{Change: "changed", Type: "application", Name: "allowBackup", Old: "false", New: "unset", Severity: "warning", Reason: "allowBackup is now on"},
+			{Change: "changed", Type: "application", Name: "usesCleartextTraffic", Old: "unset", New: "true", Severity: "warning", Reason: "usesCleartextTraffic is now on"},
+		},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Errorf("diff =\n%+v\nwant\n%+v", diff, want)
+	}
+
+	back := diffReports(debug, release)
+	for _, entries := range [][]diffEntry{back.Components, back.Permissions, back.Declared, back.Flags} {
+		for _, e := range entries {
+			if e.Severity != "info" {
+				t.Errorf("going back to the release build: %+v is a %s", e, e.Severity)
+			}
+		}
+	}
+	if len(back.Components) != 1 || back.Components[0].Change != "removed" || len(back.Permissions) != 1 || back.Permissions[0].Change != "removed" {
+		t.Errorf("going back to the release build: components %+v, permissions %+v; want both removed", back.Components, back.Permissions)
+	}
+	if same := diffReports(release, release); len(same.Components)+len(same.Permissions)+len(same.Declared)+len(same.Flags) != 0 {
+		t.Errorf("a build differs from itself: %+v", same)
+	}
+}
+
+func TestDiffPermissionLimits(t *testing.T) {
+	old := []permissionRequest{
+		{Name: "android.permission.CAMERA", MaxSDKVersion: 28, Dangerous: true},
+		{Name: "android.permission.READ_CONTACTS", Dangerous: true},
+		{Name: "android.permission.INTERNET", MaxSDKVersion: 28},
+	}
+	current := []permissionRequest{
+		{Name: "android.permission.CAMERA", Dangerous: true},
+		{Name: "android.permission.READ_CONTACTS", MaxSDKVersion: 30, Dangerous: true},
+		{Name: "android.permission.INTERNET"},
+	}
+	want := []diffEntry{
+		{Change: "changed", Type: "uses-permission", Name: "android.permission.CAMERA", Old: "maxSdkVersion 28", New: "no maxSdkVersion", Severity: "warning", Reason: "dangerous permission requested on more SDK levels"},
+		{Change: "changed", Type: "uses-permission", Name: "android.permission.READ_CONTACTS", Old: "no maxSdkVersion", New: "maxSdkVersion 30", Severity: "info"},
+		{Change: "changed", Type: "uses-permission", Name: "android.permission.INTERNET", Old: "maxSdkVersion 28", New: "no maxSdkVersion", Severity: "info"},
+	}
+	if got := diffPermissionRequests(old, current); !reflect.DeepEqual(got, want) {
+		t.Errorf("diff =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+// TestDiffRun runs -diff against a saved JSON report of the release build and
+// checks both the text sections and the arrays of the JSON diff document.
+func TestDiffRun(t *testing.T) {
+	setOpts(t, textOptions())
+	var saved bytes.Buffer
+	if err := writeJSON(&saved, []*report{analyzeFixture(t, releaseManifest, "<resources/>")}); err != nil {
+		t.Fatal(err)
+	}
+	baselinePath := filepath.Join(t.TempDir(), "release.json")
+	if err := os.WriteFile(baselinePath, saved.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	baseline, err := loadBaseline(baselinePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, ok := baseline["org.example.shop"]
+	if !ok {
+		t.Fatalf("baseline lacks org.example.shop: %v", baseline)
+	}
+	current := analyzeFixture(t, debugBuildManifest, "<resources/>")
+	current.Diff = diffReports(old, current)
+
+	var text bytes.Buffer
+	printDiff(&text, current.Package, current.Diff)
+	for _, want := range []string{
+		"Component changes of org.example.shop since " + old.Target + ":\n  added    activity .DebugMenu [warning: new exported component]\n",
+		"Permission changes of org.example.shop since " + old.Target + ":\n",
+		"  added    uses-permission android.permission.CAMERA [warning: new dangerous permission]\n",
+		"  changed  permission org.example.shop.ORDERS: signature|privileged -> normal [warning: any app can now obtain it]\n",
+		"  added    permission org.example.shop.DEBUG (0x12)\n",
+		"  changed  application debuggable: unset -> true [warning: debuggable is now on]\n",
+	} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("diff output lacks %q:\n%s", want, text.String())
+		}
+	}
+
+	var document struct {
+		Reports []struct {
+			Diff map[string]json.RawMessage `json:"diff"`
+		} `json:"reports"`
+	}
+	rendered := reportJSON(t, current)
+	if err := json.Unmarshal([]byte(rendered), &document); err != nil {
+		t.Fatalf("%v:\n%s", err, rendered)
+	}
+	if len(document.Reports) != 1 {
+		t.Fatalf("reports = %d, want 1:\n%s", len(document.Reports), rendered)
+	}
+	for field, count := range map[string]int{"components": 1, "permission_requests": 1, "declared_permissions": 2, "application_flags": 3} {
+		var entries []diffEntry
+		if err := json.Unmarshal(document.Reports[0].Diff[field], &entries); err != nil || len(entries) != count {
+			t.Errorf("diff.%s = %s, want %d entries", field, document.Reports[0].Diff[field], count)
+		}
+	}
+
+	if _, err := loadBaseline(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("loading a missing baseline succeeded")
+	}
+}
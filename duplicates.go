@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"     // Conflict descriptions
+	"reflect" // Field-wise merging
+)
+
+// mergeDuplicates folds components declared more than once under the same
+// fully qualified name within a kind, as manifest merging sometimes leaves
+// behind. Following the manifest merger, the first declaration (the app's own
+// manifest comes before library manifests) wins attribute conflicts, attributes
+// only the later one sets are added, and intent filters, meta-data and provider
+// paths are combined. It returns a description of every conflict.
+func mergeDuplicates(manifest *Manifest) []string {
+	var conflicts []string
+	lists := []struct {
+		kind       string
+		components *[]App
+	}{
+		{"activity", &manifest.Application.Activities},
+		{"alias", &manifest.Application.Aliases},
+		{"service", &manifest.Application.Services},
+		{"receiver", &manifest.Application.Receivers},
+		{"provider", &manifest.Application.Providers},
+	}
+	for _, list := range lists {
+		index := make(map[string]int)
+		var merged []App
+		for _, component := range *list.components {
+			name := qualifiedName(manifest.Package, component.Name)
+			i, seen := index[name]
+			if !seen {
+				index[name] = len(merged)
+				component.Declarations = 1
+				merged = append(merged, component)
+				continue
+			}
+			for _, conflict := range mergeComponent(&merged[i], component) {
+				conflicts = append(conflicts, fmt.Sprintf("%s %s declared more than once: %s", list.kind, name, conflict))
+			}
+		}
+		*list.components = merged
+	}
+	return conflicts
+}
+
+// mergeComponent merges a later declaration into the first one and describes
+// the attributes on which they disagree.
+func mergeComponent(first *App, later App) []string {
+	var conflicts []string
+	for _, key := range sortedKeys(later.Attributes) {
+		value := later.Attributes[key]
+		if key == "android:name" {
+			continue // Equal once qualified
+		}
+		if existing, ok := first.Attributes[key]; ok && existing != value {
+			conflicts = append(conflicts, fmt.Sprintf("%s=%q and %q, keeping %q", key, existing, value, existing))
+		} else if !ok {
+			if first.Attributes == nil {
+				first.Attributes = make(map[string]string)
+			}
+			first.Attributes[key] = value
+		}
+	}
+	// Modeled attributes follow the same rule: fill in only what the first left unset
+	dst, src := reflect.ValueOf(first).Elem(), reflect.ValueOf(later)
+	for i := 0; i < dst.NumField(); i++ {
+		if dst.Field(i).Kind() == reflect.String && dst.Field(i).String() == "" {
+			dst.Field(i).SetString(src.Field(i).String())
+		}
+	}
+	first.Filters = append(first.Filters, later.Filters...)
+	first.MetaData = append(first.MetaData, later.MetaData...)
+	first.PathPermissions = append(first.PathPermissions, later.PathPermissions...)
+	first.GrantURIPaths = append(first.GrantURIPaths, later.GrantURIPaths...)
+	first.Declarations++
+	return conflicts
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"   // Captured warnings
+	"slices"  // Result comparison
+	"strings" // Output checks
+	"testing" // Test harness
+)
+
+// duplicateManifest declares .Open twice, as manifest merging can leave
+// behind: the app's declaration exports it with an https filter, the library's
+// keeps it private with a custom scheme filter and sets a permission.
+const duplicateManifest = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="org.example.dup">
+    <application>
+        <activity android:name=".Open" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="https" android:host="dup.example.com"/>
+            </intent-filter>
+        </activity>
+        <activity android:name=".Other" android:exported="false"/>
+        <activity android:name="org.example.dup.Open" android:exported="false" android:permission="org.example.dup.OPEN">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="dup" android:host="open"/>
+            </intent-filter>
+        </activity>
+    </application>
+</manifest>
+`
+
+func TestMergeDuplicates(t *testing.T) {
+	manifest := parseTestManifest(t, duplicateManifest)
+	conflicts := mergeDuplicates(&manifest)
+	want := []string{`activity org.example.dup.Open declared more than once: android:exported="true" and "false", keeping "true"`}
+	if !slices.Equal(conflicts, want) {
+		t.Errorf("conflicts = %q, want %q", conflicts, want)
+	}
+	activities := manifest.Application.Activities
+	if len(activities) != 2 || activities[0].Name != ".Open" || activities[1].Name != ".Other" {
+		t.Fatalf("activities = %+v, want .Open merged in place and .Other", activities)
+	}
+	open := activities[0]
+	if open.Declarations != 2 || open.Exported != "true" || open.Permission != "org.example.dup.OPEN" || open.Attributes["android:permission"] != "org.example.dup.OPEN" {
+		t.Errorf(".Open = %+v, want the first exported value and the later permission", open)
+	}
+	var schemes []string
+	for _, filter := range open.Filters {
+		for _, data := range filter.Data {
+			schemes = append(schemes, data.Scheme)
+		}
+	}
+	if !slices.Equal(schemes, []string{"https", "dup"}) {
+		t.Errorf("merged filter schemes = %q, want both declarations' filters in order", schemes)
+	}
+	if activities[1].Declarations != 1 {
+		t.Errorf(".Other declarations = %d, want 1", activities[1].Declarations)
+	}
+}
+
+// TestDuplicatesReport checks the conflict is warned about and the merged
+// component is annotated with its declaration count.
+func TestDuplicatesReport(t *testing.T) {
+	setOpts(t, textOptions())
+	dir := t.TempDir()
+	if err := writeSelftestTarget(dir, duplicateManifest, "<resources/>"); err != nil {
+		t.Fatal(err)
+	}
+	var text, progress bytes.Buffer
+	if _, err := analyzeFolder(&text, &progress, dir); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(progress.String(), `Warning: activity org.example.dup.Open declared more than once: android:exported="true" and "false", keeping "true"`) {
+		t.Errorf("warnings lack the conflict:\n%s", progress.String())
+	}
+	if !strings.Contains(text.String(), ".Open (exported=true, declared 2×, permission=org.example.dup.OPEN)\n  android.intent.action.VIEW\n  https://dup.example.com\n  android.intent.action.VIEW\n  dup://open\n") {
+		t.Errorf("report lacks the merged .Open:\n%s", text.String())
+	}
+}
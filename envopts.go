@@ -0,0 +1,139 @@
+package main
+
+import (
+	"flag"    // Visiting the parsed flags
+	"fmt"     // Tokenizer errors and output
+	"io"      // Output destination
+	"os"      // Environment and arguments
+	"sort"    // Stable listing
+	"strings" // Tokenizing
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// envOptsVar holds default arguments parsed before the real command line.
+const envOptsVar = "DEEEEPER_OPTS"
+
+// splitArgs splits s into arguments the way a POSIX shell would, honoring
+// single quotes, double quotes and backslash escapes. Nothing is expanded.
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			if quote == '"' && !strings.ContainsRune("\"\\$`", r) {
+				current.WriteRune('\\') // Inside double quotes only these are escapable
+			}
+			current.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// envArgs returns the arguments from DEEEEPER_OPTS. They must all be flags,
+// since positional arguments would end flag parsing of the real command line.
+func envArgs() ([]string, error) {
+	args, err := splitArgs(os.Getenv(envOptsVar))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", envOptsVar, err)
+	}
+	for _, arg := range args {
+		if arg == "--" {
+			return nil, fmt.Errorf("%s: \"--\" is not allowed", envOptsVar)
+		}
+	}
+	return args, nil
+}
+
+// commandLineFlags returns the names of the flags given on the real command
+// line, used to tell where an effective value came from.
+func commandLineFlags(args []string) map[string]bool {
+	names := make(map[string]bool)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || !strings.HasPrefix(arg, "-") || arg == "-" {
+			break // Flag parsing stops here too
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		names[name] = true
+		if f := flag.Lookup(name); f != nil && !hasValue && !isBoolFlag(f) {
+			i++ // Skip the separate value
+		}
+	}
+	return names
+}
+
+// isBoolFlag reports whether a flag takes no separate value.
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// printEffectiveOptions lists every flag that was set, with the place it was
+// set, so DEEEEPER_OPTS precedence can be debugged.
+// Credential-bearing values are redacted.
+func printEffectiveOptions(w io.Writer, fromEnv, fromArgs map[string]bool) {
+	values := make(map[string]string)
+	flag.Visit(func(f *flag.Flag) {
+		values[f.Name] = f.Value.String()
+		if secretFlags[f.Name] {
+			values[f.Name] = redactURL(f.Value.String())
+		}
+	})
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	color.New(color.FgYellow).Fprintln(w, "Effective options:")
+	for _, name := range names {
+		source := envOptsVar
+		if fromArgs[name] {
+			source = "command line"
+			if fromEnv[name] {
+				source += ", overriding " + envOptsVar
+			}
+		}
+		fmt.Fprintf(w, "  -%s=%s (%s)\n", name, values[name], source)
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"         // Spec file lines
+	"bytes"         // Format sniffing
+	"encoding/json" // JSON specs
+	"fmt"           // Output formatting
+	"io"            // Output destination
+	"os"            // Spec file access
+	"regexp"        // Wildcard matching
+	"strings"       // YAML subset parsing
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// expectedLink is one entry of a -expect spec: a URI, optionally with "*"
+// wildcards ("https://*.example.com/product/*") or a path prefix, and the
+// component that should handle it.
+type expectedLink struct {
+	URI        string `json:"uri"`        // Expected URI or pattern
+	PathPrefix string `json:"pathPrefix"` // Path the URI must start with, appended to URI's path
+	Component  string `json:"component"`  // Expected handler, empty for any
+}
+
+// matches reports whether a discovered URI and handler satisfy the entry. The
+// wildcards follow the manifest: a host "*.example.com" covers every subdomain
+// but not example.com itself, a host "*" any host, and pathPrefix any path
+// starting with it. Elsewhere "*" spans any characters, slashes included.
+// Components may be given in short form, matching whole name segments only.
+func (e expectedLink) matches(uri, component string) bool {
+	if e.Component != "" && component != e.Component && !strings.HasSuffix(component, "."+strings.TrimPrefix(e.Component, ".")) {
+		return false
+	}
+	if e.PathPrefix == "" && !strings.Contains(e.URI, "*") {
+		return e.URI == uri
+	}
+	return e.pattern().MatchString(uri)
+}
+
+// pattern compiles the entry into a regular expression over whole URIs.
+func (e expectedLink) pattern() *regexp.Regexp {
+	prefix, rest := "", e.URI
+	if scheme, authority, ok := strings.Cut(e.URI, "://"); ok {
+		host, path := authority, ""
+		if i := strings.IndexAny(authority, "/?#"); i >= 0 {
+			host, path = authority[:i], authority[i:]
+		}
+		switch {
+		case host == "*":
+			prefix, rest = globPattern(scheme)+"://[^/?#]+", path
+		case strings.HasPrefix(host, "*."):
+			prefix, rest = globPattern(scheme)+"://[^/?#]+"+regexp.QuoteMeta(host[1:]), path
+		}
+	}
+	expression := "^" + prefix + globPattern(rest)
+	if e.PathPrefix != "" {
+		expression += regexp.QuoteMeta(e.PathPrefix) + ".*"
+	}
+	return regexp.MustCompile(expression + "$")
+}
+
+// globPattern quotes text for a regular expression, turning "*" into ".*".
+func globPattern(text string) string {
+	parts := strings.Split(text, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return strings.Join(parts, ".*")
+}
+
+// expectResult sorts the deeplinks of one report into the three buckets.
+type expectResult struct {
+	Matched    []string // Discovered and expected
+	Unexpected []string // Discovered but not in the spec
+	Missing    []string // In the spec but not discovered
+}
+
+// loadExpectations reads a spec file: a JSON array of strings or {uri,
+// pathPrefix, component} objects, or the same as a YAML list ("- https://..."
+// or "- uri: ..." with indented "pathPrefix: ..." and "component: ..." lines).
+func loadExpectations(file string) ([]expectedLink, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("[")) {
+		var raw []json.RawMessage
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, err
+		}
+		links := make([]expectedLink, len(raw))
+		for i, entry := range raw {
+			if err := json.Unmarshal(entry, &links[i].URI); err != nil {
+				if err := json.Unmarshal(entry, &links[i]); err != nil {
+					return nil, fmt.Errorf("entry %d: %w", i+1, err)
+				}
+			}
+		}
+		return links, nil
+	}
+	return parseExpectYAML(data)
+}
+
+// parseExpectYAML parses the YAML list subset accepted by -expect.
+func parseExpectYAML(data []byte) ([]expectedLink, error) {
+	var links []expectedLink
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		item, isItem := strings.CutPrefix(text, "- ")
+		if isItem {
+			links = append(links, expectedLink{})
+		} else if len(links) == 0 {
+			return nil, fmt.Errorf("line %d: expected a list item", line)
+		}
+		current := &links[len(links)-1]
+		key, value, isField := strings.Cut(item, ": ")
+		switch {
+		case isField && key == "uri":
+			current.URI = unquoteYAML(value)
+		case isField && key == "pathPrefix":
+			current.PathPrefix = unquoteYAML(value)
+		case isField && key == "component":
+			current.Component = unquoteYAML(value)
+		case isItem && !isField:
+			current.URI = unquoteYAML(item)
+		default:
+			return nil, fmt.Errorf("line %d: unsupported entry %q", line, text)
+		}
+	}
+	return links, scanner.Err()
+}
+
+// unquoteYAML strips matching single or double quotes around a scalar.
+func unquoteYAML(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// checkExpectations compares the deeplinks of a report with the spec.
+func checkExpectations(r *report, spec []expectedLink) expectResult {
+	var result expectResult
+	used := make([]bool, len(spec))
+	seen := make(map[string]bool)
+	for _, c := range r.TestCases {
+		if c.URI == "" || isProviderCase(c) || seen[c.URI+" "+c.Component] {
+			continue
+		}
+		seen[c.URI+" "+c.Component] = true
+		matched := false
+		for i, entry := range spec {
+			if entry.matches(c.URI, c.Component) {
+				used[i], matched = true, true
+			}
+		}
+		entry := fmt.Sprintf("%s (%s)", c.URI, c.Component)
+		if matched {
+			result.Matched = append(result.Matched, entry)
+		} else {
+			result.Unexpected = append(result.Unexpected, entry)
+		}
+	}
+	for i, entry := range spec {
+		if !used[i] {
+			missing := entry.URI + entry.PathPrefix
+			if entry.PathPrefix != "" {
+				missing += "*"
+			}
+			if entry.Component != "" {
+				missing += " (" + entry.Component + ")"
+			}
+			result.Missing = append(result.Missing, missing)
+		}
+	}
+	return result
+}
+
+// runExpectations checks every report against the -expect spec, prints the
+// buckets and returns the number of reports that violate it. Missing and
+// unexpected links count as violations unless relaxed by -expect-allow-missing
+// or -expect-allow-unexpected.
+func runExpectations(w io.Writer, reports []*report) (int, error) {
+	spec, err := loadExpectations(opts.Expect)
+	if err != nil {
+		return 0, err
+	}
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	violations := 0
+	for _, r := range reports {
+		result := checkExpectations(r, spec)
+		color.New(color.FgYellow).Fprintf(w, "\nExpected deeplinks for %s: %d matched, %d unexpected, %d missing\n", r.Package, len(result.Matched), len(result.Unexpected), len(result.Missing))
+		for _, link := range result.Unexpected {
+			fmt.Fprintf(w, "  %s %s\n", red("unexpected"), link)
+		}
+		for _, link := range result.Missing {
+			fmt.Fprintf(w, "  %s %s\n", red("missing"), link)
+		}
+		if opts.Verbose {
+			for _, link := range result.Matched {
+				fmt.Fprintf(w, "  %s %s\n", green("matched"), link)
+			}
+		}
+		failMissing := len(result.Missing) > 0 && !opts.ExpectAllowMissing
+		failUnexpected := len(result.Unexpected) > 0 && !opts.ExpectAllowUnexpected
+		if failMissing || failUnexpected {
+			violations++
+		} else if len(result.Missing)+len(result.Unexpected) > 0 {
+			fmt.Fprintf(w, "  %s\n", yellow("differences tolerated by -expect-allow-* flags"))
+		}
+	}
+	return violations, nil
+}
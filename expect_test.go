@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"            // Spec files
+	"path/filepath" // Spec paths
+	"reflect"       // Result comparison
+	"testing"       // Test harness
+)
+
+func TestExpectedLinkMatches(t *testing.T) {
+	for _, tc := range []struct {
+		entry     expectedLink
+		uri       string
+		component string
+		want      bool
+	}{
+		{expectedLink{URI: "myapp://checkout"}, "myapp://checkout", "org.example.Checkout", true},
+		{expectedLink{URI: "myapp://checkout"}, "myapp://checkout/", "org.example.Checkout", false},
+		{expectedLink{URI: "https://app.example.com/product/*"}, "https://app.example.com/product/1/reviews", "", true},
+		{expectedLink{URI: "https://app.example.com/product/*"}, "https://app.example.com/cart", "", false},
+
+		// Wildcard hosts cover subdomains only, and never reach into the path
+		{expectedLink{URI: "https://*.example.com/*"}, "https://shop.example.com/p", "", true},
+		{expectedLink{URI: "https://*.example.com/*"}, "https://a.b.example.com/p", "", true},
+		{expectedLink{URI: "https://*.example.com/*"}, "https://example.com/p", "", false},
+		{expectedLink{URI: "https://*.example.com/*"}, "https://evil.test/x.example.com/p", "", false},
+		{expectedLink{URI: "https://*.example.com"}, "https://*.example.com", "", true},
+		{expectedLink{URI: "https://*/open"}, "https://any.host/open", "", true},
+		{expectedLink{URI: "https://*/open"}, "https://any.host/other/open", "", false},
+
+		// pathPrefix as the manifest attribute
+		{expectedLink{URI: "https://app.example.com", PathPrefix: "/account/"}, "https://app.example.com/account/", "", true},
+		{expectedLink{URI: "https://app.example.com", PathPrefix: "/account/"}, "https://app.example.com/account/settings", "", true},
+		{expectedLink{URI: "https://app.example.com", PathPrefix: "/account/"}, "https://app.example.com/accounts", "", false},
+		{expectedLink{URI: "https://*.example.com", PathPrefix: "/p."}, "https://m.example.com/p.html", "", true},
+		{expectedLink{URI: "https://*.example.com", PathPrefix: "/p."}, "https://m.example.com/pXhtml", "", false},
+
+		// Short component names end on a name boundary
+		{expectedLink{URI: "myapp://checkout", Component: ".Checkout"}, "myapp://checkout", "org.example.Checkout", true},
+		{expectedLink{URI: "myapp://checkout", Component: "Checkout"}, "myapp://checkout", "org.example.Checkout", true},
+		{expectedLink{URI: "myapp://checkout", Component: "example.Checkout"}, "myapp://checkout", "org.example.Checkout", true},
+		{expectedLink{URI: "myapp://checkout", Component: "org.example.Checkout"}, "myapp://checkout", "org.example.Checkout", true},
+		{expectedLink{URI: "myapp://checkout", Component: "Checkout"}, "myapp://checkout", "org.example.FakeCheckout", false},
+		{expectedLink{URI: "myapp://checkout", Component: ".Checkout"}, "myapp://checkout", "org.example.FakeCheckout", false},
+	} {
+		if got := tc.entry.matches(tc.uri, tc.component); got != tc.want {
+			t.Errorf("%+v matches(%q, %q) = %t, want %t", tc.entry, tc.uri, tc.component, got, tc.want)
+		}
+	}
+}
+
+func TestLoadExpectations(t *testing.T) {
+	want := []expectedLink{
+		{URI: "https://app.example.com/product/*"},
+		{URI: "myapp://checkout", Component: ".CheckoutActivity"},
+		{URI: "https://*.example.com", PathPrefix: "/account/"},
+	}
+	dir := t.TempDir()
+	for name, spec := range map[string]string{
+		"links.yaml": `# expected-links.yaml
+- https://app.example.com/product/*
+- uri: myapp://checkout
+  component: .CheckoutActivity
+- uri: "https://*.example.com"
+  pathPrefix: /account/
+`,
+		"links.json": `["https://app.example.com/product/*",
+ {"uri": "myapp://checkout", "component": ".CheckoutActivity"},
+ {"uri": "https://*.example.com", "pathPrefix": "/account/"}]`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(spec), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := loadExpectations(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %+v, want %+v", name, got, want)
+		}
+	}
+}
+
+func TestCheckExpectations(t *testing.T) {
+	view := "android.intent.action.VIEW"
+	r := &report{TestCases: []testCase{
+		{URI: "https://m.example.com/account/orders", Action: view, Component: "org.example.Account"},
+		{URI: "https://example.com/account/orders", Action: view, Component: "org.example.Account"},
+		{URI: "myapp://checkout", Action: view, Component: "org.example.FakeCheckoutActivity"},
+	}}
+	got := checkExpectations(r, []expectedLink{
+		{URI: "https://*.example.com", PathPrefix: "/account/"},
+		{URI: "myapp://checkout", Component: ".CheckoutActivity"},
+	})
+	want := expectResult{
+		Matched:    []string{"https://m.example.com/account/orders (org.example.Account)"},
+		Unexpected: []string{"https://example.com/account/orders (org.example.Account)", "myapp://checkout (org.example.FakeCheckoutActivity)"},
+		Missing:    []string{"myapp://checkout (.CheckoutActivity)"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("checkExpectations = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"crypto/sha256" // Finding fingerprints
+	"encoding/hex"  // Fingerprint encoding
+	"fmt"           // Title and evidence formatting
+	"sort"          // Deterministic fingerprints
+	"strings"       // Fingerprint input assembly
+)
+
+// severityOrder lists Deeeeper severities from most to least severe.
+var severityOrder = []string{"critical", "high", "medium", "low", "info"}
+
+// severityRank orders severities so they can be compared against thresholds.
+var severityRank = map[string]int{"info": 0, "low": 1, "medium": 2, "high": 3, "critical": 4}
+
+// rule describes a class of finding together with the advice attached to it.
+type rule struct {
+	Title       string // Title format; %s is the component name
+	Severity    string // Deeeeper severity: high, medium, low or info
+	CWE         int    // Closest CWE identifier
+	Description string // What the finding means
+	Mitigation  string // How developers fix it
+}
+
+// rules is the metadata for every finding Deeeeper can raise, keyed by rule ID.
+var rules = map[string]rule{
+	"exported-activity": {
+		Title:       "Exported activity %s",
+		Severity:    "low",
+		CWE:         926,
+		Description: "The activity can be started by any application on the device.",
+		Mitigation:  "Set android:exported=\"false\" unless other apps must start this activity; otherwise validate every extra it reads and consider protecting it with a signature permission.",
+	},
+	"deeplink-handler": {
+		Title:       "Deeplink handler %s",
+		Severity:    "medium",
+		CWE:         939,
+		Description: "The component is reachable through deeplink URIs from browsers and other applications, so every URI component and parameter is attacker controlled.",
+		Mitigation:  "Treat all deeplink data as untrusted input: validate hosts and paths against an allow-list, never load arbitrary URLs into WebViews and require user confirmation for sensitive actions.",
+	},
+	"exported-service": {
+		Title:       "Exported service %s",
+		Severity:    "medium",
+		CWE:         926,
+		Description: "Any application can start or bind to the service.",
+		Mitigation:  "Set android:exported=\"false\" or guard the service with a signature-level android:permission.",
+	},
+	"exported-receiver": {
+		Title:       "Exported broadcast receiver %s",
+		Severity:    "medium",
+		CWE:         925,
+		Description: "Any application can send broadcasts to the receiver, including spoofed versions of the actions it listens for.",
+		Mitigation:  "Set android:exported=\"false\", register the receiver at runtime with RECEIVER_NOT_EXPORTED or require a signature-level permission from senders.",
+	},
+	"injectable-receiver": {
+		Title:       "Exported broadcast receiver %s accepts spoofable broadcasts",
+		Severity:    "high",
+		CWE:         925,
+		Description: "The receiver listens for actions that are not system-protected broadcasts, so any application can inject them with arbitrary extras.",
+		Mitigation:  "Set android:exported=\"false\", require a signature-level android:permission from senders, or validate every extra before acting on it.",
+	},
+	"exported-provider": {
+		Title:       "Exported content provider %s",
+		Severity:    "high",
+		CWE:         926,
+		Description: "Any application can query and, unless restricted, modify the provider's data.",
+		Mitigation:  "Set android:exported=\"false\" and share individual URIs with android:grantUriPermissions, or protect the provider with signature-level read and write permissions.",
+	},
+	"direct-boot-aware": {
+		Title:       "Direct-boot-aware exported component %s",
+		Severity:    "info",
+		CWE:         926,
+		Description: "The component runs before the user first unlocks the device and can only use device-protected storage at that point.",
+		Mitigation:  "Confirm the component must run before unlock and that it never exposes credential-protected data.",
+	},
+	"shared-user-id": {
+		Title:       "Application %s uses android:sharedUserId",
+		Severity:    "info",
+		CWE:         250,
+		Description: "The app shares its Linux user ID, and therefore its sandbox, files and permissions, with every other app signed by the same key that declares the same sharedUserId. Any exported surface of one of those apps can reach the data of all of them. The attribute is deprecated.",
+		Mitigation:  "Remove android:sharedUserId where possible (use android:sharedUserMaxSdkVersion to migrate) and review the exported components of every app in the shared user group together.",
+	},
+	"share-target-webview": {
+		Title:       "Share target %s loads content into a WebView",
+		Severity:    "medium",
+		CWE:         749,
+		Description: "The exported share target receives text, streams and URIs from any application and its code loads content into a WebView (smali heuristic), a common path to loading attacker-controlled pages or local files.",
+		Mitigation:  "Never load shared text or URIs into a WebView directly; validate them against an allow-list and disable file and content access on the WebView.",
+	},
+	"uri-grant-forwarding": {
+		Title:       "Exported activity %s forwards URI grants from incoming intents",
+		Severity:    "medium",
+		CWE:         441,
+		Description: "Code of the exported activity reads a URI from the intent that started it and sends an intent on with FLAG_GRANT_READ_URI_PERMISSION or FLAG_GRANT_WRITE_URI_PERMISSION set (smali heuristic). A caller can pass a content:// URI of a provider it can't access, and the activity grants the recipient, possibly the caller itself, access to it: a confused deputy for the app's grantable providers.",
+		Mitigation:  "Only grant access to URIs the activity created itself or validated against an allow-list of authorities and paths, and never return a caller-supplied intent through setResult.",
+	},
+	"browsable-deeplink": {
+		Title:       "Browsable deeplink of %s",
+		Severity:    "low",
+		CWE:         939,
+		Description: "The URI is handled by a VIEW intent filter with the BROWSABLE category, so any web page can open it with a link and control every part of it.",
+		Mitigation:  "Validate the host, path and every query parameter before acting on them, and require user confirmation for state-changing actions reachable this way.",
+	},
+	"deeplink-collision": {
+		Title:       "Deeplink pattern of %s is claimed by other components too",
+		Severity:    "info",
+		CWE:         694,
+		Description: "Several exported activities of the app declare the same URI pattern for the same action, so Android shows a chooser instead of opening one of them. This is often a copy-paste leftover that keeps an old or debug entry point reachable.",
+		Mitigation:  "Keep the pattern on the one activity meant to handle it and remove or narrow the other filters; use an activity-alias when two entry points should share behavior.",
+	},
+	"instant-app-constraint": {
+		Title:       "%s breaks an instant app constraint",
+		Severity:    "low",
+		CWE:         710,
+		Description: "The app opts into the instant app sandbox (dist:instant or android:targetSandboxVersion=\"2\") but declares something the sandbox doesn't allow, such as a custom scheme on an instant entry point. That configuration is dead at best and gets the bundle rejected at worst.",
+		Mitigation:  "Follow the named constraint: use verified https App Links for instant entry points and move unsupported components to an installed-only module.",
+	},
+	"undeclared-permission": {
+		Title:       "Component %s references an undeclared permission",
+		Severity:    "high",
+		CWE:         276,
+		Description: "The permission guarding the component is declared neither by the app nor by the platform, so protection may be ineffective: any app can declare the same name with protectionLevel normal (squatting it) and then request it.",
+		Mitigation:  "Fix the permission name or declare it in the manifest with android:protectionLevel=\"signature\".",
+	},
+	"path-permission-only": {
+		Title:       "Content provider %s is protected only by path-permissions",
+		Severity:    "high",
+		CWE:         284,
+		Description: "The exported provider declares <path-permission> elements but no provider-level permission, so every path not covered by them is readable and writable by any app.",
+		Mitigation:  "Add a provider-level android:permission (or readPermission and writePermission) with signature protection and use path-permissions only to relax or tighten individual paths.",
+	},
+	"weak-permission": {
+		Title:       "Component %s is guarded only by a normal or dangerous permission",
+		Severity:    "medium",
+		CWE:         732,
+		Description: "The custom permission guarding the exported component has protectionLevel normal or dangerous, so any app can request it and reach the component. It looks protected but isn't.",
+		Mitigation:  "Declare the permission with android:protectionLevel=\"signature\" (or signature|privileged) so only apps signed with the same key can hold it.",
+	},
+	"single-user-provider": {
+		Title:       "Single-user content provider %s",
+		Severity:    "info",
+		CWE:         926,
+		Description: "One provider instance is shared across all users on the device, so data may cross user (and work profile) boundaries.",
+		Mitigation:  "Confirm cross-user sharing is intended and enforce per-user access checks inside the provider.",
+	},
+}
+
+// exportRules maps component kinds to the rule raised when they are exported.
+var exportRules = map[string]string{
+	"activity": "exported-activity",
+	"alias":    "exported-activity",
+	"service":  "exported-service",
+	"receiver": "exported-receiver",
+	"provider": "exported-provider",
+}
+
+// finding is a single reportable issue about one component.
+type finding struct {
+	Rule        string   `json:"rule"`              // Rule ID, see rules
+	Severity    string   `json:"severity"`          // Deeeeper severity
+	Title       string   `json:"title"`             // Human-readable title
+	Kind        string   `json:"type"`              // Component kind (activity, alias, service, receiver, provider)
+	Component   string   `json:"component"`         // Fully qualified component name
+	URIs        []string `json:"uris,omitempty"`    // Deeplink URIs relevant to the finding
+	Evidence    string   `json:"evidence"`          // Manifest facts the finding is based on
+	Snippet     string   `json:"snippet,omitempty"` // Manifest or smali excerpt behind the finding, see attachSnippets
+	Fingerprint string   `json:"id"`                // Stable identifier used to deduplicate findings across runs, see fingerprint
+}
+
+// collectFindings derives findings from every exported component in the manifest.
+func collectFindings(manifest Manifest, folder string) []finding {
+	var findings []finding
+	if manifest.SharedUserID != "" {
+		evidence := fmt.Sprintf("android:sharedUserId=%q", manifest.SharedUserID)
+		if manifest.SharedUserLabel != "" {
+			evidence += fmt.Sprintf(" android:sharedUserLabel=%q", manifest.SharedUserLabel)
+		}
+		findings = append(findings, newFinding(manifest.Package, "shared-user-id", "application", manifest.Package, nil, evidence))
+	}
+	findings = append(findings, undeclaredPermissionFindings(manifest)...)
+	findings = append(findings, pathPermissionFindings(manifest)...)
+	for _, group := range componentGroups(manifest) {
+		for _, component := range group.Components {
+			exported, implicit := isExported(component, group.Kind)
+			if !exported {
+				continue
+			}
+			evidence := exportedEvidence(component, implicit)
+			uris := componentURIs(folder, component)
+
+			ruleID := exportRules[group.Kind]
+			if len(uris) > 0 && (group.Kind == "activity" || group.Kind == "alias") {
+				ruleID = "deeplink-handler"
+			}
+			spoofable := spoofableActions(component)
+			if group.Kind == "receiver" && len(spoofable) > 0 {
+				ruleID = "injectable-receiver"
+				evidence += "; spoofable actions: " + strings.Join(spoofable, ", ")
+			}
+			name := component.Name
+			if target, ok := resolvedAlias(component, group.Kind); ok {
+				name = target
+				evidence += fmt.Sprintf("; declared by activity-alias %s", component.Name)
+			}
+			exportFinding := newFinding(manifest.Package, ruleID, group.Kind, name, uris, evidence)
+			switch {
+			case group.Kind == "service":
+				weighActions(&exportFinding, component)
+			case group.Kind == "receiver" && len(component.Filters) > 0 && len(spoofable) == 0:
+				exportFinding.Severity = "info" // Only the system can send what it listens for
+				exportFinding.Evidence += "; protected broadcasts only"
+			}
+			weighReachability(&exportFinding, component)
+			findings = append(findings, exportFinding)
+
+			if group.Kind != "provider" && componentHasAction(component, "SEND") && classLoadsWebView(folder, qualifiedName(manifest.Package, name)) {
+				findings = append(findings, newFinding(manifest.Package, "share-target-webview", group.Kind, name, nil, "ACTION_SEND filter; WebView load call in smali"))
+			}
+			if isTrue(component.DirectBootAware) {
+				findings = append(findings, newFinding(manifest.Package, "direct-boot-aware", group.Kind, name, nil, `android:directBootAware="true"`))
+			}
+			if isTrue(component.SingleUser) {
+				findings = append(findings, newFinding(manifest.Package, "single-user-provider", group.Kind, name, nil, `android:singleUser="true"`))
+			}
+		}
+	}
+	return findings
+}
+
+// weighActions adjusts the severity of an exported service by what it listens
+// for: app-defined actions are protocols any app can speak, while services
+// handling only framework actions mostly react to system events.
+func weighActions(f *finding, component App) {
+	custom := customActions(component)
+	switch {
+	case len(custom) > 0:
+		f.Severity = "high"
+		f.Evidence += "; custom actions: " + strings.Join(custom, ", ")
+	case len(component.Filters) > 0:
+		f.Severity = "low"
+		f.Evidence += "; framework actions only"
+	}
+}
+
+// newFinding fills in a finding from its rule metadata.
+func newFinding(pkg, ruleID, kind, component string, uris []string, evidence string) finding {
+	meta := rules[ruleID]
+	return finding{
+		Rule:        ruleID,
+		Severity:    meta.Severity,
+		Title:       fmt.Sprintf(meta.Title, component),
+		Kind:        kind,
+		Component:   component,
+		URIs:        uris,
+		Evidence:    evidence,
+		Fingerprint: fingerprint(pkg, ruleID, kind, component),
+	}
+}
+
+// fingerprintVersion prefixes the fingerprint input, so a change to the rules
+// below yields new IDs instead of colliding with old ones.
+const fingerprintVersion = "deeeeper-finding-v2"
+
+// fingerprint derives the stable ID of a finding, the same for every scan of
+// the same app with the same rules, as the first 16 bytes of a SHA-256, in hex,
+// over these fields joined by NUL bytes:
+//
+//   - fingerprintVersion
+//   - the package name
+//   - the rule ID and the component kind
+//   - the component name qualified against the package (".Main" and
+//     "com.example.Main" are the same component)
+//   - the keys telling several findings of one rule on one component apart,
+//     such as the permission attribute, sorted
+//
+// URIs, evidence text, snippets and severities are left out: they depend on
+// how string resources resolved, on manifest order and on adjustments like
+// -target-sdk, while the finding stays the same one to triage.
+func fingerprint(pkg, ruleID, kind, component string, keys ...string) string {
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+	fields := []string{fingerprintVersion, pkg, ruleID, kind, qualifiedName(pkg, strings.TrimSpace(component))}
+	sum := sha256.Sum256([]byte(strings.Join(append(fields, sorted...), "\x00")))
+	return hex.EncodeToString(sum[:16])
+}
+
+// exportedEvidence describes how the component's exported state was declared.
+func exportedEvidence(component App, implicit bool) string {
+	if implicit && opts.TargetSDK == 0 {
+		return fmt.Sprintf("android:exported absent; implicitly exported with %d intent filter(s), as the app must target SDK 30 or lower", len(component.Filters))
+	}
+	if implicit {
+		return fmt.Sprintf("android:exported absent; implicitly exported with %d intent filter(s) on target SDK %d", len(component.Filters), opts.TargetSDK)
+	}
+	return fmt.Sprintf("android:exported=%q", component.Exported)
+}
+
+// componentURIs returns every deeplink URI declared for the component, from its
+// intent filters and from res/xml files referenced by its meta-data, without duplicates.
+func componentURIs(folder string, component App) []string {
+	var uris []string
+	seen := make(map[string]bool)
+	add := func(uri string) {
+		if uri != "" && !seen[uri] {
+			seen[uri] = true
+			uris = append(uris, uri)
+		}
+	}
+	for _, filter := range component.Filters {
+		for _, data := range filter.Data {
+			add(constructURI(data))
+		}
+	}
+	for _, link := range metaDataDeeplinks(folder, component) {
+		add(link.URI)
+	}
+	return uris
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"   // Captured warnings
+	"io"      // Discarded text report
+	"slices"  // Result comparison
+	"strings" // Manifest rewriting, output checks
+	"testing" // Test harness
+)
+
+func TestFingerprint(t *testing.T) {
+	base := fingerprint("com.example", "exported-activity", "activity", ".Main")
+	for _, tc := range []struct {
+		name string
+		id   string
+		same bool
+	}{
+		{"qualified name", fingerprint("com.example", "exported-activity", "activity", "com.example.Main"), true},
+		{"surrounding space", fingerprint("com.example", "exported-activity", "activity", " .Main\n"), true},
+		{"other package", fingerprint("org.example", "exported-activity", "activity", ".Main"), false},
+		{"other rule", fingerprint("com.example", "deeplink-handler", "activity", ".Main"), false},
+		{"other kind", fingerprint("com.example", "exported-activity", "alias", ".Main"), false},
+		{"other component", fingerprint("com.example", "exported-activity", "activity", ".Settings"), false},
+		{"with a key", fingerprint("com.example", "exported-activity", "activity", ".Main", "android:permission"), false},
+	} {
+		if (tc.id == base) != tc.same {
+			t.Errorf("%s: id %s, base %s, want same=%t", tc.name, tc.id, base, tc.same)
+		}
+	}
+	if len(base) != 32 {
+		t.Errorf("id %q, want 32 hex digits", base)
+	}
+	if a, b := fingerprint("p", "r", "k", "c", "x", "y"), fingerprint("p", "r", "k", "c", "y", "x"); a != b {
+		t.Errorf("key order changes the id: %s != %s", a, b)
+	}
+}
+
+// analyzeIDs analyzes a fixture and returns its sorted finding IDs and the
+// warnings printed along the way.
+func analyzeIDs(t *testing.T, manifest, stringsXML string) ([]string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := writeSelftestTarget(dir, manifest, stringsXML); err != nil {
+		t.Fatal(err)
+	}
+	var progress bytes.Buffer
+	result, err := analyzeFolder(io.Discard, &progress, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Findings) == 0 {
+		t.Fatal("fixture produced no findings")
+	}
+	return findingIDs(result), progress.String()
+}
+
+// reverseComponents reverses the order of the components declared in the
+// application, keeping each declaration intact.
+func reverseComponents(manifest string) string {
+	head, rest, _ := strings.Cut(manifest, "    <application")
+	open, rest, _ := strings.Cut(rest, "\n")
+	body, tail, _ := strings.Cut(rest, "    </application>")
+	var blocks []string
+	for _, line := range strings.SplitAfter(body, "\n") {
+		if strings.HasPrefix(line, "        <") && !strings.HasPrefix(line, "        </") || len(blocks) == 0 {
+			blocks = append(blocks, "")
+		}
+		blocks[len(blocks)-1] += line
+	}
+	slices.Reverse(blocks)
+	return head + "    <application" + open + "\n" + strings.Join(blocks, "") + "    </application>" + tail
+}
+
+// TestFindingIDsStable checks the IDs survive what changes between two
+// decompilations of one app: the target directory and line endings, the order
+// of the components, and string resources that no longer resolve. The last is
+// warned about instead.
+func TestFindingIDsStable(t *testing.T) {
+	setOpts(t, options{Format: "json"})
+	want, warnings := analyzeIDs(t, selftestManifest, selftestStrings)
+	if strings.Contains(warnings, "unresolved @string") {
+		t.Fatalf("resolved fixture warns about strings:\n%s", warnings)
+	}
+
+	reordered := reverseComponents(selftestManifest)
+	if reordered == selftestManifest || !strings.Contains(reordered, "<provider android:name=\".DataProvider\"") ||
+		strings.Index(reordered, ".DataProvider") > strings.Index(reordered, ".MainActivity") {
+		t.Fatalf("reverseComponents didn't move the provider first:\n%s", reordered)
+	}
+
+	for _, tc := range []struct {
+		name, manifest, strings, warning string
+	}{
+		{name: "decompiled twice", manifest: strings.ReplaceAll(selftestManifest, "\n", "\r\n"), strings: selftestStrings},
+		{name: "reordered manifest", manifest: reordered, strings: selftestStrings},
+		{name: "qualified names", manifest: strings.ReplaceAll(selftestManifest, `android:name=".`, `android:name="org.deeeeper.selftest.`), strings: selftestStrings},
+		{name: "unresolved strings", manifest: selftestManifest, strings: "<resources/>\n", warning: "Warning: unresolved @string/deeplink_host"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, warnings := analyzeIDs(t, tc.manifest, tc.strings)
+			if !slices.Equal(got, want) {
+				t.Errorf("ids:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+			}
+			if tc.warning != "" && !strings.Contains(warnings, tc.warning) {
+				t.Errorf("warnings lack %q:\n%s", tc.warning, warnings)
+			}
+		})
+	}
+}
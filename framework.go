@@ -0,0 +1,124 @@
+package main
+
+import (
+	"archive/zip"   // Reading resources.arsc from framework-res.apk
+	"fmt"           // Unresolved reference descriptions
+	"io"            // Reading the table
+	"maps"          // Copying the bundled table
+	"os"            // Locating apktool's framework
+	"path/filepath" // Framework paths
+	"runtime"       // Per-OS apktool framework location
+	"strings"       // Reference prefixes
+)
+
+// bundledFramework holds AOSP defaults of framework resources commonly referenced
+// from system app manifests. OEM builds may override them; -framework reads
+// the values of the actual device.
+var bundledFramework = resourceValues{
+	"bool/config_voice_capable": "true",
+	"bool/config_sms_capable":   "true",
+	"string/ok":                 "OK",
+	"string/cancel":             "Cancel",
+	"string/copy":               "Copy",
+	"string/paste":              "Paste",
+	"string/unknownName":        "Unknown",
+	"string/untitled":           "<Untitled>",
+	"string/dialog_alert_title": "Attention",
+	"string/emptyPhoneNumber":   "(No phone number)",
+}
+
+// frameworkValues resolves @android: and @*android: references. It starts as
+// the bundled table and is extended in main from -framework or apktool's
+// installed framework.
+var frameworkValues = maps.Clone(bundledFramework)
+
+// frameworkNames maps framework resource IDs to their "type/name", for binary
+// manifests referencing them. It is filled by loadFramework.
+var frameworkNames = make(map[uint32]string)
+
+// loadFramework adds the values of a framework-res.apk, or of a bare
+// resources.arsc, to frameworkValues and its resource names to frameworkNames.
+// Values from the file replace bundled ones.
+func loadFramework(path string) error {
+	data, err := frameworkTable(path)
+	if err != nil {
+		return err
+	}
+	values, names, err := readResourceTableNames(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	maps.Copy(frameworkValues, values)
+	maps.Copy(frameworkNames, names)
+	return nil
+}
+
+// frameworkTable returns the resources.arsc bytes of an APK or the file itself.
+func frameworkTable(path string) ([]byte, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil { // Not a zip: treat it as a resources.arsc
+		return os.ReadFile(path)
+	}
+	defer archive.Close()
+	table, err := archive.Open("resources.arsc")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	defer table.Close()
+	return io.ReadAll(table)
+}
+
+// installedFramework returns the framework-res.apk apktool installed for
+// itself (1.apk in -framework-dir or its default framework directory), or ""
+// when there is none.
+func installedFramework() string {
+	dir := opts.FrameworkDir
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "share", "apktool", "framework")
+		switch runtime.GOOS {
+		case "darwin":
+			dir = filepath.Join(home, "Library", "apktool", "framework")
+		case "windows":
+			dir = filepath.Join(home, "AppData", "Local", "apktool", "framework")
+		}
+	}
+	path := filepath.Join(dir, "1.apk")
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// isFrameworkReference reports whether an attribute value still holds an
+// @android: or @*android: reference.
+func isFrameworkReference(value string) bool {
+	return strings.HasPrefix(value, "@android:") || strings.HasPrefix(value, "@*android:")
+}
+
+// unresolvedFrameworkReferences lists the component and filter attributes that
+// still reference framework resources after resolution, so output depending on
+// them can be flagged instead of silently reading them as false or empty.
+func unresolvedFrameworkReferences(manifest Manifest) []string {
+	var unresolved []string
+	for _, group := range componentGroups(manifest) {
+		for _, component := range group.Components {
+			for _, attr := range sortedKeys(component.Attributes) {
+				if value := component.Attributes[attr]; isFrameworkReference(value) {
+					unresolved = append(unresolved, fmt.Sprintf("%s %s=%q", component.Name, attr, value))
+				}
+			}
+			for _, filter := range component.Filters {
+				for _, attr := range sortedKeys(filter.Attributes) {
+					if value := filter.Attributes[attr]; isFrameworkReference(value) {
+						unresolved = append(unresolved, fmt.Sprintf("%s intent-filter %s=%q", component.Name, attr, value))
+					}
+				}
+			}
+		}
+	}
+	return unresolved
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"archive/zip"     // Fake framework-res.apk
+	"bytes"           // Table assembly and captured warnings
+	"encoding/binary" // Little-endian chunk fields
+	"maps"            // Restoring the framework tables
+	"os"              // Fixture files
+	"path/filepath"   // Fixture paths
+	"slices"          // Result comparison
+	"strings"         // Output checks
+	"testing"         // Test harness
+
+	"Deeeeper/Deeeeper/deeeeper" // Resolution under test
+)
+
+// frameworkEntry is one resource of a fake framework table.
+type frameworkEntry struct {
+	name     string // Key name
+	dataType byte   // Res_value type, resValueBoolean or resValueString
+	value    string // "true"/"false" for booleans, the text for strings
+}
+
+// fakeFramework builds the resources.arsc of a package 0x01 named android,
+// with a bool and a string type in the default configuration, as aapt lays
+// them out.
+func fakeFramework(bools, strs []frameworkEntry) []byte {
+	var globals, keys []string
+	typeChunk := func(id byte, entries []frameworkEntry) []byte {
+		const configSize = 64
+		headerSize := 20 + configSize
+		var offsets, body bytes.Buffer
+		for _, e := range entries {
+			putLE(&offsets, uint32(body.Len()))
+			data := uint32(0)
+			switch {
+			case e.dataType == resValueString:
+				data = uint32(len(globals))
+				globals = append(globals, e.value)
+			case e.value == "true":
+				data = 0xFFFFFFFF
+			}
+			putLE(&body, uint16(8), uint16(0), uint32(len(keys)))
+			putLE(&body, uint16(8), byte(0), e.dataType, data)
+			keys = append(keys, e.name)
+		}
+		var chunk bytes.Buffer
+		putLE(&chunk, uint16(resTableTypeType), uint16(headerSize), uint32(headerSize+offsets.Len()+body.Len()))
+		putLE(&chunk, id, byte(0), uint16(0), uint32(len(entries)), uint32(headerSize+offsets.Len()))
+		config := make([]byte, configSize)
+		binary.LittleEndian.PutUint32(config, configSize)
+		chunk.Write(config)
+		chunk.Write(offsets.Bytes())
+		chunk.Write(body.Bytes())
+		return chunk.Bytes()
+	}
+	types := append(typeChunk(1, bools), typeChunk(2, strs)...)
+
+	const packageHeader = 288
+	typeStrings := testStringPool([]string{"bool", "string"})
+	keyStrings := testStringPool(keys)
+	var pkg bytes.Buffer
+	putLE(&pkg, uint16(resTablePackageType), uint16(packageHeader), uint32(packageHeader+len(typeStrings)+len(keyStrings)+len(types)), uint32(0x01))
+	name := make([]uint16, 128)
+	for i, r := range "android" {
+		name[i] = uint16(r)
+	}
+	putLE(&pkg, name)
+	putLE(&pkg, uint32(packageHeader), uint32(2), uint32(packageHeader+len(typeStrings)), uint32(len(keys)), uint32(0))
+	pkg.Write(typeStrings)
+	pkg.Write(keyStrings)
+	pkg.Write(types)
+
+	globalStrings := testStringPool(globals)
+	var table bytes.Buffer
+	putLE(&table, uint16(resTableType), uint16(12), uint32(12+len(globalStrings)+pkg.Len()), uint32(1))
+	table.Write(globalStrings)
+	table.Write(pkg.Bytes())
+	return table.Bytes()
+}
+
+// putLE appends fixed-size values in little-endian order.
+func putLE(buf *bytes.Buffer, values ...any) {
+	for _, v := range values {
+		binary.Write(buf, binary.LittleEndian, v)
+	}
+}
+
+// testStringPool encodes a UTF-8 ResStringPool chunk of short strings.
+func testStringPool(pool []string) []byte {
+	var offsets, data bytes.Buffer
+	for _, s := range pool {
+		putLE(&offsets, uint32(data.Len()))
+		data.Write([]byte{byte(len(s)), byte(len(s))})
+		data.WriteString(s)
+		data.WriteByte(0)
+	}
+	for data.Len()%4 != 0 {
+		data.WriteByte(0)
+	}
+	var chunk bytes.Buffer
+	putLE(&chunk, uint16(resStringPoolType), uint16(28), uint32(28+offsets.Len()+data.Len()),
+		uint32(len(pool)), uint32(0), uint32(stringPoolUTF8), uint32(28+offsets.Len()), uint32(0))
+	chunk.Write(offsets.Bytes())
+	chunk.Write(data.Bytes())
+	return chunk.Bytes()
+}
+
+// writeFakeFramework writes the fake table as a framework-res.apk and returns
+// its path. The loaded framework tables are restored after the test.
+func writeFakeFramework(t *testing.T) string {
+	t.Helper()
+	savedValues, savedNames := maps.Clone(frameworkValues), maps.Clone(frameworkNames)
+	t.Cleanup(func() { frameworkValues, frameworkNames = savedValues, savedNames })
+
+	table := fakeFramework(
+		[]frameworkEntry{{"config_voice_capable", resValueBoolean, "false"}, {"config_priv_exported", resValueBoolean, "true"}},
+		[]frameworkEntry{{"ok", resValueString, "Okay"}, {"config_update_host", resValueString, "updates.oem.example"}},
+	)
+	var apk bytes.Buffer
+	w := zip.NewWriter(&apk)
+	f, err := w.Create("resources.arsc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(table)
+	w.Close()
+	path := filepath.Join(t.TempDir(), "framework-res.apk")
+	if err := os.WriteFile(path, apk.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), "resources.arsc"), table, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFramework(t *testing.T) {
+	apk := writeFakeFramework(t)
+	for _, path := range []string{apk, filepath.Join(filepath.Dir(apk), "resources.arsc")} {
+		frameworkValues, frameworkNames = maps.Clone(bundledFramework), make(map[uint32]string)
+		if err := loadFramework(path); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		for name, want := range map[string]string{
+			"bool/config_voice_capable": "false", // Replaces the bundled AOSP default
+			"bool/config_priv_exported": "true",
+			"string/ok":                 "Okay",
+			"string/config_update_host": "updates.oem.example",
+			"string/cancel":             "Cancel", // Bundled, not in the file
+		} {
+			if got := frameworkValues[name]; got != want {
+				t.Errorf("%s: %s = %q, want %q", filepath.Base(path), name, got, want)
+			}
+		}
+		if got := frameworkNames[0x01020001]; got != "string/config_update_host" {
+			t.Errorf("%s: name of 0x01020001 = %q, want string/config_update_host", filepath.Base(path), got)
+		}
+	}
+	if err := loadFramework(filepath.Join(t.TempDir(), "missing.apk")); err == nil {
+		t.Error("loading a missing framework succeeded")
+	}
+}
+
+// privAppManifest is a system app whose exported state and deeplink host come
+// from the framework, and which references a resource the framework lacks.
+const privAppManifest = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.oem.updater" android:sharedUserId="android.uid.system">
+    <application android:label="@android:string/ok">
+        <activity android:name=".Update" android:exported="@android:bool/config_priv_exported">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="https" android:host="@android:string/config_update_host"/>
+            </intent-filter>
+        </activity>
+        <receiver android:name=".Boot" android:exported="@*android:bool/config_oem_boot"/>
+    </application>
+</manifest>
+`
+
+// TestFrameworkResolution checks a priv-app manifest resolves @android:
+// values through a loaded framework and flags the reference it can't resolve.
+func TestFrameworkResolution(t *testing.T) {
+	setOpts(t, textOptions())
+	if err := loadFramework(writeFakeFramework(t)); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := writeSelftestTarget(dir, privAppManifest, "<resources/>"); err != nil {
+		t.Fatal(err)
+	}
+	var text, progress bytes.Buffer
+	result, err := analyzeFolder(&text, &progress, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Hosts) != 1 || result.Hosts[0].Host != "updates.oem.example" {
+		t.Errorf("hosts = %+v, want updates.oem.example from @android:string/config_update_host", result.Hosts)
+	}
+	if !strings.Contains(text.String(), ".Update (exported=true)\n  android.intent.action.VIEW\n  https://updates.oem.example\n") {
+		t.Errorf("report lacks the exported .Update:\n%s", text.String())
+	}
+	want := `Warning: unresolved framework reference .Boot android:exported="@*android:bool/config_oem_boot"`
+	if !strings.Contains(progress.String(), want) {
+		t.Errorf("warnings lack %s:\n%s", want, progress.String())
+	}
+
+	manifest := parseTestManifest(t, privAppManifest)
+	deeeeper.ResolveManifest(&manifest, resourceValues{}.resources())
+	if got := unresolvedFrameworkReferences(manifest); !slices.Equal(got, []string{`.Boot android:exported="@*android:bool/config_oem_boot"`}) {
+		t.Errorf("unresolvedFrameworkReferences = %q", got)
+	}
+}
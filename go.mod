@@ -2,10 +2,14 @@ module Deeeeper/Deeeeper
 
 go 1.22.0
 
-require github.com/fatih/color v1.16.0
+require (
+	github.com/fatih/color v1.16.0
+	github.com/mattn/go-isatty v0.0.20
+	golang.org/x/net v0.35.0
+)
 
 require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
-	github.com/mattn/go-isatty v0.0.20 // indirect
-	golang.org/x/sys v0.14.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/text v0.22.0 // indirect
 )
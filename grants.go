@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bufio"         // Scanning smali lines
+	"fmt"           // Evidence formatting
+	"io"            // Output destination
+	"os"            // Reading smali files
+	"path/filepath" // Smali file names
+	"regexp"        // Constant loads
+	"strconv"       // Flag values
+	"strings"       // Call matching
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// Smali call fragments the URI grant heuristic looks for within one method.
+var (
+	incomingIntent = []string{"->getIntent()Landroid/content/Intent;"}
+	incomingURIs   = []string{
+		"Landroid/content/Intent;->getData()",
+		"Landroid/content/Intent;->getClipData()",
+		"Landroid/content/Intent;->getParcelableExtra(",
+		"Landroid/content/Intent;->getParcelableArrayListExtra(",
+		"Landroid/os/Bundle;->getParcelable(",
+	}
+	outgoingIntents = []string{
+		"->startActivity(Landroid/content/Intent;",
+		"->startActivityForResult(Landroid/content/Intent;",
+		"->setResult(ILandroid/content/Intent;)V",
+		"->sendBroadcast(Landroid/content/Intent;",
+		"->startService(Landroid/content/Intent;",
+	}
+	flagSetters = []string{"Landroid/content/Intent;->addFlags(I)", "Landroid/content/Intent;->setFlags(I)"}
+)
+
+// grantFlags are FLAG_GRANT_READ_URI_PERMISSION and FLAG_GRANT_WRITE_URI_PERMISSION.
+const grantFlags = 0x1 | 0x2
+
+// constLoad matches a smali constant load: the register and the value.
+var constLoad = regexp.MustCompile(`^const(?:/4|/16|/high16)?\s+([vp]\d+),\s+(-?0x[0-9a-fA-F]+|-?\d+)`)
+
+// flagArgument extracts the flags register of an addFlags/setFlags call.
+var flagArgument = regexp.MustCompile(`^invoke-virtual\s+\{[vp]\d+,\s*([vp]\d+)\}`)
+
+// grantChain is a potential confused-deputy path: an exported activity whose
+// code forwards URIs from its incoming intent with grant flags set, and the
+// providers of the app whose URIs such a grant could cover.
+type grantChain struct {
+	Kind      string   `json:"type"`      // activity or alias
+	Component string   `json:"component"` // Component name as in the manifest
+	Providers []string `json:"providers"` // Grantable providers with their base content:// URIs
+	Evidence  []string `json:"evidence"`  // Smali lines behind the heuristic, "File.smali:line: code"
+}
+
+// collectGrantChains runs the URI grant heuristic on every exported activity
+// and alias and pairs each hit with the app's grantable providers.
+func collectGrantChains(manifest Manifest, folder string) []grantChain {
+	var providers []string
+	for _, component := range manifest.Application.Providers {
+		if grantable(component) {
+			entry := qualifiedName(manifest.Package, component.Name)
+			if authorities := providerAuthorities(component); len(authorities) > 0 {
+				entry += " (content://" + authorities[0] + ")"
+			}
+			providers = append(providers, entry)
+		}
+	}
+	var chains []grantChain
+	for _, group := range componentGroups(manifest) {
+		if group.Kind != "activity" && group.Kind != "alias" {
+			continue
+		}
+		for _, component := range group.Components {
+			if exported, _ := isExported(component, group.Kind); !exported {
+				continue
+			}
+			name, code := component.Name, component.Name
+			if target, ok := resolvedAlias(component, group.Kind); ok {
+				name = target
+			}
+			if group.Kind == "alias" {
+				code = component.TargetActivity // The alias runs its target's code
+			}
+			if evidence := grantForwardLines(folder, qualifiedName(manifest.Package, code)); len(evidence) > 0 {
+				chains = append(chains, grantChain{Kind: group.Kind, Component: name, Providers: providers, Evidence: evidence})
+			}
+		}
+	}
+	return chains
+}
+
+// grantForwardLines is a heuristic: it returns the evidence lines of the first
+// method of the class (or its inner classes) that reads the incoming intent
+// and a URI from it, sets a grant flag on an intent and sends an intent on
+// with startActivity, setResult and the like. It returns nil when no method
+// does all of that.
+func grantForwardLines(folder, className string) []string {
+	for _, path := range smaliFiles(folder, className) {
+		if lines := scanGrantForwarding(path); lines != nil {
+			return lines
+		}
+	}
+	return nil
+}
+
+// scanGrantForwarding applies the grant heuristic to the methods of one smali file.
+func scanGrantForwarding(path string) []string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	base := filepath.Base(path)
+
+	var intent, uri, grant, outgoing string
+	constants := make(map[string]int64)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // Long string constants
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		evidence := fmt.Sprintf("%s:%d: %s", base, number, line)
+		switch {
+		case strings.HasPrefix(line, ".method"):
+			intent, uri, grant, outgoing = "", "", "", ""
+			clear(constants)
+		case strings.HasPrefix(line, ".end method"):
+			if intent != "" && uri != "" && grant != "" && outgoing != "" {
+				return []string{intent, uri, grant, outgoing}
+			}
+		case constLoad.MatchString(line):
+			match := constLoad.FindStringSubmatch(line)
+			if value, err := strconv.ParseInt(match[2], 0, 64); err == nil {
+				constants[match[1]] = value
+			}
+		case containsAny(line, flagSetters):
+			if match := flagArgument.FindStringSubmatch(line); match != nil && constants[match[1]]&grantFlags != 0 && grant == "" {
+				grant = evidence
+			}
+		case intent == "" && containsAny(line, incomingIntent):
+			intent = evidence
+		case uri == "" && containsAny(line, incomingURIs):
+			uri = evidence
+		case outgoing == "" && containsAny(line, outgoingIntents):
+			outgoing = evidence
+		}
+	}
+	return nil
+}
+
+// containsAny reports whether line contains one of the fragments.
+func containsAny(line string, fragments []string) bool {
+	for _, fragment := range fragments {
+		if strings.Contains(line, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// grantChainFindings raises a finding per grant chain. It is rated high when
+// the app has grantable providers the forwarded grant could open up.
+func grantChainFindings(pkg string, chains []grantChain) []finding {
+	var findings []finding
+	for _, chain := range chains {
+		evidence := "smali heuristic: incoming intent URI forwarded with FLAG_GRANT_*_URI_PERMISSION"
+		f := newFinding(pkg, "uri-grant-forwarding", chain.Kind, chain.Component, nil, evidence)
+		if len(chain.Providers) > 0 {
+			f.Severity = "high"
+			f.Evidence += "; grantable providers: " + strings.Join(chain.Providers, ", ")
+		} else {
+			f.Evidence += "; no provider of the app sets grantUriPermissions, URIs granted to the app can still be forwarded"
+		}
+		f.Snippet = capSnippet(strings.Join(chain.Evidence, "\n"))
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// printGrantChains lists the potential provider-via-activity chains.
+func printGrantChains(w io.Writer, chains []grantChain) {
+	printSection(w, "\nURI grant chains (smali heuristic):")
+	cyan := color.New(color.FgCyan).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+	for _, chain := range chains {
+		fmt.Fprintf(w, "%s forwards URIs from its incoming intent with grant flags\n", cyan(chain.Component))
+		for _, provider := range chain.Providers {
+			fmt.Fprintf(w, "  may grant %s\n", red(provider))
+		}
+		if len(chain.Providers) == 0 {
+			fmt.Fprintln(w, "  no grantable provider in this app; URIs granted to it can still be passed on")
+		}
+		for _, line := range chain.Evidence {
+			fmt.Fprintf(w, "    %s\n", line)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"         // Heartbeat formatting
+	"os"          // Writing to stderr
+	"sync/atomic" // Progress counter shared with workers
+	"time"        // Ticker and elapsed time
+
+	"github.com/mattn/go-isatty" // Terminal detection
+)
+
+// progress counts finished targets so a heartbeat can report on them.
+type progress struct {
+	done  atomic.Int64 // Targets finished so far, successful or not
+	total int          // Targets in the batch
+}
+
+// startHeartbeat prints a plain "[120s] processed 45/300 APKs" line to stderr
+// every interval while a batch runs. Heartbeats are only emitted when stderr is
+// not a terminal (CI logs), where a silent gap would otherwise look like a hang.
+// The returned function stops the heartbeat.
+func startHeartbeat(p *progress, interval time.Duration) func() {
+	if interval <= 0 || p.total < 2 || isatty.IsTerminal(os.Stderr.Fd()) || isatty.IsCygwinTerminal(os.Stderr.Fd()) {
+		return func() {}
+	}
+
+	start := time.Now()
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				elapsed := time.Since(start).Round(time.Second)
+				fmt.Fprintf(os.Stderr, "[%ds] processed %d/%d APKs\n", int(elapsed.Seconds()), p.done.Load(), p.total)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"   // JSON input and captured stderr
+	"context" // Hook timeout
+	"fmt"     // Environment values and errors
+	"os"      // Inherited environment
+	"os/exec" // Running the hook
+	"runtime" // Shell selection
+	"strings" // Environment names
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// runHooks executes -hook once per report and, with -hook-combined, once more
+// for all reports together. It returns the number of hook runs that failed.
+func runHooks(reports []*report) int {
+	if opts.Hook == "" {
+		return 0
+	}
+	failed := 0
+	for _, r := range reports {
+		if err := runHook([]*report{r}, r.Target, r.Package); err != nil {
+			color.Yellow("Warning: hook failed for %s: %s", r.Target, err)
+			failed++
+		}
+	}
+	if opts.HookCombined && len(reports) > 1 {
+		input := opts.APKPath
+		if input == "" {
+			input = opts.Folder
+		}
+		if err := runHook(reports, input, ""); err != nil {
+			color.Yellow("Warning: combined hook failed: %s", err)
+			failed++
+		}
+	}
+	return failed
+}
+
+// runHook runs the hook command through the platform shell with the JSON
+// document of the reports on stdin and a summary in DEEEEPER_* variables.
+func runHook(reports []*report, targetPath, pkg string) error {
+	var input bytes.Buffer
+	if err := writeJSON(&input, reports); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if opts.HookTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.HookTimeout)
+		defer cancel()
+	}
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", opts.Hook)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", opts.Hook)
+	}
+	cmd.Stdin = &input
+	cmd.Stdout = color.Output // Hook output joins the progress chatter, never a machine document
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), hookEnv(reports, targetPath, pkg)...)
+
+	err := cmd.Run()
+	if opts.Verbose && stderr.Len() > 0 {
+		fmt.Fprintf(color.Output, "hook stderr:\n%s", stderr.String())
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", opts.HookTimeout)
+	}
+	if err != nil && stderr.Len() > 0 && !opts.Verbose {
+		return fmt.Errorf("%w: %s", err, lastLine(stderr.String()))
+	}
+	return err
+}
+
+// hookEnv describes the hook's input: target, package and finding counts in
+// total and per severity (DEEEEPER_FINDINGS_HIGH and so on).
+func hookEnv(reports []*report, targetPath, pkg string) []string {
+	counts := make(map[string]int)
+	total := 0
+	for _, r := range reports {
+		for _, f := range r.Findings {
+			counts[f.Severity]++
+			total++
+		}
+	}
+	env := []string{
+		"DEEEEPER_TARGET=" + targetPath,
+		"DEEEEPER_PACKAGE=" + pkg,
+		fmt.Sprintf("DEEEEPER_REPORTS=%d", len(reports)),
+		fmt.Sprintf("DEEEEPER_FINDINGS=%d", total),
+	}
+	for _, severity := range severityOrder {
+		env = append(env, fmt.Sprintf("DEEEEPER_FINDINGS_%s=%d", strings.ToUpper(severity), counts[severity]))
+	}
+	return env
+}
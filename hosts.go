@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"     // Output formatting
+	"io"      // Output destination
+	"slices"  // Scheme lists
+	"sort"    // Deterministic ordering
+	"strings" // Case folding
+
+	"Deeeeper/Deeeeper/deeeeper" // Host normalization and IDN forms
+	"github.com/fatih/color"     // Colorized output in terminal
+)
+
+// hostInfo aggregates every filter that declares a host.
+type hostInfo struct {
+	Host         string   `json:"host"`                    // Lower-cased ASCII (punycode) host name
+	Unicode      string   `json:"unicode,omitempty"`       // Display form of an internationalized host
+	MixedScripts []string `json:"mixed_scripts,omitempty"` // Scripts mixed within one label: a possible homograph lure
+	Schemes      []string `json:"schemes"`                 // Schemes declared alongside the host
+	Components   []string `json:"components"`              // Components whose filters declare it
+	AutoVerify   bool     `json:"auto_verify"`             // App Links verification requested by any declaring filter
+}
+
+// collectHosts gathers the hosts of every intent filter with an aggregated
+// "verification requested" flag. A filter requests verification through its own
+// android:autoVerify or an app-wide one on <application>. Hosts are keyed by
+// their ASCII form, so Unicode and xn-- spellings of one host merge.
+func collectHosts(manifest Manifest) []hostInfo {
+	appVerify := isTrue(manifest.Application.AutoVerify)
+	index := make(map[string]*hostInfo)
+	for _, group := range componentGroups(manifest) {
+		for _, component := range group.Components {
+			for _, filter := range component.Filters {
+				var schemes, hosts []string
+				for _, data := range filter.Data { // <data> elements of one filter combine
+					if data.Scheme != "" {
+						schemes = append(schemes, deeeeper.NormalizeScheme(data.Scheme))
+					}
+					if data.Host != "" && !deeeeper.HasUnresolved(data.Host) {
+						ascii, _ := deeeeper.HostToASCII(data.Host)
+						hosts = append(hosts, ascii)
+					}
+				}
+				for _, host := range hosts {
+					info := index[host]
+					if info == nil {
+						info = &hostInfo{Host: host, MixedScripts: deeeeper.MixedScripts(host)}
+						if display := deeeeper.HostToUnicode(host); display != host {
+							info.Unicode = display
+						}
+						index[host] = info
+					}
+					for _, scheme := range schemes {
+						if !slices.Contains(info.Schemes, scheme) {
+							info.Schemes = append(info.Schemes, scheme)
+						}
+					}
+					if !slices.Contains(info.Components, component.Name) {
+						info.Components = append(info.Components, component.Name)
+					}
+					info.AutoVerify = info.AutoVerify || appVerify || isTrue(filter.AutoVerify)
+				}
+			}
+		}
+	}
+
+	hosts := make([]hostInfo, 0, len(index))
+	for _, info := range index {
+		sort.Strings(info.Schemes)
+		hosts = append(hosts, *info)
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Host < hosts[j].Host })
+	return hosts
+}
+
+// printHosts renders the host list with App Links verification state. Internationalized
+// hosts are shown in Unicode followed by their ASCII form.
+func printHosts(w io.Writer, hosts []hostInfo) {
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	printSection(w, "\nHosts:")
+	for _, host := range hosts {
+		state := "verification not requested"
+		if host.AutoVerify {
+			state = yellow("autoVerify requested")
+		}
+		name := green(host.Host)
+		if host.Unicode != "" {
+			name = fmt.Sprintf("%s (%s)", green(host.Unicode), host.Host)
+		}
+		fmt.Fprintf(w, "  %s [%s] %s\n", name, strings.Join(host.Schemes, ", "), state)
+		if len(host.MixedScripts) > 0 {
+			fmt.Fprintf(w, "    %s\n", red("mixes "+strings.Join(host.MixedScripts, " and ")+" scripts: possible homograph"))
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"slices"  // Host lookup
+	"strings" // Output checks
+	"testing" // Test harness
+)
+
+// lookalikeManifest declares a Cyrillic lookalike of apple.com in Unicode on
+// one activity and punycoded on another, next to an ordinary IDN.
+const lookalikeManifest = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example.app">
+    <application>
+        <activity android:name=".Login" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="https" android:host="аpple.com" android:path="/login"/>
+            </intent-filter>
+        </activity>
+        <activity android:name=".Legacy" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="http" android:host="xn--pple-43d.com"/>
+                <data android:scheme="https" android:host="bücher.example"/>
+            </intent-filter>
+        </activity>
+    </application>
+</manifest>
+`
+
+func TestCollectHostsIDN(t *testing.T) {
+	hosts := collectHosts(parseTestManifest(t, lookalikeManifest))
+	lure := slices.IndexFunc(hosts, func(h hostInfo) bool { return h.Host == "xn--pple-43d.com" })
+	if lure < 0 {
+		t.Fatalf("hosts %+v lack the ASCII form of the lookalike", hosts)
+	}
+	got := hosts[lure]
+	if got.Unicode != "аpple.com" {
+		t.Errorf("unicode = %q, want the Cyrillic display form", got.Unicode)
+	}
+	if !slices.Equal(got.MixedScripts, []string{"Cyrillic", "Latin"}) {
+		t.Errorf("mixed scripts = %q, want Cyrillic and Latin", got.MixedScripts)
+	}
+	if !slices.Equal(got.Components, []string{".Login", ".Legacy"}) || !slices.Equal(got.Schemes, []string{"http", "https"}) {
+		t.Errorf("Unicode and punycode spellings weren't merged: %+v", got)
+	}
+	idn := slices.IndexFunc(hosts, func(h hostInfo) bool { return h.Host == "xn--bcher-kva.example" })
+	if idn < 0 || hosts[idn].MixedScripts != nil {
+		t.Errorf("bücher.example missing or flagged: %+v", hosts)
+	}
+}
+
+// TestLookalikeReport checks the text report shows the display and ASCII forms
+// with a homograph warning, and constructed URIs use the ASCII form.
+func TestLookalikeReport(t *testing.T) {
+	setOpts(t, textOptions())
+	result, text := renderFixture(t, lookalikeManifest, "<resources/>")
+	for _, want := range []string{
+		"аpple.com (xn--pple-43d.com) [http, https]",
+		"mixes Cyrillic and Latin scripts: possible homograph",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report lacks %q:\n%s", want, text)
+		}
+	}
+	for _, c := range result.TestCases {
+		if strings.ContainsFunc(c.URI, func(r rune) bool { return r > 0x7f }) {
+			t.Errorf("test case URI %q isn't ASCII", c.URI)
+		}
+	}
+	if !slices.ContainsFunc(result.TestCases, func(c testCase) bool { return c.URI == "https://xn--pple-43d.com/login" }) {
+		t.Errorf("no test case for https://xn--pple-43d.com/login")
+	}
+}
+
+// TestHostCasing checks a host spelled App.Example.COM, app.example.com and
+// APP.EXAMPLE.COM. across filters is one host wherever hosts are grouped or
+// de-duplicated, while test cases keep the declared spelling.
+func TestHostCasing(t *testing.T) {
+	options := textOptions()
+	options.Inventory = true
+	setOpts(t, options)
+	result, text := renderFixture(t, selftestHostCasing, "<resources/>")
+
+	if len(result.Hosts) != 1 || result.Hosts[0].Host != "app.example.com" || !slices.Equal(result.Hosts[0].Components, []string{".First", ".Second"}) {
+		t.Errorf("hosts = %+v, want app.example.com once for .First and .Second", result.Hosts)
+	}
+	if n := strings.Count(strings.ToLower(text), "app.example.com ["); n != 1 {
+		t.Errorf("text report lists the host %d times, want 1:\n%s", n, text)
+	}
+	if len(result.Inventory) != 1 || result.Inventory[0].URI != "https://app.example.com/open" || len(result.Inventory[0].Handlers) != 2 {
+		t.Errorf("inventory = %+v, want https://app.example.com/open with both handlers", result.Inventory)
+	}
+	if len(result.Collisions) != 1 || result.Collisions[0].Pattern != "https://app.example.com/open" {
+		t.Errorf("collisions = %+v, want one on https://app.example.com/open", result.Collisions)
+	}
+	urls, includes := proxySeeds([]*report{result})
+	if !slices.Equal(urls, []string{"https://app.example.com/open"}) || len(includes) != 1 {
+		t.Errorf("proxy seeds = %q, %q; want one URL and one include", urls, includes)
+	}
+
+	var raws []string
+	for _, c := range result.TestCases {
+		raws = append(raws, c.Raw)
+	}
+	for _, raw := range []string{"HTTPS://App.Example.COM/open", "https://APP.EXAMPLE.COM./open"} {
+		if !slices.Contains(raws, raw) {
+			t.Errorf("test case raw URIs %q lack the declared %q", raws, raw)
+		}
+	}
+
+	other := analyzeFixture(t, strings.NewReplacer(`package="org.deeeeper.casing"`, `package="org.example.other"`, "App.Example.COM", "app.EXAMPLE.com").Replace(selftestHostCasing), "<resources/>")
+	catalog := buildCatalog([]*report{result, other})
+	if want := []string{"org.deeeeper.casing", "org.example.other"}; len(catalog.SharedHosts) != 1 || !slices.Equal(catalog.SharedHosts["https://app.example.com"], want) {
+		t.Errorf("shared hosts = %v, want https://app.example.com shared by %q", catalog.SharedHosts, want)
+	}
+}
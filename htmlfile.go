@@ -0,0 +1,127 @@
+package main
+
+import (
+	"html/template" // Report rendering
+	"time"          // Generation timestamp
+)
+
+// htmlFileSections are the component types of a -html report, in order.
+// Providers are left out: they have no intent filters to list.
+var htmlFileSections = []struct{ kind, title string }{
+	{"activity", "Activities"},
+	{"alias", "Activity aliases"},
+	{"service", "Services"},
+	{"receiver", "Receivers"},
+}
+
+// htmlFile is the data behind a -html report.
+type htmlFile struct {
+	Generated string                   // Generation time, RFC 3339
+	Version   string                   // Deeeeper version
+	Apps      []htmlFileApp            // One entry per analyzed target
+	QR        map[string]template.HTML // Inline SVG QR code per deeplink under -qr
+}
+
+// htmlFileApp is one target of a -html report.
+type htmlFileApp struct {
+	Package  string            // Package name from the manifest
+	Target   string            // APK or folder that was analyzed
+	Sections []htmlFileSection // Exported components per type
+}
+
+// htmlFileSection lists the exported components of one type.
+type htmlFileSection struct {
+	Title      string          // Section heading, e.g. "Activities"
+	Components []componentInfo // Exported components of the type
+}
+
+// htmlFileTemplate renders the whole batch as one page without external
+// resources. Deeplinks are plain text with a copy button, never links, so
+// browsing the report can't fire one by accident. The copy attribute avoids
+// "uri" in its name, which html/template would treat as a URL and sanitize.
+var htmlFileTemplate = template.Must(template.New("file").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Deeeeper report</title>
+<style>
+body{font-family:sans-serif;margin:2em}
+#filter{width:100%;max-width:40em;padding:.4em;margin-bottom:1em}
+summary{cursor:pointer;font-weight:bold;margin:.6em 0}
+table{border-collapse:collapse;margin-bottom:1em}
+td,th{border:1px solid #ccc;padding:.3em .6em;text-align:left;vertical-align:top}
+th{background:#f4f4f4}
+ul{list-style:none;margin:0;padding:0}
+code{word-break:break-all}
+button{margin-left:.4em;font-size:.8em}
+svg{display:block;margin:.3em 0}
+.hidden{display:none}
+</style>
+</head><body>
+<h1>Deeeeper report</h1>
+<p>Generated {{.Generated}} by Deeeeper {{.Version}}. Deeplinks are shown as text; use the copy buttons to test them.</p>
+<input id="filter" type="search" placeholder="Filter components, actions and deeplinks">
+{{range .Apps}}<h2>{{.Package}}</h2>
+<p><small>{{.Target}}</small></p>
+{{range .Sections}}<details open><summary>{{.Title}} ({{len .Components}})</summary>
+{{if .Components}}<table><tr><th>Component</th><th>Actions</th><th>Deeplinks</th></tr>
+{{range .Components}}<tr class="component"><td><code>{{.Name}}</code></td>
+<td><ul>{{range .Actions}}<li><code>{{.}}</code></li>{{end}}</ul></td>
+<td><ul>{{range .URIs}}<li><code>{{.}}</code><button type="button" data-copy="{{.}}">Copy</button>{{index $.QR .}}</li>{{end}}</ul></td></tr>
+{{end}}</table>
+{{else}}<p>No exported components.</p>
+{{end}}</details>
+{{end}}{{end}}<script>
+document.getElementById('filter').addEventListener('input', function () {
+  var query = this.value.toLowerCase();
+  document.querySelectorAll('tr.component').forEach(function (row) {
+    row.classList.toggle('hidden', query !== '' && row.textContent.toLowerCase().indexOf(query) < 0);
+  });
+});
+document.querySelectorAll('button[data-copy]').forEach(function (button) {
+  button.addEventListener('click', function () {
+    var uri = button.dataset.copy;
+    var done = function () {
+      button.textContent = 'Copied';
+      setTimeout(function () { button.textContent = 'Copy'; }, 1500);
+    };
+    if (navigator.clipboard && window.isSecureContext) {
+      navigator.clipboard.writeText(uri).then(done);
+      return;
+    }
+    var scratch = document.createElement('textarea');
+    scratch.value = uri;
+    document.body.appendChild(scratch);
+    scratch.select();
+    document.execCommand('copy');
+    document.body.removeChild(scratch);
+    done();
+  });
+});
+</script>
+</body></html>
+`))
+
+// writeHTMLFile writes every report into one self-contained HTML file listing
+// the exported activities, aliases, services and receivers with their actions
+// and deeplinks, with a filter box and a collapsible section per type. Under
+// -qr each scannable deeplink carries its QR code.
+func writeHTMLFile(path string, reports []*report) error {
+	page := htmlFile{Generated: time.Now().UTC().Format(time.RFC3339), Version: toolVersion, QR: make(map[string]template.HTML)}
+	for _, r := range reports {
+		app := htmlFileApp{Package: r.Package, Target: r.Target}
+		for _, section := range htmlFileSections {
+			listed := htmlFileSection{Title: section.title}
+			for _, c := range r.Components {
+				if c.Type == section.kind && c.Exported {
+					listed.Components = append(listed.Components, c)
+					for _, uri := range c.URIs {
+						if _, done := page.QR[uri]; opts.QR && !done {
+							page.QR[uri] = qrImage(uri)
+						}
+					}
+				}
+			}
+			app.Sections = append(app.Sections, listed)
+		}
+		page.Apps = append(page.Apps, app)
+	}
+	return writeTemplate(path, htmlFileTemplate, page)
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"           // File names and summaries
+	"html/template" // Report rendering
+	"os"            // Output files
+	"path/filepath" // Output paths
+	"regexp"        // File name sanitizing
+	"strings"       // Summary assembly
+)
+
+// htmlPage is the data behind one per-APK report page.
+type htmlPage struct {
+	File      string     // File name relative to the site directory
+	Report    *report    // Analysis result
+	Deeplinks []htmlLink // Distinct deeplinks with their handlers
+	Risk      string     // Severity summary, e.g. "2 high, 1 medium"
+	RiskRank  int        // Worst severity as a sortable number
+}
+
+// htmlLink is one deeplink row of a report page.
+type htmlLink struct {
+	URI       string        // Deeplink as declared
+	Component string        // Component handling it
+	QR        template.HTML // Inline SVG QR code under -qr, empty otherwise
+}
+
+// unsafeFileChars are replaced when a package name becomes a file name.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// htmlReportTemplate renders the page of a single APK.
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Report.Package}} · Deeeeper</title>
+<style>body{font-family:sans-serif;margin:2em}table{border-collapse:collapse}td,th{border:1px solid #ccc;padding:.3em .6em;text-align:left;vertical-align:top}.high,.critical{color:#b00}.medium{color:#b60}svg{display:block;margin-top:.3em}</style>
+</head><body>
+<p><a href="index.html">← all apps</a></p>
+<h1>{{.Report.Package}}</h1>
+<p>{{.Report.Target}}{{if .Report.SHA256}}<br><small>sha256 {{.Report.SHA256}}</small>{{end}}</p>
+<h2>Findings</h2>
+<table><tr><th>Severity</th><th>Finding</th><th>Evidence</th></tr>
+{{range .Report.Findings}}<tr><td class="{{.Severity}}">{{.Severity}}</td><td>{{.Title}}</td><td>{{.Evidence}}{{if .Snippet}}<pre>{{.Snippet}}</pre>{{end}}</td></tr>
+{{end}}</table>
+<h2>Deeplinks</h2>
+<table><tr><th>URI</th><th>Component</th></tr>
+{{range .Deeplinks}}<tr><td>{{.URI}}{{.QR}}</td><td>{{.Component}}</td></tr>
+{{end}}</table>
+</body></html>
+`))
+
+// htmlIndexTemplate renders the landing page of a batch, sortable by any column.
+var htmlIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Deeeeper batch report</title>
+<style>body{font-family:sans-serif;margin:2em}table{border-collapse:collapse}td,th{border:1px solid #ccc;padding:.3em .6em;text-align:left}th{cursor:pointer;background:#f4f4f4}</style>
+</head><body>
+<h1>Deeeeper batch report</h1>
+<p>{{len .}} apps analyzed. Click a column header to sort.</p>
+<table id="apps"><thead><tr><th>Package</th><th>Target</th><th data-numeric>Deeplinks</th><th data-numeric>Risk</th></tr></thead><tbody>
+{{range .}}<tr><td><a href="{{.File}}">{{.Report.Package}}</a></td><td>{{.Report.Target}}</td><td data-sort="{{len .Deeplinks}}">{{len .Deeplinks}}</td><td data-sort="{{.RiskRank}}">{{.Risk}}</td></tr>
+{{end}}</tbody></table>
+<script>
+document.querySelectorAll('#apps th').forEach(function (th, column) {
+  var ascending = false;
+  th.addEventListener('click', function () {
+    ascending = !ascending;
+    var body = document.querySelector('#apps tbody');
+    var rows = Array.from(body.rows);
+    rows.sort(function (a, b) {
+      var x = a.cells[column].dataset.sort || a.cells[column].textContent;
+      var y = b.cells[column].dataset.sort || b.cells[column].textContent;
+      var order = th.hasAttribute('data-numeric') ? x - y : x.localeCompare(y);
+      return ascending ? order : -order;
+    });
+    rows.forEach(function (row) { body.appendChild(row); });
+  });
+});
+</script>
+</body></html>
+`))
+
+// writeHTMLSite writes one HTML page per report into dir plus an index.html
+// linking them with their package, deeplink count and risk summary.
+func writeHTMLSite(dir string, reports []*report) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	var pages []htmlPage
+	for i, r := range reports {
+		page := htmlPage{
+			File:      fmt.Sprintf("%03d-%s.html", i+1, unsafeFileChars.ReplaceAllString(r.Package, "_")),
+			Report:    r,
+			Deeplinks: htmlLinks(r.TestCases),
+		}
+		page.Risk, page.RiskRank = riskCounts(r.Findings)
+		if err := writeTemplate(filepath.Join(dir, page.File), htmlReportTemplate, page); err != nil {
+			return err
+		}
+		pages = append(pages, page)
+	}
+	return writeTemplate(filepath.Join(dir, "index.html"), htmlIndexTemplate, pages)
+}
+
+// htmlLinks lists the distinct deeplinks of a report, with inline QR codes
+// under -qr for those that are scannable.
+func htmlLinks(cases []testCase) []htmlLink {
+	seen := make(map[string]bool)
+	var links []htmlLink
+	for _, c := range cases {
+		if c.URI == "" || isProviderCase(c) || seen[c.URI+c.Component] {
+			continue // Mime-type-only and provider cases have no link
+		}
+		seen[c.URI+c.Component] = true
+		link := htmlLink{URI: c.URI, Component: c.Component}
+		if opts.QR {
+			link.QR = qrImage(c.URI)
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// qrImage returns the inline SVG QR code of a scannable deeplink, empty when
+// the deeplink isn't worth scanning or too long to encode.
+func qrImage(uri string) template.HTML {
+	if ok, _ := scannableURI(uri); !ok {
+		return ""
+	}
+	code, err := encodeQR(uri)
+	if err != nil {
+		return ""
+	}
+	return template.HTML(code.svg())
+}
+
+// riskCounts summarizes findings by severity, worst first, and returns the
+// worst severity's rank for sorting.
+func riskCounts(findings []finding) (string, int) {
+	counts := make(map[string]int)
+	worst := -1
+	for _, f := range findings {
+		counts[f.Severity]++
+		worst = max(worst, severityRank[f.Severity])
+	}
+	var parts []string
+	for _, severity := range severityOrder {
+		if counts[severity] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[severity], severity))
+		}
+	}
+	if len(parts) == 0 {
+		return "no findings", worst
+	}
+	return strings.Join(parts, ", "), worst
+}
+
+// writeTemplate renders a template into a new file.
+func writeTemplate(path string, tmpl *template.Template, data any) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(file, data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
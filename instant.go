@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"     // Violation details
+	"io"      // Output destination
+	"slices"  // Scheme checks
+	"strconv" // Sandbox version
+	"strings" // Hidden component list
+
+	"Deeeeper/Deeeeper/deeeeper" // Scheme and host normalization
+	"github.com/fatih/color"     // Colorized output in terminal
+)
+
+// instantConstraints names the instant app and sandbox version 2 rules the
+// manifest is checked against, keyed by constraint ID.
+var instantConstraints = map[string]string{
+	"sandbox-version":  `instant-enabled modules must set android:targetSandboxVersion="2"`,
+	"https-only":       "instant apps are launched by https URL only; other schemes never reach the instant app",
+	"verified-links":   `instant app URL filters must be verified App Links (android:autoVerify="true")`,
+	"entry-point":      "an instant app needs an activity with a verified https VIEW+BROWSABLE filter to launch from",
+	"no-cleartext":     "instant apps only support HTTPS traffic",
+	"no-wallpapers":    "instant apps can't provide live wallpapers",
+	"no-widgets":       "instant apps can't provide launcher widgets",
+	"no-shared-userid": "sandbox version 2 doesn't permit shared user IDs, so the app fails to install",
+}
+
+// instantInfo is what the instant app sandbox changes for an app.
+type instantInfo struct {
+	SandboxVersion int                `json:"target_sandbox_version,omitempty"` // android:targetSandboxVersion, 0 when absent
+	Instant        bool               `json:"instant_enabled"`                  // dist:module dist:instant="true"
+	Hidden         []string           `json:"hidden_when_instant,omitempty"`    // Exported components other apps can't reach while it runs instant
+	Violations     []instantViolation `json:"violations,omitempty"`             // Constraints the manifest breaks
+}
+
+// instantViolation is one broken constraint.
+type instantViolation struct {
+	Constraint string `json:"constraint"` // Constraint ID, see instantConstraints
+	Kind       string `json:"type"`       // Component kind, or application
+	Component  string `json:"component"`  // Component name, or the package
+	Detail     string `json:"detail"`     // What in the manifest breaks it
+}
+
+// collectInstant checks an app that opts into the instant app sandbox, with
+// dist:instant or android:targetSandboxVersion="2", against instantConstraints.
+// Filter and component rules only apply to instant-enabled modules: an
+// installed app on sandbox version 2 keeps its usual reach. It returns nil for
+// apps that opt into neither.
+func collectInstant(manifest Manifest) *instantInfo {
+	info := &instantInfo{Instant: isTrue(manifest.DistModule.Instant)}
+	info.SandboxVersion, _ = strconv.Atoi(manifest.SandboxVersion)
+	if !info.Instant && info.SandboxVersion < 2 {
+		return nil
+	}
+	violate := func(constraint, kind, component, detail string) {
+		info.Violations = append(info.Violations, instantViolation{constraint, kind, component, detail})
+	}
+	if manifest.SharedUserID != "" && info.SandboxVersion >= 2 {
+		violate("no-shared-userid", "application", manifest.Package, fmt.Sprintf("android:sharedUserId=%q", manifest.SharedUserID))
+	}
+	if !info.Instant {
+		return info
+	}
+	switch {
+	case manifest.SandboxVersion == "":
+		violate("sandbox-version", "application", manifest.Package, "no android:targetSandboxVersion")
+	case info.SandboxVersion < 2:
+		violate("sandbox-version", "application", manifest.Package, fmt.Sprintf("android:targetSandboxVersion=%q", manifest.SandboxVersion))
+	}
+	if isTrue(manifest.Application.Cleartext) {
+		violate("no-cleartext", "application", manifest.Package, `android:usesCleartextTraffic="true"`)
+	}
+	appVerify := isTrue(manifest.Application.AutoVerify)
+	entryPoint := false
+	for _, group := range componentGroups(manifest) {
+		for _, component := range group.Components {
+			exported, _ := isExported(component, group.Kind)
+			switch group.Kind {
+			case "activity", "alias":
+				for _, filter := range component.Filters {
+					if !isBrowsable(filter) {
+						continue
+					}
+					verified := appVerify || isTrue(filter.AutoVerify)
+					https := false
+					for _, scheme := range filterSchemes(filter) {
+						switch scheme {
+						case "https":
+							https = true
+						case "http":
+						default:
+							violate("https-only", group.Kind, component.Name, fmt.Sprintf("BROWSABLE filter with scheme %q", scheme))
+						}
+					}
+					if https && !verified {
+						violate("verified-links", group.Kind, component.Name, "https BROWSABLE filter without android:autoVerify")
+					}
+					entryPoint = entryPoint || (https && verified)
+				}
+				if exported && !slices.ContainsFunc(component.Filters, isBrowsable) {
+					info.Hidden = append(info.Hidden, component.Name)
+				}
+			case "service":
+				if componentHasAction(component, "android.service.wallpaper.WallpaperService") {
+					violate("no-wallpapers", group.Kind, component.Name, "WallpaperService action")
+				}
+				if exported {
+					info.Hidden = append(info.Hidden, component.Name)
+				}
+			case "receiver":
+				if componentHasAction(component, "android.appwidget.action.APPWIDGET_UPDATE") {
+					violate("no-widgets", group.Kind, component.Name, "APPWIDGET_UPDATE receiver")
+				}
+				if exported {
+					info.Hidden = append(info.Hidden, component.Name)
+				}
+			case "provider":
+				if exported {
+					info.Hidden = append(info.Hidden, component.Name)
+				}
+			}
+		}
+	}
+	if !entryPoint {
+		violate("entry-point", "application", manifest.Package, "no verified https VIEW+BROWSABLE filter")
+	}
+	return info
+}
+
+// filterSchemes lists the distinct normalized schemes of a filter's <data> elements.
+func filterSchemes(filter IntentFilter) []string {
+	var schemes []string
+	for _, data := range filter.Data {
+		if data.Scheme != "" {
+			schemes = appendUnique(schemes, deeeeper.NormalizeScheme(data.Scheme))
+		}
+	}
+	return schemes
+}
+
+// instantFindings raises a finding per violated constraint, naming it.
+func instantFindings(pkg string, info *instantInfo) []finding {
+	if info == nil {
+		return nil
+	}
+	var findings []finding
+	for _, v := range info.Violations {
+		evidence := fmt.Sprintf("%s: %s (%s)", v.Constraint, v.Detail, instantConstraints[v.Constraint])
+		f := newFinding(pkg, "instant-app-constraint", v.Kind, v.Component, nil, evidence)
+		f.Fingerprint = fingerprint(pkg, "instant-app-constraint", v.Kind, v.Component, v.Constraint, v.Detail)
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// printInstant shows the sandbox the app opts into, the exported components
+// it hides while instant and the constraints it breaks.
+func printInstant(w io.Writer, info *instantInfo) {
+	printSection(w, "\nInstant app sandbox:")
+	switch {
+	case info.Instant && info.SandboxVersion >= 2:
+		fmt.Fprintln(w, "  instant-enabled module on sandbox version 2")
+	case info.Instant:
+		fmt.Fprintln(w, "  instant-enabled module")
+	default:
+		fmt.Fprintln(w, "  installed app on sandbox version 2: cleartext traffic is off unless a network security config allows it")
+	}
+	if len(info.Hidden) > 0 {
+		fmt.Fprintf(w, "  exported, but not reachable by other apps while running instant: %s\n", strings.Join(info.Hidden, ", "))
+	}
+	red := color.New(color.FgRed).SprintFunc()
+	for _, v := range info.Violations {
+		fmt.Fprintf(w, "  %s %s: %s; %s\n", red(v.Constraint), v.Component, v.Detail, instantConstraints[v.Constraint])
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"slices"  // Result comparison
+	"strings" // Output checks
+	"testing" // Test harness
+)
+
+// instantModule builds an instant-enabled manifest of org.example.instant with
+// the given manifest and application attributes and components. Its .Entry
+// activity is a valid entry point, so only the rule under test is broken.
+func instantModule(manifestAttrs, applicationAttrs, components string) string {
+	return `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" xmlns:dist="http://schemas.android.com/apk/distribution" package="org.example.instant"` + manifestAttrs + `>
+    <dist:module dist:instant="true"/>
+    <application` + applicationAttrs + `>
+        <activity android:name=".Entry" android:exported="true">
+            <intent-filter android:autoVerify="true">
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="https" android:host="instant.example.com"/>
+            </intent-filter>
+        </activity>
+` + components + `    </application>
+</manifest>
+`
+}
+
+// violationList renders violations as "constraint component: detail" lines.
+func violationList(info *instantInfo) []string {
+	var list []string
+	for _, v := range info.Violations {
+		list = append(list, v.Constraint+" "+v.Component+": "+v.Detail)
+	}
+	return list
+}
+
+func TestCollectInstantViolating(t *testing.T) {
+	setOpts(t, textOptions())
+	info := collectInstant(parseTestManifest(t, selftestInstantViolating))
+	if info == nil || !info.Instant || info.SandboxVersion != 0 {
+		t.Fatalf("info = %+v, want an instant-enabled module without sandbox version", info)
+	}
+	want := []string{
+		"sandbox-version org.deeeeper.instant: no android:targetSandboxVersion",
+		`https-only .Launch: BROWSABLE filter with scheme "instant"`,
+		"verified-links .Launch: https BROWSABLE filter without android:autoVerify",
+		"entry-point org.deeeeper.instant: no verified https VIEW+BROWSABLE filter",
+	}
+	if got := violationList(info); !slices.Equal(got, want) {
+		t.Errorf("violations:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCollectInstantConforming(t *testing.T) {
+	setOpts(t, textOptions())
+	info := collectInstant(parseTestManifest(t, selftestInstantConforming))
+	if info == nil || !info.Instant || info.SandboxVersion != 2 {
+		t.Fatalf("info = %+v, want an instant-enabled module on sandbox version 2", info)
+	}
+	if len(info.Violations) != 0 {
+		t.Errorf("violations = %q, want none", violationList(info))
+	}
+	if !slices.Equal(info.Hidden, []string{".Sync"}) {
+		t.Errorf("hidden = %q, want the exported service", info.Hidden)
+	}
+}
+
+func TestCollectInstantRules(t *testing.T) {
+	sandbox := ` android:targetSandboxVersion="2"`
+	for _, tc := range []struct {
+		name     string
+		manifest string
+		want     []string // Violations, nil for none
+	}{
+		{
+			name:     "sandbox version 1",
+			manifest: instantModule(` android:targetSandboxVersion="1"`, "", ""),
+			want:     []string{`sandbox-version org.example.instant: android:targetSandboxVersion="1"`},
+		},
+		{
+			name:     "cleartext",
+			manifest: instantModule(sandbox, ` android:usesCleartextTraffic="true"`, ""),
+			want:     []string{`no-cleartext org.example.instant: android:usesCleartextTraffic="true"`},
+		},
+		{
+			name: "live wallpaper",
+			manifest: instantModule(sandbox, "", `        <service android:name=".Wallpaper" android:exported="true">
+            <intent-filter><action android:name="android.service.wallpaper.WallpaperService"/></intent-filter>
+        </service>
+`),
+			want: []string{"no-wallpapers .Wallpaper: WallpaperService action"},
+		},
+		{
+			name: "widget",
+			manifest: instantModule(sandbox, "", `        <receiver android:name=".Widget" android:exported="true">
+            <intent-filter><action android:name="android.appwidget.action.APPWIDGET_UPDATE"/></intent-filter>
+        </receiver>
+`),
+			want: []string{"no-widgets .Widget: APPWIDGET_UPDATE receiver"},
+		},
+		{
+			name: "http alongside https",
+			manifest: instantModule(sandbox, "", `        <activity android:name=".Web" android:exported="true">
+            <intent-filter android:autoVerify="true">
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="http"/>
+                <data android:scheme="HTTPS" android:host="instant.example.com"/>
+            </intent-filter>
+        </activity>
+`),
+		},
+		{
+			name: "verified at application level",
+			manifest: strings.Replace(instantModule(sandbox, ` android:autoVerify="true"`, ""),
+				`<intent-filter android:autoVerify="true">`, "<intent-filter>", 1),
+		},
+		{
+			name:     "shared user id on sandbox version 2",
+			manifest: instantModule(sandbox+` android:sharedUserId="org.example.shared"`, "", ""),
+			want:     []string{`no-shared-userid org.example.instant: android:sharedUserId="org.example.shared"`},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			setOpts(t, textOptions())
+			info := collectInstant(parseTestManifest(t, tc.manifest))
+			if info == nil {
+				t.Fatal("instant-enabled module not detected")
+			}
+			if got := violationList(info); !slices.Equal(got, tc.want) {
+				t.Errorf("violations = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestCollectInstantInstalledApp checks component rules are left alone for an
+// installed app on sandbox version 2, and that apps opting into neither have
+// nothing to report.
+func TestCollectInstantInstalledApp(t *testing.T) {
+	setOpts(t, textOptions())
+	installed := strings.Replace(selftestInstantViolating, `    <dist:module dist:instant="true"/>
+`, "", 1)
+	if info := collectInstant(parseTestManifest(t, installed)); info != nil {
+		t.Errorf("app without dist:instant or sandbox version 2: info = %+v, want nil", info)
+	}
+	sandboxed := strings.Replace(installed, `package="org.deeeeper.instant"`, `package="org.deeeeper.instant" android:targetSandboxVersion="2"`, 1)
+	info := collectInstant(parseTestManifest(t, sandboxed))
+	if info == nil || info.Instant || info.SandboxVersion != 2 || len(info.Violations) != 0 {
+		t.Errorf("installed app on sandbox version 2: info = %+v, want no violations", info)
+	}
+}
+
+// TestInstantReport checks each violation is rendered in the text report and
+// raised as a finding with the constraint named.
+func TestInstantReport(t *testing.T) {
+	setOpts(t, textOptions())
+	result, text := renderFixture(t, selftestInstantViolating, "<resources/>")
+	if !strings.Contains(text, "Instant app sandbox:\n  instant-enabled module\n") {
+		t.Errorf("report lacks the instant app section:\n%s", text)
+	}
+	if !strings.Contains(text, `  https-only .Launch: BROWSABLE filter with scheme "instant"; `+instantConstraints["https-only"]+"\n") {
+		t.Errorf("report lacks the https-only violation:\n%s", text)
+	}
+	var constraints []string
+	for _, f := range result.Findings {
+		if f.Rule == "instant-app-constraint" {
+			constraint, _, _ := strings.Cut(f.Evidence, ":")
+			constraints = append(constraints, constraint)
+		}
+	}
+	if want := []string{"sandbox-version", "https-only", "verified-links", "entry-point"}; !slices.Equal(constraints, want) {
+		t.Errorf("instant-app-constraint findings name %q, want %q", constraints, want)
+	}
+
+	result, _ = renderFixture(t, selftestInstantConforming, "<resources/>")
+	for _, f := range result.Findings {
+		if f.Rule == "instant-app-constraint" {
+			t.Errorf("conforming module raised %+v", f)
+		}
+	}
+}
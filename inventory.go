@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"     // Output formatting
+	"io"      // Output destination
+	"sort"    // Stable, diffable ordering
+	"strings" // Handler lists
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// inventoryEntry is one distinct deeplink of the app with everything declaring it.
+type inventoryEntry struct {
+	URI      string             `json:"uri"`      // Deeplink or content:// URI as constructed
+	Handlers []inventoryHandler `json:"handlers"` // Exported components declaring the URI
+}
+
+// inventoryHandler names a component declaring an inventory URI.
+type inventoryHandler struct {
+	Type string `json:"type"` // Component kind (activity, alias, service, receiver, provider)
+	Name string `json:"name"` // Component name as declared
+}
+
+// collectInventory flattens the deeplinks of every exported component, content
+// provider authorities included, into one list sorted by URI with each URI
+// listed once. The order is stable so inventories of two releases diff cleanly.
+func collectInventory(manifest Manifest, folder string) []inventoryEntry {
+	index := make(map[string]map[inventoryHandler]bool)
+	add := func(uri string, handler inventoryHandler) {
+		if index[uri] == nil {
+			index[uri] = make(map[inventoryHandler]bool)
+		}
+		index[uri][handler] = true
+	}
+	for _, group := range componentGroups(manifest) {
+		for _, component := range group.Components {
+			if exported, _ := isExported(component, group.Kind); !exported {
+				continue
+			}
+			handler := inventoryHandler{Type: group.Kind, Name: component.Name}
+			for _, authority := range providerAuthorities(component) {
+				add("content://"+authority, handler)
+			}
+			for _, uri := range componentURIs(folder, component) {
+				add(uri, handler)
+			}
+		}
+	}
+
+	inventory := make([]inventoryEntry, 0, len(index))
+	for _, uri := range sortedKeys(index) {
+		entry := inventoryEntry{URI: uri}
+		for handler := range index[uri] {
+			entry.Handlers = append(entry.Handlers, handler)
+		}
+		sort.Slice(entry.Handlers, func(i, j int) bool {
+			if entry.Handlers[i].Type != entry.Handlers[j].Type {
+				return entry.Handlers[i].Type < entry.Handlers[j].Type
+			}
+			return entry.Handlers[i].Name < entry.Handlers[j].Name
+		})
+		inventory = append(inventory, entry)
+	}
+	return inventory
+}
+
+// printInventory renders the inventory, one URI per line followed by its handlers.
+func printInventory(w io.Writer, inventory []inventoryEntry) {
+	green := color.New(color.FgGreen).SprintFunc()
+	printSection(w, "\nDeeplink inventory:")
+	for _, entry := range inventory {
+		handlers := make([]string, len(entry.Handlers))
+		for i, handler := range entry.Handlers {
+			handlers[i] = handler.Type + " " + handler.Name
+		}
+		fmt.Fprintf(w, "  %s [%s]\n", green(entry.URI), strings.Join(handlers, ", "))
+	}
+}
+
+// writeURIs writes every deeplink URI of the exported activities, aliases,
+// services and receivers for -uris, one per line and each once across all
+// targets, in the order they were found. Nothing else goes to stdout, so the
+// list pipes straight into other tools; an empty list is no error.
+func writeURIs(w io.Writer, reports []*report) error {
+	seen := make(map[string]bool)
+	for _, r := range reports {
+		for _, c := range r.Components {
+			if !c.Exported || c.Type == "provider" {
+				continue
+			}
+			for _, uri := range c.URIs {
+				if seen[uri] {
+					continue
+				}
+				seen[uri] = true
+				if _, err := fmt.Fprintln(w, uri); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"crypto/sha256" // Input hashes
+	"encoding/hex"  // Hash encoding
+	"encoding/json" // Report serialization
+	"flag"          // Recording the flags used
+	"io"            // Hashing and output
+	"os"            // Reading inputs
+	"time"          // Report timestamp
+)
+
+// jsonDocument is the top-level -format json document.
+type jsonDocument struct {
+	Meta    runMeta         `json:"meta"`              // How the report was produced
+	Reports []*report       `json:"reports"`           // One report per analyzed APK or folder
+	Catalog *catalogSummary `json:"catalog,omitempty"` // Cross-app summary under -catalog
+}
+
+// runMeta makes a saved report self-describing: which tool, toolchain, input
+// and options produced it.
+type runMeta struct {
+	ToolVersion    string            `json:"tool_version"`
+	APKToolVersion string            `json:"apktool_version,omitempty"`
+	Timestamp      string            `json:"timestamp"`
+	Input          string            `json:"input"`
+	InputSHA256    string            `json:"input_sha256,omitempty"`
+	Flags          map[string]string `json:"flags"`
+}
+
+// secretFlags are flags whose values may embed credentials and are redacted in metadata.
+var secretFlags = map[string]bool{"notify-webhook": true, "webhook": true, "post-url": true, "post-header": true}
+
+// writeJSON writes the reports with a metadata header as indented JSON.
+func writeJSON(w io.Writer, reports []*report) error {
+	document := jsonDocument{Meta: collectMeta(), Reports: reports}
+	if document.Reports == nil {
+		document.Reports = []*report{}
+	}
+	if opts.Catalog {
+		summary := buildCatalog(reports)
+		document.Catalog = &summary
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
+
+// collectMeta gathers the run metadata: versions, time, input and explicitly set flags.
+func collectMeta() runMeta {
+	meta := runMeta{
+		ToolVersion: toolVersion,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Input:       opts.APKPath,
+		Flags:       make(map[string]string),
+	}
+	if meta.Input == "" {
+		meta.Input = opts.Folder
+	} else {
+		meta.InputSHA256, _ = fileSHA256(opts.APKPath)
+		if !opts.Quick { // Asking apktool starts a JVM
+			meta.APKToolVersion, _ = apktoolVersion()
+		}
+	}
+	flag.Visit(func(f *flag.Flag) {
+		meta.Flags[f.Name] = f.Value.String()
+		if secretFlags[f.Name] {
+			meta.Flags[f.Name] = redactURL(f.Value.String())
+		}
+	})
+	return meta
+}
+
+// fileSHA256 returns the hex SHA-256 of a file's contents.
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
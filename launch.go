@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"     // Result listing
+	"io"      // Output destination
+	"strings" // am output parsing
+	"time"    // Pause between intents
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// launchInterval is the pause between two intents fired by -launch, long
+// enough for a started activity to come up before the next one replaces it.
+// Tests shorten it.
+var launchInterval = 2 * time.Second
+
+// launchTestCases fires the intent of every test case at the device and
+// records each outcome in the case's Result. Provider cases and cases without
+// a command are left alone. Nothing is fired unless the package is installed.
+func launchTestCases(device *adbClient, pkg string, cases []testCase) {
+	var pending []int
+	for i, c := range cases {
+		if c.am != "" && c.ADB != "" {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+	if err := device.requirePackage(pkg); err != nil {
+		for _, i := range pending {
+			cases[i].Result = err.Error()
+		}
+		return
+	}
+	for n, i := range pending {
+		if n > 0 {
+			device.wait(launchInterval)
+		}
+		output, err := device.run(intentArgs(cases[i])...)
+		if err != nil { // Under -dry-run this marks the case as not executed
+			cases[i].Result = err.Error()
+			continue
+		}
+		cases[i].Result = amResult(output)
+	}
+}
+
+// amResult reads the outcome of an am command from its output. am reports
+// unresolved intents and missing components on stdout and still exits 0.
+func amResult(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, "Error") {
+			return line
+		}
+	}
+	return "ok"
+}
+
+// printLaunchResults lists the outcome of every fired intent.
+func printLaunchResults(w io.Writer, cases []testCase) {
+	printSection(w, "\nLaunch results:")
+	for _, c := range cases {
+		if c.Result == "" {
+			continue
+		}
+		target := c.URI
+		if target == "" {
+			target = c.MimeType
+		}
+		line := fmt.Sprintf("%s %s %s -> %s", target, c.Action, c.Component, c.Result)
+		switch {
+		case c.Result == "ok":
+			color.New(color.FgGreen).Fprintln(w, line)
+		case c.Result == errDryRun.Error():
+			fmt.Fprintln(w, line)
+		default:
+			color.New(color.FgRed).Fprintln(w, line)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"   // Captured text report
+	"io"      // Silencing progress and warnings
+	"os"      // Exit code of the test run
+	"testing" // Test harness
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// TestMain keeps the warnings and progress chatter of the code under test out
+// of the test output and disables colors, so rendered text compares plainly.
+func TestMain(m *testing.M) {
+	color.Output = io.Discard
+	color.NoColor = true
+	os.Exit(m.Run())
+}
+
+// analyzeFixture writes a decompiled target with the manifest and strings.xml
+// given, the selftest's bools and integers alongside, and analyzes it.
+func analyzeFixture(t testing.TB, manifest, stringsXML string) *report {
+	t.Helper()
+	result, _ := renderFixture(t, manifest, stringsXML)
+	return result
+}
+
+// renderFixture analyzes a fixture like analyzeFixture and also returns the
+// text report.
+func renderFixture(t testing.TB, manifest, stringsXML string) (*report, string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := writeSelftestTarget(dir, manifest, stringsXML); err != nil {
+		t.Fatal(err)
+	}
+	var text bytes.Buffer
+	result, err := analyzeFolder(&text, io.Discard, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result, text.String()
+}
+
+// setOpts replaces the options for the duration of a test.
+func setOpts(t testing.TB, o options) {
+	t.Helper()
+	saved := opts
+	opts = o
+	t.Cleanup(func() { opts = saved })
+}
+
+// textOptions are the flag defaults the text report depends on.
+func textOptions() options {
+	return options{Format: "text", Sort: "manifest"}
+}
+
+// parseTestManifest parses a manifest fixture without resolving resources.
+func parseTestManifest(t testing.TB, manifest string) Manifest {
+	t.Helper()
+	parsed, _, err := parseManifest([]byte(manifest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
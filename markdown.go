@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"     // Document formatting
+	"os"      // Output file
+	"strings" // Document assembly
+	"time"    // Generation timestamp
+)
+
+// markdownSections are the component types of a Markdown report, in order.
+var markdownSections = []struct{ kind, title string }{
+	{"activity", "Activities"},
+	{"alias", "Activity aliases"},
+	{"service", "Services"},
+	{"receiver", "Receivers"},
+	{"provider", "Providers"},
+}
+
+// writeMarkdown writes the reports as one Markdown document for triage notes:
+// per target a table of the exported components of each type and a fenced
+// block with every deeplink URI.
+func writeMarkdown(path string, reports []*report) error {
+	var b strings.Builder
+	input := opts.APKPath
+	if input == "" {
+		input = opts.Folder
+	}
+	fmt.Fprintf(&b, "# Deeeeper report: %s\n\n", markdownEscape(input))
+	fmt.Fprintf(&b, "Generated %s by Deeeeper %s.\n", time.Now().UTC().Format(time.RFC3339), toolVersion)
+	for _, r := range reports {
+		writeMarkdownReport(&b, r)
+	}
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// writeMarkdownReport renders one target.
+func writeMarkdownReport(b *strings.Builder, r *report) {
+	fmt.Fprintf(b, "\n## %s\n\n", markdownEscape(r.Package))
+	fmt.Fprintf(b, "Target: `%s`\n", r.Target)
+	for _, section := range markdownSections {
+		var rows []componentInfo
+		for _, c := range r.Components {
+			if c.Type == section.kind && c.Exported {
+				rows = append(rows, c)
+			}
+		}
+		fmt.Fprintf(b, "\n### %s\n\n", section.title)
+		if len(rows) == 0 {
+			b.WriteString("No exported components.\n")
+			continue
+		}
+		b.WriteString("| Component | Actions | Deeplinks |\n|---|---|---|\n")
+		for _, c := range rows {
+			uris := c.URIs
+			if section.kind == "provider" {
+				uris = nil
+				for _, authority := range c.Authorities {
+					uris = append(uris, "content://"+authority)
+				}
+			}
+			fmt.Fprintf(b, "| %s | %s | %s |\n", markdownCell([]string{c.Name}), markdownCell(c.Actions), markdownCell(uris))
+		}
+	}
+
+	var uris []string
+	for _, c := range r.Components {
+		if c.Exported && c.Type != "provider" {
+			for _, uri := range c.URIs {
+				uris = appendUnique(uris, uri)
+			}
+		}
+	}
+	b.WriteString("\n### Deeplink URIs\n\n")
+	if len(uris) == 0 {
+		b.WriteString("No deeplinks.\n")
+		return
+	}
+	fence := codeFence(strings.Join(uris, "\n"))
+	fmt.Fprintf(b, "%s\n%s\n%s\n", fence, strings.Join(uris, "\n"), fence)
+}
+
+// markdownCell renders values as comma-separated code spans in a table cell,
+// or "-" when there are none. Pipes are escaped, since they would split the
+// cell even inside code spans, and newlines would end the row.
+func markdownCell(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	spans := make([]string, len(values))
+	for i, value := range values {
+		spans[i] = codeSpan(value)
+	}
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(strings.Join(spans, ", "))
+}
+
+// codeSpan wraps text in a code span delimited by more backticks than any run
+// inside it, padded with spaces when the text contains backticks.
+func codeSpan(text string) string {
+	if !strings.Contains(text, "`") {
+		return "`" + text + "`"
+	}
+	longest := 0
+	for _, run := range strings.FieldsFunc(text, func(r rune) bool { return r != '`' }) {
+		longest = max(longest, len(run))
+	}
+	delimiter := strings.Repeat("`", longest+1)
+	return delimiter + " " + text + " " + delimiter
+}
+
+// markdownEscape escapes the characters Markdown would read as emphasis or markup.
+func markdownEscape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "<", `\<`, "[", `\[`).Replace(text)
+}
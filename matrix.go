@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"          // Table buffer
+	"fmt"            // Row formatting
+	"strings"        // Joining categories and splitting rows
+	"text/tabwriter" // Column alignment
+)
+
+// filterRow is one expanded scheme/host/port/path combination of an intent filter.
+type filterRow struct {
+	Filter     int    // 1-based position of the filter in the component
+	Scheme     string // URI scheme, "-" when absent
+	Host       string // Host, "-" when absent
+	Port       string // Port, "-" when absent
+	Path       string // "/p" exact, "/p*" prefix, "/p (pattern)" pattern, "ssp x" scheme-specific part, "-" when absent
+	Categories string // Short category names, e.g. "DEFAULT,BROWSABLE"
+	AutoVerify string // Filter-level android:autoVerify
+}
+
+// expandFilter lists every URI shape a filter matches. Android treats the
+// <data> elements of one filter as a single pool, so every scheme combines with
+// every authority and every path, not just those declared on the same element.
+func expandFilter(index int, filter IntentFilter) []filterRow {
+	type authority struct{ host, port string }
+	var schemes, paths []string
+	var authorities []authority
+	for _, data := range filter.Data {
+		if data.Scheme != "" {
+			schemes = appendUnique(schemes, data.Scheme)
+		}
+		if data.Host != "" || data.Port != "" {
+			a := authority{orDash(data.Host), orDash(data.Port)}
+			found := false
+			for _, existing := range authorities {
+				found = found || existing == a
+			}
+			if !found {
+				authorities = append(authorities, a)
+			}
+		}
+		switch {
+		case data.Path != "":
+			paths = appendUnique(paths, data.Path)
+		case data.PathPrefix != "":
+			paths = appendUnique(paths, data.PathPrefix+"*")
+		case data.PathPattern != "":
+			paths = appendUnique(paths, data.PathPattern+" (pattern)")
+		case data.Ssp != "" || data.SspPrefix != "":
+			paths = appendUnique(paths, "ssp "+data.SchemeSpecificPart())
+		case data.SspPattern != "":
+			paths = appendUnique(paths, "ssp "+data.SspPattern+" (pattern)")
+		}
+	}
+	if len(schemes) == 0 {
+		return nil // Without a scheme the filter matches content by type, not URI
+	}
+	if len(authorities) == 0 {
+		authorities = []authority{{"-", "-"}}
+	}
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+
+	var categories []string
+	for _, category := range filter.Categories {
+		categories = append(categories, strings.TrimPrefix(category.Name, "android.intent.category."))
+	}
+	autoVerify := "-"
+	if isTrue(filter.AutoVerify) {
+		autoVerify = "true"
+	}
+
+	var rows []filterRow
+	for _, scheme := range schemes {
+		for _, a := range authorities {
+			for _, path := range paths {
+				rows = append(rows, filterRow{index, scheme, a.host, a.port, path, orDash(strings.Join(categories, ",")), autoVerify})
+			}
+		}
+	}
+	return rows
+}
+
+// matrixTable renders the expanded filters of a component as aligned text rows,
+// header first. It returns nil when no filter declares a URI.
+func matrixTable(filters []IntentFilter) []string {
+	var rows []filterRow
+	for i, filter := range filters {
+		if opts.Browsable && !isBrowsable(filter) {
+			continue // Skipped, but later filters keep their manifest number
+		}
+		rows = append(rows, expandFilter(i+1, filter)...)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	var table bytes.Buffer
+	tw := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "filter\tscheme\thost\tport\tpath\tcategories\tautoVerify")
+	for _, row := range rows {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", row.Filter, row.Scheme, row.Host, row.Port, row.Path, row.Categories, row.AutoVerify)
+	}
+	tw.Flush()
+	return strings.Split(strings.TrimRight(table.String(), "\n"), "\n")
+}
+
+// appendUnique appends value unless it is already present.
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// orDash returns value, or "-" when it is empty.
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"         // Decoding the raw manifest
+	"encoding/xml"  // Namespace-aware rule detection
+	"fmt"           // Note formatting
+	"io"            // Output destination
+	"os"            // Detecting apktool output
+	"path/filepath" // apktool.yml location
+	"slices"        // Dropping removed elements
+	"strings"       // Attribute lists
+)
+
+// removingNodes are the tools:node values that keep an element out of the
+// merged manifest. removeAll drops the marker element as well as its siblings
+// in library manifests, which a single manifest can't show.
+var removingNodes = map[string]bool{"remove": true, "removeAll": true}
+
+// toolsNamespace is the namespace URI of the manifest merger's tools: attributes.
+const toolsNamespace = "http://schemas.android.com/tools"
+
+// mergeRuleAttributes are the tools: attributes that steer the manifest merger.
+var mergeRuleAttributes = map[string]bool{"node": true, "replace": true, "remove": true}
+
+// applyMergeRules applies the manifest merger's tools: rules that change what
+// the merged manifest contains: components, intent filters, permission
+// declarations and permission requests marked tools:node="remove" are dropped.
+// tools:replace and tools:node="replace" keep the element and are noted, since
+// the values shown win over those of library manifests. It returns one note
+// per rule applied, in manifest order.
+func applyMergeRules(manifest *Manifest) []string {
+	var notes []string
+	manifest.Permissions = slices.DeleteFunc(manifest.Permissions, func(p PermissionDecl) bool {
+		if node := p.Attributes["tools:node"]; removingNodes[node] {
+			notes = append(notes, fmt.Sprintf("permission %s is not declared (tools:node=%q)", p.Name, node))
+			return true
+		}
+		return false
+	})
+	manifest.UsesPermissions = slices.DeleteFunc(manifest.UsesPermissions, func(u UsesPermission) bool {
+		if node := u.Attributes["tools:node"]; removingNodes[node] {
+			notes = append(notes, fmt.Sprintf("permission %s is not requested (tools:node=%q)", u.Name, node))
+			return true
+		}
+		return false
+	})
+	app := &manifest.Application
+	for _, group := range []struct {
+		kind       string
+		components *[]App
+	}{
+		{"activity", &app.Activities}, {"alias", &app.Aliases}, {"service", &app.Services},
+		{"receiver", &app.Receivers}, {"provider", &app.Providers},
+	} {
+		*group.components = slices.DeleteFunc(*group.components, func(component App) bool {
+			if node := component.Attributes["tools:node"]; removingNodes[node] {
+				notes = append(notes, fmt.Sprintf("%s %s is not in the merged manifest (tools:node=%q)", group.kind, component.Name, node))
+				return true
+			}
+			return false
+		})
+		for i := range *group.components {
+			component := &(*group.components)[i]
+			component.Filters = slices.DeleteFunc(component.Filters, func(filter IntentFilter) bool {
+				if node := filter.Attributes["tools:node"]; removingNodes[node] {
+					notes = append(notes, fmt.Sprintf("an intent filter of %s %s is removed (tools:node=%q)", group.kind, component.Name, node))
+					return true
+				}
+				return false
+			})
+			if note := replaceNote(*component); note != "" {
+				notes = append(notes, fmt.Sprintf("%s %s %s", group.kind, component.Name, note))
+			}
+		}
+	}
+	return notes
+}
+
+// printMergeRules lists the tools: merge rules that changed or annotate the analysis.
+func printMergeRules(w io.Writer, notes []string) {
+	printSection(w, "\nMerge rules:")
+	for _, note := range notes {
+		fmt.Fprintf(w, "  %s\n", note)
+	}
+}
+
+// replaceNote describes the tools: rules by which a component overrides the
+// declarations of library manifests, or returns "" when it has none.
+func replaceNote(component App) string {
+	if component.Attributes["tools:node"] == "replace" {
+		return "replaces library declarations entirely (tools:node=\"replace\")"
+	}
+	var parts []string
+	if replaced := component.Attributes["tools:replace"]; replaced != "" {
+		parts = append(parts, "overrides library values of "+attributeList(replaced)+" (tools:replace)")
+	}
+	if removed := component.Attributes["tools:remove"]; removed != "" {
+		parts = append(parts, "drops library values of "+attributeList(removed)+" (tools:remove)")
+	}
+	return strings.Join(parts, "; ")
+}
+
+// attributeList normalizes a comma-separated tools: attribute list for display.
+func attributeList(value string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(value, ",", " ")), ", ")
+}
+
+// hasMergeRules reports whether a manifest still carries tools: merge rules,
+// which the merger strips from the manifest it packages. The tools namespace
+// is matched by URI, whatever prefix the manifest binds it to.
+func hasMergeRules(raw []byte) bool {
+	decoder := newXMLDecoder(bytes.NewReader(raw))
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			for _, attr := range start.Attr {
+				if attr.Name.Space == toolsNamespace && mergeRuleAttributes[attr.Name.Local] {
+					return true
+				}
+			}
+		}
+	}
+}
+
+// isPreMergeManifest reports whether the analyzed manifest looks like a source
+// manifest from a project tree rather than apktool output: it carries merge
+// rules and there is no apktool.yml next to it.
+func isPreMergeManifest(folder string, raw []byte) bool {
+	if !hasMergeRules(raw) {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(folder, "apktool.yml"))
+	return err != nil
+}
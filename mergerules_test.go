@@ -0,0 +1,127 @@
+package main
+
+import (
+	"os"            // apktool.yml fixture
+	"path/filepath" // Fixture paths
+	"slices"        // Result comparison
+	"strings"       // Output checks
+	"testing"       // Test harness
+)
+
+// mergeManifest is a source manifest of org.example that drops a library
+// activity, a library permission request and declaration, and overrides two
+// others. The tools namespace is bound to the prefix t, as the merger only
+// cares about the namespace URI.
+const mergeManifest = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" xmlns:t="http://schemas.android.com/tools" package="org.example">
+    <permission android:name="com.library.ACCESS" android:protectionLevel="normal" t:node="remove"/>
+    <permission android:name="org.example.ACCESS" android:protectionLevel="signature"/>
+    <uses-permission android:name="android.permission.INTERNET"/>
+    <uses-permission android:name="android.permission.CAMERA" t:node="remove"/>
+    <application>
+        <activity android:name=".Main" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="https" android:host="example.com"/>
+            </intent-filter>
+            <intent-filter t:node="remove">
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="https" android:host="library.example"/>
+            </intent-filter>
+        </activity>
+        <activity android:name="com.library.TrackingActivity" android:exported="true" t:node="remove">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="tracking" android:host="open"/>
+            </intent-filter>
+        </activity>
+        <activity android:name=".Settings" android:exported="false" t:replace="android:exported,android:theme"/>
+        <service android:name="com.library.SyncService" android:exported="false" t:node="replace"/>
+    </application>
+</manifest>`
+
+func TestApplyMergeRules(t *testing.T) {
+	manifest := parseTestManifest(t, mergeManifest)
+	notes := applyMergeRules(&manifest)
+
+	want := []string{
+		`permission com.library.ACCESS is not declared (tools:node="remove")`,
+		`permission android.permission.CAMERA is not requested (tools:node="remove")`,
+		`activity com.library.TrackingActivity is not in the merged manifest (tools:node="remove")`,
+		`an intent filter of activity .Main is removed (tools:node="remove")`,
+		`activity .Settings overrides library values of android:exported, android:theme (tools:replace)`,
+		`service com.library.SyncService replaces library declarations entirely (tools:node="replace")`,
+	}
+	if !slices.Equal(notes, want) {
+		t.Errorf("notes:\n%s\nwant:\n%s", strings.Join(notes, "\n"), strings.Join(want, "\n"))
+	}
+
+	if len(manifest.Permissions) != 1 || manifest.Permissions[0].Name != "org.example.ACCESS" {
+		t.Errorf("declared permissions = %+v, want only org.example.ACCESS", manifest.Permissions)
+	}
+	if len(manifest.UsesPermissions) != 1 || manifest.UsesPermissions[0].Name != "android.permission.INTERNET" {
+		t.Errorf("requested permissions = %+v, want only INTERNET", manifest.UsesPermissions)
+	}
+	var activities []string
+	for _, a := range manifest.Application.Activities {
+		activities = append(activities, a.Name)
+	}
+	if !slices.Equal(activities, []string{".Main", ".Settings"}) {
+		t.Errorf("activities = %v, want .Main and .Settings", activities)
+	}
+	if filters := manifest.Application.Activities[0].Filters; len(filters) != 1 || filters[0].Data[0].Host != "example.com" {
+		t.Errorf(".Main filters = %+v, want only the example.com filter", filters)
+	}
+	if len(manifest.Application.Services) != 1 {
+		t.Errorf("tools:node=\"replace\" should keep the service, got %+v", manifest.Application.Services)
+	}
+}
+
+// TestMergeRulesReport checks removed elements leave no trace in the analysis
+// outside the listing of the rules applied.
+func TestMergeRulesReport(t *testing.T) {
+	setOpts(t, textOptions())
+	result, text := renderFixture(t, mergeManifest, "<resources/>")
+	if slices.Contains(result.Requested, "android.permission.CAMERA") {
+		t.Errorf("requested = %v; CAMERA is removed", result.Requested)
+	}
+	analysis, _, _ := strings.Cut(text, "Merge rules:")
+	for _, gone := range []string{"TrackingActivity", "tracking://open", "library.example", "com.library.ACCESS", "CAMERA"} {
+		if strings.Contains(analysis, gone) {
+			t.Errorf("report mentions removed %s:\n%s", gone, text)
+		}
+	}
+	if !strings.Contains(text, "Merge rules:\n") || !strings.Contains(text, "  activity com.library.TrackingActivity is not in the merged manifest") {
+		t.Errorf("report lacks the merge rules section:\n%s", text)
+	}
+	if len(result.MergeRules) != 6 {
+		t.Errorf("merge_rules = %q, want 6 notes", result.MergeRules)
+	}
+}
+
+func TestIsPreMergeManifest(t *testing.T) {
+	raw := []byte(selftestManifest)
+	source := t.TempDir()
+	if !isPreMergeManifest(source, raw) {
+		t.Error("a manifest with tools: rules and no apktool.yml should read as a source manifest")
+	}
+	decoded := t.TempDir()
+	if err := os.WriteFile(filepath.Join(decoded, "apktool.yml"), []byte("version: 2.9.3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if isPreMergeManifest(decoded, raw) {
+		t.Error("apktool output should not read as a source manifest")
+	}
+	if !isPreMergeManifest(source, []byte(mergeManifest)) {
+		t.Error("merge rules under a prefix other than tools: should be recognized")
+	}
+	if isPreMergeManifest(source, []byte(sspManifest)) {
+		t.Error("a manifest without tools: rules should not read as a source manifest")
+	}
+	if isPreMergeManifest(source, []byte(`<manifest xmlns:tools="http://schemas.android.com/tools" tools:ignore="MissingVersion"/>`)) {
+		t.Error("tools:ignore is lint configuration, not a merge rule")
+	}
+}
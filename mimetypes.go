@@ -0,0 +1,38 @@
+package main
+
+import (
+	"slices"  // Deduplicating types
+	"strings" // Line assembly
+)
+
+// filterMimeTypes returns the distinct mime types a filter declares.
+func filterMimeTypes(filter IntentFilter) []string {
+	var types []string
+	for _, data := range filter.Data {
+		if data.MimeType != "" && !slices.Contains(types, data.MimeType) {
+			types = append(types, data.MimeType)
+		}
+	}
+	return types
+}
+
+// isContentHandler reports whether a filter matches by mime type alone. Such
+// filters accept content:// and file:// URIs of that type, as document viewers
+// do, without declaring any URI shape.
+func isContentHandler(filter IntentFilter) bool {
+	return len(filterMimeTypes(filter)) > 0 && !slices.ContainsFunc(filter.Data, Data.IsSchemeData)
+}
+
+// contentHandlerLine describes a mime-type-only filter, e.g.
+// "handles content of type application/pdf (via VIEW/SEND)".
+func contentHandlerLine(filter IntentFilter) string {
+	var actions []string
+	for _, action := range filter.Actions {
+		actions = append(actions, strings.TrimPrefix(action.Name, "android.intent.action."))
+	}
+	line := "handles content of type " + strings.Join(filterMimeTypes(filter), ", ")
+	if len(actions) > 0 {
+		line += " (via " + strings.Join(actions, "/") + ")"
+	}
+	return line
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"slices"  // Share target lookup
+	"strings" // Output checks
+	"testing" // Test harness
+)
+
+// viewerManifest is a document viewer: a PDF viewer taking VIEW and SEND by
+// type only, an image importer taking two types, and a browser-style filter
+// whose mime type comes with a scheme and so isn't a content handler.
+const viewerManifest = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="org.example.viewer">
+    <application>
+        <activity android:name=".PdfViewer" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <action android:name="android.intent.action.SEND"/>
+                <category android:name="android.intent.category.DEFAULT"/>
+                <data android:mimeType="application/pdf"/>
+            </intent-filter>
+        </activity>
+        <activity android:name=".ImageImport" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.SEND_MULTIPLE"/>
+                <category android:name="android.intent.category.DEFAULT"/>
+                <data android:mimeType="image/png"/>
+                <data android:mimeType="image/jpeg"/>
+                <data android:mimeType="image/png"/>
+            </intent-filter>
+        </activity>
+        <activity android:name=".Browser" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.DEFAULT"/>
+                <data android:scheme="https" android:mimeType="text/html"/>
+            </intent-filter>
+        </activity>
+    </application>
+</manifest>
+`
+
+func TestIsContentHandler(t *testing.T) {
+	manifest := parseTestManifest(t, viewerManifest)
+	activities := manifest.Application.Activities
+	for i, want := range []bool{true, true, false} {
+		if got := isContentHandler(activities[i].Filters[0]); got != want {
+			t.Errorf("%s: isContentHandler = %v, want %v", activities[i].Name, got, want)
+		}
+	}
+	if got := filterMimeTypes(activities[1].Filters[0]); !slices.Equal(got, []string{"image/png", "image/jpeg"}) {
+		t.Errorf("filterMimeTypes = %v, want each type once in manifest order", got)
+	}
+	if got := contentHandlerLine(activities[0].Filters[0]); got != "handles content of type application/pdf (via VIEW/SEND)" {
+		t.Errorf("contentHandlerLine = %q", got)
+	}
+}
+
+// TestContentHandlerReport checks mime-type-only filters are listed under
+// their component, count as share targets when they take SEND, and get test
+// cases carrying the type.
+func TestContentHandlerReport(t *testing.T) {
+	setOpts(t, textOptions())
+	result, text := renderFixture(t, viewerManifest, "<resources/>")
+	for _, want := range []string{
+		".PdfViewer (exported=true)",
+		"handles content of type application/pdf (via VIEW/SEND)",
+		"handles content of type image/png, image/jpeg (via SEND_MULTIPLE)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report lacks %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "handles content of type text/html") {
+		t.Errorf("a filter with a scheme is listed as a content handler:\n%s", text)
+	}
+
+	share := slices.IndexFunc(result.ShareTargets, func(s shareTarget) bool { return s.Component == ".PdfViewer" })
+	if share < 0 || !slices.Equal(result.ShareTargets[share].MimeTypes, []string{"application/pdf"}) {
+		t.Errorf("share targets %+v lack .PdfViewer with application/pdf", result.ShareTargets)
+	}
+
+	var pdf []string
+	for _, c := range result.TestCases {
+		if c.Component == "org.example.viewer.PdfViewer" {
+			if c.URI != "" || c.MimeType != "application/pdf" {
+				t.Errorf("PDF case %+v, want no URI and the mime type", c)
+			}
+			pdf = append(pdf, c.ADB)
+		}
+	}
+	want := []string{
+		"adb shell am start -W -a android.intent.action.VIEW -t application/pdf -n org.example.viewer/org.example.viewer.PdfViewer",
+		"adb shell am start -W -a android.intent.action.SEND -t application/pdf -n org.example.viewer/org.example.viewer.PdfViewer",
+	}
+	if !slices.Equal(pdf, want) {
+		t.Errorf("PDF adb commands:\n%s\nwant:\n%s", strings.Join(pdf, "\n"), strings.Join(want, "\n"))
+	}
+}
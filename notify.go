@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"         // Request bodies
+	"encoding/json" // Payload serialization
+	"errors"        // Unwrapping transport errors
+	"fmt"           // Message formatting
+	"net/http"      // Webhook delivery
+	"net/url"       // URL redaction
+	"sort"          // Ranking findings
+	"strings"       // Slack message assembly
+	"time"          // Request timeout
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// notifyTimeout bounds a single webhook delivery.
+const notifyTimeout = 15 * time.Second
+
+// notifyPayload is the compact JSON body posted for one analyzed target.
+type notifyPayload struct {
+	Target   string         `json:"target"`
+	Package  string         `json:"package"`
+	Counts   map[string]int `json:"counts"`
+	Findings []notifyItem   `json:"top_findings"`
+}
+
+// notifyItem is a trimmed-down finding for notifications.
+type notifyItem struct {
+	Severity  string `json:"severity"`
+	Title     string `json:"title"`
+	Component string `json:"component"`
+	ID        string `json:"id"`
+}
+
+// sendNotifications posts a summary of every report with at least one finding at
+// or above -notify-min-severity. Delivery problems are printed but never fail the run.
+func sendNotifications(reports []*report) {
+	if opts.NotifyWebhook == "" {
+		return
+	}
+	threshold := severityRank[opts.NotifyMinSeverity]
+	client := &http.Client{Timeout: notifyTimeout}
+	for _, r := range reports {
+		payload, notable := buildNotifyPayload(r, threshold)
+		if !notable {
+			continue
+		}
+		var body any = payload
+		if opts.NotifyFormat == "slack" {
+			body = slackMessage(payload)
+		}
+		if err := postJSON(client, opts.NotifyWebhook, nil, body); err != nil {
+			color.Yellow("Warning: could not notify %s about %s: %s", redactURL(opts.NotifyWebhook), r.Target, err)
+		}
+	}
+}
+
+// buildNotifyPayload summarizes a report. It reports false when no finding reaches the threshold.
+func buildNotifyPayload(r *report, threshold int) (notifyPayload, bool) {
+	payload := notifyPayload{Target: r.Target, Package: r.Package, Counts: make(map[string]int)}
+	var notable []finding
+	for _, f := range r.Findings {
+		payload.Counts[f.Severity]++
+		if severityRank[f.Severity] >= threshold {
+			notable = append(notable, f)
+		}
+	}
+	if len(notable) == 0 {
+		return payload, false
+	}
+	sort.SliceStable(notable, func(i, j int) bool {
+		return severityRank[notable[i].Severity] > severityRank[notable[j].Severity]
+	})
+	for _, f := range notable[:min(len(notable), opts.NotifyTop)] {
+		payload.Findings = append(payload.Findings, notifyItem{Severity: f.Severity, Title: f.Title, Component: f.Component, ID: f.Fingerprint})
+	}
+	return payload, true
+}
+
+// slackMessage renders the payload as a Slack Block Kit message.
+func slackMessage(payload notifyPayload) map[string]any {
+	var counts []string
+	for _, severity := range severityOrder {
+		if n := payload.Counts[severity]; n > 0 {
+			counts = append(counts, fmt.Sprintf("*%s:* %d", severity, n))
+		}
+	}
+	var lines []string
+	for _, f := range payload.Findings {
+		lines = append(lines, fmt.Sprintf("• `%s` %s", f.Severity, f.Title))
+	}
+	summary := fmt.Sprintf("Deeeeper findings for %s", payload.Package)
+	return map[string]any{
+		"text": summary, // Fallback for notifications
+		"blocks": []map[string]any{
+			{"type": "header", "text": map[string]any{"type": "plain_text", "text": summary}},
+			{"type": "section", "text": map[string]any{"type": "mrkdwn", "text": fmt.Sprintf("*Target:* %s\n%s", payload.Target, strings.Join(counts, "  "))}},
+			{"type": "section", "text": map[string]any{"type": "mrkdwn", "text": strings.Join(lines, "\n")}},
+		},
+	}
+}
+
+// postJSON sends body as JSON with the extra headers and treats any non-2xx
+// status as an error.
+// Errors never contain the URL, which may embed a secret token.
+func postJSON(client *http.Client, endpoint string, headers http.Header, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err // Drop the URL from the message
+		}
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &httpStatusError{code: resp.StatusCode, status: resp.Status}
+	}
+	return nil
+}
+
+// httpStatusError is a delivery the endpoint answered with a non-2xx status.
+type httpStatusError struct {
+	code   int
+	status string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("endpoint answered %s", e.status)
+}
+
+// redactURL reduces a URL to scheme and host so tokens in paths or queries never reach logs.
+func redactURL(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return "<webhook>"
+	}
+	return parsed.Scheme + "://" + parsed.Host + "/…"
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os/exec"       // Opener processes
+	"path/filepath" // Absolute report paths
+	"runtime"       // Platform detection
+	"strings"       // URL detection
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// openers lists the commands that open a file or URL in the default browser,
+// per platform, in order of preference.
+var openers = map[string][][]string{
+	"linux":   {{"xdg-open"}, {"termux-open-url"}, {"sensible-browser"}},
+	"darwin":  {{"open"}},
+	"windows": {{"rundll32", "url.dll,FileProtocolHandler"}},
+	"freebsd": {{"xdg-open"}},
+	"openbsd": {{"xdg-open"}},
+}
+
+// openInBrowser opens a report file or URL with the platform's opener. It only
+// runs under -open, never fails the run and prints the target instead when no
+// opener is available (headless CI, minimal containers).
+func openInBrowser(target string) {
+	if !opts.Open {
+		return
+	}
+	if abs, err := filepath.Abs(target); err == nil && !isURL(target) {
+		target = abs
+	}
+	for _, opener := range openers[runtime.GOOS] {
+		path, err := exec.LookPath(opener[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, append(opener[1:], target)...)
+		if err := cmd.Start(); err != nil {
+			color.Yellow("Warning: could not open %s with %s: %s", target, opener[0], err)
+			return
+		}
+		go cmd.Wait() // Reap the opener without holding up the run
+		return
+	}
+	color.Yellow("No browser opener found; open %s manually.", target)
+}
+
+// isURL reports whether target is an http(s) URL rather than a file path.
+func isURL(target string) bool {
+	return strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://")
+}
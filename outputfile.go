@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"        // Existing file detection
+	"fmt"           // Error messages
+	"io"            // Writer plumbing
+	"io/fs"         // Existing file detection
+	"os"            // Output file
+	"path/filepath" // Parent directories
+	"regexp"        // ANSI escape sequences
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// ansiEscapes matches the SGR color sequences the terminal output carries.
+var ansiEscapes = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// resultsFile is the -o file, nil when results go to stdout.
+var resultsFile *os.File
+
+// openResultsFile creates the -o file and its parent directories. An existing
+// file is only replaced with -force, so one file per APK can't be clobbered by
+// a re-run with the wrong name.
+func openResultsFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if opts.Force {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	file, err := os.OpenFile(path, flags, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%s already exists; pass -force to overwrite it", path)
+	}
+	if err != nil {
+		return err
+	}
+	resultsFile = file
+	return nil
+}
+
+// resultsWriter is where structured documents go: the -o file or stdout.
+func resultsWriter() io.Writer {
+	if resultsFile != nil {
+		return resultsFile
+	}
+	return os.Stdout
+}
+
+// textWriter is where the text report goes: stdout, copied without colors to
+// the -o file. Progress, warnings and errors go to color.Output, which is
+// stderr, so stdout holds nothing but results. With a machine format the
+// document owns stdout and any text joins the progress on stderr.
+func textWriter() io.Writer {
+	switch {
+	case machineFormat():
+		return color.Output
+	case resultsFile == nil:
+		return os.Stdout
+	}
+	return io.MultiWriter(os.Stdout, ansiStripper{resultsFile}) // The terminal keeps its colors
+}
+
+// ansiStripper writes through to w with ANSI color sequences removed, so the
+// -o copy of the terminal output is plain text.
+type ansiStripper struct {
+	w io.Writer // Destination of the plain text
+}
+
+// Write strips the color sequences of p. Color codes are written whole by the
+// color package, so a sequence never spans two calls.
+func (s ansiStripper) Write(p []byte) (int, error) {
+	if _, err := s.w.Write(ansiEscapes.ReplaceAll(p, nil)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
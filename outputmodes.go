@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"  // Conflicting modes
+	"flag"    // Finding URI options that were set
+	"fmt"     // Error formatting
+	"slices"  // Component filtering
+	"strings" // Joining conflicts
+)
+
+// uriFlags are options that work on constructed URIs and so have nothing to do
+// under -only-components.
+var uriFlags = []string{"expect", "testcases", "inventory", "adb-commands", "adb", "launch", "qr", "gen-assetlinks", "matrix", "collapse", "serve-poc", "zap-context"}
+
+// checkOutputMode rejects -only-deeplinks together with -only-components and
+// -only-components together with options that need URIs.
+func checkOutputMode() error {
+	if opts.OnlyDeeplinks && opts.OnlyComponents {
+		return errors.New("-only-deeplinks and -only-components are mutually exclusive")
+	}
+	if !opts.OnlyComponents {
+		return nil
+	}
+	var conflicts []string
+	flag.Visit(func(f *flag.Flag) {
+		if slices.Contains(uriFlags, f.Name) {
+			conflicts = append(conflicts, "-"+f.Name)
+		}
+	})
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%s need URIs and can't be combined with -only-components", strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+// isBrowsable reports whether a filter takes VIEW intents with the BROWSABLE
+// category, the combination a link clicked in a browser must match.
+func isBrowsable(filter IntentFilter) bool {
+	hasView := slices.ContainsFunc(filter.Actions, func(a Action) bool { return a.Name == "android.intent.action.VIEW" })
+	return hasView && slices.ContainsFunc(filter.Categories, func(c Category) bool { return c.Name == "android.intent.category.BROWSABLE" })
+}
+
+// narrowToDeeplinks drops services, receivers and providers from the manifest
+// and keeps only the activities and aliases declaring at least one deeplink,
+// so every later step of -only-deeplinks sees just those.
+func narrowToDeeplinks(manifest *Manifest, folder string) {
+	app := &manifest.Application
+	app.Services, app.Receivers, app.Providers = nil, nil, nil
+	noDeeplinks := func(component App) bool { return len(componentURIs(folder, component)) == 0 }
+	app.Activities = slices.DeleteFunc(app.Activities, noDeeplinks)
+	app.Aliases = slices.DeleteFunc(app.Aliases, noDeeplinks)
+}
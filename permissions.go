@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"     // Evidence formatting
+	"io"      // Output destination
+	"strconv" // Numeric protection levels
+	"strings" // Permission name checks
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// protectionInfo is one row of the protected-components summary: an exported
+// component guarded by a permission and how much that permission protects.
+type protectionInfo struct {
+	Kind       string `json:"type"`       // Component kind
+	Component  string `json:"component"`  // Component name
+	Attribute  string `json:"attribute"`  // Attribute naming the permission
+	Permission string `json:"permission"` // Permission name
+	Level      string `json:"level"`      // Protection level, "platform" or "undeclared"
+	Protected  bool   `json:"protected"`  // False when any app can obtain the permission
+}
+
+// protectionLevels maps numeric protectionLevel bases to their names.
+var protectionLevels = []string{"normal", "dangerous", "signature", "signatureOrSystem", "internal"}
+
+// platformPermissions are permissions declared by the Android framework and
+// Google Play services that apps commonly use to guard their components.
+// References to them resolve even though the app doesn't declare them.
+var platformPermissions = map[string]bool{
+	"android.permission.ACCESS_CHECKIN_PROPERTIES":                           true,
+	"android.permission.ACCESS_COARSE_LOCATION":                              true,
+	"android.permission.ACCESS_FINE_LOCATION":                                true,
+	"android.permission.ACCESS_NETWORK_STATE":                                true,
+	"android.permission.ACCESS_NOTIFICATION_POLICY":                          true,
+	"android.permission.ACCESS_WIFI_STATE":                                   true,
+	"android.permission.BIND_ACCESSIBILITY_SERVICE":                          true,
+	"android.permission.BIND_APPWIDGET":                                      true,
+	"android.permission.BIND_AUTOFILL_SERVICE":                               true,
+	"android.permission.BIND_CALL_REDIRECTION_SERVICE":                       true,
+	"android.permission.BIND_CARRIER_MESSAGING_CLIENT_SERVICE":               true,
+	"android.permission.BIND_CARRIER_MESSAGING_SERVICE":                      true,
+	"android.permission.BIND_CARRIER_SERVICES":                               true,
+	"android.permission.BIND_CHOOSER_TARGET_SERVICE":                         true,
+	"android.permission.BIND_COMPANION_DEVICE_SERVICE":                       true,
+	"android.permission.BIND_CONDITION_PROVIDER_SERVICE":                     true,
+	"android.permission.BIND_CONTROLS":                                       true,
+	"android.permission.BIND_CREDENTIAL_PROVIDER_SERVICE":                    true,
+	"android.permission.BIND_DEVICE_ADMIN":                                   true,
+	"android.permission.BIND_DREAM_SERVICE":                                  true,
+	"android.permission.BIND_INCALL_SERVICE":                                 true,
+	"android.permission.BIND_INPUT_METHOD":                                   true,
+	"android.permission.BIND_JOB_SERVICE":                                    true,
+	"android.permission.BIND_MIDI_DEVICE_SERVICE":                            true,
+	"android.permission.BIND_NFC_SERVICE":                                    true,
+	"android.permission.BIND_NOTIFICATION_LISTENER_SERVICE":                  true,
+	"android.permission.BIND_PRINT_SERVICE":                                  true,
+	"android.permission.BIND_QUICK_ACCESS_WALLET_SERVICE":                    true,
+	"android.permission.BIND_QUICK_SETTINGS_TILE":                            true,
+	"android.permission.BIND_REMOTEVIEWS":                                    true,
+	"android.permission.BIND_SCREENING_SERVICE":                              true,
+	"android.permission.BIND_TELECOM_CONNECTION_SERVICE":                     true,
+	"android.permission.BIND_TEXT_SERVICE":                                   true,
+	"android.permission.BIND_TV_INPUT":                                       true,
+	"android.permission.BIND_VISUAL_VOICEMAIL_SERVICE":                       true,
+	"android.permission.BIND_VOICE_INTERACTION":                              true,
+	"android.permission.BIND_VPN_SERVICE":                                    true,
+	"android.permission.BIND_VR_LISTENER_SERVICE":                            true,
+	"android.permission.BIND_WALLPAPER":                                      true,
+	"android.permission.BLUETOOTH":                                           true,
+	"android.permission.BLUETOOTH_ADMIN":                                     true,
+	"android.permission.BLUETOOTH_CONNECT":                                   true,
+	"android.permission.BLUETOOTH_SCAN":                                      true,
+	"android.permission.BROADCAST_SMS":                                       true,
+	"android.permission.BROADCAST_WAP_PUSH":                                  true,
+	"android.permission.CALL_PHONE":                                          true,
+	"android.permission.CALL_PRIVILEGED":                                     true,
+	"android.permission.CAMERA":                                              true,
+	"android.permission.DUMP":                                                true,
+	"android.permission.FOREGROUND_SERVICE":                                  true,
+	"android.permission.GET_ACCOUNTS":                                        true,
+	"android.permission.GLOBAL_SEARCH":                                       true,
+	"android.permission.INSTALL_PACKAGES":                                    true,
+	"android.permission.INTERACT_ACROSS_USERS":                               true,
+	"android.permission.INTERACT_ACROSS_USERS_FULL":                          true,
+	"android.permission.INTERNET":                                            true,
+	"android.permission.MANAGE_DOCUMENTS":                                    true,
+	"android.permission.MODIFY_PHONE_STATE":                                  true,
+	"android.permission.NFC":                                                 true,
+	"android.permission.POST_NOTIFICATIONS":                                  true,
+	"android.permission.READ_CALENDAR":                                       true,
+	"android.permission.READ_CALL_LOG":                                       true,
+	"android.permission.READ_CONTACTS":                                       true,
+	"android.permission.READ_EXTERNAL_STORAGE":                               true,
+	"android.permission.READ_PHONE_STATE":                                    true,
+	"android.permission.READ_SMS":                                            true,
+	"android.permission.READ_USER_DICTIONARY":                                true,
+	"android.permission.RECEIVE_BOOT_COMPLETED":                              true,
+	"android.permission.RECEIVE_SMS":                                         true,
+	"android.permission.RECORD_AUDIO":                                        true,
+	"android.permission.SEND_RESPOND_VIA_MESSAGE":                            true,
+	"android.permission.SEND_SMS":                                            true,
+	"android.permission.START_VIEW_PERMISSION_USAGE":                         true,
+	"android.permission.START_VIEW_APP_FEATURES":                             true,
+	"android.permission.UPDATE_DEVICE_STATS":                                 true,
+	"android.permission.VIBRATE":                                             true,
+	"android.permission.WAKE_LOCK":                                           true,
+	"android.permission.WRITE_CALENDAR":                                      true,
+	"android.permission.WRITE_CONTACTS":                                      true,
+	"android.permission.WRITE_EXTERNAL_STORAGE":                              true,
+	"android.permission.WRITE_SETTINGS":                                      true,
+	"android.permission.WRITE_USER_DICTIONARY":                               true,
+	"com.android.launcher.permission.INSTALL_SHORTCUT":                       true,
+	"com.android.launcher.permission.UNINSTALL_SHORTCUT":                     true,
+	"com.android.vending.BILLING":                                            true,
+	"com.android.vending.CHECK_LICENSE":                                      true,
+	"com.android.vending.INSTALL_REFERRER":                                   true,
+	"com.google.android.c2dm.permission.RECEIVE":                             true,
+	"com.google.android.c2dm.permission.SEND":                                true,
+	"com.google.android.gms.permission.ACTIVITY_RECOGNITION":                 true,
+	"com.google.android.gms.permission.AD_ID":                                true,
+	"com.google.android.finsky.permission.BIND_GET_INSTALL_REFERRER_SERVICE": true,
+}
+
+// permissionRefs lists the permission attributes of a component with the
+// permission each one names, falling back to the application-wide
+// android:permission for components that don't declare their own.
+func permissionRefs(component App, appPermission string) map[string]string {
+	refs := make(map[string]string)
+	switch {
+	case component.Permission != "":
+		refs["android:permission"] = component.Permission
+	case appPermission != "":
+		refs["android:permission (from <application>)"] = appPermission
+	}
+	if component.ReadPermission != "" {
+		refs["android:readPermission"] = component.ReadPermission
+	}
+	if component.WritePermission != "" {
+		refs["android:writePermission"] = component.WritePermission
+	}
+	return refs
+}
+
+// declaredPermissions indexes the manifest's <permission> declarations by name.
+func declaredPermissions(manifest Manifest) map[string]PermissionDecl {
+	declared := make(map[string]PermissionDecl)
+	for _, permission := range manifest.Permissions {
+		declared[permission.Name] = permission
+	}
+	return declared
+}
+
+// baseProtection returns the base level of a protectionLevel value, which may
+// combine flags ("signature|privileged") or be numeric ("0x12"). The default
+// when the attribute is absent is normal.
+func baseProtection(level string) string {
+	if level == "" {
+		return "normal"
+	}
+	if n, err := strconv.ParseUint(level, 0, 32); err == nil {
+		if base := int(n & 0xf); base < len(protectionLevels) {
+			return protectionLevels[base]
+		}
+		return level
+	}
+	base, _, _ := strings.Cut(level, "|")
+	return base
+}
+
+// weakProtection reports whether any app can obtain a permission at this base
+// level: normal is granted on install and dangerous after a user prompt.
+func weakProtection(base string) bool {
+	return base == "normal" || base == "dangerous"
+}
+
+// collectProtections lists every permission guarding an exported component
+// and whether it actually keeps other apps out.
+func collectProtections(manifest Manifest) []protectionInfo {
+	declared := declaredPermissions(manifest)
+	var protections []protectionInfo
+	for _, group := range componentGroups(manifest) {
+		for _, component := range group.Components {
+			if exported, _ := isExported(component, group.Kind); !exported {
+				continue
+			}
+			refs := permissionRefs(component, manifest.Application.Permission)
+			for _, attr := range sortedKeys(refs) {
+				info := protectionInfo{Kind: group.Kind, Component: component.Name, Attribute: attr, Permission: refs[attr]}
+				switch decl, ok := declared[info.Permission]; {
+				case ok:
+					level := baseProtection(decl.ProtectionLevel)
+					info.Level, info.Protected = level, !weakProtection(level)
+					if decl.ProtectionLevel != "" {
+						info.Level = decl.ProtectionLevel
+					}
+				case platformPermissions[info.Permission]:
+					info.Level, info.Protected = "platform", true
+				default:
+					info.Level = "undeclared"
+				}
+				protections = append(protections, info)
+			}
+		}
+	}
+	return protections
+}
+
+// weakPermissionFindings flags exported components whose only guard is a
+// custom permission at normal or dangerous level. They are ranked one step
+// below the finding for the same component left unprotected, since the
+// permission at least has to be requested.
+func weakPermissionFindings(manifest Manifest, protections []protectionInfo) []finding {
+	var findings []finding
+	for _, p := range protections {
+		if p.Protected || p.Level == "undeclared" || p.Level == "platform" {
+			continue
+		}
+		evidence := fmt.Sprintf("%s=%q declared with protectionLevel %q; any app can request it", p.Attribute, p.Permission, p.Level)
+		f := newFinding(manifest.Package, "weak-permission", p.Kind, p.Component, nil, evidence)
+		f.Fingerprint = fingerprint(manifest.Package, f.Rule, p.Kind, p.Component, p.Attribute)
+		f.Severity = severityBelow(rules[exportRules[p.Kind]].Severity)
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// severityBelow returns the next lower severity, never going below low.
+func severityBelow(severity string) string {
+	for i, s := range severityOrder {
+		if s == severity && i+1 < len(severityOrder)-1 {
+			return severityOrder[i+1]
+		}
+	}
+	return "low"
+}
+
+// printProtections renders the protected-components summary.
+func printProtections(w io.Writer, protections []protectionInfo) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	red := color.New(color.FgRed).SprintFunc()
+
+	printSection(w, "\nProtected components:")
+	for _, p := range protections {
+		state := green("protected")
+		if !p.Protected {
+			state = red("not protected, any app can obtain it")
+		}
+		fmt.Fprintf(w, "  %s (%s) %s=%s [%s] %s\n", cyan(p.Component), p.Kind, p.Attribute, p.Permission, p.Level, state)
+	}
+}
+
+// undeclaredPermissionFindings flags component permissions that resolve to
+// neither a <permission> of this manifest nor a platform permission. Such a
+// reference, often a typo, protects nothing: any app can declare the name
+// itself with protectionLevel normal and then request it.
+func undeclaredPermissionFindings(manifest Manifest) []finding {
+	declared := declaredPermissions(manifest)
+	var findings []finding
+	for _, group := range componentGroups(manifest) {
+		for _, component := range group.Components {
+			refs := permissionRefs(component, manifest.Application.Permission)
+			for _, attr := range sortedKeys(refs) {
+				permission := refs[attr]
+				if _, ok := declared[permission]; ok || platformPermissions[permission] {
+					continue
+				}
+				evidence := fmt.Sprintf("%s=%q is not declared by the app or the platform", attr, permission)
+				if strings.HasPrefix(permission, "android.permission.") {
+					evidence += "; unknown android.permission name, possibly a typo"
+				}
+				f := newFinding(manifest.Package, "undeclared-permission", group.Kind, component.Name, nil, evidence)
+				f.Fingerprint = fingerprint(manifest.Package, f.Rule, group.Kind, component.Name, attr)
+				if exported, _ := isExported(component, group.Kind); !exported {
+					f.Severity = "low" // Only reachable from the app itself until it is exported
+				}
+				findings = append(findings, f)
+			}
+		}
+	}
+	return findings
+}
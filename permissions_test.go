@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strings" // Evidence checks
+	"testing" // Test harness
+)
+
+// typoManifest declares com.example.app.PERMISSION but guards its sync service
+// with the misspelled com.example.app.PERMISSON. The other components cover a
+// platform permission, a misspelled platform permission, an unexported
+// component and a provider's read permission.
+const typoManifest = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example.app">
+    <permission android:name="com.example.app.PERMISSION" android:protectionLevel="signature"/>
+    <application>
+        <service android:name=".SyncService" android:exported="true" android:permission="com.example.app.PERMISSON"/>
+        <service android:name=".Jobs" android:exported="true" android:permission="android.permission.BIND_JOB_SERVICE"/>
+        <activity android:name=".Scanner" android:exported="true" android:permission="android.permission.CAMREA"/>
+        <activity android:name=".Settings" android:exported="true" android:permission="com.example.app.PERMISSION"/>
+        <receiver android:name=".Internal" android:exported="false" android:permission="com.example.app.INTERNAL"/>
+        <provider android:name=".Files" android:authorities="com.example.app.files" android:exported="true" android:readPermission="com.example.app.READ"/>
+    </application>
+</manifest>
+`
+
+func TestUndeclaredPermissionFindings(t *testing.T) {
+	manifest := parseTestManifest(t, typoManifest)
+	got := make(map[string]finding)
+	for _, f := range undeclaredPermissionFindings(manifest) {
+		got[f.Component] = f
+	}
+	for component, want := range map[string]struct {
+		severity string
+		evidence string
+	}{
+		".SyncService": {"high", `android:permission="com.example.app.PERMISSON" is not declared by the app or the platform`},
+		".Scanner":     {"high", "unknown android.permission name, possibly a typo"},
+		".Internal":    {"low", `android:permission="com.example.app.INTERNAL"`},
+		".Files":       {"high", `android:readPermission="com.example.app.READ"`},
+	} {
+		f, ok := got[component]
+		if !ok {
+			t.Errorf("%s: no undeclared-permission finding", component)
+			continue
+		}
+		if f.Severity != want.severity {
+			t.Errorf("%s: severity %s, want %s", component, f.Severity, want.severity)
+		}
+		if !strings.Contains(f.Evidence, want.evidence) {
+			t.Errorf("%s: evidence %q lacks %q", component, f.Evidence, want.evidence)
+		}
+	}
+	for _, component := range []string{".Jobs", ".Settings"} {
+		if f, ok := got[component]; ok {
+			t.Errorf("%s: unexpected finding %q", component, f.Evidence)
+		}
+	}
+	if len(got) != 4 {
+		t.Errorf("%d findings, want 4", len(got))
+	}
+}
+
+// TestUndeclaredApplicationPermission checks components inherit the
+// <application> permission and are flagged when it is undeclared.
+func TestUndeclaredApplicationPermission(t *testing.T) {
+	manifest := parseTestManifest(t, strings.Replace(typoManifest, "<application>", `<application android:permission="com.example.app.APP">`, 1))
+	var evidence []string
+	for _, f := range undeclaredPermissionFindings(manifest) {
+		if strings.Contains(f.Evidence, "(from <application>)") {
+			evidence = append(evidence, f.Component)
+		}
+	}
+	if strings.Join(evidence, " ") != ".Files" { // Every other component names its own permission
+		t.Errorf("application permission flagged on %v, want .Files", evidence)
+	}
+}
+
+func TestCollectProtectionsLevels(t *testing.T) {
+	manifest := parseTestManifest(t, typoManifest)
+	levels := make(map[string]string)
+	for _, p := range collectProtections(manifest) {
+		levels[p.Component] = p.Level
+	}
+	for component, want := range map[string]string{
+		".SyncService": "undeclared",
+		".Jobs":        "platform",
+		".Settings":    "signature",
+		".Files":       "undeclared",
+	} {
+		if levels[component] != want {
+			t.Errorf("%s: level %q, want %q", component, levels[component], want)
+		}
+	}
+	if _, ok := levels[".Internal"]; ok {
+		t.Errorf("unexported component listed among protections")
+	}
+}
+
+// TestUndeclaredPermissionReported checks the typo reaches the findings of a
+// full analysis.
+func TestUndeclaredPermissionReported(t *testing.T) {
+	setOpts(t, textOptions())
+	result := analyzeFixture(t, typoManifest, "<resources/>")
+	for _, f := range result.Findings {
+		if f.Rule == "undeclared-permission" && f.Component == ".SyncService" {
+			return
+		}
+	}
+	t.Errorf("findings lack the undeclared permission of .SyncService: %+v", result.Findings)
+}
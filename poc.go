@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"       // Shutdown on Ctrl-C or timeout
+	"fmt"           // Log lines
+	"html/template" // PoC page rendering
+	"io"            // Beacon bodies
+	"net"           // Listener and LAN address lookup
+	"net/http"      // PoC server
+	"net/url"       // intent:// construction
+	"os"            // Interrupt signal
+	"os/signal"     // Ctrl-C handling
+	"slices"        // Script scheme filtering
+	"strings"       // intent:// construction
+	"sync"          // Click bookkeeping
+	"time"          // Timeouts
+	"unicode"       // Case folding of script schemes
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// pocLink is one row of the PoC page.
+type pocLink struct {
+	ID        int          // Index reported by the click beacon
+	URI       template.URL // Deeplink as declared; custom schemes are the point, so it is not sanitized
+	Intent    template.URL // intent:// variant that names the package explicitly
+	Text      string       // Deeplink shown as text only, set instead of URI and Intent for script URIs
+	Component string       // Component expected to handle the link
+}
+
+// scriptSchemes are never linked from the page, whatever the manifest declares.
+var scriptSchemes = []string{"javascript:", "vbscript:", "data:"}
+
+// pocPage is the page served by -serve-poc. Every link reports its click with a
+// beacon so coverage can be followed from the terminal.
+var pocPage = template.Must(template.New("poc").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Deeeeper PoC</title>
+<style>body{font-family:sans-serif;margin:1em}li{margin:.8em 0;word-break:break-all}small{color:#666}a.clicked{color:#080}</style>
+</head><body>
+<h1>Deeeeper deeplinks</h1>
+<ol>{{range .}}
+<li>{{if .Text}}<code>{{.Text}}</code> <small>(runs script in the browser, not linked)</small>{{else}}<a href="{{.URI}}" data-id="{{.ID}}">{{.URI}}</a> · <a href="{{.Intent}}" data-id="{{.ID}}">intent://</a>{{end}}<br><small>{{.Component}}</small></li>{{end}}
+</ol>
+<script>
+document.querySelectorAll('a[data-id]').forEach(function (a) {
+  a.addEventListener('click', function () {
+    navigator.sendBeacon('/clicked', a.dataset.id);
+    a.classList.add('clicked');
+  });
+});
+</script>
+</body></html>
+`))
+
+// pocLinks collects the distinct deeplinks of all reports. Script URIs are
+// kept as text: they are checked before anything is marked as a safe URL.
+func pocLinks(reports []*report) []pocLink {
+	seen := make(map[string]bool)
+	var links []pocLink
+	for _, r := range reports {
+		for _, c := range r.TestCases {
+			if c.URI == "" || isProviderCase(c) || seen[c.URI+c.Component] {
+				continue
+			}
+			seen[c.URI+c.Component] = true
+			if isScriptURI(c.URI) {
+				links = append(links, pocLink{ID: len(links), Text: c.URI, Component: c.Component})
+				continue
+			}
+			links = append(links, pocLink{ID: len(links), URI: template.URL(c.URI), Intent: template.URL(intentURI(c)), Component: c.Component})
+		}
+	}
+	return links
+}
+
+// isScriptURI reports whether a URI would run code in the page if clicked.
+// Like a browser, it ignores leading controls and spaces and tabs or newlines
+// anywhere, so "java\tscript:" is caught too.
+func isScriptURI(uri string) bool {
+	lower := strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, strings.TrimLeftFunc(uri, func(r rune) bool { return r <= ' ' }))
+	return slices.ContainsFunc(scriptSchemes, func(scheme string) bool { return strings.HasPrefix(lower, scheme) })
+}
+
+// intentURI rewrites a deeplink into Chrome's intent:// syntax so the browser
+// hands it to the expected package even when other apps claim the same URI.
+func intentURI(c testCase) string {
+	parsed, err := url.Parse(c.URI)
+	if err != nil || parsed.Scheme == "" {
+		return c.URI
+	}
+	rest := strings.TrimPrefix(strings.TrimPrefix(c.URI, parsed.Scheme+":"), "//")
+	return fmt.Sprintf("intent://%s#Intent;scheme=%s;package=%s;action=%s;end", rest, parsed.Scheme, c.Package, c.Action)
+}
+
+// servePoC hosts the PoC page until Ctrl-C or the -serve-timeout expires.
+// It binds to localhost unless -serve-lan is given.
+func servePoC(reports []*report) error {
+	links := pocLinks(reports)
+	linked := 0
+	for _, link := range links {
+		if link.Text == "" {
+			linked++
+		}
+	}
+	if linked == 0 {
+		return fmt.Errorf("no deeplinks to serve")
+	}
+
+	addr := opts.ServePoC
+	if !strings.Contains(addr, ":") {
+		addr = ":" + addr // Accept a bare port
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if host == "" {
+		host = "127.0.0.1"
+		if opts.ServeLAN {
+			host = "0.0.0.0"
+		}
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	clicked := make(map[int]bool)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		pocPage.Execute(w, links)
+	})
+	mux.HandleFunc("POST /clicked", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(io.LimitReader(r.Body, 16))
+		var id int
+		if _, err := fmt.Sscan(string(body), &id); err != nil || id < 0 || id >= len(links) || links[id].Text != "" {
+			http.Error(w, "unknown link", http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		clicked[id] = true
+		covered := len(clicked)
+		mu.Unlock()
+		fmt.Fprintf(color.Output, "clicked %s (%d/%d links covered)\n", links[id].URI, covered, linked)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	pageURL := "http://" + listener.Addr().String() + "/"
+	if opts.ServeLAN {
+		if ip := lanAddress(); ip != "" {
+			_, boundPort, _ := net.SplitHostPort(listener.Addr().String())
+			pageURL = "http://" + net.JoinHostPort(ip, boundPort) + "/"
+		}
+	}
+	color.Green("Serving %d deeplinks at %s (Ctrl-C to stop)", linked, pageURL)
+	if code, err := encodeQR(pageURL); err == nil {
+		code.render(color.Output)
+	}
+	openInBrowser(pageURL)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if opts.ServeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.ServeTimeout)
+		defer cancel()
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+	if err := server.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+	mu.Lock()
+	color.Green("PoC server stopped; %d of %d links were clicked.", len(clicked), linked)
+	mu.Unlock()
+	return nil
+}
+
+// lanAddress returns the first non-loopback IPv4 address, so the printed URL
+// and QR code work from a phone on the same network.
+func lanAddress() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return ""
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
+			return ipNet.IP.String()
+		}
+	}
+	return ""
+}
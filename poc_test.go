@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings" // Output checks
+	"testing" // Test harness
+)
+
+func TestIsScriptURI(t *testing.T) {
+	for uri, want := range map[string]bool{
+		"javascript:alert(1)":      true,
+		"JavaScript:alert(1)":      true,
+		" \x01javascript:alert(1)": true,
+		"java\tscript:alert(1)":    true,
+		"VBScript:msgbox":          true,
+		"data:text/html,<b>":       true,
+		"shop://product/1":         false,
+		"https://example.com/js":   false,
+		"intent://x#Intent;end":    false,
+	} {
+		if got := isScriptURI(uri); got != want {
+			t.Errorf("isScriptURI(%q) = %t, want %t", uri, got, want)
+		}
+	}
+}
+
+// TestPoCPage checks custom schemes are linked as declared while script URIs
+// only appear as text, never in an href.
+func TestPoCPage(t *testing.T) {
+	view := "android.intent.action.VIEW"
+	links := pocLinks([]*report{{TestCases: []testCase{
+		{URI: "shop://product/1", Action: view, Package: "org.example.shop", Component: "org.example.shop.Product"},
+		{URI: "javascript:alert(document.domain)", Action: view, Package: "org.example.shop", Component: "org.example.shop.Web"},
+		{URI: "Data:text/html,<script>alert(1)</script>", Action: view, Package: "org.example.shop", Component: "org.example.shop.Web"},
+		{URI: "shop://product/1", Action: view, Package: "org.example.shop", Component: "org.example.shop.Product"},
+	}}})
+	if len(links) != 3 || links[0].Text != "" || links[1].Text == "" || links[1].URI != "" || links[2].Intent != "" {
+		t.Fatalf("links = %+v, want the custom scheme linked and both script URIs as text", links)
+	}
+	var page strings.Builder
+	if err := pocPage.Execute(&page, links); err != nil {
+		t.Fatal(err)
+	}
+	html := page.String()
+	if !strings.Contains(html, `<a href="shop://product/1" data-id="0">`) {
+		t.Errorf("custom scheme not linked as declared:\n%s", html)
+	}
+	if !strings.Contains(html, "<code>javascript:alert(document.domain)</code>") {
+		t.Errorf("javascript: URI not shown as text:\n%s", html)
+	}
+	for _, unsafe := range []string{`href="javascript:`, `href="Data:`, "<script>alert"} {
+		if strings.Contains(html, unsafe) {
+			t.Errorf("page contains %s:\n%s", unsafe, html)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"         // Encoded documents
+	"crypto/tls"    // -post-insecure
+	"encoding/json" // Raw document bodies
+	"fmt"           // Header validation
+	"net/http"      // Delivery
+	"os"            // Environment expansion in header values
+	"strings"       // Header parsing
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// deliveryExitCode is returned when analysis succeeded but -post-url delivery
+// failed, so pipelines can tell the two apart.
+const deliveryExitCode = 3
+
+// headerList collects repeated -post-header flags.
+type headerList []string
+
+func (h *headerList) String() string { return strings.Join(*h, ", ") }
+
+func (h *headerList) Set(value string) error {
+	if name, _, ok := strings.Cut(value, ":"); !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("expected \"Name: value\", got %q", value)
+	}
+	*h = append(*h, value)
+	return nil
+}
+
+// postHeaders turns the -post-header flags into request headers. Values may
+// reference environment variables ($TOKEN or ${TOKEN}) so secrets stay out of
+// command lines and process listings.
+func postHeaders() http.Header {
+	headers := make(http.Header)
+	for _, header := range opts.PostHeaders {
+		name, value, _ := strings.Cut(header, ":")
+		headers.Add(strings.TrimSpace(name), os.ExpandEnv(strings.TrimSpace(value)))
+	}
+	return headers
+}
+
+// postResults sends the JSON results to -post-url: one request per report, or
+// a single combined document under -post-mode combined. It returns the number
+// of deliveries that failed after retries.
+func postResults(reports []*report) int {
+	if opts.PostURL == "" || len(reports) == 0 {
+		return 0
+	}
+	client := &http.Client{Timeout: opts.PostTimeout}
+	if opts.PostInsecure {
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+	headers := postHeaders()
+
+	batches := [][]*report{reports}
+	if opts.PostMode == "per-target" {
+		batches = nil
+		for _, r := range reports {
+			batches = append(batches, []*report{r})
+		}
+	}
+	failed := 0
+	for _, batch := range batches {
+		var document bytes.Buffer
+		if err := writeJSON(&document, batch); err != nil {
+			color.Red("Error encoding results for %s: %s\n", redactURL(opts.PostURL), err)
+			failed++
+			continue
+		}
+		body := json.RawMessage(document.Bytes())
+		if err := withRetry(func() error { return postJSON(client, opts.PostURL, headers, body) }); err != nil {
+			label := "combined results"
+			if len(batch) == 1 {
+				label = batch[0].Target
+			}
+			color.Red("Error delivering %s to %s: %s\n", label, redactURL(opts.PostURL), err)
+			failed++
+		}
+	}
+	return failed
+}
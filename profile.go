@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"            // Profile output files
+	"runtime"       // Forcing a GC before the heap snapshot
+	"runtime/pprof" // CPU and heap profiles
+	"runtime/trace" // Execution traces
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// startProfiling starts the CPU profile and execution trace requested on the
+// command line. Empty paths disable the corresponding profiler. The returned
+// function stops them and writes the heap profile; it is safe to call when
+// nothing was enabled.
+func startProfiling(cpuPath, memPath, tracePath string) (func(), error) {
+	var stops []func()
+	stop := func() {
+		for i := len(stops) - 1; i >= 0; i-- {
+			stops[i]()
+		}
+	}
+
+	if cpuPath != "" {
+		file, err := os.Create(cpuPath)
+		if err != nil {
+			return stop, err
+		}
+		if err := pprof.StartCPUProfile(file); err != nil {
+			file.Close()
+			return stop, err
+		}
+		stops = append(stops, func() {
+			pprof.StopCPUProfile()
+			file.Close()
+		})
+	}
+
+	if tracePath != "" {
+		file, err := os.Create(tracePath)
+		if err != nil {
+			stop()
+			return func() {}, err
+		}
+		if err := trace.Start(file); err != nil {
+			file.Close()
+			stop()
+			return func() {}, err
+		}
+		stops = append(stops, func() {
+			trace.Stop()
+			file.Close()
+		})
+	}
+
+	if memPath != "" {
+		stops = append(stops, func() {
+			file, err := os.Create(memPath)
+			if err != nil {
+				color.Red("Error writing heap profile: %s\n", err)
+				return
+			}
+			defer file.Close()
+			runtime.GC() // Get up-to-date statistics for the heap snapshot
+			if err := pprof.WriteHeapProfile(file); err != nil {
+				color.Red("Error writing heap profile: %s\n", err)
+			}
+		})
+	}
+
+	return stop, nil
+}
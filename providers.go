@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"     // Output formatting
+	"io"      // Output destination
+	"slices"  // Path coverage checks
+	"strings" // Authority splitting and path synthesis
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// unprotectedPaths are tried in order as the example of an open provider path.
+var unprotectedPaths = []string{"/", "/example", "/deeeeper/unprotected"}
+
+// pathPermissionFindings flags exported providers relying solely on
+// <path-permission>: with no provider-level permission, every path outside the
+// declared ones is open to any app.
+func pathPermissionFindings(manifest Manifest) []finding {
+	var findings []finding
+	for _, component := range manifest.Application.Providers {
+		exported, _ := isExported(component, "provider")
+		if !exported || len(component.PathPermissions) == 0 || len(permissionRefs(component, manifest.Application.Permission)) > 0 {
+			continue
+		}
+		var protected []string
+		for _, p := range component.PathPermissions {
+			protected = append(protected, p.Declared())
+		}
+		evidence := fmt.Sprintf("no android:permission, readPermission or writePermission; path-permission covers only %s; all other paths are open", strings.Join(protected, ", "))
+		var uris []string
+		if authorities := providerAuthorities(component); len(authorities) > 0 {
+			for _, candidate := range unprotectedPaths {
+				if !slices.ContainsFunc(component.PathPermissions, func(p ProviderPath) bool { return p.Matches(candidate) }) {
+					uris = []string{"content://" + authorities[0] + candidate}
+					evidence += "; e.g. " + uris[0]
+					break
+				}
+			}
+		}
+		findings = append(findings, newFinding(manifest.Package, "path-permission-only", "provider", component.Name, uris, evidence))
+	}
+	return findings
+}
+
+// providerAuthorities splits a provider's android:authorities, which lists
+// several authorities separated by semicolons, trimming and deduplicating them.
+func providerAuthorities(component App) []string {
+	var authorities []string
+	for _, authority := range strings.Split(component.Authorities, ";") {
+		if authority = strings.TrimSpace(authority); authority != "" {
+			authorities = appendUnique(authorities, authority)
+		}
+	}
+	return authorities
+}
+
+// duplicateAuthorities describes every authority claimed by more than one
+// provider. Only one of them can be installed under that authority.
+func duplicateAuthorities(manifest Manifest) []string {
+	claims := make(map[string][]string)
+	for _, component := range manifest.Application.Providers {
+		for _, authority := range providerAuthorities(component) {
+			claims[authority] = append(claims[authority], component.Name)
+		}
+	}
+	var conflicts []string
+	for _, authority := range sortedKeys(claims) {
+		if len(claims[authority]) > 1 {
+			conflicts = append(conflicts, fmt.Sprintf("authority %s is claimed by several providers: %s", authority, strings.Join(claims[authority], ", ")))
+		}
+	}
+	return conflicts
+}
+
+// grantable reports whether other apps can be handed access to the provider's
+// URIs even when it isn't exported.
+func grantable(component App) bool {
+	return isTrue(component.GrantURIPermissions) || len(component.GrantURIPaths) > 0
+}
+
+// providerURIs lists, for every authority, the base content:// URI followed by
+// one URI per path-permission and grant-uri-permission path.
+func providerURIs(component App) []string {
+	var uris []string
+	for _, authority := range providerAuthorities(component) {
+		base := "content://" + authority
+		uris = appendUnique(uris, base)
+		for _, paths := range [][]ProviderPath{component.PathPermissions, component.GrantURIPaths} {
+			for _, p := range paths {
+				path := p.Example()
+				if path == "" {
+					continue
+				}
+				if !strings.HasPrefix(path, "/") {
+					path = "/" + path
+				}
+				uris = appendUnique(uris, base+path)
+			}
+		}
+	}
+	return uris
+}
+
+// providerTestCases builds a "content query" and a "content read" case for every
+// URI of an exported or grantable provider. They carry no intent action.
+func providerTestCases(manifest Manifest) []testCase {
+	var cases []testCase
+	for _, component := range manifest.Application.Providers {
+		if exported, _ := isExported(component, "provider"); !exported && !grantable(component) {
+			continue
+		}
+		name := qualifiedName(manifest.Package, component.Name)
+		for _, uri := range providerURIs(component) {
+			problem := uriProblem(uri)
+			for _, operation := range []string{"query", "read"} {
+				c := testCase{URI: uri, Package: manifest.Package, Component: name, Invalid: problem}
+				if problem == "" {
+					c.ADB = fmt.Sprintf("adb shell content %s --uri %s", operation, shellQuote(uri))
+				}
+				cases = append(cases, c)
+			}
+		}
+	}
+	return cases
+}
+
+// isProviderCase reports whether a test case targets a content provider rather
+// than an intent handler, so it has nothing a browser or camera could open.
+func isProviderCase(c testCase) bool {
+	return c.Action == ""
+}
+
+// printADBCommands lists the adb command of every test case with a valid URI
+// under the component it targets, providers included.
+func printADBCommands(w io.Writer, cases []testCase) {
+	printGroupedCommands(w, "\nADB commands:", cases, func(testCase) bool { return true })
+}
+
+// printDeeplinkCommands lists only the am commands of deeplinks under the
+// component they target, each with the filter's action and -n package/component.
+func printDeeplinkCommands(w io.Writer, cases []testCase) {
+	printGroupedCommands(w, "\nDeeplink commands:", cases, func(c testCase) bool {
+		return c.URI != "" && !isProviderCase(c)
+	})
+}
+
+// printGroupedCommands prints the adb command of the kept test cases grouped
+// under the component they target, in manifest order and without repeats, so
+// a component's commands can be copied and run together.
+func printGroupedCommands(w io.Writer, title string, cases []testCase, keep func(testCase) bool) {
+	printSection(w, title)
+	var components []string
+	commands := make(map[string][]string)
+	for _, c := range cases {
+		if c.ADB == "" || !keep(c) {
+			continue
+		}
+		if _, ok := commands[c.Component]; !ok {
+			components = append(components, c.Component)
+		}
+		commands[c.Component] = appendUnique(commands[c.Component], c.ADB)
+	}
+	cyan := color.New(color.FgCyan).SprintFunc()
+	for _, component := range components {
+		fmt.Fprintf(w, "  %s\n", cyan(component))
+		for _, command := range commands[component] {
+			fmt.Fprintf(w, "    %s\n", command)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"   // Captured warnings
+	"io"      // Discarded text report
+	"slices"  // Result comparison
+	"strings" // Output checks
+	"testing" // Test harness
+)
+
+// authoritiesManifest has a provider with one authority, one with two plus a
+// repeated entry, and one claiming an authority the second already has.
+const authoritiesManifest = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example.app">
+    <application>
+        <provider android:name=".Single" android:authorities="com.example.app.single" android:exported="true"/>
+        <provider android:name=".Files" android:authorities="com.example.app.files; com.example.app.files.legacy ;com.example.app.files;" android:exported="true"/>
+        <provider android:name=".LegacyFiles" android:authorities="com.example.app.files.legacy" android:exported="true"/>
+    </application>
+</manifest>
+`
+
+func TestProviderAuthorities(t *testing.T) {
+	for _, tc := range []struct {
+		authorities string
+		want        []string
+	}{
+		{"", nil},
+		{"com.example.app.single", []string{"com.example.app.single"}},
+		{"com.example.app.files;com.example.app.files.legacy", []string{"com.example.app.files", "com.example.app.files.legacy"}},
+		{" a ; b ;; ", []string{"a", "b"}},
+		{"a;b;a", []string{"a", "b"}},
+	} {
+		if got := providerAuthorities(App{Authorities: tc.authorities}); !slices.Equal(got, tc.want) {
+			t.Errorf("providerAuthorities(%q) = %q, want %q", tc.authorities, got, tc.want)
+		}
+	}
+}
+
+func TestDuplicateAuthorities(t *testing.T) {
+	manifest := parseTestManifest(t, authoritiesManifest)
+	want := []string{"authority com.example.app.files.legacy is claimed by several providers: .Files, .LegacyFiles"}
+	if got := duplicateAuthorities(manifest); !slices.Equal(got, want) {
+		t.Errorf("duplicateAuthorities = %q, want %q", got, want)
+	}
+	manifest.Application.Providers = manifest.Application.Providers[:2]
+	if got := duplicateAuthorities(manifest); len(got) != 0 {
+		t.Errorf("a provider repeating its own authority is reported: %q", got)
+	}
+}
+
+// TestAuthoritiesReport checks each authority is its own entry in the text
+// report, the structured components, the content:// test cases and the
+// warnings.
+func TestAuthoritiesReport(t *testing.T) {
+	setOpts(t, textOptions())
+	result, text := renderFixture(t, authoritiesManifest, "<resources/>")
+	for _, want := range []string{"content://com.example.app.single\n", "content://com.example.app.files\n", "content://com.example.app.files.legacy\n"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report lacks %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, ";") {
+		t.Errorf("report shows a raw authorities list:\n%s", text)
+	}
+
+	for _, c := range result.Components {
+		if c.Name == ".Files" && !slices.Equal(c.Authorities, []string{"com.example.app.files", "com.example.app.files.legacy"}) {
+			t.Errorf(".Files authorities = %q, want an array of both", c.Authorities)
+		}
+	}
+	if out := reportJSON(t, result); !strings.Contains(out, "\"authorities\": [\n") {
+		t.Errorf("JSON doesn't model authorities as an array")
+	}
+
+	var uris []string
+	for _, c := range result.TestCases {
+		if c.Component == "com.example.app.Files" {
+			uris = appendUnique(uris, c.URI)
+		}
+	}
+	if want := []string{"content://com.example.app.files", "content://com.example.app.files.legacy"}; !slices.Equal(uris, want) {
+		t.Errorf(".Files test case URIs = %q, want %q", uris, want)
+	}
+
+	dir := t.TempDir()
+	if err := writeSelftestTarget(dir, authoritiesManifest, "<resources/>"); err != nil {
+		t.Fatal(err)
+	}
+	var progress bytes.Buffer
+	if _, err := analyzeFolder(io.Discard, &progress, dir); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(progress.String(), "Warning: authority com.example.app.files.legacy is claimed by several providers: .Files, .LegacyFiles") {
+		t.Errorf("no warning for the authority claimed twice:\n%s", progress.String())
+	}
+}
+
+// commandSection returns the lines of a titled command section of a report.
+func commandSection(text, title string) string {
+	_, section, _ := strings.Cut(text, title+"\n")
+	section, _, _ = strings.Cut(section, "\n\n")
+	return strings.TrimSuffix(section, "\n")
+}
+
+// TestADBCommandsGrouped checks -adb lists each component's deeplink commands
+// together, in manifest order and without repeats, with the package from the
+// manifest, and that -adb-commands adds the providers.
+func TestADBCommandsGrouped(t *testing.T) {
+	options := textOptions()
+	options.ADB = true
+	setOpts(t, options)
+	manifest := strings.Replace(sspManifest, "</application>", `    <provider android:name=".Files" android:authorities="com.example.files" android:exported="true"/>
+    </application>`, 1)
+	_, text := renderFixture(t, manifest, "<resources/>")
+	deeplinks := `  org.example.watcher.Compose
+    adb shell am start -W -a android.intent.action.SENDTO -d mailto:support@example.com -n org.example.watcher/org.example.watcher.Compose
+    adb shell am start -W -a android.intent.action.SENDTO -d 'sms:555.*' -n org.example.watcher/org.example.watcher.Compose
+  org.example.watcher.PackageWatcher
+    adb shell am broadcast -a android.intent.action.PACKAGE_REMOVED -d 'package:com.example.*' -n org.example.watcher/org.example.watcher.PackageWatcher`
+	if section := commandSection(text, "Deeplink commands:"); section != deeplinks {
+		t.Errorf("Deeplink commands section:\n%s\nwant:\n%s", section, deeplinks)
+	}
+	if strings.Contains(text, "ADB commands:") || strings.Contains(text, "content query") {
+		t.Errorf("-adb listed provider commands:\n%s", text)
+	}
+
+	options.ADB, options.ADBCommands = false, true
+	setOpts(t, options)
+	_, text = renderFixture(t, manifest, "<resources/>")
+	section := commandSection(text, "ADB commands:")
+	want := deeplinks + `
+  org.example.watcher.Files
+    adb shell content query --uri content://com.example.files
+    adb shell content read --uri content://com.example.files`
+	if section != want {
+		t.Errorf("ADB commands section:\n%s\nwant:\n%s", section, want)
+	}
+}
@@ -0,0 +1,493 @@
+package main
+
+import (
+	"fmt"     // Rendering
+	"io"      // Output destination
+	"net/url" // Deciding which URIs are scannable
+	"strings" // Row assembly
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// qrBlocks describes the error correction layout of one QR version at level M:
+// EC codewords per block, then the count and data length of both block groups.
+type qrBlocks struct {
+	ecPerBlock           int
+	group1, group1Length int
+	group2, group2Length int
+}
+
+// qrVersions lists the level-M block layout of versions 1-20, enough for URIs
+// of about 400 bytes.
+var qrVersions = []qrBlocks{
+	{10, 1, 16, 0, 0}, {16, 1, 28, 0, 0}, {26, 1, 44, 0, 0}, {18, 2, 32, 0, 0},
+	{24, 2, 43, 0, 0}, {16, 4, 27, 0, 0}, {18, 4, 31, 0, 0}, {22, 2, 38, 2, 39},
+	{22, 3, 36, 2, 37}, {26, 4, 43, 1, 44}, {30, 1, 50, 4, 51}, {22, 6, 36, 2, 37},
+	{22, 8, 37, 1, 38}, {24, 4, 40, 5, 41}, {24, 5, 41, 5, 42}, {28, 7, 45, 3, 46},
+	{28, 10, 46, 1, 47}, {26, 9, 43, 4, 44}, {26, 3, 44, 11, 45}, {26, 3, 41, 13, 42},
+}
+
+// qrAlignment lists the alignment pattern centers of versions 2-20.
+var qrAlignment = [][]int{
+	nil, {6, 18}, {6, 22}, {6, 26}, {6, 30}, {6, 34}, {6, 22, 38}, {6, 24, 42},
+	{6, 26, 46}, {6, 28, 50}, {6, 30, 54}, {6, 32, 58}, {6, 34, 62}, {6, 26, 46, 66},
+	{6, 26, 48, 70}, {6, 26, 50, 74}, {6, 30, 54, 78}, {6, 30, 56, 82}, {6, 30, 58, 86},
+	{6, 34, 62, 90},
+}
+
+// qrCode is an encoded symbol; modules[y][x] is true for dark modules.
+type qrCode struct {
+	size     int
+	modules  [][]bool
+	function [][]bool // Finder, timing, alignment and format modules, never masked
+}
+
+// encodeQR encodes text in byte mode at error correction level M, choosing the
+// smallest version that fits and the mask with the lowest penalty.
+func encodeQR(text string) (*qrCode, error) {
+	data := []byte(text)
+	version := 0
+	for v := 1; v <= len(qrVersions); v++ {
+		if 4+qrCountBits(v)+8*len(data) <= 8*qrDataCapacity(v) {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("%d bytes is too long for a QR code", len(data))
+	}
+
+	// Mode indicator, character count, payload, terminator and padding
+	var bits qrBits
+	bits.append(0b0100, 4)
+	bits.append(len(data), qrCountBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	capacity := 8 * qrDataCapacity(version)
+	bits.append(0, min(4, capacity-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+
+	q := newQRCode(version)
+	q.placeData(qrInterleave(bits.bytes(), qrVersions[version-1]))
+
+	best, bestPenalty := -1, 0
+	for mask := range 8 {
+		q.applyMask(mask)
+		q.drawFormat(mask)
+		if penalty := q.penalty(); best < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		q.applyMask(mask) // Masks are XORs, applying one twice undoes it
+	}
+	q.applyMask(best)
+	q.drawFormat(best)
+	return q, nil
+}
+
+// qrCountBits is the width of the byte-mode character count field.
+func qrCountBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// qrDataCapacity is the number of data codewords a version holds at level M.
+func qrDataCapacity(version int) int {
+	b := qrVersions[version-1]
+	return b.group1*b.group1Length + b.group2*b.group2Length
+}
+
+// qrBits is a bit stream, one bit per element.
+type qrBits []bool
+
+func (b *qrBits) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*b = append(*b, value>>i&1 == 1)
+	}
+}
+
+func (b qrBits) bytes() []byte {
+	out := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			out[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return out
+}
+
+// qrInterleave splits data into blocks, appends Reed-Solomon codewords to each
+// and interleaves the result column by column as the standard requires.
+func qrInterleave(data []byte, layout qrBlocks) []byte {
+	divisor := rsDivisor(layout.ecPerBlock)
+	var blocks, ecBlocks [][]byte
+	for i := range layout.group1 + layout.group2 {
+		length := layout.group1Length
+		if i >= layout.group1 {
+			length = layout.group2Length
+		}
+		block := data[:length]
+		data = data[length:]
+		blocks = append(blocks, block)
+		ecBlocks = append(ecBlocks, rsRemainder(block, divisor))
+	}
+
+	var out []byte
+	for i := range max(layout.group1Length, layout.group2Length) {
+		for _, block := range blocks {
+			if i < len(block) {
+				out = append(out, block[i])
+			}
+		}
+	}
+	for i := range layout.ecPerBlock {
+		for _, ec := range ecBlocks {
+			out = append(out, ec[i])
+		}
+	}
+	return out
+}
+
+// rsDivisor returns the Reed-Solomon generator polynomial of the given degree,
+// highest coefficient first and without the leading 1.
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for range degree {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// rsRemainder computes the error correction codewords of one block.
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coefficient := range divisor {
+			result[i] ^= gfMultiply(coefficient, factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies in GF(2^8) modulo the QR polynomial x^8+x^4+x^3+x^2+1.
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11D
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+// newQRCode lays out the function patterns of a version.
+func newQRCode(version int) *qrCode {
+	size := 17 + 4*version
+	q := &qrCode{size: size, modules: make([][]bool, size), function: make([][]bool, size)}
+	for y := range size {
+		q.modules[y] = make([]bool, size)
+		q.function[y] = make([]bool, size)
+	}
+
+	for i := range size { // Timing patterns
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+	for _, corner := range [][2]int{{3, 3}, {size - 4, 3}, {3, size - 4}} { // Finders with separators
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := corner[0]+dx, corner[1]+dy
+				if x >= 0 && x < size && y >= 0 && y < size {
+					dist := max(abs(dx), abs(dy))
+					q.setFunction(x, y, dist != 2 && dist != 4)
+				}
+			}
+		}
+	}
+	centers := qrAlignment[version-1]
+	for i, cx := range centers {
+		for j, cy := range centers {
+			last := len(centers) - 1
+			if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+				continue // Overlaps a finder
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					q.setFunction(cx+dx, cy+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+	q.drawFormat(0) // Reserve the format areas; redrawn once the mask is chosen
+
+	if version >= 7 {
+		rem := version
+		for range 12 {
+			rem = rem<<1 ^ (rem>>11)*0x1F25
+		}
+		bits := version<<12 | rem
+		for i := range 18 {
+			dark := bits>>i&1 == 1
+			a, b := size-11+i%3, i/3
+			q.setFunction(a, b, dark)
+			q.setFunction(b, a, dark)
+		}
+	}
+	return q
+}
+
+func (q *qrCode) setFunction(x, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.function[y][x] = true
+}
+
+// drawFormat writes both copies of the format information for level M and the
+// given mask, plus the always-dark module.
+func (q *qrCode) drawFormat(mask int) {
+	data := mask // Level M's two format bits are 00
+	rem := data
+	for range 10 {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		q.setFunction(8, i, bit(i))
+	}
+	q.setFunction(8, 7, bit(6))
+	q.setFunction(8, 8, bit(7))
+	q.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		q.setFunction(14-i, 8, bit(i))
+	}
+	for i := range 8 {
+		q.setFunction(q.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunction(8, q.size-15+i, bit(i))
+	}
+	q.setFunction(8, q.size-8, true)
+}
+
+// placeData fills the non-function modules in the standard zigzag order,
+// two columns at a time from the bottom right. Remainder modules stay light.
+func (q *qrCode) placeData(codewords []byte) {
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // Skip the vertical timing pattern
+		}
+		for vert := range q.size {
+			for j := range 2 {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 { // Upward column pair
+					y = q.size - 1 - vert
+				}
+				if !q.function[y][x] && i < len(codewords)*8 {
+					q.modules[y][x] = codewords[i>>3]>>(7-i&7)&1 == 1
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask XORs one of the eight standard mask patterns over the data modules.
+func (q *qrCode) applyMask(mask int) {
+	for y := range q.size {
+		for x := range q.size {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !q.function[y][x] {
+				q.modules[y][x] = !q.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores the symbol with the four standard rules; lower scans better.
+func (q *qrCode) penalty() int {
+	score, dark := 0, 0
+	finderLike := []string{"10111010000", "00001011101"}
+	line := make([]byte, q.size)
+	for pass := range 2 { // Rows, then columns
+		for a := range q.size {
+			run := 0
+			for b := range q.size {
+				module := q.modules[a][b]
+				if pass == 1 {
+					module = q.modules[b][a]
+				}
+				line[b] = '0'
+				if module {
+					line[b] = '1'
+				}
+				if b > 0 && line[b] == line[b-1] {
+					run++
+				} else {
+					run = 1
+				}
+				if run == 5 {
+					score += 3
+				} else if run > 5 {
+					score++
+				}
+			}
+			for _, pattern := range finderLike {
+				score += 40 * strings.Count(string(line), pattern)
+			}
+		}
+	}
+	for y := range q.size {
+		for x := range q.size {
+			if q.modules[y][x] {
+				dark++
+			}
+			if x+1 < q.size && y+1 < q.size {
+				c := q.modules[y][x]
+				if q.modules[y][x+1] == c && q.modules[y+1][x] == c && q.modules[y+1][x+1] == c {
+					score += 3
+				}
+			}
+		}
+	}
+	percent := dark * 100 / (q.size * q.size)
+	return score + 10*(abs(percent-50)/5)
+}
+
+// render draws the symbol with Unicode half blocks, two module rows per line,
+// inside a four-module quiet zone. Light modules are drawn as filled cells so
+// the code scans on the usual dark-background terminal.
+func (q *qrCode) render(w io.Writer) {
+	const quiet = 4
+	light := func(x, y int) bool {
+		x, y = x-quiet, y-quiet
+		return x < 0 || y < 0 || x >= q.size || y >= q.size || !q.modules[y][x]
+	}
+	total := q.size + 2*quiet
+	for y := 0; y < total; y += 2 {
+		var line strings.Builder
+		for x := range total {
+			top, bottom := light(x, y), y+1 >= total || light(x, y+1)
+			switch {
+			case top && bottom:
+				line.WriteString("█")
+			case top:
+				line.WriteString("▀")
+			case bottom:
+				line.WriteString("▄")
+			default:
+				line.WriteString(" ")
+			}
+		}
+		fmt.Fprintln(w, line.String())
+	}
+}
+
+// svg draws the symbol as an inline SVG image with a four-module quiet zone,
+// for embedding in HTML reports.
+func (q *qrCode) svg() string {
+	const quiet = 4
+	var path strings.Builder
+	for y := range q.size {
+		for x := range q.size {
+			if q.modules[y][x] {
+				fmt.Fprintf(&path, "M%d,%dh1v1h-1z", x+quiet, y+quiet)
+			}
+		}
+	}
+	total := q.size + 2*quiet
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d" shape-rendering="crispEdges"><rect width="100%%" height="100%%" fill="#fff"/><path d="%s" fill="#000"/></svg>`,
+		total, total, total*4, total*4, path.String())
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// scannableURI reports whether a deeplink is concrete enough to be worth a QR
+// code, and if not, why.
+func scannableURI(uri string) (bool, string) {
+	if strings.Contains(uri, "*") {
+		return false, "contains an unexpanded wildcard"
+	}
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme == "" {
+		return false, "not a well-formed URI"
+	}
+	if (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host == "" {
+		return false, "web URI without a host"
+	}
+	if parsed.Host == "" && parsed.Opaque == "" && parsed.Path == "" {
+		return false, "nothing after the scheme"
+	}
+	return true, ""
+}
+
+// printQRCodes renders a QR code for every distinct scannable deeplink, up to
+// limit codes when limit is positive.
+func printQRCodes(w io.Writer, cases []testCase, limit int) {
+	printSection(w, "\nQR codes:")
+	cyan := color.New(color.FgCyan).SprintFunc()
+	seen := make(map[string]bool)
+	printed := 0
+	for _, c := range cases {
+		if c.URI == "" || isProviderCase(c) || seen[c.URI] {
+			continue
+		}
+		seen[c.URI] = true
+		if ok, reason := scannableURI(c.URI); !ok {
+			fmt.Fprintf(w, "%s skipped: %s\n", c.URI, reason)
+			continue
+		}
+		if limit > 0 && printed == limit {
+			fmt.Fprintf(w, "(more deeplinks omitted, -qr-limit %d reached)\n", limit)
+			return
+		}
+		code, err := encodeQR(c.URI)
+		if err != nil {
+			fmt.Fprintf(w, "%s skipped: %s\n", c.URI, err)
+			continue
+		}
+		fmt.Fprintf(w, "%s → %s\n", cyan(c.URI), c.Component)
+		code.render(w)
+		printed++
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"os"            // Written report
+	"path/filepath" // Report path
+	"slices"        // Result comparison
+	"strings"       // Output checks
+	"testing"       // Test harness
+)
+
+// qrFormatM lists the 15-bit format information of level M for masks 0-7, as
+// tabulated in ISO/IEC 18004 (after the 0x5412 XOR).
+var qrFormatM = []int{0x5412, 0x5125, 0x5E7C, 0x5B4B, 0x45F9, 0x40CE, 0x4F97, 0x4AA0}
+
+// readFormat reads the format information copy around the top left finder.
+func readFormat(q *qrCode) int {
+	var bits int
+	at := func(i, x, y int) {
+		if q.modules[y][x] {
+			bits |= 1 << i
+		}
+	}
+	for i := 0; i <= 5; i++ {
+		at(i, 8, i)
+	}
+	at(6, 8, 7)
+	at(7, 8, 8)
+	at(8, 7, 8)
+	for i := 9; i < 15; i++ {
+		at(i, 14-i, 8)
+	}
+	return bits
+}
+
+// readCodewords reads the data modules back in placement order.
+func readCodewords(q *qrCode) []byte {
+	var bits qrBits
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := range q.size {
+			for j := range 2 {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = q.size - 1 - vert
+				}
+				if !q.function[y][x] {
+					bits = append(bits, q.modules[y][x])
+				}
+			}
+		}
+	}
+	return bits[:len(bits)/8*8].bytes()
+}
+
+// TestRSRemainder checks the error correction of the worked 1-M example of the
+// standard's tutorials ("HELLO WORLD" in alphanumeric mode).
+func TestRSRemainder(t *testing.T) {
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsDivisor(10)); !slices.Equal(got, want) {
+		t.Errorf("rsRemainder = %v, want %v", got, want)
+	}
+}
+
+func TestDrawFormat(t *testing.T) {
+	q := newQRCode(1)
+	for mask, want := range qrFormatM {
+		q.drawFormat(mask)
+		if got := readFormat(q); got != want {
+			t.Errorf("mask %d: format bits %015b, want %015b", mask, got, want)
+		}
+		var second int
+		for i := range 8 {
+			if q.modules[8][q.size-1-i] {
+				second |= 1 << i
+			}
+		}
+		for i := 8; i < 15; i++ {
+			if q.modules[q.size-15+i][8] {
+				second |= 1 << i
+			}
+		}
+		if second != want {
+			t.Errorf("mask %d: second copy %015b, want %015b", mask, second, want)
+		}
+	}
+}
+
+// TestVersionInformation checks the version 7 block against the standard's
+// table entry 0x07C94.
+func TestVersionInformation(t *testing.T) {
+	q := newQRCode(7)
+	var bits int
+	for i := range 18 {
+		if q.modules[i/3][q.size-11+i%3] {
+			bits |= 1 << i
+		}
+	}
+	if bits != 0x07C94 {
+		t.Errorf("version information %018b, want %018b", bits, 0x07C94)
+	}
+}
+
+func TestQRInterleave(t *testing.T) {
+	layout := qrVersions[7] // Version 8: two blocks of 38 and two of 39
+	data := make([]byte, qrDataCapacity(8))
+	for i := range data {
+		data[i] = byte(i)
+	}
+	out := qrInterleave(data, layout)
+	if len(out) != len(data)+4*layout.ecPerBlock {
+		t.Fatalf("%d codewords, want %d", len(out), len(data)+4*layout.ecPerBlock)
+	}
+	if want := []byte{0, 38, 76, 115, 1, 39}; !slices.Equal(out[:6], want) {
+		t.Errorf("first codewords %v, want %v", out[:6], want)
+	}
+	if want := []byte{114, 153}; !slices.Equal(out[152:154], want) {
+		t.Errorf("codewords of the longer blocks %v, want %v", out[152:154], want)
+	}
+}
+
+// TestEncodeQR reads a symbol back: the format names the mask the encoder
+// chose, and once unmasked the data modules hold the byte-mode payload padded
+// as the standard requires, followed by its error correction.
+func TestEncodeQR(t *testing.T) {
+	const uri = "https://example.com/p/1"
+	q, err := encodeQR(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.size != 25 {
+		t.Fatalf("size %d, want version 2 (25 modules)", q.size)
+	}
+	for i := range q.size { // Timing pattern along row 6
+		if i > 7 && i < q.size-8 && q.modules[6][i] != (i%2 == 0) {
+			t.Errorf("timing module (%d,6) = %t", i, q.modules[6][i])
+		}
+	}
+	if !q.modules[q.size-8][8] {
+		t.Error("dark module is light")
+	}
+
+	mask := slices.Index(qrFormatM, readFormat(q))
+	if mask < 0 {
+		t.Fatalf("format bits %015b are no level M format", readFormat(q))
+	}
+	q.applyMask(mask)
+	codewords := readCodewords(q)
+	data, ec := codewords[:28], codewords[28:44]
+
+	var want qrBits
+	want.append(0b0100, 4)
+	want.append(len(uri), 8)
+	for _, b := range []byte(uri) {
+		want.append(int(b), 8)
+	}
+	want.append(0, 4)
+	wantData := append(want.bytes(), 0xEC, 0x11, 0xEC)
+	if !slices.Equal(data, wantData) {
+		t.Errorf("data codewords\n%v\nwant\n%v", data, wantData)
+	}
+	if want := rsRemainder(wantData, rsDivisor(16)); !slices.Equal(ec, want) {
+		t.Errorf("error correction %v, want %v", ec, want)
+	}
+}
+
+func TestEncodeQRVersions(t *testing.T) {
+	for length, size := range map[int]int{14: 21, 15: 25, 213: 57, 214: 61, 666: 97} {
+		q, err := encodeQR(strings.Repeat("a", length))
+		if err != nil {
+			t.Errorf("%d bytes: %v", length, err)
+			continue
+		}
+		if q.size != size {
+			t.Errorf("%d bytes: size %d, want %d", length, q.size, size)
+		}
+	}
+	if _, err := encodeQR(strings.Repeat("a", 667)); err == nil {
+		t.Error("667 bytes encoded, want an error past version 20")
+	}
+}
+
+// TestHTMLFileQR checks -html embeds a QR code under each scannable deeplink
+// and leaves patterns with wildcards as text only.
+func TestHTMLFileQR(t *testing.T) {
+	options := textOptions()
+	options.QR = true
+	setOpts(t, options)
+	r := analyzeFixture(t, `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="org.example.qr">
+    <application>
+        <activity android:name=".Open" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="qrapp" android:host="open"/>
+                <data android:scheme="qrapp" android:host="open" android:pathPattern="/.*"/>
+            </intent-filter>
+        </activity>
+    </application>
+</manifest>
+`, "<resources/>")
+	path := filepath.Join(t.TempDir(), "report.html")
+	if err := writeHTMLFile(path, []*report{r}); err != nil {
+		t.Fatal(err)
+	}
+	page, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<li><code>qrapp://open</code><button type="button" data-copy="qrapp://open">Copy</button><svg `
+	if !strings.Contains(string(page), want) {
+		t.Errorf("report lacks the QR code of qrapp://open:\n%s", page)
+	}
+	if n := strings.Count(string(page), "<svg "); n != 1 {
+		t.Errorf("%d QR codes, want 1 (the wildcard pattern isn't scannable):\n%s", n, page)
+	}
+}
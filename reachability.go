@@ -0,0 +1,50 @@
+package main
+
+import (
+	"slices"  // Severity lookup
+	"strings" // Evidence assembly
+)
+
+// chooserTargetMetaData names the meta-data through which an activity offers
+// direct share targets, served by the ChooserTargetService it points to.
+const chooserTargetMetaData = "android.service.chooser.chooser_target_service"
+
+// Badges of components reachable in reduced-trust contexts.
+const (
+	badgeLockScreen  = "reachable from lock screen"
+	badgeDirectShare = "direct share target"
+)
+
+// reachabilityBadges lists the reduced-trust contexts a component can be
+// reached from, each with the manifest evidence behind it: the lock screen
+// (android:showWhenLocked, or the legacy showOnLockScreen) and the share sheet's
+// direct share row (chooser_target_service meta-data).
+func reachabilityBadges(component App) (badges, evidence []string) {
+	switch {
+	case isTrue(component.ShowWhenLocked):
+		badges, evidence = append(badges, badgeLockScreen), append(evidence, `android:showWhenLocked="true"`)
+	case isTrue(component.ShowOnLockScreen):
+		badges, evidence = append(badges, badgeLockScreen), append(evidence, `android:showOnLockScreen="true"`)
+	}
+	for _, meta := range component.MetaData {
+		if meta.Name == chooserTargetMetaData {
+			badges, evidence = append(badges, badgeDirectShare), append(evidence, chooserTargetMetaData+"="+meta.Value)
+			break
+		}
+	}
+	return badges, evidence
+}
+
+// weighReachability raises an exported component's finding one severity level
+// when it is reachable from the lock screen or the direct share row, where the
+// user (or whoever holds the device) acts with less context than usual.
+func weighReachability(f *finding, component App) {
+	badges, evidence := reachabilityBadges(component)
+	if len(badges) == 0 {
+		return
+	}
+	if i := slices.Index(severityOrder, f.Severity); i > 0 {
+		f.Severity = severityOrder[i-1]
+	}
+	f.Evidence += "; " + strings.Join(badges, ", ") + " (" + strings.Join(evidence, ", ") + ")"
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"slices"  // Badge comparison
+	"strings" // Output checks
+	"testing" // Test harness
+)
+
+// reachabilityManifest has one activity per reduced-trust context: lock
+// screen, legacy lock screen spelling, direct share, both at once, neither,
+// and a lock-screen activity that isn't exported.
+const reachabilityManifest = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example.app">
+    <application>
+        <activity android:name=".Plain" android:exported="true"/>
+        <activity android:name=".Locked" android:exported="true" android:showWhenLocked="true"/>
+        <activity android:name=".LegacyLocked" android:exported="true" android:showOnLockScreen="true"/>
+        <activity android:name=".Share" android:exported="true">
+            <meta-data android:name="android.service.chooser.chooser_target_service" android:value=".ShareTargetService"/>
+        </activity>
+        <activity android:name=".LockedShare" android:exported="true" android:showWhenLocked="true" android:showOnLockScreen="true">
+            <meta-data android:name="android.service.chooser.chooser_target_service" android:value=".ShareTargetService"/>
+        </activity>
+        <activity android:name=".InternalLocked" android:exported="false" android:showWhenLocked="true"/>
+        <activity android:name=".NotLocked" android:exported="true" android:showWhenLocked="false"/>
+    </application>
+</manifest>
+`
+
+func TestReachabilityBadges(t *testing.T) {
+	manifest := parseTestManifest(t, reachabilityManifest)
+	for _, tc := range []struct {
+		name     string
+		badges   []string
+		evidence []string
+	}{
+		{".Plain", nil, nil},
+		{".Locked", []string{badgeLockScreen}, []string{`android:showWhenLocked="true"`}},
+		{".LegacyLocked", []string{badgeLockScreen}, []string{`android:showOnLockScreen="true"`}},
+		{".Share", []string{badgeDirectShare}, []string{"android.service.chooser.chooser_target_service=.ShareTargetService"}},
+		{".LockedShare", []string{badgeLockScreen, badgeDirectShare}, []string{`android:showWhenLocked="true"`, "android.service.chooser.chooser_target_service=.ShareTargetService"}},
+		{".NotLocked", nil, nil},
+	} {
+		i := slices.IndexFunc(manifest.Application.Activities, func(a App) bool { return a.Name == tc.name })
+		badges, evidence := reachabilityBadges(manifest.Application.Activities[i])
+		if !slices.Equal(badges, tc.badges) || !slices.Equal(evidence, tc.evidence) {
+			t.Errorf("%s: badges %q %q, want %q %q", tc.name, badges, evidence, tc.badges, tc.evidence)
+		}
+	}
+}
+
+// TestReachabilityReport checks badged components are annotated in the text
+// report and structured output, and that their export findings rank one
+// severity above an otherwise identical component.
+func TestReachabilityReport(t *testing.T) {
+	setOpts(t, textOptions())
+	result, text := renderFixture(t, reachabilityManifest, "<resources/>")
+	for _, want := range []string{
+		"reachable from lock screen (android:showWhenLocked=\"true\")",
+		"reachable from lock screen (android:showOnLockScreen=\"true\")",
+		"direct share target (android.service.chooser.chooser_target_service=.ShareTargetService)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report lacks %q:\n%s", want, text)
+		}
+	}
+
+	severity := make(map[string]string)
+	for _, f := range result.Findings {
+		if f.Rule == exportRules["activity"] {
+			severity[f.Component] = f.Severity
+		}
+	}
+	base := slices.Index(severityOrder, severity[".Plain"])
+	if base < 1 {
+		t.Fatalf(".Plain export finding severity %q leaves no room for a bump", severity[".Plain"])
+	}
+	for _, name := range []string{".Locked", ".LegacyLocked", ".Share", ".LockedShare"} {
+		if want := severityOrder[base-1]; severity[name] != want {
+			t.Errorf("%s severity %q, want %q, one above .Plain", name, severity[name], want)
+		}
+	}
+	if severity[".NotLocked"] != severity[".Plain"] {
+		t.Errorf(".NotLocked severity %q, want %q like .Plain", severity[".NotLocked"], severity[".Plain"])
+	}
+	if _, ok := severity[".InternalLocked"]; ok {
+		t.Errorf("unexported .InternalLocked has an export finding")
+	}
+
+	for _, c := range result.Components {
+		if c.Name == ".LockedShare" && !slices.Equal(c.Badges, []string{badgeLockScreen, badgeDirectShare}) {
+			t.Errorf(".LockedShare badges in structured output = %q", c.Badges)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"   // Manifest rewriting
+	"fmt"     // Repair notes and error context
+	"regexp"  // Fixup patterns
+	"strconv" // Quoted error snippets
+
+	"Deeeeper/Deeeeper/deeeeper" // Manifest decoding
+)
+
+// entityReference matches what may legitimately follow an '&' in XML.
+var entityReference = regexp.MustCompile(`^&([A-Za-z_][A-Za-z0-9._-]*|#[0-9]+|#x[0-9A-Fa-f]+);`)
+
+// startTag and namespaceAttr find namespace declarations within start tags.
+var (
+	startTag      = regexp.MustCompile(`<[A-Za-z_][^<>]*>`)
+	namespaceAttr = regexp.MustCompile(`\s+(xmlns(?::[A-Za-z0-9._-]+)?)\s*=\s*("[^"]*"|'[^']*')`)
+)
+
+// manifestFixup is one well-known repair for apktool output that isn't
+// well-formed XML. It returns the repaired document and how many spots it changed.
+type manifestFixup struct {
+	Note  string                     // What the fixup did, formatted with the count
+	Apply func([]byte) ([]byte, int) // The repair itself
+}
+
+// manifestFixups are tried, in order, when the manifest fails to parse.
+var manifestFixups = []manifestFixup{
+	{"escaped %d bare ampersand(s)", escapeBareAmpersands},
+	{"dropped %d duplicate namespace declaration(s)", dropDuplicateNamespaces},
+}
+
+// parseManifest decodes the manifest. When it is malformed and -strict is not
+// set, the fixups are applied and parsing is retried; the notes of the fixups
+// that changed something are returned so the result can be marked as repaired.
+// If parsing still fails the error points at the offending region.
+func parseManifest(raw []byte) (Manifest, []string, error) {
+	manifest, err := decodeManifest(raw)
+	if err == nil || opts.Strict {
+		return manifest, nil, describeParseError(raw, err)
+	}
+	repaired := raw
+	var notes []string
+	for _, fixup := range manifestFixups {
+		var count int
+		if repaired, count = fixup.Apply(repaired); count > 0 {
+			notes = append(notes, fmt.Sprintf(fixup.Note, count))
+		}
+	}
+	if len(notes) == 0 {
+		return manifest, nil, describeParseError(raw, err)
+	}
+	manifest, err = decodeManifest(repaired)
+	if err != nil {
+		return manifest, nil, describeParseError(repaired, err)
+	}
+	return manifest, notes, nil
+}
+
+// decodeManifest unmarshals a UTF-8 manifest with deeeeper.DecodeManifest,
+// recording where decoding stopped in the returned error.
+func decodeManifest(raw []byte) (Manifest, error) {
+	decoder := newXMLDecoder(bytes.NewReader(raw))
+	manifest, err := deeeeper.DecodeManifest(decoder)
+	if err != nil {
+		return manifest, &manifestParseError{offset: decoder.InputOffset(), err: err}
+	}
+	return manifest, nil
+}
+
+// manifestParseError is a manifest syntax error with its position.
+type manifestParseError struct {
+	offset  int64  // Byte offset where decoding stopped
+	snippet string // Document text around the offset
+	err     error  // Underlying decoder error
+}
+
+func (e *manifestParseError) Error() string {
+	if e.snippet == "" {
+		return e.err.Error()
+	}
+	return fmt.Sprintf("%s at byte %d, near %s", e.err, e.offset, e.snippet)
+}
+
+func (e *manifestParseError) Unwrap() error { return e.err }
+
+// describeParseError fills in the context snippet of a parse error.
+func describeParseError(raw []byte, err error) error {
+	parseErr, ok := err.(*manifestParseError)
+	if !ok {
+		return err
+	}
+	if parseErr.offset <= int64(len(raw)) {
+		start := max(0, int(parseErr.offset)-40)
+		end := min(len(raw), int(parseErr.offset)+40)
+		parseErr.snippet = strconv.Quote(string(raw[start:end]))
+	}
+	return parseErr
+}
+
+// escapeBareAmpersands escapes every '&' that does not start an entity or
+// character reference, as left behind by values injected into the manifest.
+func escapeBareAmpersands(raw []byte) ([]byte, int) {
+	var out bytes.Buffer
+	count := 0
+	for i := 0; i < len(raw); i++ {
+		if raw[i] == '&' && !entityReference.Match(raw[i:]) {
+			out.WriteString("&amp;")
+			count++
+			continue
+		}
+		out.WriteByte(raw[i])
+	}
+	return out.Bytes(), count
+}
+
+// dropDuplicateNamespaces removes repeated xmlns declarations of the same
+// prefix within one start tag, keeping the first.
+func dropDuplicateNamespaces(raw []byte) ([]byte, int) {
+	count := 0
+	repaired := startTag.ReplaceAllFunc(raw, func(tag []byte) []byte {
+		seen := make(map[string]bool)
+		return namespaceAttr.ReplaceAllFunc(tag, func(attr []byte) []byte {
+			name := string(namespaceAttr.FindSubmatch(attr)[1])
+			if seen[name] {
+				count++
+				return nil
+			}
+			seen[name] = true
+			return attr
+		})
+	})
+	return repaired, count
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"     // Error formatting
+	"io"      // Output destinations
+	"slices"  // Grouping packages
+	"strings" // Joining package lists
+)
+
+// report is everything collected while analyzing one decompiled APK.
+type report struct {
+	Target       string              `json:"target"`                          // APK or folder that was analyzed
+	Origin       string              `json:"origin,omitempty"`                // Archive the APK was extracted from, if any
+	SHA256       string              `json:"sha256,omitempty"`                // Hash of the APK file, empty for folders
+	SigningCert  string              `json:"signing_cert_sha256,omitempty"`   // SHA-256 fingerprint of the signing certificate, if found
+	Package      string              `json:"package"`                         // Package name from the manifest
+	SharedUserID string              `json:"shared_user_id,omitempty"`        // android:sharedUserId of the manifest
+	Findings     []finding           `json:"findings"`                        // Findings raised for the app
+	ShareTargets []shareTarget       `json:"share_targets"`                   // Components accepting ACTION_SEND
+	Hosts        []hostInfo          `json:"hosts"`                           // Deeplink hosts with their App Links verification state
+	Protections  []protectionInfo    `json:"protections"`                     // Permissions guarding exported components
+	Actions      []actionInfo        `json:"actions"`                         // Distinct intent actions with their handlers
+	Components   []componentInfo     `json:"components"`                      // Every declared component with its raw attributes
+	Inventory    []inventoryEntry    `json:"inventory,omitempty"`             // Flat deeplink inventory under -inventory
+	Surface      []surfaceInfo       `json:"deeplink_surface,omitempty"`      // Deeplink surface per exported activity and alias, largest first
+	GrantChains  []grantChain        `json:"grant_chains,omitempty"`          // Activities forwarding URI grants, see collectGrantChains
+	Collisions   []deeplinkCollision `json:"deeplink_collisions,omitempty"`   // URI patterns several exported activities claim, see collectDeeplinkCollisions
+	Instant      *instantInfo        `json:"instant,omitempty"`               // Instant app sandbox checks, see collectInstant
+	Repairs      []string            `json:"repairs,omitempty"`               // Fixups applied to parse a malformed manifest
+	InvalidURIs  int                 `json:"invalid_uris,omitempty"`          // Constructed URIs that don't parse cleanly
+	Unresolved   []string            `json:"unresolved_references,omitempty"` // Attributes whose resource references could not be resolved
+	MergeRules   []string            `json:"merge_rules,omitempty"`           // tools: merge rules applied to the manifest, see applyMergeRules
+	Requested    []string            `json:"requested_permissions,omitempty"` // Permissions the app requests with <uses-permission>
+	MinSDK       int                 `json:"min_sdk,omitempty"`               // minSdkVersion, 0 when unknown
+	Permissions  []permissionRequest `json:"permission_requests,omitempty"`   // Requested permissions with dangerous and inert flags
+	Features     []featureInfo       `json:"features,omitempty"`              // Named <uses-feature> elements
+	Declared     []customPermission  `json:"declared_permissions,omitempty"`  // Custom <permission> declarations with their protection levels
+	Flags        applicationFlags    `json:"application_flags"`               // Security-relevant <application> attributes as declared
+	Unchanged    bool                `json:"unchanged,omitempty"`             // Reused from the previous run by -skip-unchanged
+	Diff         *reportDiff         `json:"diff,omitempty"`                  // Changes since the -diff baseline
+	TestCases    []testCase          `json:"-"`                               // Deeplink test cases for -testcases
+}
+
+// componentGroup pairs a manifest component list with its kind.
+type componentGroup struct {
+	Kind       string // activity, alias, service, receiver or provider
+	Components []App  // Components of that kind
+}
+
+// componentGroups lists the manifest's components in reporting order.
+func componentGroups(manifest Manifest) []componentGroup {
+	return []componentGroup{
+		{"activity", manifest.Application.Activities},
+		{"alias", manifest.Application.Aliases},
+		{"service", manifest.Application.Services},
+		{"receiver", manifest.Application.Receivers},
+		{"provider", manifest.Application.Providers},
+	}
+}
+
+// outputFormats lists the accepted -format values.
+var outputFormats = []string{"text", "json", "defectdojo", "zap-urls", "uris"}
+
+// machineFormat reports whether the selected format or a -template writes a
+// document to stdout, in which case all progress chatter is moved to stderr.
+func machineFormat() bool {
+	return opts.Format != "text" || opts.Template != ""
+}
+
+// writeReports renders the collected reports in the selected machine format.
+func writeReports(w io.Writer, reports []*report) error {
+	if outputTemplate != nil {
+		return writeTemplateOutput(w, reports)
+	}
+	switch opts.Format {
+	case "json":
+		return writeJSON(w, reports)
+	case "defectdojo":
+		return writeDefectDojo(w, reports)
+	case "zap-urls":
+		return writeZapURLs(w, reports)
+	case "uris":
+		return writeURIs(w, reports)
+	}
+	return fmt.Errorf("unknown output format %q", opts.Format)
+}
+
+// printSharedUserGroups lists packages of a batch that declare the same
+// sharedUserId. Such apps share one sandbox and effectively form one trust domain.
+func printSharedUserGroups(w io.Writer, reports []*report) {
+	groups := make(map[string][]string)
+	for _, r := range reports {
+		if r.SharedUserID != "" && !slices.Contains(groups[r.SharedUserID], r.Package) {
+			groups[r.SharedUserID] = append(groups[r.SharedUserID], r.Package)
+		}
+	}
+	header := false
+	for _, id := range sortedKeys(groups) {
+		if len(groups[id]) < 2 {
+			continue
+		}
+		if !header {
+			printSection(w, "\nShared user IDs (one trust domain each):")
+			header = true
+		}
+		slices.Sort(groups[id])
+		fmt.Fprintf(w, "  %s: %s\n", id, strings.Join(groups[id], ", "))
+	}
+}
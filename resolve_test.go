@@ -0,0 +1,271 @@
+package main
+
+import (
+	"fmt"     // Generated manifests
+	"reflect" // Walking the resolved manifest
+	"strings" // Generated manifests, leftovers
+	"testing" // Test harness
+
+	"Deeeeper/Deeeeper/deeeeper" // Resolution under test
+)
+
+// resolverManifest puts a resource reference in every attribute position the
+// old whole-file @string/ replacement used to resolve, plus @bool and
+// @integer references, a reference chain, a value needing XML escaping and
+// one unknown reference.
+const resolverManifest = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="@string/package" android:sharedUserId="@string/shared_user" android:sharedUserLabel="@string/shared_label">
+    <permission android:name="@string/perm_name" android:protectionLevel="@string/perm_level"/>
+    <uses-permission android:name="@string/uses_perm" android:maxSdkVersion="@integer/max_sdk"/>
+    <uses-sdk android:minSdkVersion="@integer/min_sdk" android:targetSdkVersion="@integer/target_sdk"/>
+    <uses-feature android:name="@string/feature" android:required="@bool/no"/>
+    <application android:label="@string/label" android:permission="@string/app_perm" android:autoVerify="@bool/yes" android:usesCleartextTraffic="@bool/no" android:debuggable="@bool/no" android:allowBackup="@bool/no">
+        <activity android:name="@string/activity_name" android:exported="@bool/yes" android:showWhenLocked="@bool/yes" android:permission="@string/activity_perm" android:directBootAware="@bool/yes">
+            <intent-filter android:autoVerify="@bool/yes" android:priority="@integer/priority" android:order="@integer/order">
+                <action android:name="@string/action"/>
+                <category android:name="@string/category"/>
+                <data android:scheme="@string/scheme" android:host="@string/host" android:port="@integer/port" android:path="@string/path"/>
+                <data android:scheme="@string/scheme" android:host="@string/chained_host" android:pathPrefix="@string/path_prefix"/>
+                <data android:scheme="@string/scheme" android:host="@string/host" android:pathPattern="@string/path_pattern"/>
+                <data android:scheme="package" android:ssp="@string/ssp"/>
+                <data android:scheme="package" android:sspPrefix="@string/ssp_prefix"/>
+                <data android:scheme="package" android:sspPattern="@string/ssp_pattern"/>
+                <data android:mimeType="@string/mime"/>
+                <data android:scheme="@string/scheme" android:host="@string/missing"/>
+            </intent-filter>
+            <meta-data android:name="@string/meta_name" android:value="@string/meta_value"/>
+        </activity>
+        <activity-alias android:name="@string/alias_name" android:targetActivity="@string/activity_name" android:exported="@bool/yes"/>
+        <provider android:name="@string/provider_name" android:authorities="@string/authorities" android:exported="@bool/no" android:readPermission="@string/read_perm" android:writePermission="@string/write_perm" android:grantUriPermissions="@bool/yes" android:singleUser="@bool/no" android:multiprocess="@bool/no">
+            <path-permission android:pathPrefix="@string/path_prefix" android:permission="@string/path_perm" android:readPermission="@string/read_perm" android:writePermission="@string/write_perm"/>
+            <grant-uri-permission android:path="@string/path" android:pathPattern="@string/path_pattern"/>
+        </provider>
+        <service android:name="@string/service_name" android:exported="@bool/yes" android:permission="@string/service_perm"/>
+        <receiver android:name="@string/receiver_name" android:exported="@bool/yes" android:label="see @string/host"/>
+    </application>
+</manifest>
+`
+
+// resolverValues are the resources behind resolverManifest.
+var resolverValues = resourceValues{
+	"string/package":       "com.example.app",
+	"string/shared_user":   "com.example.shared",
+	"string/shared_label":  "Shared",
+	"string/perm_name":     "com.example.app.PERMISSION",
+	"string/perm_level":    "signature",
+	"string/uses_perm":     "android.permission.INTERNET",
+	"integer/max_sdk":      "28",
+	"integer/min_sdk":      "21",
+	"integer/target_sdk":   "34",
+	"string/feature":       "android.hardware.camera",
+	"bool/no":              "false",
+	"bool/yes":             "true",
+	"string/label":         `Tom & Jerry's "app" <beta>`,
+	"string/app_perm":      "com.example.app.APP",
+	"string/activity_name": ".MainActivity",
+	"string/activity_perm": "com.example.app.ACTIVITY",
+	"integer/priority":     "999",
+	"integer/order":        "2",
+	"string/action":        "android.intent.action.VIEW",
+	"string/category":      "android.intent.category.BROWSABLE",
+	"string/scheme":        "https",
+	"string/host":          "links.example.com",
+	"string/chained_host":  "@string/host_alias",
+	"string/host_alias":    "@string/host",
+	"integer/port":         "8443",
+	"string/path":          "/open",
+	"string/path_prefix":   "/shop",
+	"string/path_pattern":  "/item/.*",
+	"string/ssp":           "com.example.app",
+	"string/ssp_prefix":    "com.example.",
+	"string/ssp_pattern":   "com\\..*",
+	"string/mime":          "application/pdf",
+	"string/meta_name":     "android.service.chooser.chooser_target_service",
+	"string/meta_value":    ".ShareTargets",
+	"string/alias_name":    ".Shortcut",
+	"string/provider_name": ".Files",
+	"string/authorities":   "com.example.app.files;com.example.app.legacy",
+	"string/read_perm":     "com.example.app.READ",
+	"string/write_perm":    "com.example.app.WRITE",
+	"string/path_perm":     "com.example.app.PATH",
+	"string/service_name":  ".SyncService",
+	"string/service_perm":  "com.example.app.SYNC",
+	"string/receiver_name": ".Receiver",
+}
+
+// leftoverReferences returns every string of v that still starts with "@".
+func leftoverReferences(v reflect.Value, path string) []string {
+	var leftovers []string
+	switch v.Kind() {
+	case reflect.String:
+		if strings.HasPrefix(v.String(), "@") {
+			leftovers = append(leftovers, path+"="+v.String())
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if field := v.Type().Field(i); field.IsExported() {
+				leftovers = append(leftovers, leftoverReferences(v.Field(i), path+"."+field.Name)...)
+			}
+		}
+	case reflect.Slice:
+		for i := range v.Len() {
+			leftovers = append(leftovers, leftoverReferences(v.Index(i), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			leftovers = append(leftovers, leftoverReferences(v.MapIndex(key), fmt.Sprintf("%s[%s]", path, key))...)
+		}
+	}
+	return leftovers
+}
+
+func TestResolveManifestPositions(t *testing.T) {
+	manifest := parseTestManifest(t, resolverManifest)
+	deeeeper.ResolveManifest(&manifest, resolverValues.resources())
+
+	leftovers := leftoverReferences(reflect.ValueOf(manifest), "manifest")
+	if strings.Join(leftovers, "\n") != "manifest.Application.Activities[0].Filters[0].Data[7].Host=@string/missing" {
+		t.Errorf("references left after resolution, want only the unknown one:\n%s", strings.Join(leftovers, "\n"))
+	}
+
+	app := manifest.Application
+	activity, provider := app.Activities[0], app.Providers[0]
+	filter := activity.Filters[0]
+	for _, tc := range []struct {
+		position, got, want string
+	}{
+		{"manifest package", manifest.Package, "com.example.app"},
+		{"manifest sharedUserId", manifest.SharedUserID, "com.example.shared"},
+		{"manifest sharedUserLabel", manifest.SharedUserLabel, "Shared"},
+		{"permission name", manifest.Permissions[0].Name, "com.example.app.PERMISSION"},
+		{"permission protectionLevel", manifest.Permissions[0].ProtectionLevel, "signature"},
+		{"permission raw attribute", manifest.Permissions[0].Attributes["android:protectionLevel"], "signature"},
+		{"uses-permission name", manifest.UsesPermissions[0].Name, "android.permission.INTERNET"},
+		{"uses-permission maxSdkVersion", manifest.UsesPermissions[0].MaxSDKVersion, "28"},
+		{"uses-sdk minSdkVersion", manifest.UsesSDK.MinSDKVersion, "21"},
+		{"uses-sdk targetSdkVersion", manifest.UsesSDK.TargetSDKVersion, "34"},
+		{"uses-feature name", manifest.UsesFeatures[0].Name, "android.hardware.camera"},
+		{"uses-feature required", manifest.UsesFeatures[0].Required, "false"},
+		{"application permission", app.Permission, "com.example.app.APP"},
+		{"application autoVerify", app.AutoVerify, "true"},
+		{"application usesCleartextTraffic", app.Cleartext, "false"},
+		{"application debuggable", app.Debuggable, "false"},
+		{"application allowBackup", app.Backup, "false"},
+		{"component name", activity.Name, ".MainActivity"},
+		{"component exported", activity.Exported, "true"},
+		{"component permission", activity.Permission, "com.example.app.ACTIVITY"},
+		{"component showWhenLocked", activity.ShowWhenLocked, "true"},
+		{"component directBootAware", activity.DirectBootAware, "true"},
+		{"component raw attribute", activity.Attributes["android:name"], ".MainActivity"},
+		{"intent-filter autoVerify", filter.AutoVerify, "true"},
+		{"intent-filter priority", filter.Priority, "999"},
+		{"intent-filter order", filter.Order, "2"},
+		{"intent-filter raw attribute", filter.Attributes["android:priority"], "999"},
+		{"action name", filter.Actions[0].Name, "android.intent.action.VIEW"},
+		{"category name", filter.Categories[0].Name, "android.intent.category.BROWSABLE"},
+		{"data scheme", filter.Data[0].Scheme, "https"},
+		{"data host", filter.Data[0].Host, "links.example.com"},
+		{"data port", filter.Data[0].Port, "8443"},
+		{"data path", filter.Data[0].Path, "/open"},
+		{"data host through a reference chain", filter.Data[1].Host, "links.example.com"},
+		{"data pathPrefix", filter.Data[1].PathPrefix, "/shop"},
+		{"data pathPattern", filter.Data[2].PathPattern, "/item/.*"},
+		{"data ssp", filter.Data[3].Ssp, "com.example.app"},
+		{"data sspPrefix", filter.Data[4].SspPrefix, "com.example."},
+		{"data sspPattern", filter.Data[5].SspPattern, "com\\..*"},
+		{"data mimeType", filter.Data[6].MimeType, "application/pdf"},
+		{"data unknown reference", filter.Data[7].Host, "@string/missing"},
+		{"meta-data name", activity.MetaData[0].Name, "android.service.chooser.chooser_target_service"},
+		{"meta-data value", activity.MetaData[0].Value, ".ShareTargets"},
+		{"alias name", app.Aliases[0].Name, ".Shortcut"},
+		{"alias targetActivity", app.Aliases[0].TargetActivity, ".MainActivity"},
+		{"provider authorities", provider.Authorities, "com.example.app.files;com.example.app.legacy"},
+		{"provider readPermission", provider.ReadPermission, "com.example.app.READ"},
+		{"provider writePermission", provider.WritePermission, "com.example.app.WRITE"},
+		{"provider grantUriPermissions", provider.GrantURIPermissions, "true"},
+		{"provider singleUser", provider.SingleUser, "false"},
+		{"provider multiprocess", provider.Multiprocess, "false"},
+		{"path-permission pathPrefix", provider.PathPermissions[0].PathPrefix, "/shop"},
+		{"path-permission permission", provider.PathPermissions[0].Permission, "com.example.app.PATH"},
+		{"path-permission readPermission", provider.PathPermissions[0].ReadPermission, "com.example.app.READ"},
+		{"path-permission writePermission", provider.PathPermissions[0].WritePermission, "com.example.app.WRITE"},
+		{"grant-uri-permission path", provider.GrantURIPaths[0].Path, "/open"},
+		{"grant-uri-permission pathPattern", provider.GrantURIPaths[0].PathPattern, "/item/.*"},
+		{"service name", app.Services[0].Name, ".SyncService"},
+		{"service permission", app.Services[0].Permission, "com.example.app.SYNC"},
+		{"receiver name", app.Receivers[0].Name, ".Receiver"},
+		{"partial reference", app.Receivers[0].Attributes["android:label"], "see @string/host"},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s = %q, want %q", tc.position, tc.got, tc.want)
+		}
+	}
+}
+
+// TestResolveManifestEscaping checks values with XML metacharacters arrive
+// verbatim and leave the rest of the manifest intact, which the old
+// replacement only managed by escaping them into the document.
+func TestResolveManifestEscaping(t *testing.T) {
+	manifest := parseTestManifest(t, strings.Replace(resolverManifest, `android:name="@string/receiver_name"`, `android:name="@string/label"`, 1))
+	deeeeper.ResolveManifest(&manifest, resolverValues.resources())
+	if got := manifest.Application.Receivers[0].Name; got != `Tom & Jerry's "app" <beta>` {
+		t.Errorf("receiver name = %q, want the value verbatim", got)
+	}
+	if got := manifest.Application.Receivers[0].Exported; got != "true" {
+		t.Errorf("attributes after the escaped value broke: exported = %q", got)
+	}
+}
+
+// benchmarkManifest generates a manifest with n activities, each with a
+// deeplink whose host and path are string references, and the strings
+// behind them plus as many unrelated ones.
+func benchmarkManifest(n int) (string, map[string]string) {
+	var manifest strings.Builder
+	manifest.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n" + `<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example.app"><application>` + "\n")
+	stringMap := make(map[string]string, 4*n)
+	for i := range n {
+		fmt.Fprintf(&manifest, `<activity android:name=".Activity%d" android:exported="true" android:label="@string/label_%d"><intent-filter><action android:name="android.intent.action.VIEW"/><data android:scheme="https" android:host="@string/host_%d" android:path="@string/path_%d"/></intent-filter></activity>`+"\n", i, i, i, i)
+		stringMap[fmt.Sprintf("label_%d", i)] = fmt.Sprintf("Activity & screen %d", i)
+		stringMap[fmt.Sprintf("host_%d", i)] = fmt.Sprintf("host%d.example.com", i)
+		stringMap[fmt.Sprintf("path_%d", i)] = fmt.Sprintf("/path/%d", i)
+		stringMap[fmt.Sprintf("unrelated_%d", i)] = fmt.Sprintf("Unrelated copy %d", i)
+	}
+	manifest.WriteString("</application></manifest>\n")
+	return manifest.String(), stringMap
+}
+
+// BenchmarkResolveManifest measures parsing plus per-attribute resolution for
+// growing manifests. It scales with the number of attributes, not with
+// strings × manifest size.
+func BenchmarkResolveManifest(b *testing.B) {
+	for _, n := range []int{100, 1000, 5000} {
+		manifest, stringMap := benchmarkManifest(n)
+		values := loadResourceValues(b.TempDir(), stringMap)
+		b.Run(fmt.Sprintf("activities=%d", n), func(b *testing.B) {
+			b.SetBytes(int64(len(manifest)))
+			b.ReportAllocs()
+			for range b.N {
+				parsed, _, err := parseManifest([]byte(manifest))
+				if err != nil {
+					b.Fatal(err)
+				}
+				deeeeper.ResolveManifest(&parsed, values.resources())
+			}
+		})
+	}
+}
+
+// BenchmarkResolveReference measures a single lookup, including a chain.
+func BenchmarkResolveReference(b *testing.B) {
+	_, stringMap := benchmarkManifest(5000)
+	values := loadResourceValues(b.TempDir(), stringMap)
+	values["string/chain"] = "@string/host_42"
+	resources := values.resources()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if resources.Resolve("@string/chain") != "host42.example.com" {
+			b.Fatal("chain not resolved")
+		}
+	}
+}
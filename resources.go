@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"         // Buffered streaming of resource files
+	"errors"        // Malformed file marker
+	"fmt"           // Error wrapping
+	"io"            // Warning destination
+	"os"            // File access
+	"path/filepath" // Value file locations
+	"regexp"        // BCP 47 locales
+	"sort"          // Strings file precedence
+	"strings"       // String manipulation functions
+
+	"Deeeeper/Deeeeper/deeeeper" // Values files and leftover references
+)
+
+// errMalformedStrings marks a strings file that could only be read in part.
+var errMalformedStrings = errors.New("malformed strings file")
+
+// valueFiles maps the resource types attributes may reference, besides strings,
+// to the res/values file apktool writes them to.
+var valueFiles = map[string]string{"bool": "bools.xml", "integer": "integers.xml"}
+
+// resourceValues holds simple resource values keyed by type and name, such as
+// "string/app_name" or "bool/exported".
+type resourceValues map[string]string
+
+// loadResourceValues collects the strings and the bool and integer resources
+// of a decompiled folder. The value files are optional: apktool only writes
+// them when the app defines such resources.
+func loadResourceValues(folder string, stringMap map[string]string) resourceValues {
+	values := make(resourceValues, len(stringMap))
+	for name, value := range stringMap {
+		values["string/"+name] = value
+	}
+	for kind, file := range valueFiles {
+		loaded, _ := loadValues(filepath.Join(folder, "res", "values", file), kind)
+		for name, value := range loaded {
+			values[kind+"/"+name] = value
+		}
+	}
+	return values
+}
+
+// resources pairs the app's values with the loaded framework, which is what
+// deeeeper.Resources resolves @android: references against.
+func (v resourceValues) resources() deeeeper.Resources {
+	return deeeeper.Resources{App: v, Framework: frameworkValues}
+}
+
+// unresolvedManifestReferences lists the tokens deeeeper.UnresolvedReferences
+// finds in the
+// component, intent-filter and <data> attributes after resolution, as
+// "token in component element attribute". Component names are attributes too.
+// @string/ references are left out when no strings were loaded. <data>
+// elements holding any of them yield no URI.
+func unresolvedManifestReferences(manifest Manifest, stringsLoaded bool) []string {
+	var unresolved []string
+	add := func(value, where string) {
+		for _, token := range deeeeper.UnresolvedReferences(value) {
+			if stringsLoaded || !strings.HasPrefix(token, "@string/") {
+				unresolved = appendUnique(unresolved, fmt.Sprintf("%s in %s", token, where))
+			}
+		}
+	}
+	for _, group := range componentGroups(manifest) {
+		for _, component := range group.Components {
+			for _, attr := range sortedKeys(component.Attributes) {
+				add(component.Attributes[attr], fmt.Sprintf("%s %s %s", component.Name, group.Kind, attr))
+			}
+			for _, filter := range component.Filters {
+				for _, attr := range sortedKeys(filter.Attributes) {
+					add(filter.Attributes[attr], fmt.Sprintf("%s intent-filter %s", component.Name, attr))
+				}
+				for _, data := range filter.Data {
+					for _, attr := range data.URIAttributes() {
+						add(attr.Value, fmt.Sprintf("%s data %s", component.Name, attr.Name))
+					}
+				}
+			}
+		}
+	}
+	return unresolved
+}
+
+// loadStrings reads a strings.xml file into a name-value map.
+// The file is decoded as a stream one <string> element at a time, so even
+// tens of megabytes of resources never sit in memory as a whole document.
+// The returned map is always usable, even when an error is reported: a file
+// that breaks off mid-way keeps the strings decoded before the damage and
+// reports the cause wrapped in errMalformedStrings.
+func loadStrings(path string) (map[string]string, error) {
+	return loadValues(path, "string")
+}
+
+// loadValues streams the <element name="..."> entries of a res/values file
+// into a name-value map, with the error semantics of loadStrings.
+func loadValues(path, element string) (map[string]string, error) {
+	stringMap := make(map[string]string) // Map for string name-value pairs
+
+	stringsFile, err := os.Open(path)
+	if err != nil { // Error handling for file reading failure
+		return stringMap, err
+	}
+	defer stringsFile.Close()
+
+	reader, err := utf8Reader(stringsFile)
+	if err != nil {
+		return stringMap, err
+	}
+	stringMap, err = deeeeper.DecodeValues(newXMLDecoder(reader), element)
+	if err != nil { // Keep what was decoded so far
+		return stringMap, fmt.Errorf("%w: %w", errMalformedStrings, err)
+	}
+	return stringMap, nil
+}
+
+// bcp47Region matches a language tag with a region, such as pt-BR, which
+// resource folders spell pt-rBR.
+var bcp47Region = regexp.MustCompile(`^([a-z]{2,3})-([A-Z]{2})$`)
+
+// stringsFiles lists the res/values*/strings.xml files of a folder in the
+// order their strings are merged: the -locale folder first, then the default
+// res/values, then every other values folder alphabetically. Without an exact
+// match, other folders of the locale's language count, so "de" matches
+// values-de-rAT and "fr-FR" matches values-fr.
+func stringsFiles(progress io.Writer, folder string) []string {
+	files, _ := filepath.Glob(filepath.Join(folder, "res", "values*", "strings.xml"))
+	defaultFile := filepath.Join(folder, "res", "values", "strings.xml")
+	qualifier := localeQualifier(opts.Locale)
+	language, _, _ := strings.Cut(qualifier, "-")
+	rank := func(file string) int {
+		dir := filepath.Base(filepath.Dir(file))
+		switch {
+		case opts.Locale != "" && dir == "values-"+qualifier:
+			return 0
+		case opts.Locale != "" && (dir == "values-"+language || strings.HasPrefix(dir, "values-"+language+"-")):
+			return 1
+		case file == defaultFile:
+			return 2
+		}
+		return 3
+	}
+	sort.SliceStable(files, func(i, j int) bool { return rank(files[i]) < rank(files[j]) })
+	if opts.Locale != "" && (len(files) == 0 || rank(files[0]) > 1) {
+		warnf(progress, "Warning: no res/values-%s folder in %s; using the default strings", qualifier, folder)
+	}
+	return files
+}
+
+// localeQualifier turns a -locale value into the resource folder qualifier:
+// BCP 47 regions become the r-prefixed form, anything else is used as given.
+func localeQualifier(locale string) string {
+	if match := bcp47Region.FindStringSubmatch(locale); match != nil {
+		return match[1] + "-r" + match[2]
+	}
+	return locale
+}
+
+// loadStringMap merges the strings.xml files of a folder, see stringsFiles,
+// with the first definition of each name winning, and the -strings-properties
+// file, whose values take precedence. A malformed strings.xml is used as far
+// as it could be read, and having none is fine when properties stand in.
+func loadStringMap(progress io.Writer, folder string) (map[string]string, error) {
+	files := stringsFiles(progress, folder)
+	if len(files) == 0 && opts.StringsProperties == "" { // A properties dump can stand in for strings.xml
+		_, err := os.Stat(filepath.Join(folder, "res", "values", "strings.xml"))
+		return nil, fmt.Errorf("reading strings file: %w", err)
+	}
+	stringMap := make(map[string]string)
+	for _, file := range files {
+		loaded, err := loadStrings(file)
+		if errors.Is(err, errMalformedStrings) { // Use what was readable rather than nothing
+			warnf(progress, "Warning: %s: %s; placeholders defined after the error stay unresolved", file, err)
+		} else if err != nil {
+			return nil, fmt.Errorf("reading strings file: %w", err)
+		}
+		for name, value := range loaded {
+			if _, defined := stringMap[name]; !defined {
+				stringMap[name] = value
+			}
+		}
+	}
+	if opts.StringsProperties != "" {
+		properties, err := loadProperties(opts.StringsProperties)
+		if err != nil {
+			return nil, fmt.Errorf("reading strings properties: %w", err)
+		}
+		for name, value := range properties { // Properties take precedence over strings.xml
+			stringMap[name] = value
+		}
+	}
+	return stringMap, nil
+}
+
+// loadProperties reads a flat name=value properties file as produced by some
+// build pipelines instead of strings.xml. Blank lines and lines starting with
+// '#' or '!' are ignored, and either '=' or ':' separates name from value.
+func loadProperties(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	properties := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024) // Allow long resolved values
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		sep := strings.IndexAny(line, "=:")
+		if sep < 0 { // A bare key maps to an empty value, as in java.util.Properties
+			properties[line] = ""
+			continue
+		}
+		name := strings.TrimSpace(line[:sep])
+		value := strings.TrimSpace(line[sep+1:])
+		properties[name] = unescapeProperty(value)
+	}
+	return properties, scanner.Err()
+}
+
+// unescapeProperty expands the common backslash escapes used in properties values.
+func unescapeProperty(value string) string {
+	if !strings.Contains(value, "\\") {
+		return value
+	}
+	replacer := strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\=`, "=", `\:`, ":", `\\`, `\`)
+	return replacer.Replace(value)
+}
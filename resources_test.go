@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"         // Generating the large strings file
+	"encoding/xml"  // Whole-document decoding for comparison
+	"fmt"           // String names and values
+	"os"            // Fixture files, /proc/self/status
+	"path/filepath" // Fixture paths
+	"slices"        // Host lookup
+	"strconv"       // Parsing VmHWM
+	"strings"       // Reading /proc/self/status
+	"testing"       // Benchmarks
+
+	"Deeeeper/Deeeeper/deeeeper" // Whole-document strings model
+)
+
+// largeStringsSize is the size of the generated strings.xml, as large as the
+// biggest resource files seen in app bundles.
+const largeStringsSize = 50 << 20
+
+// writeLargeStrings writes a strings.xml of at least size bytes, with markup
+// and entities in some of the values, and returns how many strings it holds.
+func writeLargeStrings(tb testing.TB, path string, size int) int {
+	tb.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+	written, _ := w.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n<resources xmlns:xliff=\"urn:oasis:names:tc:xliff:document:1.2\">\n")
+	count := 0
+	for ; written < size; count++ {
+		var n int
+		switch count % 3 {
+		case 0:
+			n, _ = fmt.Fprintf(w, "    <string name=\"plain_%d\">A plain value number %d that reads like UI copy</string>\n", count, count)
+		case 1:
+			n, _ = fmt.Fprintf(w, "    <string name=\"host_%d\"><xliff:g id=\"host\">host%d.example.com</xliff:g></string>\n", count, count)
+		default:
+			n, _ = fmt.Fprintf(w, "    <string name=\"entity_%d\">Terms &amp; conditions &lt;%d&gt;</string>\n", count, count)
+		}
+		written += n
+	}
+	w.WriteString("</resources>\n")
+	if err := w.Flush(); err != nil {
+		tb.Fatal(err)
+	}
+	return count
+}
+
+// peakRSS returns the peak resident set size of the process in MB, from
+// /proc/self/status, or 0 where that isn't available.
+func peakRSS() float64 {
+	status, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(status), "\n") {
+		if value, ok := strings.CutPrefix(line, "VmHWM:"); ok {
+			kb, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value), " kB"))
+			return float64(kb) / 1024
+		}
+	}
+	return 0
+}
+
+// BenchmarkLoadStrings loads a generated 50 MB strings.xml with the streaming
+// decoder, and for comparison with the whole-document ReadFile and Unmarshal it
+// replaced. Run one sub-benchmark per process for meaningful peak-RSS-MB:
+//
+//	go test -run '^$' -bench 'LoadStrings/stream' -benchtime 3x
+//	go test -run '^$' -bench 'LoadStrings/unmarshal' -benchtime 3x
+func BenchmarkLoadStrings(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "strings.xml")
+	count := writeLargeStrings(b, path, largeStringsSize)
+	b.Run("stream", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			loaded, err := loadStrings(path)
+			if err != nil || len(loaded) != count {
+				b.Fatalf("loaded %d of %d strings: %v", len(loaded), count, err)
+			}
+		}
+		b.ReportMetric(peakRSS(), "peak-RSS-MB")
+	})
+	b.Run("unmarshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			var resource deeeeper.StringResource
+			if err := xml.Unmarshal(data, &resource); err != nil {
+				b.Fatal(err)
+			}
+			loaded := make(map[string]string, len(resource.Strings))
+			for _, s := range resource.Strings {
+				loaded[s.Name] = s.Text
+			}
+			if len(loaded) != count {
+				b.Fatalf("loaded %d of %d strings", len(loaded), count)
+			}
+		}
+		b.ReportMetric(peakRSS(), "peak-RSS-MB")
+	})
+}
+
+// TestLoadStringsLarge checks the streaming decoder reads every string of a
+// large file, markup and entities included.
+func TestLoadStringsLarge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "strings.xml")
+	count := writeLargeStrings(t, path, 1<<20)
+	loaded, err := loadStrings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != count {
+		t.Fatalf("loaded %d strings, want %d", len(loaded), count)
+	}
+	if got := loaded["host_1"]; got != "host1.example.com" {
+		t.Errorf("host_1 = %q, want the text inside xliff:g", got)
+	}
+	if got := loaded["entity_2"]; got != "Terms & conditions <2>" {
+		t.Errorf("entity_2 = %q, want entities decoded", got)
+	}
+}
+
+// TestLoadStringsMarkup checks string values keep the text of nested markup
+// and CDATA sections in document order, the markup itself stripped.
+func TestLoadStringsMarkup(t *testing.T) {
+	loaded, err := loadStrings(filepath.Join("testdata", "strings", "markup.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"plain":        "example.com",
+		"xliff_host":   "links.example.com",
+		"xliff_mixed":  "https://shop.example.com/cart",
+		"bold":         "Open now",
+		"nested":       "deeply nested",
+		"cdata":        "cdata.example.com",
+		"cdata_markup": "<b>kept</b> as text",
+		"mixed":        "abcd",
+		"entities":     "Terms & conditions <1>",
+		"empty":        "",
+	} {
+		got, ok := loaded[name]
+		if !ok {
+			t.Errorf("%s missing", name)
+		} else if got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+// TestMarkupHostInDeeplink checks a deeplink host wrapped entirely in xliff:g
+// resolves to the wrapped text rather than an empty host.
+func TestMarkupHostInDeeplink(t *testing.T) {
+	setOpts(t, textOptions())
+	stringsXML, err := os.ReadFile(filepath.Join("testdata", "strings", "markup.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := strings.Replace(selftestManifest, "@string/deeplink_host", "@string/xliff_host", 1)
+	result := analyzeFixture(t, manifest, string(stringsXML))
+	hasHost := func(host string) bool {
+		return slices.ContainsFunc(result.Hosts, func(h hostInfo) bool { return h.Host == host })
+	}
+	if !hasHost("links.example.com") {
+		t.Errorf("hosts %v lack links.example.com from xliff:g", result.Hosts)
+	}
+	if hasHost("") {
+		t.Errorf("hosts %v contain an empty host", result.Hosts)
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"     // Summary formatting
+	"io"      // Output destination
+	"slices"  // Ordering components
+	"sort"    // Deterministic ordering
+	"strings" // Path suffixes
+
+	"Deeeeper/Deeeeper/deeeeper" // Scheme and host normalization
+	"github.com/fatih/color"     // Colorized output in terminal
+)
+
+// surfaceInfo measures the deeplink surface of one exported activity or alias.
+type surfaceInfo struct {
+	Kind         string `json:"kind"`             // activity or alias
+	Component    string `json:"component"`        // Component name
+	Deeplinks    int    `json:"deeplinks"`        // Distinct scheme/host/port/path combinations its filters match
+	Schemes      int    `json:"schemes"`          // Distinct schemes
+	Hosts        int    `json:"hosts"`            // Distinct hosts
+	PathFamilies int    `json:"path_families"`    // Distinct first path segments, see pathFamily
+	Router       bool   `json:"router,omitempty"` // Above one of the -router-* thresholds
+}
+
+// measureSurface counts what a component's filters match, using the same
+// per-filter expansion as -matrix: every scheme of a filter combines with
+// every authority and path of that filter.
+func measureSurface(component App, kind string) surfaceInfo {
+	info := surfaceInfo{Kind: kind, Component: component.Name}
+	combinations := make(map[filterRow]bool)
+	schemes, hosts, families := make(map[string]bool), make(map[string]bool), make(map[string]bool)
+	for _, filter := range component.Filters {
+		for _, row := range expandFilter(0, filter) {
+			row.Categories, row.AutoVerify = "", "" // Only the URI shape counts
+			row.Scheme = deeeeper.NormalizeScheme(row.Scheme)
+			if row.Host != "-" {
+				row.Host = deeeeper.NormalizeHost(row.Host)
+				hosts[row.Host] = true
+			}
+			combinations[row] = true
+			schemes[row.Scheme] = true
+			if strings.HasPrefix(row.Path, "/") {
+				families[pathFamily(strings.TrimSuffix(strings.TrimSuffix(row.Path, " (pattern)"), "*"))] = true
+			}
+		}
+	}
+	info.Deeplinks, info.Schemes, info.Hosts, info.PathFamilies = len(combinations), len(schemes), len(hosts), len(families)
+	info.Router = isRouter(info)
+	return info
+}
+
+// isRouter reports whether a surface reaches any of the -router-* thresholds.
+// A threshold of 0 disables its criterion.
+func isRouter(info surfaceInfo) bool {
+	reaches := func(count, threshold int) bool { return threshold > 0 && count >= threshold }
+	return reaches(info.Hosts, opts.RouterHosts) || reaches(info.Schemes, opts.RouterSchemes) || reaches(info.PathFamilies, opts.RouterPaths)
+}
+
+// collectSurface measures every exported activity and alias that declares
+// deeplinks, largest surface first.
+func collectSurface(manifest Manifest) []surfaceInfo {
+	var surface []surfaceInfo
+	for _, group := range componentGroups(manifest)[:2] { // Activities and aliases
+		for _, component := range group.Components {
+			if exported, _ := isExported(component, group.Kind); !exported {
+				continue
+			}
+			if info := measureSurface(component, group.Kind); info.Deeplinks > 0 {
+				surface = append(surface, info)
+			}
+		}
+	}
+	sort.SliceStable(surface, func(i, j int) bool { return surface[i].Deeplinks > surface[j].Deeplinks })
+	return surface
+}
+
+// routerNote describes why a component counts as a router, e.g.
+// "router-style: 24 hosts, 3 schemes, 11 path families". It is empty otherwise.
+func routerNote(info surfaceInfo) string {
+	if !info.Router {
+		return ""
+	}
+	return fmt.Sprintf("router-style: %d hosts, %d schemes, %d path families", info.Hosts, info.Schemes, info.PathFamilies)
+}
+
+// sortByRisk orders components for -sort risk: router-style components first,
+// then by the worst severity of their findings, then by deeplink count.
+// Components that tie keep their manifest order.
+func sortByRisk(components []App, kind string, findings []finding) []App {
+	type ranked struct {
+		component App
+		router    bool
+		severity  int
+		deeplinks int
+	}
+	rankedComponents := make([]ranked, len(components))
+	for i, component := range components {
+		info := measureSurface(component, kind)
+		severity, _ := riskSummary(findings, kind, component.Name)
+		rankedComponents[i] = ranked{component, info.Router, severityRank[severity], info.Deeplinks}
+	}
+	slices.SortStableFunc(rankedComponents, func(a, b ranked) int {
+		switch {
+		case a.router != b.router:
+			if a.router {
+				return -1
+			}
+			return 1
+		case a.severity != b.severity:
+			return b.severity - a.severity
+		}
+		return b.deeplinks - a.deeplinks
+	})
+	sorted := make([]App, len(rankedComponents))
+	for i, r := range rankedComponents {
+		sorted[i] = r.component
+	}
+	return sorted
+}
+
+// printSurface writes the deeplink surface summary: the three components with
+// the most deeplinks and every router-style component.
+func printSurface(w io.Writer, surface []surfaceInfo) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	printSection(w, "\nDeeplink Surface:")
+	for i, info := range surface {
+		if i >= 3 && !info.Router {
+			continue
+		}
+		fmt.Fprintf(w, "  %s (%s): %d deeplink(s)", cyan(info.Component), info.Kind, info.Deeplinks)
+		if note := routerNote(info); note != "" {
+			fmt.Fprintf(w, " — %s", yellow(note))
+		}
+		fmt.Fprintln(w)
+	}
+}
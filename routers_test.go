@@ -0,0 +1,118 @@
+package main
+
+import (
+	"os"            // Fixture file
+	"path/filepath" // Fixture path
+	"strings"       // Output checks
+	"testing"       // Test harness
+)
+
+// routerFixture reads testdata/router.xml: a central .Router activity with
+// 12 hosts, 2 schemes and 4 path families, smaller activities around it and an
+// activity with three custom schemes.
+func routerFixture(t *testing.T) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "router.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// routerOptions are the default -router-* thresholds.
+func routerOptions() options {
+	o := textOptions()
+	o.RouterHosts, o.RouterSchemes, o.RouterPaths = 10, 5, 15
+	return o
+}
+
+func TestMeasureSurface(t *testing.T) {
+	setOpts(t, routerOptions())
+	manifest := parseTestManifest(t, routerFixture(t))
+	for _, tc := range []struct {
+		component App
+		want      surfaceInfo
+	}{
+		{manifest.Application.Activities[1], surfaceInfo{Kind: "activity", Component: ".Router", Deeplinks: 96, Schemes: 2, Hosts: 12, PathFamilies: 4, Router: true}},
+		{manifest.Application.Activities[2], surfaceInfo{Kind: "activity", Component: ".Small", Deeplinks: 5, Schemes: 1, Hosts: 1, PathFamilies: 1}},
+		{manifest.Application.Activities[4], surfaceInfo{Kind: "activity", Component: ".Schemes", Deeplinks: 3, Schemes: 3, Hosts: 1}},
+	} {
+		if got := measureSurface(tc.component, "activity"); got != tc.want {
+			t.Errorf("measureSurface(%s) = %+v, want %+v", tc.component.Name, got, tc.want)
+		}
+	}
+}
+
+func TestIsRouter(t *testing.T) {
+	info := surfaceInfo{Hosts: 12, Schemes: 3, PathFamilies: 4}
+	for _, tc := range []struct {
+		hosts, schemes, paths int
+		want                  bool
+	}{
+		{10, 4, 8, true},  // Hosts reach the threshold
+		{12, 0, 0, true},  // Reaching counts
+		{13, 4, 8, false}, // Below every threshold
+		{0, 3, 0, true},   // Schemes alone
+		{0, 0, 4, true},   // Path families alone
+		{0, 0, 0, false},  // Every criterion disabled
+	} {
+		setOpts(t, options{RouterHosts: tc.hosts, RouterSchemes: tc.schemes, RouterPaths: tc.paths})
+		if got := isRouter(info); got != tc.want {
+			t.Errorf("thresholds hosts=%d schemes=%d paths=%d: isRouter = %v, want %v", tc.hosts, tc.schemes, tc.paths, got, tc.want)
+		}
+	}
+}
+
+// TestSurfaceSummary checks the summary names the three components with the
+// most deeplinks plus every router-style one, and leaves out the rest and
+// unexported components.
+func TestSurfaceSummary(t *testing.T) {
+	o := routerOptions()
+	o.RouterSchemes = 3 // Makes .Schemes, fourth by deeplink count, a router too
+	setOpts(t, o)
+	result, text := renderFixture(t, routerFixture(t), "<resources/>")
+	var order []string
+	for _, info := range result.Surface {
+		order = append(order, info.Component)
+	}
+	if got := strings.Join(order, " "); got != ".Router .Small .Medium .Schemes .Tiny" {
+		t.Errorf("surface order %s, want largest first without .Hidden", got)
+	}
+	summary := text[strings.Index(text, "Deeplink Surface:"):]
+	for _, want := range []string{
+		".Router (activity): 96 deeplink(s) — router-style: 12 hosts, 2 schemes, 4 path families\n",
+		".Small (activity): 5 deeplink(s)\n",
+		".Medium (activity): 4 deeplink(s)\n",
+		".Schemes (activity): 3 deeplink(s) — router-style: 1 hosts, 3 schemes, 0 path families\n",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary lacks %q:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, ".Tiny") {
+		t.Errorf("summary lists .Tiny, neither in the top three nor a router:\n%s", summary)
+	}
+}
+
+// TestSortRiskRoutersFirst checks -sort risk moves router-style components to
+// the top of their section.
+func TestSortRiskRoutersFirst(t *testing.T) {
+	setOpts(t, routerOptions())
+	manifest := parseTestManifest(t, routerFixture(t))
+	var names []string
+	for _, component := range sortByRisk(manifest.Application.Activities, "activity", nil) {
+		names = append(names, component.Name)
+	}
+	if got := strings.Join(names, " "); !strings.HasPrefix(got, ".Router .Small .Medium") {
+		t.Errorf("sorted %s, want .Router first, then by deeplink count", got)
+	}
+
+	o := routerOptions()
+	o.Sort = "risk"
+	setOpts(t, o)
+	_, text := renderFixture(t, routerFixture(t), "<resources/>")
+	activities := text[strings.Index(text, "Processing Activities:"):]
+	if !strings.HasPrefix(activities, "Processing Activities:\n.Router") {
+		t.Errorf("-sort risk doesn't list .Router first:\n%s", activities[:min(len(activities), 300)])
+	}
+}
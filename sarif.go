@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json" // SARIF serialization
+	"fmt"           // Result messages
+	"net/url"       // File URIs of manifests outside the checkout
+	"os"            // Output file and folder detection
+	"path/filepath" // Manifest location
+	"slices"        // Filter actions and categories
+	"sort"          // Deterministic rule order
+	"strings"       // Rule help text
+)
+
+// SARIF document constants for code scanning uploads.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifInfoURI = "https://github.com/0xAlmighty/deeeeper"
+	// sarifFingerprintKey names the finding id in partialFingerprints; its
+	// version follows fingerprintVersion.
+	sarifFingerprintKey = "deeeeperFindingId/v2"
+)
+
+// sarifLevels maps Deeeeper severities onto SARIF result levels.
+var sarifLevels = map[string]string{
+	"critical": "error",
+	"high":     "error",
+	"medium":   "warning",
+	"low":      "note",
+	"info":     "note",
+}
+
+// sarifSecurityScores maps Deeeeper severities onto the CVSS-like score GitHub
+// code scanning reads from a rule's security-severity property.
+var sarifSecurityScores = map[string]string{
+	"critical": "9.5",
+	"high":     "8.0",
+	"medium":   "5.5",
+	"low":      "3.0",
+	"info":     "1.0",
+}
+
+// sarifLog is the root of a SARIF 2.1.0 document.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// sarifRun is the single run of a document, covering every analyzed target.
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+// sarifTool describes Deeeeper and its rules.
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+// sarifDriver is the tool component that produced the results.
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+// sarifRule is the metadata of one rule, taken from rules.
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	FullDescription      sarifMessage       `json:"fullDescription"`
+	Help                 sarifMessage       `json:"help"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	Properties           sarifRuleProps     `json:"properties"`
+}
+
+// sarifConfiguration is the default level of a rule.
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+// sarifRuleProps are the rule properties GitHub code scanning reads.
+type sarifRuleProps struct {
+	Tags             []string `json:"tags"`
+	SecuritySeverity string   `json:"security-severity"`
+}
+
+// sarifMessage is a plain-text message.
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+// sarifResult is one finding or browsable deeplink.
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          map[string]string `json:"properties,omitempty"`
+}
+
+// sarifLocation places a result in the manifest and names the component.
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+// sarifPhysicalLocation is the file a result is reported in.
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+// sarifArtifactLocation is a file path relative to the scanned checkout.
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// sarifLogicalLocation is the component a result is about.
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// writeSARIF writes every finding, which covers each exported component, and
+// one browsable-deeplink result per URI of a browsable filter as a SARIF 2.1.0
+// log for code scanning. Results point at the target's AndroidManifest.xml and
+// carry the finding id as partial fingerprint, so alerts survive re-runs.
+func writeSARIF(path string, reports []*report) error {
+	run := sarifRun{Results: []sarifResult{}}
+	used := make(map[string]bool)
+	for _, r := range reports {
+		manifest := sarifManifestPath(r.Target)
+		for _, f := range r.Findings {
+			used[f.Rule] = true
+			message := f.Title + ": " + f.Evidence
+			if len(f.URIs) > 0 {
+				message += fmt.Sprintf(" (%d deeplink(s), e.g. %s)", len(f.URIs), f.URIs[0])
+			}
+			run.Results = append(run.Results, sarifResultFor(r, manifest, f.Rule, f.Severity, f.Kind, f.Component, message, f.Fingerprint))
+		}
+		for _, c := range r.Components {
+			if !c.Exported || (c.Type != "activity" && c.Type != "alias") {
+				continue
+			}
+			seen := make(map[string]bool)
+			for _, filter := range c.Filters {
+				if !browsableInfo(filter) {
+					continue
+				}
+				for _, data := range filter.Data {
+					uri := constructURI(data)
+					if uri == "" || seen[uri] {
+						continue
+					}
+					seen[uri] = true
+					used["browsable-deeplink"] = true
+					message := fmt.Sprintf("%s is reachable from any web page via %s", c.Name, uri)
+					id := fingerprint(r.Package, "browsable-deeplink", c.Type, c.Name, uri)
+					result := sarifResultFor(r, manifest, "browsable-deeplink", rules["browsable-deeplink"].Severity, c.Type, c.Name, message, id)
+					result.Properties["uri"] = uri
+					run.Results = append(run.Results, result)
+				}
+			}
+		}
+	}
+	run.Tool.Driver = sarifDriver{Name: "Deeeeper", Version: toolVersion, InformationURI: sarifInfoURI, Rules: []sarifRule{}}
+	ids := make([]string, 0, len(used))
+	for id := range used {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRuleFor(id))
+	}
+
+	data, err := json.MarshalIndent(sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// sarifResultFor builds a result located at the manifest and the component.
+func sarifResultFor(r *report, manifest, ruleID, severity, kind, component, message, id string) sarifResult {
+	return sarifResult{
+		RuleID:  ruleID,
+		Level:   sarifLevels[severity],
+		Message: sarifMessage{Text: message},
+		Locations: []sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: manifest}},
+			LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: qualifiedName(r.Package, component), Kind: kind}},
+		}},
+		PartialFingerprints: map[string]string{sarifFingerprintKey: id},
+		Properties:          map[string]string{"package": r.Package, "severity": severity},
+	}
+}
+
+// sarifRuleFor renders the metadata of a rule. The name is the rule ID in
+// CamelCase, as SARIF viewers expect.
+func sarifRuleFor(id string) sarifRule {
+	meta := rules[id]
+	var name strings.Builder
+	for _, part := range strings.Split(id, "-") {
+		name.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	short, _, _ := strings.Cut(meta.Description, ". ") // First sentence
+	short = strings.TrimSuffix(short, ".") + "."
+	return sarifRule{
+		ID:                   id,
+		Name:                 name.String(),
+		ShortDescription:     sarifMessage{Text: short},
+		FullDescription:      sarifMessage{Text: meta.Description},
+		Help:                 sarifMessage{Text: meta.Mitigation},
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevels[meta.Severity]},
+		Properties: sarifRuleProps{
+			Tags:             []string{"security", "android", fmt.Sprintf("external/cwe/cwe-%d", meta.CWE)},
+			SecuritySeverity: sarifSecurityScores[meta.Severity],
+		},
+	}
+}
+
+// sarifManifestPath is the artifact location of a target's manifest: inside the
+// analyzed folder for -folder targets, relative to the working directory when
+// it is below it and a file: URI otherwise, and the bare name for APKs, whose
+// decoded manifest has no place in the scanned repository.
+func sarifManifestPath(target string) string {
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		return "AndroidManifest.xml"
+	}
+	manifest := filepath.Join(target, "AndroidManifest.xml")
+	if !filepath.IsAbs(manifest) {
+		return filepath.ToSlash(filepath.Clean(manifest))
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, manifest); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(manifest)}).String()
+}
+
+// browsableInfo is isBrowsable for the structured view of a filter.
+func browsableInfo(filter filterInfo) bool {
+	return slices.Contains(filter.Actions, "android.intent.action.VIEW") && slices.Contains(filter.Categories, "android.intent.category.BROWSABLE")
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/json" // Rules file parsing
+	"fmt"           // Error formatting
+	"os"            // Rules file access
+	"strings"       // Scheme normalization
+
+	"Deeeeper/Deeeeper/deeeeper" // Scheme rules of URI construction
+)
+
+// loadSchemeRules merges a JSON object of scheme rules into
+// deeeeper.SchemeRules, e.g. {"myapp": {"style": "authority", "default_host": "open"}}.
+func loadSchemeRules(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var loaded map[string]deeeeper.SchemeRule
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	for scheme, rule := range loaded {
+		switch rule.Style {
+		case "", "authority", "opaque":
+		default:
+			return fmt.Errorf("scheme %s: unknown style %q (expected authority or opaque)", scheme, rule.Style)
+		}
+		deeeeper.SchemeRules[strings.ToLower(scheme)] = rule
+	}
+	return nil
+}
@@ -0,0 +1,469 @@
+package main
+
+import (
+	"fmt"           // Check output
+	"io"            // Discarding the text report
+	"os"            // Temporary target
+	"path/filepath" // Target layout
+	"slices"        // Result lookups
+	"strings"       // Variant manifest
+
+	"Deeeeper/Deeeeper/deeeeper" // Package API check
+	"github.com/fatih/color"     // Colorized output in terminal
+)
+
+// selftestManifest declares one component of each type with filters covering
+// schemes, hosts, paths, patterns and categories, plus string references and a
+// host with a Cyrillic lookalike letter and a path needing percent-encoding.
+// The main activity shows over the lock screen and the alias offers direct share targets.
+// tools: merge rules remove a library activity, a permission and a permission request.
+// One permission request is capped below minSdk, and the camera feature is optional.
+// The legacy activity's code returns its incoming URI with a read grant, and it
+// repeats a deeplink pattern of the main activity, which shares another with its alias.
+const selftestManifest = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" xmlns:tools="http://schemas.android.com/tools" package="org.deeeeper.selftest">
+    <permission android:name="org.deeeeper.selftest.LIBRARY" android:protectionLevel="normal" tools:node="remove"/>
+    <uses-sdk android:minSdkVersion="21" android:targetSdkVersion="34"/>
+    <uses-permission android:name="android.permission.INTERNET"/>
+    <uses-permission android:name="android.permission.CAMERA" tools:node="remove"/>
+    <uses-permission android:name="android.permission.WRITE_EXTERNAL_STORAGE" android:maxSdkVersion="18"/>
+    <uses-permission android:name="android.permission.READ_EXTERNAL_STORAGE" android:maxSdkVersion="32"/>
+    <uses-feature android:name="android.hardware.camera" android:required="false"/>
+    <application android:label="@string/app_name">
+        <activity android:name=".MainActivity" android:exported="true" android:showWhenLocked="true">
+            <intent-filter android:autoVerify="true">
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.DEFAULT"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="https" android:host="@string/deeplink_host" android:pathPrefix="/open/"/>
+            </intent-filter>
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="selftest" android:host="item" android:pathPattern="/.*"/>
+            </intent-filter>
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="selftest" android:host="@string/alias_host"/>
+            </intent-filter>
+        </activity>
+        <activity-alias android:name=".Shortcut" android:targetActivity=".MainActivity" android:exported="true">
+            <meta-data android:name="android.service.chooser.chooser_target_service" android:value=".ShareTargetService"/>
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="selftest" android:host="@string/alias_host"/>
+            </intent-filter>
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="https" android:host="p&#1072;ypal.example"/>
+            </intent-filter>
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="selftest" android:host="docs" android:path="/read me/{id}/&#252;"/>
+            </intent-filter>
+        </activity-alias>
+        <activity android:name=".Internal" android:exported="false" tools:replace="android:exported"/>
+        <activity android:name="com.library.TrackingActivity" android:exported="true" tools:node="remove">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="tracking"/>
+            </intent-filter>
+        </activity>
+        <activity android:name=".Legacy">
+            <intent-filter>
+                <action android:name="org.deeeeper.selftest.action.LEGACY"/>
+            </intent-filter>
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="selftest" android:host="item" android:pathPattern="/.*"/>
+            </intent-filter>
+        </activity>
+        <service android:name=".SyncService" android:exported="@bool/sync_exported">
+            <intent-filter android:priority="@integer/sync_priority">
+                <action android:name="org.deeeeper.selftest.action.SYNC"/>
+            </intent-filter>
+        </service>
+        <receiver android:name=".BootReceiver" android:exported="@*android:bool/config_voice_capable">
+            <intent-filter>
+                <action android:name="android.intent.action.BOOT_COMPLETED"/>
+            </intent-filter>
+        </receiver>
+        <provider android:name=".DataProvider" android:authorities="@string/provider_authority" android:exported="true" android:grantUriPermissions="true"/>
+    </application>
+</manifest>
+`
+
+// selftestStrings exercises markup, entities and special characters.
+const selftestStrings = `<?xml version="1.0" encoding="utf-8"?>
+<resources xmlns:xliff="urn:oasis:names:tc:xliff:document:1.2">
+    <string name="app_name">Self &amp; Test "ünïcödé"</string>
+    <string name="deeplink_host"><xliff:g id="host">selftest.example.com</xliff:g></string>
+    <string name="alias_host"><![CDATA[alias]]></string>
+    <string name="provider_authority">@string/data_authority</string>
+    <string name="data_authority">org.deeeeper.selftest.data</string>
+</resources>
+`
+
+// selftestBools and selftestIntegers back the @bool and @integer references.
+const (
+	selftestBools = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <bool name="sync_exported">true</bool>
+</resources>
+`
+	selftestIntegers = `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <integer name="sync_priority">5</integer>
+</resources>
+`
+)
+
+// selftestInstantViolating is an instant-enabled module that stays on sandbox
+// version 1 and launches from a custom scheme and an unverified https filter.
+// selftestInstantConforming is the same module done right.
+const (
+	selftestInstantViolating = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" xmlns:dist="http://schemas.android.com/apk/distribution" package="org.deeeeper.instant">
+    <dist:module dist:instant="true"/>
+    <application>
+        <activity android:name=".Launch" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="instant" android:host="open"/>
+            </intent-filter>
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="https" android:host="instant.example.com"/>
+            </intent-filter>
+        </activity>
+    </application>
+</manifest>
+`
+	selftestInstantConforming = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" xmlns:dist="http://schemas.android.com/apk/distribution" package="org.deeeeper.instant" android:targetSandboxVersion="2">
+    <dist:module dist:instant="true"/>
+    <application>
+        <activity android:name=".Launch" android:exported="true">
+            <intent-filter android:autoVerify="true">
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="https" android:host="instant.example.com"/>
+            </intent-filter>
+        </activity>
+        <service android:name=".Sync" android:exported="true"/>
+    </application>
+</manifest>
+`
+)
+
+// selftestHostCasing spells one host three ways, trailing dot included, across
+// the filters of two activities.
+const selftestHostCasing = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="org.deeeeper.casing">
+    <application>
+        <activity android:name=".First" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="HTTPS" android:host="App.Example.COM" android:path="/open"/>
+                <data android:scheme="https" android:host="app.example.com" android:path="/open"/>
+            </intent-filter>
+        </activity>
+        <activity android:name=".Second" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="https" android:host="APP.EXAMPLE.COM." android:path="/open"/>
+            </intent-filter>
+        </activity>
+    </application>
+</manifest>
+`
+
+// selftestLegacySmali forwards the URI it was started with, granting read access.
+const selftestLegacySmali = `.class public Lorg/deeeeper/selftest/Legacy;
+.super Landroid/app/Activity;
+
+.method protected onCreate(Landroid/os/Bundle;)V
+    .locals 3
+    invoke-super {p0, p1}, Landroid/app/Activity;->onCreate(Landroid/os/Bundle;)V
+    invoke-virtual {p0}, Lorg/deeeeper/selftest/Legacy;->getIntent()Landroid/content/Intent;
+    move-result-object v0
+    invoke-virtual {v0}, Landroid/content/Intent;->getData()Landroid/net/Uri;
+    move-result-object v1
+    new-instance v0, Landroid/content/Intent;
+    invoke-direct {v0}, Landroid/content/Intent;-><init>()V
+    invoke-virtual {v0, v1}, Landroid/content/Intent;->setData(Landroid/net/Uri;)Landroid/content/Intent;
+    const/4 v2, 0x1
+    invoke-virtual {v0, v2}, Landroid/content/Intent;->addFlags(I)Landroid/content/Intent;
+    const/4 v2, -0x1
+    invoke-virtual {p0, v2, v0}, Lorg/deeeeper/selftest/Legacy;->setResult(ILandroid/content/Intent;)V
+    return-void
+.end method
+`
+
+// selftestVariant analyzes the same app decompiled differently: the provider
+// moved first under a fully qualified name and strings.xml left empty, so no
+// @string reference resolves. Its findings must keep their IDs.
+var selftestVariant *report
+
+// selftestCheck is one expectation about the analysis of the synthetic target.
+type selftestCheck struct {
+	Name string               // What is verified
+	OK   func(r *report) bool // The expectation
+}
+
+// selftestChecks are the embedded expectations.
+var selftestChecks = []selftestCheck{
+	{"package name parsed", func(r *report) bool { return r.Package == "org.deeeeper.selftest" }},
+	{"special characters in resolved strings need no repair", func(r *report) bool { return len(r.Repairs) == 0 }},
+	{"all seven components collected", func(r *report) bool { return len(r.Components) == 7 }},
+	{"string reference inside xliff:g resolved in a host", func(r *report) bool {
+		return hasTestCase(r, "https://selftest.example.com/open/")
+	}},
+	{"path pattern deeplink constructed", func(r *report) bool { return hasTestCase(r, "selftest://item/.*") }},
+	{"CDATA string resolved in an alias host", func(r *report) bool { return hasTestCase(r, "selftest://alias") }},
+	{"deeplink handler reported", func(r *report) bool { return hasFinding(r, "deeplink-handler", "high", "medium") }},
+	{"lock screen activity badged and rated one level higher", func(r *report) bool {
+		return hasBadge(r, ".MainActivity", badgeLockScreen) && slices.ContainsFunc(r.Findings, func(f finding) bool {
+			return f.Component == ".MainActivity" && f.Severity == "high"
+		})
+	}},
+	{"direct share target badged from chooser meta-data", func(r *report) bool { return hasBadge(r, ".Shortcut", badgeDirectShare) }},
+	{"@bool reference resolved in exported", func(r *report) bool {
+		return slices.ContainsFunc(r.Findings, func(f finding) bool { return f.Component == ".SyncService" })
+	}},
+	{"@integer reference resolved in a filter attribute", func(r *report) bool {
+		return slices.ContainsFunc(r.Components, func(c componentInfo) bool {
+			return c.Name == ".SyncService" && len(c.Filters) == 1 && c.Filters[0].Attributes["android:priority"] == "5"
+		})
+	}},
+	{"chained string references resolved in provider authorities", func(r *report) bool {
+		return hasTestCase(r, "content://org.deeeeper.selftest.data")
+	}},
+	{"private framework reference resolved from the bundled table", func(r *report) bool {
+		return len(r.Unresolved) == 0 && slices.ContainsFunc(r.Findings, func(f finding) bool { return f.Component == ".BootReceiver" })
+	}},
+	{"service with custom action rated high", func(r *report) bool { return hasFinding(r, "exported-service", "high") }},
+	{"protected-broadcast receiver rated info", func(r *report) bool { return hasFinding(r, "exported-receiver", "info") }},
+	{"exported provider reported", func(r *report) bool { return hasFinding(r, "exported-provider", "high") }},
+	{"activity with filters but no android:exported implicitly exported", func(r *report) bool {
+		return slices.ContainsFunc(r.Components, func(c componentInfo) bool { return c.Name == ".Legacy" && c.Exported })
+	}},
+	{"non-exported activity not reported", func(r *report) bool {
+		return !slices.ContainsFunc(r.Findings, func(f finding) bool { return f.Component == ".Internal" })
+	}},
+	{"App Links host verification detected", func(r *report) bool {
+		return slices.ContainsFunc(r.Hosts, func(h hostInfo) bool { return h.Host == "selftest.example.com" && h.AutoVerify })
+	}},
+	{"spaces and unicode percent-encoded, placeholder braces kept", func(r *report) bool {
+		return slices.ContainsFunc(r.TestCases, func(c testCase) bool {
+			return c.URI == "selftest://docs/read%20me/{id}/%C3%BC" && c.Raw == "selftest://docs/read me/{id}/\u00fc"
+		})
+	}},
+	{"every constructed URI parses cleanly", func(r *report) bool { return r.InvalidURIs == 0 }},
+	{"Cyrillic lookalike host punycoded and flagged", func(r *report) bool {
+		return slices.ContainsFunc(r.Hosts, func(h hostInfo) bool {
+			return h.Host == "xn--pypal-4ve.example" && h.Unicode == "p\u0430ypal.example" && len(h.MixedScripts) > 0
+		})
+	}},
+	{"deeplink surface counted per filter expansion, no router at default thresholds", func(r *report) bool {
+		return slices.ContainsFunc(r.Surface, func(s surfaceInfo) bool {
+			return s.Component == ".Shortcut" && s.Deeplinks == 3 && s.Hosts == 3 && s.Schemes == 2 && !s.Router
+		}) && !slices.ContainsFunc(r.Surface, func(s surfaceInfo) bool { return s.Router })
+	}},
+	{"tools:node=\"remove\" drops a library component and its deeplinks", func(r *report) bool {
+		return !slices.ContainsFunc(r.Components, func(c componentInfo) bool { return c.Name == "com.library.TrackingActivity" }) &&
+			!hasTestCase(r, "tracking://")
+	}},
+	{"tools:node=\"remove\" drops a permission request and a declaration", func(r *report) bool {
+		return slices.Equal(r.Requested, []string{"android.permission.INTERNET", "android.permission.WRITE_EXTERNAL_STORAGE", "android.permission.READ_EXTERNAL_STORAGE"}) && len(r.MergeRules) == 4
+	}},
+	{"maxSdkVersion below minSdk makes a request inert, optional features noted", func(r *report) bool {
+		return r.MinSDK == 21 && slices.Equal(r.Permissions, []permissionRequest{
+			{Name: "android.permission.INTERNET"},
+			{Name: "android.permission.WRITE_EXTERNAL_STORAGE", MaxSDKVersion: 18, Dangerous: true, Inert: true},
+			{Name: "android.permission.READ_EXTERNAL_STORAGE", MaxSDKVersion: 32, Dangerous: true},
+		}) && slices.Equal(r.Features, []featureInfo{{Name: "android.hardware.camera"}})
+	}},
+	{"URI grant forwarding chained to the grantable provider", func(r *report) bool {
+		return hasFinding(r, "uri-grant-forwarding", "high") && len(r.GrantChains) == 1 &&
+			slices.Equal(r.GrantChains[0].Providers, []string{"org.deeeeper.selftest.DataProvider (content://org.deeeeper.selftest.data)"})
+	}},
+	{"finding ids unique", func(r *report) bool {
+		ids := make(map[string]bool)
+		for _, f := range r.Findings {
+			ids[f.Fingerprint] = true
+		}
+		return len(ids) == len(r.Findings)
+	}},
+	{"finding ids unaffected by manifest order, name qualification and string resolution", func(r *report) bool {
+		return selftestVariant != nil && slices.Equal(findingIDs(r), findingIDs(selftestVariant))
+	}},
+	{"pattern claimed by two activities collides, alias and target don't", func(r *report) bool {
+		return len(r.Collisions) == 1 && r.Collisions[0].Pattern == "selftest://item/.* (pattern)" &&
+			slices.Equal(r.Collisions[0].Components, []string{".MainActivity", ".Legacy"}) && hasFinding(r, "deeplink-collision", "info")
+	}},
+	{"instant app constraints broken by one manifest and kept by another", func(*report) bool {
+		violating, _, err := parseManifest([]byte(selftestInstantViolating))
+		if err != nil {
+			return false
+		}
+		conforming, _, err := parseManifest([]byte(selftestInstantConforming))
+		if err != nil {
+			return false
+		}
+		var broken []string
+		if info := collectInstant(violating); info != nil {
+			for _, v := range info.Violations {
+				broken = appendUnique(broken, v.Constraint)
+			}
+		}
+		slices.Sort(broken)
+		info := collectInstant(conforming)
+		return slices.Equal(broken, []string{"entry-point", "https-only", "sandbox-version", "verified-links"}) &&
+			info != nil && len(info.Violations) == 0 && slices.Equal(info.Hidden, []string{".Sync"}) &&
+			collectInstant(Manifest{}) == nil
+	}},
+	{"strings missing from the variant reported where they appear", func(*report) bool {
+		return selftestVariant != nil && slices.Contains(selftestVariant.Unresolved, "@string/deeplink_host in .MainActivity data host") &&
+			slices.Contains(selftestVariant.Unresolved, "@string/provider_authority in org.deeeeper.selftest.DataProvider provider android:authorities")
+	}},
+	{"host spelled in three casings reported once wherever hosts are de-duplicated", func(*report) bool {
+		manifest, _, err := parseManifest([]byte(selftestHostCasing))
+		if err != nil {
+			return false
+		}
+		hosts := collectHosts(manifest)
+		first := manifest.Application.Activities[0]
+		cases := collectTestCases(manifest, "")
+		collisions := collectDeeplinkCollisions(manifest)
+		surface := measureSurface(first, "activity")
+		return len(hosts) == 1 && hosts[0].Host == "app.example.com" && slices.Equal(hosts[0].Components, []string{".First", ".Second"}) &&
+			slices.Equal(componentURIs("", first), []string{"https://app.example.com/open"}) &&
+			len(cases) == 2 && cases[0].Raw == "HTTPS://App.Example.COM/open" && cases[1].Raw == "https://APP.EXAMPLE.COM./open" &&
+			len(collisions) == 1 && collisions[0].Pattern == "https://app.example.com/open" &&
+			surface.Hosts == 1 && surface.Schemes == 1 && surface.Deeplinks == 1
+	}},
+	{"custom action listed", func(r *report) bool {
+		return slices.ContainsFunc(r.Actions, func(a actionInfo) bool { return a.Action == "org.deeeeper.selftest.action.SYNC" && a.Custom })
+	}},
+	{"package API parses the manifest, resolves strings and extracts deeplinks", func(*report) bool {
+		stringMap, err := deeeeper.ParseStrings(strings.NewReader(selftestStrings))
+		if err != nil {
+			return false
+		}
+		manifest, err := deeeeper.ParseManifest([]byte(selftestManifest))
+		if err != nil {
+			return false
+		}
+		deeeeper.ResolveManifest(&manifest, deeeeper.StringResources(stringMap))
+		deeplinks := deeeeper.ExtractDeeplinks(manifest)
+		return len(deeplinks) == 8 && deeplinks[0].Component == ".MainActivity" && deeplinks[0].URI == "https://selftest.example.com/open/" &&
+			manifest.Application.Providers[0].Authorities == "org.deeeeper.selftest.data"
+	}},
+}
+
+// hasTestCase reports whether the report contains a test case for the URI.
+func hasTestCase(r *report, uri string) bool {
+	return slices.ContainsFunc(r.TestCases, func(c testCase) bool { return c.URI == uri })
+}
+
+// findingIDs returns the sorted finding IDs of a report.
+func findingIDs(r *report) []string {
+	var ids []string
+	for _, f := range r.Findings {
+		ids = append(ids, f.Fingerprint)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// hasBadge reports whether the named component carries the badge.
+func hasBadge(r *report, name, badge string) bool {
+	return slices.ContainsFunc(r.Components, func(c componentInfo) bool { return c.Name == name && slices.Contains(c.Badges, badge) })
+}
+
+// hasFinding reports whether the report raised the rule at one of the severities.
+func hasFinding(r *report, rule string, severities ...string) bool {
+	return slices.ContainsFunc(r.Findings, func(f finding) bool {
+		return f.Rule == rule && slices.Contains(severities, f.Severity)
+	})
+}
+
+// runSelfTest writes the synthetic decompiled target to a temporary directory,
+// analyzes it and checks the embedded expectations, printing PASS or FAIL per
+// check. It returns the process exit code: 0 when every check passed.
+func runSelfTest() int {
+	dir, err := os.MkdirTemp("", "deeeeper-selftest-")
+	if err != nil {
+		color.Red("Error creating selftest directory: %s\n", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+	variantDir := filepath.Join(dir, "variant")
+	provider := `        <provider android:name=".DataProvider" android:authorities="@string/provider_authority" android:exported="true" android:grantUriPermissions="true"/>
+`
+	variantManifest := strings.Replace(selftestManifest, provider, "", 1)
+	variantManifest = strings.Replace(variantManifest, "    <application android:label=\"@string/app_name\">\n",
+		"    <application android:label=\"@string/app_name\">\n"+strings.Replace(provider, ".DataProvider", "org.deeeeper.selftest.DataProvider", 1), 1)
+	for _, target := range []struct{ dir, manifest, strings string }{
+		{dir, selftestManifest, selftestStrings},
+		{variantDir, variantManifest, "<resources/>\n"},
+	} {
+		if err := writeSelftestTarget(target.dir, target.manifest, target.strings); err != nil {
+			color.Red("Error writing selftest target: %s\n", err)
+			return 1
+		}
+	}
+
+	result, err := analyzeFolder(io.Discard, color.Output, dir)
+	if err != nil {
+		color.Red("FAIL analysis: %s\n", err)
+		return 1
+	}
+	selftestVariant, err = analyzeFolder(io.Discard, io.Discard, variantDir) // The variant's unresolved strings are deliberate, so are its warnings
+	if err != nil {
+		color.Red("FAIL analysis of the variant: %s\n", err)
+		return 1
+	}
+	out := textWriter()
+	failed := 0
+	for _, check := range selftestChecks {
+		if check.OK(result) {
+			color.New(color.FgGreen).Fprintf(out, "PASS %s\n", check.Name)
+		} else {
+			color.New(color.FgRed).Fprintf(out, "FAIL %s\n", check.Name)
+			failed++
+		}
+	}
+	if failed > 0 {
+		color.Red("%d of %d selftest checks failed.", failed, len(selftestChecks))
+		return 1
+	}
+	fmt.Fprintf(out, "All %d selftest checks passed.\n", len(selftestChecks))
+	return 0
+}
+
+// writeSelftestTarget writes a synthetic decompiled target into dir.
+func writeSelftestTarget(dir, manifest, stringsXML string) error {
+	for _, sub := range []string{filepath.Join("res", "values"), filepath.Join("smali", "org", "deeeeper", "selftest")} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return err
+		}
+	}
+	files := map[string]string{
+		"AndroidManifest.xml":                                                 manifest,
+		filepath.Join("res", "values", "strings.xml"):                         stringsXML,
+		filepath.Join("res", "values", "bools.xml"):                           selftestBools,
+		filepath.Join("res", "values", "integers.xml"):                        selftestIntegers,
+		filepath.Join("smali", "org", "deeeeper", "selftest", "Legacy.smali"): selftestLegacySmali,
+		"apktool.yml": "version: 2.9.3\n", // Marks the target as apktool output, not a source tree
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
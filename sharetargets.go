@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"     // Output formatting
+	"io"      // Output destination
+	"slices"  // Deduplicating mime types
+	"strings" // Action matching
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// Share actions deliver attacker-controlled text, streams and URIs from any app.
+const (
+	actionSend         = "android.intent.action.SEND"
+	actionSendMultiple = "android.intent.action.SEND_MULTIPLE"
+)
+
+// shareTarget is a component that accepts ACTION_SEND or ACTION_SEND_MULTIPLE.
+type shareTarget struct {
+	Kind      string   `json:"type"`       // Component kind
+	Component string   `json:"component"`  // Component name
+	Exported  bool     `json:"exported"`   // Whether other apps can reach it
+	Actions   []string `json:"actions"`    // SEND actions handled
+	MimeTypes []string `json:"mime_types"` // Accepted mime types
+	WebView   bool     `json:"webview"`    // Heuristic: the class loads content into a WebView
+}
+
+// collectShareTargets lists every component with a SEND or SEND_MULTIPLE filter.
+func collectShareTargets(manifest Manifest, folder string) []shareTarget {
+	var targets []shareTarget
+	for _, group := range componentGroups(manifest) {
+		for _, component := range group.Components {
+			var share shareTarget
+			for _, filter := range component.Filters {
+				for _, action := range filter.Actions {
+					if (action.Name == actionSend || action.Name == actionSendMultiple) && !slices.Contains(share.Actions, action.Name) {
+						share.Actions = append(share.Actions, action.Name)
+					}
+				}
+				if !filterHasAction(filter, actionSend) && !filterHasAction(filter, actionSendMultiple) {
+					continue
+				}
+				for _, data := range filter.Data {
+					if data.MimeType != "" && !slices.Contains(share.MimeTypes, data.MimeType) {
+						share.MimeTypes = append(share.MimeTypes, data.MimeType)
+					}
+				}
+			}
+			if len(share.Actions) == 0 {
+				continue
+			}
+			share.Kind, share.Component = group.Kind, component.Name
+			share.Exported, _ = isExported(component, group.Kind)
+			share.WebView = classLoadsWebView(folder, qualifiedName(manifest.Package, component.Name))
+			targets = append(targets, share)
+		}
+	}
+	return targets
+}
+
+// filterHasAction reports whether the intent filter declares the action.
+func filterHasAction(filter IntentFilter, name string) bool {
+	for _, action := range filter.Actions {
+		if action.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// printShareTargets renders the share target inventory.
+func printShareTargets(w io.Writer, targets []shareTarget) {
+	cyan := color.New(color.FgCyan).SprintFunc()
+	green := color.New(color.FgGreen).SprintFunc()
+	yellow := color.New(color.FgYellow).SprintFunc()
+
+	printSection(w, "\nShare targets:")
+	for _, share := range targets {
+		var actions []string
+		for _, action := range share.Actions {
+			actions = append(actions, strings.TrimPrefix(action, "android.intent.action."))
+		}
+		fmt.Fprintf(w, "%s (%s, exported=%t) %s\n", cyan(share.Component), share.Kind, share.Exported, strings.Join(actions, ", "))
+		mimeTypes := "any type"
+		if len(share.MimeTypes) > 0 {
+			mimeTypes = strings.Join(share.MimeTypes, ", ")
+		}
+		fmt.Fprintf(w, "  %s\n", green(mimeTypes))
+		if share.WebView {
+			fmt.Fprintf(w, "  %s\n", yellow("also loads content into a WebView (smali heuristic)"))
+		}
+	}
+}
+
+// actionMatches reports whether a filter action satisfies the -action selector.
+// A selector without dots is shorthand for android.intent.action.<NAME>, and
+// "SEND" also selects SEND_MULTIPLE.
+func actionMatches(action, selector string) bool {
+	if selector == "" {
+		return true
+	}
+	if strings.Contains(selector, ".") {
+		return action == selector
+	}
+	short, ok := strings.CutPrefix(action, "android.intent.action.")
+	if !ok {
+		return false
+	}
+	selector = strings.ToUpper(selector)
+	return short == selector || (selector == "SEND" && short == "SEND_MULTIPLE")
+}
+
+// componentHasAction reports whether any of the component's filters matches the -action selector.
+func componentHasAction(component App, selector string) bool {
+	if selector == "" {
+		return true
+	}
+	for _, filter := range component.Filters {
+		for _, action := range filter.Actions {
+			if actionMatches(action.Name, selector) {
+				return true
+			}
+		}
+	}
+	return false
+}
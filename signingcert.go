@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"           // APK signing block lookup
+	"crypto/sha256"   // Certificate fingerprints
+	"encoding/asn1"   // PKCS#7 signature files
+	"encoding/binary" // APK signing block layout
+	"encoding/hex"    // Fingerprint formatting
+	"errors"          // Parse failures
+	"fmt"             // Fingerprint validation
+	"os"              // Signature and APK files
+	"path/filepath"   // META-INF lookup
+	"strings"         // Fingerprint formatting
+)
+
+// APK Signature Scheme block IDs whose signers carry certificates.
+const (
+	apkSignatureV2 = 0x7109871a
+	apkSignatureV3 = 0xf05368c0
+)
+
+// errNoCertificate is returned when no signing certificate could be found.
+var errNoCertificate = errors.New("no signing certificate found")
+
+// formatFingerprint renders a SHA-256 digest as colon-separated upper-case hex
+// pairs, the form assetlinks.json and keytool use.
+func formatFingerprint(sum []byte) string {
+	pairs := make([]string, len(sum))
+	for i, b := range sum {
+		pairs[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(pairs, ":")
+}
+
+// normalizeFingerprint accepts a SHA-256 fingerprint with or without colons in
+// any case and returns it in formatFingerprint form.
+func normalizeFingerprint(value string) (string, error) {
+	sum, err := hex.DecodeString(strings.ReplaceAll(strings.TrimSpace(value), ":", ""))
+	if err != nil || len(sum) != sha256.Size {
+		return "", fmt.Errorf("%q is not a SHA-256 fingerprint", value)
+	}
+	return formatFingerprint(sum), nil
+}
+
+// folderCertFingerprint returns the fingerprint of the v1 (JAR) signing
+// certificate kept by apktool under original/META-INF.
+func folderCertFingerprint(folder string) (string, error) {
+	for _, pattern := range []string{"*.RSA", "*.DSA", "*.EC"} {
+		matches, _ := filepath.Glob(filepath.Join(folder, "original", "META-INF", pattern))
+		for _, path := range matches {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			if cert, err := pkcs7Certificate(data); err == nil {
+				sum := sha256.Sum256(cert)
+				return formatFingerprint(sum[:]), nil
+			}
+		}
+	}
+	return "", errNoCertificate
+}
+
+// pkcs7Certificate returns the DER bytes of the first certificate in a PKCS#7
+// SignedData structure, as found in JAR signature files.
+func pkcs7Certificate(data []byte) ([]byte, error) {
+	var contentInfo asn1.RawValue
+	if _, err := asn1.Unmarshal(data, &contentInfo); err != nil {
+		return nil, err
+	}
+	var contentType asn1.ObjectIdentifier
+	rest, err := asn1.Unmarshal(contentInfo.Bytes, &contentType)
+	if err != nil {
+		return nil, err
+	}
+	var explicit, signedData asn1.RawValue
+	if _, err := asn1.Unmarshal(rest, &explicit); err != nil {
+		return nil, err
+	}
+	if _, err := asn1.Unmarshal(explicit.Bytes, &signedData); err != nil {
+		return nil, err
+	}
+	for fields := signedData.Bytes; len(fields) > 0; {
+		var field asn1.RawValue
+		if fields, err = asn1.Unmarshal(fields, &field); err != nil {
+			return nil, err
+		}
+		if field.Class == asn1.ClassContextSpecific && field.Tag == 0 { // [0] IMPLICIT certificates
+			var cert asn1.RawValue
+			if _, err := asn1.Unmarshal(field.Bytes, &cert); err != nil {
+				return nil, err
+			}
+			return cert.FullBytes, nil
+		}
+	}
+	return nil, errNoCertificate
+}
+
+// apkCertFingerprint returns the fingerprint of the first signer's certificate
+// in the APK Signing Block (v2 or v3 scheme) of an APK file.
+func apkCertFingerprint(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	eocd := bytes.LastIndex(data, []byte{0x50, 0x4b, 0x05, 0x06})
+	if eocd < 0 || eocd+20 > len(data) {
+		return "", errNoCertificate
+	}
+	centralDir := int(binary.LittleEndian.Uint32(data[eocd+16:]))
+	if centralDir < 24 || centralDir > len(data) || string(data[centralDir-16:centralDir]) != "APK Sig Block 42" {
+		return "", errNoCertificate // v1-only or unsigned APK
+	}
+	blockSize := int(binary.LittleEndian.Uint64(data[centralDir-24:]))
+	start := centralDir - blockSize - 8
+	if blockSize < 24 || start < 0 {
+		return "", errNoCertificate
+	}
+	pairs := data[start+8 : centralDir-24]
+	for len(pairs) >= 12 {
+		size := int(binary.LittleEndian.Uint64(pairs))
+		if size < 4 || size > len(pairs)-8 {
+			break
+		}
+		id, value := binary.LittleEndian.Uint32(pairs[8:]), pairs[12:8+size]
+		pairs = pairs[8+size:]
+		if id != apkSignatureV2 && id != apkSignatureV3 {
+			continue
+		}
+		if cert, ok := signerCertificate(value); ok {
+			sum := sha256.Sum256(cert)
+			return formatFingerprint(sum[:]), nil
+		}
+	}
+	return "", errNoCertificate
+}
+
+// signerCertificate walks signers → signer → signed data → certificates of a
+// v2/v3 signature block value and returns the first certificate.
+func signerCertificate(value []byte) ([]byte, bool) {
+	signers, _, ok := lengthPrefixed(value)
+	if !ok {
+		return nil, false
+	}
+	signer, _, ok := lengthPrefixed(signers)
+	if !ok {
+		return nil, false
+	}
+	signedData, _, ok := lengthPrefixed(signer)
+	if !ok {
+		return nil, false
+	}
+	_, rest, ok := lengthPrefixed(signedData) // Digests
+	if !ok {
+		return nil, false
+	}
+	certificates, _, ok := lengthPrefixed(rest)
+	if !ok {
+		return nil, false
+	}
+	cert, _, ok := lengthPrefixed(certificates)
+	return cert, ok
+}
+
+// lengthPrefixed splits a uint32-length-prefixed chunk off the front of data.
+func lengthPrefixed(data []byte) (chunk, rest []byte, ok bool) {
+	if len(data) < 4 {
+		return nil, nil, false
+	}
+	size := int(binary.LittleEndian.Uint32(data))
+	if size > len(data)-4 {
+		return nil, nil, false
+	}
+	return data[4 : 4+size], data[4+size:], true
+}
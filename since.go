@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"           // Error formatting
+	"os"            // Modification times
+	"path/filepath" // Manifest path in decompiled folders
+	"time"          // Timestamp parsing
+)
+
+// parseSince turns -since and -newer-than into the cutoff time for incremental
+// runs. The zero time means every input is analyzed.
+func parseSince(since, newerThan string) (time.Time, error) {
+	if since != "" && newerThan != "" {
+		return time.Time{}, fmt.Errorf("-since and -newer-than are mutually exclusive")
+	}
+	if since != "" {
+		cutoff, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("parsing -since (expected RFC3339, e.g. 2024-05-01T12:00:00Z): %w", err)
+		}
+		return cutoff, nil
+	}
+	if newerThan != "" {
+		info, err := os.Stat(newerThan)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("reading -newer-than reference: %w", err)
+		}
+		return info.ModTime(), nil
+	}
+	return time.Time{}, nil
+}
+
+// filterSince drops the targets not modified after the cutoff. Archive members
+// carry the modification time recorded in the archive. It returns the kept
+// targets and the number skipped.
+func filterSince(targets []target, cutoff time.Time) ([]target, int) {
+	if cutoff.IsZero() {
+		return targets, 0
+	}
+	var kept []target
+	for _, t := range targets {
+		info, err := os.Stat(t.Path)
+		if err != nil || info.ModTime().After(cutoff) { // Let unreadable targets fail loudly in analysis
+			kept = append(kept, t)
+		}
+	}
+	return kept, len(targets) - len(kept)
+}
+
+// folderChangedSince reports whether a decompiled folder's manifest was
+// modified after the cutoff.
+func folderChangedSince(folder string, cutoff time.Time) bool {
+	if cutoff.IsZero() {
+		return true
+	}
+	info, err := os.Stat(filepath.Join(folder, "AndroidManifest.xml"))
+	return err != nil || info.ModTime().After(cutoff)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"         // Scanning smali contents
+	"os"            // Reading smali files
+	"path/filepath" // Locating smali directories
+	"strings"       // Class name handling
+)
+
+// webViewLoads are smali invocation fragments that load content into a WebView.
+var webViewLoads = [][]byte{
+	[]byte("Landroid/webkit/WebView;->loadUrl"),
+	[]byte("Landroid/webkit/WebView;->loadData"),
+	[]byte("Landroid/webkit/WebView;->loadDataWithBaseURL"),
+	[]byte("Landroid/webkit/WebView;->postUrl"),
+}
+
+// qualifiedName expands manifest shorthand (".Main" or "Main") against the package.
+func qualifiedName(pkg, name string) string {
+	if strings.HasPrefix(name, ".") {
+		return pkg + name
+	}
+	if !strings.Contains(name, ".") && pkg != "" {
+		return pkg + "." + name
+	}
+	return name
+}
+
+// smaliFiles returns the smali files of a class and its inner classes across
+// every smali, smali_classes2, ... directory of the decompiled folder.
+func smaliFiles(folder, className string) []string {
+	relative := filepath.FromSlash(strings.ReplaceAll(className, ".", "/"))
+	roots, _ := filepath.Glob(filepath.Join(folder, "smali*"))
+	var files []string
+	for _, root := range roots {
+		base := filepath.Join(root, relative)
+		if _, err := os.Stat(base + ".smali"); err == nil {
+			files = append(files, base+".smali")
+		}
+		inner, _ := filepath.Glob(base + "$*.smali")
+		files = append(files, inner...)
+	}
+	return files
+}
+
+// classLoadsWebView is a heuristic: it reports whether the class (or one of its
+// inner classes) calls a WebView load method.
+func classLoadsWebView(folder, className string) bool {
+	for _, path := range smaliFiles(folder, className) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, marker := range webViewLoads {
+			if bytes.Contains(data, marker) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"         // Scanning smali lines
+	"bytes"         // Matching smali calls
+	"encoding/xml"  // Escaping attribute values
+	"fmt"           // Snippet lines
+	"os"            // Reading smali files
+	"path/filepath" // Smali file names
+	"strings"       // Snippet assembly
+	"unicode/utf8"  // Truncating on rune boundaries
+)
+
+// maxSnippetLength caps a finding snippet in bytes. Longer snippets drop their
+// last lines and end in an ellipsis line, so the cut is the same on every run.
+const maxSnippetLength = 800
+
+// snippetAttributes are the component attributes a snippet shows, in order.
+// The rest (labels, themes, ...) says nothing about reachability.
+var snippetAttributes = []string{
+	"android:name", "android:exported", "android:enabled", "android:permission",
+	"android:readPermission", "android:writePermission", "android:authorities",
+	"android:grantUriPermissions", "android:targetActivity", "android:directBootAware",
+	"android:singleUser", "android:showWhenLocked", "android:showOnLockScreen",
+}
+
+// attachSnippets fills in the Snippet of every finding from the data it is
+// based on, unless it has one already: the smali calls behind code heuristics,
+// the <manifest> element for app-wide findings and the component with its filters and guarding
+// permissions otherwise.
+func attachSnippets(findings []finding, manifest Manifest, protections []protectionInfo, folder string) {
+	for i := range findings {
+		f := &findings[i]
+		switch {
+		case f.Snippet != "": // Set by the heuristic that raised the finding
+		case f.Rule == "share-target-webview":
+			f.Snippet = capSnippet(strings.Join(webViewLoadLines(folder, qualifiedName(manifest.Package, f.Component)), "\n"))
+		case f.Kind == "application":
+			f.Snippet = capSnippet(fmt.Sprintf("<manifest package=%s android:sharedUserId=%s>", quoteAttr(manifest.Package), quoteAttr(manifest.SharedUserID)))
+		default:
+			if component, ok := findComponent(manifest, f.Kind, f.Component); ok {
+				f.Snippet = capSnippet(componentSnippet(component, f.Kind, protections))
+			}
+		}
+	}
+}
+
+// findComponent looks up the component a finding names, following
+// -resolve-aliases back from the targetActivity to the alias.
+func findComponent(manifest Manifest, kind, name string) (App, bool) {
+	for _, group := range componentGroups(manifest) {
+		if group.Kind != kind {
+			continue
+		}
+		for _, component := range group.Components {
+			if target, ok := resolvedAlias(component, kind); component.Name == name || ok && target == name {
+				return component, true
+			}
+		}
+	}
+	return App{}, false
+}
+
+// componentSnippet renders a component as a manifest excerpt: its element with
+// the attributes that decide reachability, an explicit note when exported is
+// absent, its filters and the protection level of each guarding permission.
+func componentSnippet(component App, kind string, protections []protectionInfo) string {
+	element := map[string]string{"alias": "activity-alias"}[kind]
+	if element == "" {
+		element = kind
+	}
+	var b strings.Builder
+	b.WriteString("<" + element)
+	for _, attr := range snippetAttributes {
+		if value, ok := component.Attributes[attr]; ok {
+			fmt.Fprintf(&b, " %s=%s", attr, quoteAttr(value))
+		}
+	}
+	b.WriteString(">")
+	if _, ok := component.Attributes["android:exported"]; !ok {
+		b.WriteString(" <!-- android:exported absent -->")
+	}
+	for _, p := range protections {
+		if p.Kind == kind && p.Component == component.Name {
+			fmt.Fprintf(&b, "\n  <!-- %s %s: protectionLevel %s -->", p.Attribute, p.Permission, p.Level)
+		}
+	}
+	for _, path := range component.PathPermissions {
+		b.WriteString("\n  <path-permission" + providerPathAttributes(path) + "/>")
+	}
+	for _, path := range component.GrantURIPaths {
+		b.WriteString("\n  <grant-uri-permission" + providerPathAttributes(path) + "/>")
+	}
+	for _, filter := range component.Filters {
+		b.WriteString("\n  <intent-filter")
+		for _, attr := range sortedKeys(filter.Attributes) {
+			fmt.Fprintf(&b, " %s=%s", attr, quoteAttr(filter.Attributes[attr]))
+		}
+		b.WriteString(">")
+		for _, action := range filter.Actions {
+			fmt.Fprintf(&b, "\n    <action android:name=%s/>", quoteAttr(action.Name))
+		}
+		for _, category := range filter.Categories {
+			fmt.Fprintf(&b, "\n    <category android:name=%s/>", quoteAttr(category.Name))
+		}
+		for _, data := range filter.Data {
+			b.WriteString("\n    <data" + dataAttributes(data) + "/>")
+		}
+		b.WriteString("\n  </intent-filter>")
+	}
+	if len(component.Filters)+len(component.PathPermissions)+len(component.GrantURIPaths) > 0 {
+		b.WriteString("\n</" + element + ">")
+	}
+	return b.String()
+}
+
+// dataAttributes renders the attributes of a <data> element in manifest order.
+func dataAttributes(data Data) string {
+	var b strings.Builder
+	for _, attr := range []struct{ name, value string }{
+		{"scheme", data.Scheme}, {"host", data.Host}, {"port", data.Port},
+		{"path", data.Path}, {"pathPrefix", data.PathPrefix}, {"pathPattern", data.PathPattern},
+		{"ssp", data.Ssp}, {"sspPrefix", data.SspPrefix}, {"sspPattern", data.SspPattern},
+		{"mimeType", data.MimeType},
+	} {
+		if attr.value != "" {
+			fmt.Fprintf(&b, " android:%s=%s", attr.name, quoteAttr(attr.value))
+		}
+	}
+	return b.String()
+}
+
+// providerPathAttributes renders the attributes of a <path-permission> or
+// <grant-uri-permission> element in manifest order.
+func providerPathAttributes(path ProviderPath) string {
+	var b strings.Builder
+	for _, attr := range []struct{ name, value string }{
+		{"path", path.Path}, {"pathPrefix", path.PathPrefix}, {"pathPattern", path.PathPattern},
+		{"permission", path.Permission}, {"readPermission", path.ReadPermission}, {"writePermission", path.WritePermission},
+	} {
+		if attr.value != "" {
+			fmt.Fprintf(&b, " android:%s=%s", attr.name, quoteAttr(attr.value))
+		}
+	}
+	return b.String()
+}
+
+// quoteAttr quotes an attribute value the way it would appear in XML.
+func quoteAttr(value string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(value))
+	return `"` + b.String() + `"`
+}
+
+// capSnippet shortens a snippet to maxSnippetLength, dropping whole lines
+// where possible so excerpts stay readable.
+func capSnippet(snippet string) string {
+	if len(snippet) <= maxSnippetLength {
+		return snippet
+	}
+	cut := strings.LastIndex(snippet[:maxSnippetLength], "\n")
+	if cut <= 0 { // A single long line: cut on a rune boundary
+		cut = maxSnippetLength
+		for cut > 0 && !utf8.RuneStart(snippet[cut]) {
+			cut--
+		}
+	}
+	return snippet[:cut] + "\n…"
+}
+
+// webViewLoadLines returns the smali lines of a class (and its inner classes)
+// that load content into a WebView, prefixed with their file name.
+func webViewLoadLines(folder, className string) []string {
+	var lines []string
+	for _, path := range smaliFiles(folder, className) {
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024) // Long string constants
+		for scanner.Scan() {
+			for _, marker := range webViewLoads {
+				if bytes.Contains(scanner.Bytes(), marker) {
+					lines = append(lines, filepath.Base(path)+": "+strings.TrimSpace(scanner.Text()))
+					break
+				}
+			}
+		}
+		file.Close()
+	}
+	return lines
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json" // State file serialization
+	"errors"        // Missing state file detection
+	"io/fs"         // Missing state file detection
+	"os"            // State file access
+	"path/filepath" // Default state file location
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// defaultStateFile is the name of the state -skip-unchanged keeps unless
+// -state-file is given; stateFilePath decides its directory.
+const defaultStateFile = "deeeeper-state.json"
+
+// stateFilePath returns the state file of this run: -state-file when given,
+// else defaultStateFile next to the -o output or inside the -html-dir
+// directory, so scans writing elsewhere don't litter the working directory.
+// Only a run without an output path keeps it in the working directory.
+func stateFilePath() string {
+	switch {
+	case opts.StateFile != "":
+		return opts.StateFile
+	case opts.Output != "":
+		return filepath.Join(filepath.Dir(opts.Output), defaultStateFile)
+	case opts.HTMLDir != "":
+		return filepath.Join(opts.HTMLDir, defaultStateFile)
+	}
+	return defaultStateFile
+}
+
+// stateEntry remembers one analyzed APK: its hash and the report it produced.
+type stateEntry struct {
+	SHA256 string  `json:"sha256"`
+	Report *report `json:"report"`
+}
+
+// loadState reads the state of the previous run. A missing file is an empty state.
+func loadState(path string) (map[string]stateEntry, error) {
+	state := make(map[string]stateEntry)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// saveState writes the state of this run. Only targets seen in this run are
+// kept, so deleted APKs age out.
+func saveState(path string, reports []*report) error {
+	state := make(map[string]stateEntry)
+	for _, r := range reports {
+		if r.SHA256 != "" {
+			state[r.Target] = stateEntry{SHA256: r.SHA256, Report: r}
+		}
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// skipUnchanged splits the targets into those that need analysis and the cached
+// reports of those whose hash matches the previous run. Cached reports are
+// marked unchanged and their headers printed in place of a fresh analysis.
+func skipUnchanged(targets []target, state map[string]stateEntry) ([]target, []*report) {
+	var changed []target
+	var cached []*report
+	for _, t := range targets {
+		entry, ok := state[t.label()]
+		hash, err := fileSHA256(t.Path)
+		if !ok || err != nil || entry.Report == nil || entry.SHA256 != hash {
+			changed = append(changed, t)
+			continue
+		}
+		previous := *entry.Report
+		previous.Unchanged = true
+		color.New(color.FgMagenta).Fprintf(textWriter(), "\n=== %s [%s] (unchanged) ===\n", t.label(), previous.Package)
+		cached = append(cached, &previous)
+	}
+	return changed, cached
+}
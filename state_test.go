@@ -0,0 +1,27 @@
+package main
+
+import (
+	"path/filepath" // Expected paths
+	"testing"       // Test harness
+)
+
+func TestStateFilePath(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		options options
+		want    string
+	}{
+		{"explicit", options{StateFile: "state/mirror.json", Output: "out/report.json"}, "state/mirror.json"},
+		{"next to -o", options{Output: filepath.Join("out", "report.json"), Format: "json", HTMLDir: "site"}, filepath.Join("out", defaultStateFile)},
+		{"-o in the working directory", options{Output: "report.txt"}, defaultStateFile},
+		{"in -html-dir", options{HTMLDir: "site"}, filepath.Join("site", defaultStateFile)},
+		{"no output path", options{Format: "json"}, defaultStateFile},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			setOpts(t, tc.options)
+			if got := stateFilePath(); got != tc.want {
+				t.Errorf("stateFilePath() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"         // Rendering before writing
+	"fmt"           // Type errors of template funcs
+	"io"            // Output destination
+	"path/filepath" // Template name in error messages
+	"strings"       // join
+	"text/template" // User-supplied report templates
+	"time"          // Generation timestamp
+)
+
+// outputTemplate is the -template file, parsed before any analysis runs.
+var outputTemplate *template.Template
+
+// templateData is what a -template file executes against.
+type templateData struct {
+	Generated string    // Generation time, RFC 3339
+	Version   string    // Deeeeper version
+	Reports   []*report // One entry per analyzed target, as in -format json
+}
+
+// templateFuncs are the helpers available to -template files, besides the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	"exported": templateExported,
+	"ofType":   templateOfType,
+	"uris":     templateURIs,
+	"join":     func(sep string, elems []string) string { return strings.Join(elems, sep) },
+}
+
+// loadOutputTemplate parses the -template file. Errors name the file and the
+// line, as text/template reports them.
+func loadOutputTemplate(path string) error {
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).ParseFiles(path)
+	if err != nil {
+		return err
+	}
+	outputTemplate = tmpl
+	return nil
+}
+
+// writeTemplateOutput executes the -template file against the reports. The
+// output is rendered in full first, so a failing template writes nothing.
+func writeTemplateOutput(w io.Writer, reports []*report) error {
+	var b bytes.Buffer
+	data := templateData{Generated: time.Now().UTC().Format(time.RFC3339), Version: toolVersion, Reports: reports}
+	if err := outputTemplate.Execute(&b, data); err != nil {
+		return err
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// templateExported keeps the exported components of a report or list.
+func templateExported(v any) ([]componentInfo, error) {
+	components, err := templateComponents(v)
+	if err != nil {
+		return nil, err
+	}
+	var kept []componentInfo
+	for _, c := range components {
+		if c.Exported {
+			kept = append(kept, c)
+		}
+	}
+	return kept, nil
+}
+
+// templateOfType keeps the components of one type, e.g. "activity".
+func templateOfType(kind string, v any) ([]componentInfo, error) {
+	components, err := templateComponents(v)
+	if err != nil {
+		return nil, err
+	}
+	var kept []componentInfo
+	for _, c := range components {
+		if c.Type == kind {
+			kept = append(kept, c)
+		}
+	}
+	return kept, nil
+}
+
+// templateURIs lists the deeplink URIs of a report, a component list or a
+// single component once each, in order.
+func templateURIs(v any) ([]string, error) {
+	components, err := templateComponents(v)
+	if err != nil {
+		return nil, err
+	}
+	var uris []string
+	seen := make(map[string]bool)
+	for _, c := range components {
+		for _, uri := range c.URIs {
+			if !seen[uri] {
+				seen[uri] = true
+				uris = append(uris, uri)
+			}
+		}
+	}
+	return uris, nil
+}
+
+// templateComponents accepts what the component helpers take: a report, its
+// component list or one component.
+func templateComponents(v any) ([]componentInfo, error) {
+	switch v := v.(type) {
+	case *report:
+		return v.Components, nil
+	case []componentInfo:
+		return v, nil
+	case componentInfo:
+		return []componentInfo{v}, nil
+	}
+	return nil, fmt.Errorf("expected a report, components or a component, got %T", v)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json" // Test case serialization
+	"os"            // Output file
+	"slices"        // Duplicate URIs
+	"strings"       // Shell quoting
+
+	"Deeeeper/Deeeeper/deeeeper" // Declared URI spelling
+)
+
+// testCase is one deeplink expressed as an intent-resolution test for a device farm or harness.
+type testCase struct {
+	URI       string `json:"uri"`                 // Deeplink to fire, percent-encoded
+	Raw       string `json:"raw,omitempty"`       // Unencoded manifest values when they differ from URI
+	Action    string `json:"action"`              // Intent action the filter expects, empty for provider cases
+	Package   string `json:"package"`             // Package expected to handle the intent
+	Component string `json:"component"`           // Fully qualified component expected to handle it
+	MimeType  string `json:"mime_type,omitempty"` // Type the filter requires; empty URI for mime-type-only filters
+	Invalid   string `json:"invalid,omitempty"`   // Why the URI does not parse cleanly; such cases get no adb command
+	ADB       string `json:"adb,omitempty"`       // adb command that fires the intent
+	Result    string `json:"result,omitempty"`    // Outcome on the device under -launch
+	am        string // am subcommand delivering the intent, empty for provider cases
+	source    Data   // <data> element the URI was built from, zero for res/xml deeplinks
+}
+
+// collectTestCases builds one test case per (action, URI, mime type) combination
+// of every exported component's intent filters, plus VIEW cases for res/xml
+// deeplinks and content query/read cases for providers. Mime-type-only filters
+// get cases without a URI.
+func collectTestCases(manifest Manifest, folder string) []testCase {
+	var cases []testCase
+	for _, group := range componentGroups(manifest) {
+		for _, component := range group.Components {
+			if exported, _ := isExported(component, group.Kind); !exported {
+				continue
+			}
+			name := qualifiedName(manifest.Package, component.Name)
+			for _, filter := range component.Filters {
+				var uris []string
+				sources := make(map[string]Data)
+				for _, data := range filter.Data {
+					if uri := constructURI(data); uri != "" && !slices.Contains(uris, uri) { // Spellings differing in case are one URI
+						uris = append(uris, uri)
+						sources[uri] = data
+					}
+				}
+				mimeTypes := filterMimeTypes(filter)
+				if len(mimeTypes) == 0 {
+					mimeTypes = []string{""}
+				}
+				if isContentHandler(filter) {
+					uris = []string{""}
+				}
+				for _, uri := range uris {
+					for _, mimeType := range mimeTypes {
+						for _, action := range filter.Actions {
+							c := newTestCase(group.Kind, uri, action.Name, mimeType, manifest.Package, name)
+							c.source = sources[uri]
+							if raw := deeeeper.RawURI(c.source); raw != uri {
+								c.Raw = raw
+							}
+							cases = append(cases, c)
+						}
+					}
+				}
+			}
+			for _, link := range metaDataDeeplinks(folder, component) {
+				cases = append(cases, newTestCase(group.Kind, link.URI, "android.intent.action.VIEW", "", manifest.Package, name))
+			}
+		}
+	}
+	return append(cases, providerTestCases(manifest)...)
+}
+
+// amCommands maps component kinds to the am subcommand delivering an intent to them.
+var amCommands = map[string]string{
+	"activity": "start -W",
+	"alias":    "start -W",
+	"service":  "startservice",
+	"receiver": "broadcast",
+}
+
+// newTestCase fills in a test case with the adb command for its component kind.
+// Invalid URIs are marked instead of getting a command.
+func newTestCase(kind, uri, action, mimeType, pkg, component string) testCase {
+	c := testCase{URI: uri, Action: action, Package: pkg, Component: component, MimeType: mimeType, am: amCommands[kind]}
+	if uri != "" {
+		c.Invalid = uriProblem(uri)
+	}
+	if c.Invalid == "" {
+		c.ADB = "adb " + strings.Join(intentArgs(c), " ")
+	}
+	return c
+}
+
+// intentArgs builds the "shell am" arguments of adb that fire a test case's
+// intent. The type is passed with -t because a filter declaring a mime type
+// only matches intents carrying one.
+func intentArgs(c testCase) []string {
+	args := append([]string{"shell", "am"}, strings.Fields(c.am)...)
+	args = append(args, "-a", c.Action)
+	if c.URI != "" {
+		args = append(args, "-d", shellQuote(c.URI))
+	}
+	if c.MimeType != "" {
+		args = append(args, "-t", shellQuote(c.MimeType))
+	}
+	return append(args, "-n", shellQuote(c.Package+"/"+c.Component))
+}
+
+// shellQuote single-quotes a value for the device shell when it needs it.
+func shellQuote(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n'\"&;|<>()$`\\*?[]#~!{}") {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// writeTestCases writes the test cases of every report as one JSON array.
+func writeTestCases(path string, reports []*report) error {
+	cases := []testCase{}
+	for _, r := range reports {
+		cases = append(cases, r.TestCases...)
+	}
+	data, err := json.MarshalIndent(cases, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
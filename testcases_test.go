@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings" // Command checks
+	"testing" // Test harness
+)
+
+// encodingManifest declares paths with a space, Unicode segments and a
+// nav-graph placeholder.
+const encodingManifest = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="com.example.app">
+    <application>
+        <activity android:name=".Docs" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="https" android:host="example.com" android:path="/my docs"/>
+                <data android:scheme="https" android:host="example.com" android:pathPrefix="/café"/>
+                <data android:scheme="myapp" android:host="item" android:path="/{id}"/>
+                <data android:scheme="https" android:host="example.com" android:path="/plain"/>
+            </intent-filter>
+        </activity>
+    </application>
+</manifest>
+`
+
+func TestTestCasesEncoding(t *testing.T) {
+	for _, tc := range []struct {
+		encode bool
+		want   map[string]string // URI to Raw, empty when they're the same
+	}{
+		{false, map[string]string{
+			"https://example.com/my%20docs": "https://example.com/my docs",
+			"https://example.com/caf%C3%A9": "https://example.com/café",
+			"myapp://item/{id}":             "",
+			"https://example.com/plain":     "",
+		}},
+		{true, map[string]string{
+			"https://example.com/my%20docs": "https://example.com/my docs",
+			"https://example.com/caf%C3%A9": "https://example.com/café",
+			"myapp://item/%7Bid%7D":         "myapp://item/{id}",
+			"https://example.com/plain":     "",
+		}},
+	} {
+		o := textOptions()
+		o.EncodePlaceholders = tc.encode
+		setOpts(t, o)
+		cases := collectTestCases(parseTestManifest(t, encodingManifest), t.TempDir())
+		if len(cases) != len(tc.want) {
+			t.Errorf("encode=%v: %d cases, want %d", tc.encode, len(cases), len(tc.want))
+		}
+		for _, c := range cases {
+			raw, ok := tc.want[c.URI]
+			if !ok {
+				t.Errorf("encode=%v: unexpected URI %q", tc.encode, c.URI)
+				continue
+			}
+			if c.Raw != raw {
+				t.Errorf("encode=%v: %s raw = %q, want %q", tc.encode, c.URI, c.Raw, raw)
+			}
+			if c.Invalid != "" {
+				t.Errorf("encode=%v: %s marked invalid: %s", tc.encode, c.URI, c.Invalid)
+			}
+			if !strings.Contains(c.ADB, "-d "+shellQuote(c.URI)+" ") {
+				t.Errorf("encode=%v: adb command %q doesn't carry the encoded URI", tc.encode, c.ADB)
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"        // Manifest reader
+	"encoding/xml" // Token-level decoding
+	"fmt"          // Summary output
+	"io"           // Output destination
+	"reflect"      // Deriving the modeled schema from the manifest types
+	"strings"      // Struct tag parsing
+
+	"Deeeeper/Deeeeper/deeeeper" // Attribute keys as written
+	"github.com/fatih/color"     // Colorized output in terminal
+)
+
+// xmlSchema is the set of attributes and child elements one Go type models.
+type xmlSchema struct {
+	attrs    map[string]bool       // Modeled attribute local names
+	children map[string]*xmlSchema // Modeled child elements
+}
+
+// modeledSchema is derived from the Manifest type, so it never drifts from
+// what the parser actually reads.
+var modeledSchema = schemaOf(reflect.TypeOf(Manifest{}))
+
+// schemaOf collects the xml struct tags of a type into an xmlSchema.
+func schemaOf(t reflect.Type) *xmlSchema {
+	for t.Kind() == reflect.Slice || t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	schema := &xmlSchema{attrs: make(map[string]bool), children: make(map[string]*xmlSchema)}
+	if t.Kind() != reflect.Struct {
+		return schema
+	}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("xml")
+		if tag == "" || tag == "-" || field.Name == "XMLName" {
+			continue
+		}
+		name, flags, _ := strings.Cut(tag, ",")
+		if flags == "attr" {
+			schema.attrs[name] = true
+		} else if flags == "" {
+			schema.children[name] = schemaOf(field.Type)
+		}
+	}
+	return schema
+}
+
+// unknownUsage counts the unmodeled elements and attributes of one manifest.
+type unknownUsage struct {
+	elements   map[string]int // Element paths such as manifest/uses-permission
+	attributes map[string]int // element@attribute such as application@android:label
+}
+
+// findUnknown walks the manifest token by token and records every element and
+// attribute the Manifest types do not model. Unmodeled subtrees are counted at
+// their root and skipped.
+func findUnknown(manifest []byte) (unknownUsage, error) {
+	usage := unknownUsage{elements: make(map[string]int), attributes: make(map[string]int)}
+	decoder := newXMLDecoder(bytes.NewReader(manifest))
+	var path []string
+	var schemas []*xmlSchema
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return usage, nil
+		}
+		if err != nil {
+			return usage, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			var schema *xmlSchema
+			if len(schemas) == 0 && t.Name.Local == "manifest" {
+				schema = modeledSchema
+			} else if len(schemas) > 0 {
+				schema = schemas[len(schemas)-1].children[t.Name.Local]
+			}
+			elementPath := strings.Join(append(path, t.Name.Local), "/")
+			if schema == nil {
+				usage.elements[elementPath]++
+				if err := decoder.Skip(); err != nil {
+					return usage, err
+				}
+				continue
+			}
+			for key := range deeeeper.RawAttributes(t.Attr) {
+				_, local, found := strings.Cut(key, ":")
+				if !found {
+					local = key
+				}
+				if !schema.attrs[local] {
+					usage.attributes[t.Name.Local+"@"+key]++
+				}
+			}
+			path = append(path, t.Name.Local)
+			schemas = append(schemas, schema)
+		case xml.EndElement:
+			path = path[:len(path)-1]
+			schemas = schemas[:len(schemas)-1]
+		}
+	}
+}
+
+// printUnknown writes the -report-unknown summary for one manifest.
+func printUnknown(w io.Writer, usage unknownUsage) {
+	section := color.New(color.FgYellow)
+	section.Fprintln(w, "\nUnmodeled manifest elements:")
+	if len(usage.elements) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, path := range sortedKeys(usage.elements) {
+		fmt.Fprintf(w, "  %s (%d)\n", path, usage.elements[path])
+	}
+	section.Fprintln(w, "\nUnmodeled manifest attributes:")
+	if len(usage.attributes) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, attr := range sortedKeys(usage.attributes) {
+		fmt.Fprintf(w, "  %s (%d)\n", attr, usage.attributes[attr])
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"  // Unwrapping parse errors
+	"fmt"     // Problem descriptions
+	"net/url" // Parsing constructed URIs
+	"regexp"  // Scheme syntax
+	"strings" // Scheme extraction
+
+	"Deeeeper/Deeeeper/deeeeper" // Scheme normalization, placeholder unescaping
+)
+
+// schemeSyntax is the RFC 3986 scheme grammar.
+var schemeSyntax = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*$`)
+
+// hostRequired lists schemes whose URIs are meaningless without a host.
+var hostRequired = map[string]bool{"http": true, "https": true}
+
+// uriProblem explains why a constructed URI is invalid, or returns "" when it
+// parses and renders back unchanged. Problems point at manifest bugs in the
+// app or at construction bugs in Deeeeper; either way the URI can't be fired.
+func uriProblem(uri string) string {
+	scheme, _, found := strings.Cut(uri, ":")
+	if !found || scheme == "" || strings.HasPrefix(uri, "//") {
+		return "missing scheme"
+	}
+	if !schemeSyntax.MatchString(scheme) {
+		return fmt.Sprintf("invalid scheme %q", scheme)
+	}
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return err.Error()
+	}
+	if hostRequired[deeeeper.NormalizeScheme(scheme)] && parsed.Host == "" {
+		return scheme + " URI without a host"
+	}
+	expected := uri
+	if parsed.Host == "" && parsed.Path == "" { // url.URL drops an empty authority: myapp:// renders as myapp:
+		expected = strings.TrimSuffix(uri, "//")
+	}
+	if rendered := parsed.String(); deeeeper.UnescapePlaceholders(rendered) != deeeeper.UnescapePlaceholders(expected) {
+		return fmt.Sprintf("does not round-trip (parses as %s)", rendered)
+	}
+	return ""
+}
+
+// countInvalid returns how many distinct test case URIs are invalid.
+func countInvalid(cases []testCase) int {
+	seen := make(map[string]bool)
+	for _, c := range cases {
+		if c.Invalid != "" {
+			seen[c.URI] = true
+		}
+	}
+	return len(seen)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"           // Annotations
+	"io"            // Output destination
+	"os"            // Reading apktool.yml
+	"path/filepath" // apktool.yml location
+	"regexp"        // minSdkVersion in apktool.yml
+	"strconv"       // SDK levels
+
+	"github.com/fatih/color" // Colorized output in terminal
+)
+
+// dangerousPermissions are the platform runtime permissions, those with
+// protectionLevel dangerous that the user grants at runtime.
+var dangerousPermissions = map[string]bool{
+	"android.permission.ACCEPT_HANDOVER":                 true,
+	"android.permission.ACCESS_BACKGROUND_LOCATION":      true,
+	"android.permission.ACCESS_COARSE_LOCATION":          true,
+	"android.permission.ACCESS_FINE_LOCATION":            true,
+	"android.permission.ACCESS_MEDIA_LOCATION":           true,
+	"android.permission.ACTIVITY_RECOGNITION":            true,
+	"android.permission.ANSWER_PHONE_CALLS":              true,
+	"android.permission.BLUETOOTH_ADVERTISE":             true,
+	"android.permission.BLUETOOTH_CONNECT":               true,
+	"android.permission.BLUETOOTH_SCAN":                  true,
+	"android.permission.BODY_SENSORS":                    true,
+	"android.permission.BODY_SENSORS_BACKGROUND":         true,
+	"android.permission.CALL_PHONE":                      true,
+	"android.permission.CAMERA":                          true,
+	"android.permission.GET_ACCOUNTS":                    true,
+	"android.permission.NEARBY_WIFI_DEVICES":             true,
+	"android.permission.POST_NOTIFICATIONS":              true,
+	"android.permission.PROCESS_OUTGOING_CALLS":          true,
+	"android.permission.READ_CALENDAR":                   true,
+	"android.permission.READ_CALL_LOG":                   true,
+	"android.permission.READ_CONTACTS":                   true,
+	"android.permission.READ_EXTERNAL_STORAGE":           true,
+	"android.permission.READ_MEDIA_AUDIO":                true,
+	"android.permission.READ_MEDIA_IMAGES":               true,
+	"android.permission.READ_MEDIA_VIDEO":                true,
+	"android.permission.READ_MEDIA_VISUAL_USER_SELECTED": true,
+	"android.permission.READ_PHONE_NUMBERS":              true,
+	"android.permission.READ_PHONE_STATE":                true,
+	"android.permission.READ_SMS":                        true,
+	"android.permission.RECEIVE_MMS":                     true,
+	"android.permission.RECEIVE_SMS":                     true,
+	"android.permission.RECEIVE_WAP_PUSH":                true,
+	"android.permission.RECORD_AUDIO":                    true,
+	"android.permission.SEND_SMS":                        true,
+	"android.permission.USE_SIP":                         true,
+	"android.permission.UWB_RANGING":                     true,
+	"android.permission.WRITE_CALENDAR":                  true,
+	"android.permission.WRITE_CALL_LOG":                  true,
+	"android.permission.WRITE_CONTACTS":                  true,
+	"android.permission.WRITE_EXTERNAL_STORAGE":          true,
+	"com.android.voicemail.permission.ADD_VOICEMAIL":     true,
+}
+
+// apktoolMinSDK matches the minSdkVersion apktool moves from <uses-sdk> into apktool.yml.
+var apktoolMinSDK = regexp.MustCompile(`(?m)^\s*minSdkVersion:\s*'?(\d+)'?`)
+
+// permissionRequest is one <uses-permission> with what it means on the
+// devices the app installs on.
+type permissionRequest struct {
+	Name          string `json:"name"`                      // Permission name
+	MaxSDKVersion int    `json:"max_sdk_version,omitempty"` // android:maxSdkVersion, 0 when absent
+	Dangerous     bool   `json:"dangerous"`                 // Platform runtime permission
+	Inert         bool   `json:"inert"`                     // maxSdkVersion is below minSdkVersion, so it is never requested
+}
+
+// featureInfo is one named <uses-feature>.
+type featureInfo struct {
+	Name     string `json:"name"`     // Feature name
+	Required bool   `json:"required"` // False for android:required="false": installs without the feature
+}
+
+// minSDKVersion returns the app's minSdkVersion from the manifest or, since
+// apktool moves <uses-sdk> out of it, from apktool.yml. It is 0 when unknown.
+func minSDKVersion(manifest Manifest, folder string) int {
+	if level, err := strconv.Atoi(manifest.UsesSDK.MinSDKVersion); err == nil {
+		return level
+	}
+	data, err := os.ReadFile(filepath.Join(folder, "apktool.yml"))
+	if err != nil {
+		return 0
+	}
+	if match := apktoolMinSDK.FindSubmatch(data); match != nil {
+		level, _ := strconv.Atoi(string(match[1]))
+		return level
+	}
+	return 0
+}
+
+// collectPermissionRequests lists the requested permissions once each, in
+// manifest order. A request whose android:maxSdkVersion is below minSdk is
+// inert: no device the app installs on ever sees it.
+func collectPermissionRequests(manifest Manifest, minSDK int) []permissionRequest {
+	var requests []permissionRequest
+	seen := make(map[string]bool)
+	for _, u := range manifest.UsesPermissions {
+		if seen[u.Name] {
+			continue
+		}
+		seen[u.Name] = true
+		request := permissionRequest{Name: u.Name, Dangerous: dangerousPermissions[u.Name]}
+		request.MaxSDKVersion, _ = strconv.Atoi(u.MaxSDKVersion)
+		request.Inert = request.MaxSDKVersion > 0 && minSDK > 0 && request.MaxSDKVersion < minSDK
+		requests = append(requests, request)
+	}
+	return requests
+}
+
+// customPermission is one custom <permission> the app declares.
+type customPermission struct {
+	Name            string `json:"name"`             // Permission name
+	ProtectionLevel string `json:"protection_level"` // Base level, see baseProtection
+	Raw             string `json:"raw,omitempty"`    // protectionLevel as declared, flags included
+}
+
+// applicationFlags are the <application> attributes that change what a build
+// exposes, as declared: empty when absent, so the platform default applies.
+type applicationFlags struct {
+	Debuggable  string `json:"debuggable,omitempty"`             // android:debuggable
+	AllowBackup string `json:"allow_backup,omitempty"`           // android:allowBackup, true when absent
+	Cleartext   string `json:"uses_cleartext_traffic,omitempty"` // android:usesCleartextTraffic
+}
+
+// collectPermissionDeclarations lists the custom permissions the app declares
+// once each, in manifest order, so two builds can be compared by name.
+func collectPermissionDeclarations(manifest Manifest) []customPermission {
+	var declared []customPermission
+	seen := make(map[string]bool)
+	for _, p := range manifest.Permissions {
+		if p.Name == "" || seen[p.Name] {
+			continue
+		}
+		seen[p.Name] = true
+		declared = append(declared, customPermission{Name: p.Name, ProtectionLevel: baseProtection(p.ProtectionLevel), Raw: p.ProtectionLevel})
+	}
+	return declared
+}
+
+// collectApplicationFlags reads the flags off <application>.
+func collectApplicationFlags(manifest Manifest) applicationFlags {
+	app := manifest.Application
+	return applicationFlags{Debuggable: app.Debuggable, AllowBackup: app.Backup, Cleartext: app.Cleartext}
+}
+
+// collectFeatures lists the named <uses-feature> elements with whether the
+// app needs them to install.
+func collectFeatures(manifest Manifest) []featureInfo {
+	var features []featureInfo
+	for _, feature := range manifest.UsesFeatures {
+		if feature.Name != "" {
+			features = append(features, featureInfo{Name: feature.Name, Required: feature.Required != "false"})
+		}
+	}
+	return features
+}
+
+// printPermissionRequests lists the requested permissions, dangerous ones in
+// red and inert ones struck through, followed by the dangerous count without
+// the inert ones and the features the app uses.
+func printPermissionRequests(w io.Writer, requests []permissionRequest, features []featureInfo, minSDK int) {
+	printSection(w, "\nRequested permissions:")
+	red := color.New(color.FgRed).SprintFunc()
+	inert := color.New(color.Faint, color.CrossedOut).SprintFunc()
+	dangerous, inertCount := 0, 0
+	for _, request := range requests {
+		switch {
+		case request.Inert:
+			inertCount++
+			fmt.Fprintf(w, "  %s (inert: maxSdkVersion %d < minSdkVersion %d)\n", inert(request.Name), request.MaxSDKVersion, minSDK)
+		case request.Dangerous:
+			dangerous++
+			fmt.Fprintf(w, "  %s (dangerous%s)\n", red(request.Name), maxSDKNote(request))
+		default:
+			fmt.Fprintf(w, "  %s%s\n", request.Name, maxSDKNote(request))
+		}
+	}
+	fmt.Fprintf(w, "  %d dangerous permission(s) requested", dangerous)
+	if inertCount > 0 {
+		fmt.Fprintf(w, "; %d inert request(s) not counted", inertCount)
+	}
+	fmt.Fprintln(w)
+	if len(features) == 0 {
+		return
+	}
+	printSection(w, "\nFeatures:")
+	for _, feature := range features {
+		if feature.Required {
+			fmt.Fprintf(w, "  %s (required)\n", feature.Name)
+		} else {
+			fmt.Fprintf(w, "  %s (not required: installs on devices without it)\n", feature.Name)
+		}
+	}
+}
+
+// maxSDKNote describes a live maxSdkVersion limit, or returns "" without one.
+func maxSDKNote(request permissionRequest) string {
+	if request.MaxSDKVersion == 0 {
+		return ""
+	}
+	if request.Dangerous {
+		return fmt.Sprintf(", up to SDK %d", request.MaxSDKVersion)
+	}
+	return fmt.Sprintf(" (up to SDK %d)", request.MaxSDKVersion)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json" // Reading the JSON report back
+	"reflect"       // Result comparison
+	"strings"       // Output checks
+	"testing"       // Test harness
+)
+
+// releaseManifest and debugManifest are two builds of org.example.shop. The
+// debug build lowers a custom permission to normal, adds another, declares one
+// twice and turns on debugging and cleartext traffic; the release build sets
+// no flag but allowBackup.
+const (
+	releaseManifest = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="org.example.shop">
+    <permission android:name="org.example.shop.ORDERS" android:protectionLevel="signature|privileged"/>
+    <permission android:name="org.example.shop.SYNC"/>
+    <uses-permission android:name="android.permission.INTERNET"/>
+    <application android:allowBackup="false"/>
+</manifest>
+`
+	debugManifest = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="org.example.shop">
+    <permission android:name="org.example.shop.ORDERS" android:protectionLevel="normal"/>
+    <permission android:name="org.example.shop.SYNC"/>
+    <permission android:name="org.example.shop.DEBUG" android:protectionLevel="0x12"/>
+    <permission android:name="org.example.shop.ORDERS" android:protectionLevel="dangerous"/>
+    <uses-permission android:name="android.permission.INTERNET"/>
+    <uses-permission android:name="android.permission.CAMERA"/>
+    <application android:debuggable="true" android:usesCleartextTraffic="true"/>
+</manifest>
+`
+)
+
+func TestCollectPermissionDeclarations(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		manifest string
+		want     []customPermission
+	}{
+		{
+			name:     "release",
+			manifest: releaseManifest,
+			want: []customPermission{
+				{Name: "org.example.shop.ORDERS", ProtectionLevel: "signature", Raw: "signature|privileged"},
+				{Name: "org.example.shop.SYNC", ProtectionLevel: "normal"},
+			},
+		},
+		{
+			name:     "debug",
+			manifest: debugManifest,
+			want: []customPermission{
+				{Name: "org.example.shop.ORDERS", ProtectionLevel: "normal", Raw: "normal"},
+				{Name: "org.example.shop.SYNC", ProtectionLevel: "normal"},
+				{Name: "org.example.shop.DEBUG", ProtectionLevel: "signature", Raw: "0x12"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := collectPermissionDeclarations(parseTestManifest(t, tc.manifest))
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("declared = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCollectApplicationFlags(t *testing.T) {
+	if got, want := collectApplicationFlags(parseTestManifest(t, releaseManifest)), (applicationFlags{AllowBackup: "false"}); got != want {
+		t.Errorf("release flags = %+v, want %+v", got, want)
+	}
+	if got, want := collectApplicationFlags(parseTestManifest(t, debugManifest)), (applicationFlags{Debuggable: "true", Cleartext: "true"}); got != want {
+		t.Errorf("debug flags = %+v, want %+v", got, want)
+	}
+}
+
+// TestPermissionsJSON checks both builds carry their declarations and flags in
+// the JSON report, which is what comparing them relies on.
+func TestPermissionsJSON(t *testing.T) {
+	setOpts(t, textOptions())
+	for _, tc := range []struct {
+		name     string
+		manifest string
+		declared int
+		flags    map[string]string
+	}{
+		{"release", releaseManifest, 2, map[string]string{"allow_backup": "false"}},
+		{"debug", debugManifest, 3, map[string]string{"debuggable": "true", "uses_cleartext_traffic": "true"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var document struct {
+				Reports []struct {
+					Declared []customPermission `json:"declared_permissions"`
+					Flags    map[string]string  `json:"application_flags"`
+				} `json:"reports"`
+			}
+			rendered := reportJSON(t, analyzeFixture(t, tc.manifest, "<resources/>"))
+			if err := json.Unmarshal([]byte(rendered), &document); err != nil {
+				t.Fatalf("%v:\n%s", err, rendered)
+			}
+			if len(document.Reports) != 1 {
+				t.Fatalf("reports = %d, want 1:\n%s", len(document.Reports), rendered)
+			}
+			r := document.Reports[0]
+			if len(r.Declared) != tc.declared || !reflect.DeepEqual(r.Flags, tc.flags) {
+				t.Errorf("declared_permissions = %+v, application_flags = %v; want %d declarations and %v", r.Declared, r.Flags, tc.declared, tc.flags)
+			}
+		})
+	}
+}
+
+// maxSDKManifest requests storage and camera with maxSdkVersion below, equal
+// to and above its minSdkVersion 23.
+const maxSDKManifest = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="org.example.gallery">
+    <uses-sdk android:minSdkVersion="23" android:targetSdkVersion="34"/>
+    <uses-permission android:name="android.permission.READ_EXTERNAL_STORAGE" android:maxSdkVersion="22"/>
+    <uses-permission android:name="android.permission.WRITE_EXTERNAL_STORAGE" android:maxSdkVersion="23"/>
+    <uses-permission android:name="android.permission.CAMERA" android:maxSdkVersion="28"/>
+    <uses-permission android:name="android.permission.INTERNET"/>
+    <uses-permission android:name="android.permission.READ_EXTERNAL_STORAGE" android:maxSdkVersion="22"/>
+    <application/>
+</manifest>
+`
+
+// TestCollectPermissionRequests checks only a maxSdkVersion below minSdk makes
+// a request inert, and that nothing is inert while minSdk is unknown.
+func TestCollectPermissionRequests(t *testing.T) {
+	manifest := parseTestManifest(t, maxSDKManifest)
+	for _, tc := range []struct {
+		name   string
+		minSDK int
+		want   []permissionRequest
+	}{
+		{
+			name:   "minSdk 23",
+			minSDK: 23,
+			want: []permissionRequest{
+				{Name: "android.permission.READ_EXTERNAL_STORAGE", MaxSDKVersion: 22, Dangerous: true, Inert: true},
+				{Name: "android.permission.WRITE_EXTERNAL_STORAGE", MaxSDKVersion: 23, Dangerous: true},
+				{Name: "android.permission.CAMERA", MaxSDKVersion: 28, Dangerous: true},
+				{Name: "android.permission.INTERNET"},
+			},
+		},
+		{
+			name: "minSdk unknown",
+			want: []permissionRequest{
+				{Name: "android.permission.READ_EXTERNAL_STORAGE", MaxSDKVersion: 22, Dangerous: true},
+				{Name: "android.permission.WRITE_EXTERNAL_STORAGE", MaxSDKVersion: 23, Dangerous: true},
+				{Name: "android.permission.CAMERA", MaxSDKVersion: 28, Dangerous: true},
+				{Name: "android.permission.INTERNET"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := collectPermissionRequests(manifest, tc.minSDK); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("requests = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestInertPermissionsNotCounted checks the report reads minSdk from the
+// manifest, marks the inert request and leaves it out of the dangerous count.
+func TestInertPermissionsNotCounted(t *testing.T) {
+	setOpts(t, textOptions())
+	r, text := renderFixture(t, maxSDKManifest, "<resources/>")
+	if r.MinSDK != 23 {
+		t.Errorf("minSdk = %d, want 23", r.MinSDK)
+	}
+	for _, want := range []string{
+		"  android.permission.READ_EXTERNAL_STORAGE (inert: maxSdkVersion 22 < minSdkVersion 23)\n",
+		"  android.permission.WRITE_EXTERNAL_STORAGE (dangerous",
+		"  android.permission.CAMERA (dangerous",
+		"  2 dangerous permission(s) requested; 1 inert request(s) not counted\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report lacks %q:\n%s", want, text)
+		}
+	}
+}
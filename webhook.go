@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"   // Classifying delivery failures
+	"net/http" // Webhook delivery
+	"time"     // Retry backoff
+)
+
+// Delivery policy for -webhook. Each report is tried a few times with
+// exponential backoff before the scan moves on without it.
+const (
+	webhookAttempts = 4
+	webhookBackoff  = time.Second
+)
+
+// webhookClient is shared by all workers streaming results.
+var webhookClient = &http.Client{Timeout: notifyTimeout}
+
+// streamReport posts one finished report to -webhook. Network errors, 429 and
+// 5xx answers are retried; other answers are final. It never stops the scan:
+// the caller only prints the returned error.
+func streamReport(r *report) error {
+	if opts.Webhook == "" {
+		return nil
+	}
+	return withRetry(func() error { return postJSON(webhookClient, opts.Webhook, nil, r) })
+}
+
+// withRetry runs a delivery up to webhookAttempts times with exponential
+// backoff, stopping early on success or a failure retrying can't fix.
+func withRetry(deliver func() error) error {
+	backoff := webhookBackoff
+	var err error
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		if err = deliver(); err == nil || !retryable(err) {
+			return err
+		}
+		if attempt < webhookAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	return err
+}
+
+// retryable reports whether a failed delivery may succeed when repeated.
+func retryable(err error) bool {
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code == http.StatusTooManyRequests || statusErr.code >= 500
+	}
+	return true // Transport errors: refused connections, timeouts, resets
+}
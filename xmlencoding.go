@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"         // Peeking at byte order marks
+	"bytes"         // Encoding sniffing
+	"encoding/xml"  // Decoder setup
+	"fmt"           // Unsupported charset errors
+	"io"            // Reader plumbing
+	"strings"       // Charset label matching
+	"unicode/utf16" // UTF-16 transcoding
+	"unicode/utf8"  // Rune encoding
+)
+
+// Byte order marks recognized at the start of XML files.
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// toUTF8 normalizes an XML document to UTF-8: byte order marks are stripped and
+// UTF-16 input, with or without a BOM, is transcoded. Anything else is
+// returned unchanged; declared single-byte charsets are handled by
+// xmlCharsetReader while parsing.
+func toUTF8(data []byte) []byte {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return data[len(bomUTF8):]
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return decodeUTF16(data[len(bomUTF16LE):], false)
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return decodeUTF16(data[len(bomUTF16BE):], true)
+	case bytes.HasPrefix(data, []byte("<\x00?\x00")): // BOM-less UTF-16LE "<?"
+		return decodeUTF16(data, false)
+	case bytes.HasPrefix(data, []byte("\x00<\x00?")): // BOM-less UTF-16BE "<?"
+		return decodeUTF16(data, true)
+	}
+	return data
+}
+
+// decodeUTF16 transcodes UTF-16 bytes to UTF-8. A trailing odd byte is dropped.
+func decodeUTF16(data []byte, bigEndian bool) []byte {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		} else {
+			units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+		}
+	}
+	out := make([]byte, 0, len(units))
+	for _, r := range utf16.Decode(units) {
+		out = utf8.AppendRune(out, r)
+	}
+	return out
+}
+
+// utf8Reader wraps r so the stream it yields is UTF-8 without a BOM. UTF-8
+// streams stay streamed; UTF-16 ones are read whole and transcoded.
+func utf8Reader(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	head, _ := buffered.Peek(4)
+	switch {
+	case bytes.HasPrefix(head, bomUTF8):
+		buffered.Discard(len(bomUTF8))
+		return buffered, nil
+	case bytes.HasPrefix(head, bomUTF16LE), bytes.HasPrefix(head, bomUTF16BE),
+		bytes.HasPrefix(head, []byte("<\x00?\x00")), bytes.HasPrefix(head, []byte("\x00<\x00?")):
+		data, err := io.ReadAll(buffered)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewReader(toUTF8(data)), nil
+	}
+	return buffered, nil
+}
+
+// newXMLDecoder returns a decoder over UTF-8 input that accepts the encoding
+// declarations found in the wild. See xmlCharsetReader.
+func newXMLDecoder(r io.Reader) *xml.Decoder {
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = xmlCharsetReader
+	return decoder
+}
+
+// xmlCharsetReader converts the charsets an XML declaration may name. UTF-16
+// declarations are accepted as-is because toUTF8 and utf8Reader have already
+// transcoded the bytes by the time the declaration is read; Latin-1 style
+// single-byte charsets are mapped to UTF-8 byte by byte.
+func xmlCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-16", "utf-16le", "utf-16be", "utf16", "unicode":
+		return input, nil
+	case "iso-8859-1", "iso8859-1", "latin1", "latin-1", "l1", "us-ascii", "ascii", "windows-1252", "cp1252":
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]byte, 0, len(data))
+		for _, b := range data {
+			out = utf8.AppendRune(out, rune(b))
+		}
+		return bytes.NewReader(out), nil
+	}
+	return nil, fmt.Errorf("unsupported XML encoding %q", charset)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"         // Captured warnings
+	"os"            // Fixture files
+	"path/filepath" // Fixture paths
+	"strings"       // Output checks
+	"testing"       // Test harness
+)
+
+// encodingFixture reads a file of testdata/encoding.
+func encodingFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "encoding", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestToUTF8(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{name: "plain", input: []byte("<a/>"), want: "<a/>"},
+		{name: "utf-8 bom", input: []byte("\xEF\xBB\xBF<a/>"), want: "<a/>"},
+		{name: "utf-16le bom", input: []byte("\xFF\xFE<\x00a\x00/\x00>\x00"), want: "<a/>"},
+		{name: "utf-16be bom", input: []byte("\xFE\xFF\x00<\x00a\x00/\x00>"), want: "<a/>"},
+		{name: "utf-16le without bom", input: []byte("<\x00?\x00x\x00?\x00>\x00"), want: "<?x?>"},
+		{name: "utf-16be without bom", input: []byte("\x00<\x00?\x00x\x00?\x00>"), want: "<?x?>"},
+		{name: "surrogate pair", input: []byte("\xFF\xFE\x3D\xD8\x00\xDE"), want: "😀"},
+		{name: "odd trailing byte", input: []byte("\xFF\xFEa\x00b"), want: "a"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := string(toUTF8(tc.input)); got != tc.want {
+				t.Errorf("toUTF8(%q) = %q, want %q", tc.input, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestLoadStringsEncodings checks strings files in UTF-16 with and without a
+// BOM, UTF-8 with a BOM and a declared Latin-1 charset all decode.
+func TestLoadStringsEncodings(t *testing.T) {
+	for file, appName := range map[string]string{
+		"strings-utf16le-bom.xml": "Café ✓",
+		"strings-utf16be-bom.xml": "Café ✓",
+		"strings-utf16le.xml":     "Café ✓",
+		"strings-utf8-bom.xml":    "Café ✓",
+		"strings-latin1.xml":      "Café",
+	} {
+		t.Run(file, func(t *testing.T) {
+			loaded, err := loadStrings(filepath.Join("testdata", "encoding", file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if loaded["app_name"] != appName || loaded["link_host"] != "utf16.example.com" {
+				t.Errorf("loaded %q, want app_name %q and link_host utf16.example.com", loaded, appName)
+			}
+		})
+	}
+}
+
+// TestAnalyzeUTF16Target analyzes a target whose manifest and strings.xml are
+// both UTF-16LE with a BOM, as some Windows editors save decompiled output.
+func TestAnalyzeUTF16Target(t *testing.T) {
+	setOpts(t, textOptions())
+	manifest := encodingFixture(t, "AndroidManifest-utf16le-bom.xml")
+	stringsXML := encodingFixture(t, "strings-utf16le-bom.xml")
+	result, text := renderFixture(t, string(manifest), string(stringsXML))
+	if result.Package != "org.example.utf16" {
+		t.Errorf("package = %q, want org.example.utf16", result.Package)
+	}
+	if !strings.Contains(text, "https://utf16.example.com/caf") {
+		t.Errorf("report lacks the deeplink built from UTF-16 strings:\n%s", text)
+	}
+}
+
+// TestMalformedStringsWarning checks a strings.xml that breaks off is reported
+// with its cause while the strings before the damage are still used.
+func TestMalformedStringsWarning(t *testing.T) {
+	setOpts(t, textOptions())
+	dir := t.TempDir()
+	broken := strings.Replace(selftestStrings, `<string name="alias_host">`, `<string name="alias_host"><unclosed>`, 1)
+	if err := writeSelftestTarget(dir, selftestManifest, broken); err != nil {
+		t.Fatal(err)
+	}
+	var progress bytes.Buffer
+	loaded, err := loadStringMap(&progress, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded["deeplink_host"] != "selftest.example.com" {
+		t.Errorf("deeplink_host = %q; strings before the damage should be kept", loaded["deeplink_host"])
+	}
+	warning := progress.String()
+	if !strings.Contains(warning, "Warning: "+filepath.Join(dir, "res", "values", "strings.xml")) || !strings.Contains(warning, "element <unclosed> closed by </string>") {
+		t.Errorf("warning lacks the file or the underlying cause:\n%s", warning)
+	}
+}
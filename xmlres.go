@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/xml"  // Token-level parsing of res/xml files
+	"os"            // Opening resource files
+	"path/filepath" // Locating res/xml files
+	"strings"       // Reference parsing
+)
+
+// xmlDeeplink is a URI discovered in a res/xml file referenced by a component.
+type xmlDeeplink struct {
+	Source string // Resource file relative to the decompiled folder
+	URI    string // Constructed or declared URI
+}
+
+// metaDataDeeplinks scans every res/xml resource referenced from the component's
+// meta-data for <data>, <deepLink> and <intent> elements and returns their URIs.
+// Missing or unparsable files are skipped; they are not part of the manifest contract.
+func metaDataDeeplinks(folder string, component App) []xmlDeeplink {
+	var links []xmlDeeplink
+	seen := make(map[string]bool)
+	for _, meta := range component.MetaData {
+		name, ok := strings.CutPrefix(meta.Resource, "@xml/")
+		if !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		source := filepath.Join("res", "xml", name+".xml")
+		for _, uri := range scanXMLResource(filepath.Join(folder, source)) {
+			links = append(links, xmlDeeplink{Source: filepath.ToSlash(source), URI: uri})
+		}
+	}
+	return links
+}
+
+// scanXMLResource extracts URIs from a single XML resource file.
+func scanXMLResource(path string) []string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	reader, err := utf8Reader(file)
+	if err != nil {
+		return nil
+	}
+	var uris []string
+	decoder := newXMLDecoder(reader)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return uris // io.EOF or a malformed resource; keep what was found
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "data": // Intent-filter style <data android:scheme=... android:host=...>
+			var data Data
+			for _, attr := range start.Attr {
+				switch attr.Name.Local {
+				case "scheme":
+					data.Scheme = attr.Value
+				case "host":
+					data.Host = attr.Value
+				case "port":
+					data.Port = attr.Value
+				case "path":
+					data.Path = attr.Value
+				case "pathPrefix":
+					data.PathPrefix = attr.Value
+				case "pathPattern":
+					data.PathPattern = attr.Value
+				case "ssp":
+					data.Ssp = attr.Value
+				case "sspPrefix":
+					data.SspPrefix = attr.Value
+				case "sspPattern":
+					data.SspPattern = attr.Value
+				}
+			}
+			if uri := constructURI(data); uri != "" {
+				uris = append(uris, uri)
+			}
+		case "deepLink": // Navigation-graph style <deepLink app:uri="...">
+			if uri := xmlAttr(start, "uri"); uri != "" {
+				uris = append(uris, uri)
+			}
+		case "intent": // Shortcut style <intent android:data="...">
+			if uri := xmlAttr(start, "data"); uri != "" {
+				uris = append(uris, uri)
+			}
+		}
+	}
+}
+
+// xmlAttr returns the value of the attribute with the given local name, ignoring its namespace.
+func xmlAttr(start xml.StartElement, local string) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
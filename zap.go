@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/xml" // Context file serialization
+	"fmt"          // URL list output
+	"io"           // Output destination
+	"net/url"      // Parsing deeplinks
+	"os"           // Context file output
+	"regexp"       // Include regex quoting
+	"strings"      // Prefix extraction
+
+	"Deeeeper/Deeeeper/deeeeper" // Scheme normalization, example paths
+)
+
+// proxySchemes are the deeplink schemes an intercepting proxy can use.
+var proxySchemes = map[string]bool{"http": true, "https": true}
+
+// zapContext is the ZAP context file written by -zap-context.
+type zapContext struct {
+	XMLName xml.Name `xml:"configuration"`
+	Context struct {
+		Name       string   `xml:"name"`
+		Desc       string   `xml:"desc"`
+		InScope    bool     `xml:"inscope"`
+		IncRegexes []string `xml:"incregexes"` // One include regex per scheme, host and path prefix
+	} `xml:"context"`
+}
+
+// proxySeeds derives, from every valid http(s) deeplink of the reports, an
+// absolute example URL and an include regex covering its host and path
+// prefix. Both lists are de-duplicated and sorted. A host of "*" matches any
+// host, which no URL or scope can stand for, so such deeplinks are left out.
+func proxySeeds(reports []*report) (urls, includes []string) {
+	urlSet, includeSet := make(map[string]bool), make(map[string]bool)
+	for _, r := range reports {
+		for _, c := range r.TestCases {
+			if isProviderCase(c) || c.Invalid != "" || c.URI == "" {
+				continue
+			}
+			u, err := url.Parse(c.URI)
+			if err != nil || !proxySchemes[deeeeper.NormalizeScheme(u.Scheme)] || u.Host == "" {
+				continue // Custom schemes never reach a proxy
+			}
+			if u.Hostname() == "*" {
+				continue
+			}
+			urlSet[exampleURL(*u, c.source)] = true
+			includeSet[includeRegex(*u, c.source)] = true
+		}
+	}
+	return sortedKeys(urlSet), sortedKeys(includeSet)
+}
+
+// isPatternPath reports whether the URI's path came from a pathPattern.
+func isPatternPath(source Data) bool {
+	return source.Path == "" && source.PathPrefix == "" && source.PathPattern != ""
+}
+
+// exampleURL makes a deeplink concrete: pattern paths become example paths and
+// wildcard hosts get a www subdomain, since "*.example.com" doesn't match the
+// bare parent domain.
+func exampleURL(u url.URL, source Data) string {
+	if parent, ok := strings.CutPrefix(u.Host, "*."); ok {
+		u.Host = "www." + parent
+	}
+	if isPatternPath(source) {
+		u.Path = "/" + strings.TrimPrefix(deeeeper.ExamplePath(source.PathPattern), "/")
+		u.RawPath = ""
+	}
+	return u.String()
+}
+
+// includeRegex returns a ZAP include regex for the deeplink's scheme, host and
+// literal path prefix. Patterns and {placeholders} end the literal prefix.
+func includeRegex(u url.URL, source Data) string {
+	host := regexp.QuoteMeta(u.Host)
+	if parent, ok := strings.CutPrefix(u.Host, "*."); ok {
+		host = `[^/]+\.` + regexp.QuoteMeta(parent)
+	}
+	path := u.EscapedPath()
+	if isPatternPath(source) {
+		path = (&url.URL{Path: strings.ReplaceAll(source.PathPattern, `\\`, `\`)}).EscapedPath()
+		if cut := strings.IndexAny(path, `.*\%`); cut >= 0 {
+			path = path[:cut]
+		}
+	}
+	if cut := strings.Index(path, "%7B"); cut >= 0 {
+		path = path[:cut]
+	}
+	return regexp.QuoteMeta(deeeeper.NormalizeScheme(u.Scheme)) + "://" + host + regexp.QuoteMeta(path) + ".*"
+}
+
+// writeZapURLs writes one absolute http(s) URL per line for -format zap-urls,
+// ready to import into Burp or ZAP as a site map seed.
+func writeZapURLs(w io.Writer, reports []*report) error {
+	urls, _ := proxySeeds(reports)
+	for _, u := range urls {
+		if _, err := fmt.Fprintln(w, u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeZapContext writes a ZAP context file whose include regexes cover the
+// hosts and path prefixes of every http(s) deeplink.
+func writeZapContext(path string, reports []*report) error {
+	_, includes := proxySeeds(reports)
+	var packages []string
+	for _, r := range reports {
+		packages = appendUnique(packages, r.Package)
+	}
+	var context zapContext
+	context.Context.Name = "Deeeeper deeplinks"
+	context.Context.Desc = "http(s) deeplinks of " + strings.Join(packages, ", ")
+	context.Context.InScope = true
+	context.Context.IncRegexes = includes
+	output, err := xml.MarshalIndent(context, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append([]byte(xml.Header), append(output, '\n')...), 0o644)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"slices"  // Result comparison
+	"testing" // Test harness
+)
+
+// proxyManifest declares an exact host with a pathPattern, a subdomain
+// wildcard with a pathPrefix, a fully wildcard host and a custom scheme.
+const proxyManifest = `<?xml version="1.0" encoding="utf-8"?>
+<manifest xmlns:android="http://schemas.android.com/apk/res/android" package="org.example.proxy">
+    <application>
+        <activity android:name=".Web" android:exported="true">
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <category android:name="android.intent.category.BROWSABLE"/>
+                <data android:scheme="https" android:host="shop.example.com" android:pathPattern="/item/.*"/>
+            </intent-filter>
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="http" android:host="*.example.com" android:pathPrefix="/promo"/>
+            </intent-filter>
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="https" android:host="*" android:pathPrefix="/share"/>
+            </intent-filter>
+            <intent-filter>
+                <action android:name="android.intent.action.VIEW"/>
+                <data android:scheme="proxy" android:host="open"/>
+            </intent-filter>
+        </activity>
+    </application>
+</manifest>
+`
+
+func TestProxySeeds(t *testing.T) {
+	setOpts(t, textOptions())
+	urls, includes := proxySeeds([]*report{analyzeFixture(t, proxyManifest, "<resources/>")})
+	if want := []string{"http://www.example.com/promo", "https://shop.example.com/item/example"}; !slices.Equal(urls, want) {
+		t.Errorf("urls = %q, want %q", urls, want)
+	}
+	if want := []string{`http://[^/]+\.example\.com/promo.*`, `https://shop\.example\.com/item/.*`}; !slices.Equal(includes, want) {
+		t.Errorf("includes = %q, want %q", includes, want)
+	}
+}